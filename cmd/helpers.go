@@ -10,22 +10,64 @@ import (
 	"github.com/Global-Wizards/wizards-qa/pkg/ai"
 	"github.com/Global-Wizards/wizards-qa/pkg/config"
 	"github.com/Global-Wizards/wizards-qa/pkg/maestro"
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
 	"github.com/Global-Wizards/wizards-qa/pkg/util"
+	"github.com/spf13/cobra"
 )
 
+// defaultCassetteDir is where AI cassettes are kept when --ai-cassette-dir is not set.
+const defaultCassetteDir = "testdata/ai-cassettes"
+
 // loadConfig loads the configuration from the given path, returning a helpful error.
+// It also loads the model pricing file named by WIZARDS_QA_PRICING or ai.pricingFile
+// and the viewport presets file named by WIZARDS_QA_VIEWPORTS or browser.viewportsFile.
 func loadConfig(configPath string) (*config.Config, error) {
 	cfg, err := config.Load(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+	pricingPath := cfg.AI.PricingFile
+	if envPath := os.Getenv(ai.PricingEnvVar); envPath != "" {
+		pricingPath = envPath
+	}
+	if pricingPath != "" {
+		if err := ai.LoadPricingFile(pricingPath); err != nil {
+			return nil, err
+		}
+	}
+	viewportsPath := cfg.Browser.ViewportsFile
+	if envPath := os.Getenv(scout.ViewportsEnvVar); envPath != "" {
+		viewportsPath = envPath
+	}
+	if viewportsPath != "" {
+		if err := scout.LoadViewportFile(viewportsPath); err != nil {
+			return nil, err
+		}
+	}
 	return cfg, nil
 }
 
 // validateAPIKey checks that the AI API key is configured.
+// Replaying an AI cassette needs no key since no request reaches the network,
+// and self-hosted OpenAI-compatible servers (openai provider with a base URL)
+// often run without one.
 func validateAPIKey(cfg *config.Config) error {
-	if cfg.AI.APIKey == "" || cfg.AI.APIKey == "${ANTHROPIC_API_KEY}" {
-		return fmt.Errorf("AI API key not configured. Set ANTHROPIC_API_KEY environment variable or update wizards-qa.yaml")
+	if cfg.AI.Cassette == string(ai.CassetteReplay) {
+		return nil
+	}
+	baseURL := cfg.AI.BaseURL
+	if baseURL == "" {
+		baseURL = os.Getenv("OPENAI_BASE_URL")
+	}
+	if !ai.APIKeyRequired(cfg.AI.Provider, baseURL) {
+		return nil
+	}
+	envVar := ai.APIKeyEnvVar(cfg.AI.Provider)
+	if envVar == "" {
+		return fmt.Errorf("unsupported AI provider: %s (use 'anthropic', 'google' or 'openai')", cfg.AI.Provider)
+	}
+	if cfg.AI.APIKey == "" || cfg.AI.APIKey == "${"+envVar+"}" || cfg.AI.APIKey == "${ANTHROPIC_API_KEY}" {
+		return fmt.Errorf("AI API key not configured. Set %s environment variable or update wizards-qa.yaml", envVar)
 	}
 	return nil
 }
@@ -79,10 +121,20 @@ func newAnalyzer(cfg *config.Config, model string, maxTokens int, temperature fl
 	// Auto-detect provider + API key from model name (handles --model overrides)
 	provider, apiKey := detectProviderAndKey(m, cfg.AI.Provider, cfg.AI.APIKey)
 
-	analyzer, err := ai.NewAnalyzerFromConfig(provider, apiKey, m, t, mt)
+	client, err := ai.NewClientFromConfig(provider, apiKey, m, t, mt)
 	if err != nil {
 		return nil, err
 	}
+	applyBaseURL(client, cfg.AI.BaseURL)
+	client, err = withFallbacks(client, cfg, t, mt)
+	if err != nil {
+		return nil, err
+	}
+	client, err = wrapCassette(client, cfg, "primary")
+	if err != nil {
+		return nil, err
+	}
+	analyzer := ai.NewAnalyzer(client)
 
 	// Set up secondary client for synthesis/flow generation if configured
 	if cfg.AI.SynthesisModel != "" {
@@ -97,12 +149,97 @@ func newAnalyzer(cfg *config.Config, model string, maxTokens int, temperature fl
 		if synthErr != nil {
 			return nil, fmt.Errorf("failed to create synthesis client: %w", synthErr)
 		}
+		if synthProvider == cfg.AI.Provider {
+			applyBaseURL(secondaryClient, cfg.AI.BaseURL)
+		}
+		secondaryClient, synthErr = wrapCassette(secondaryClient, cfg, "synthesis")
+		if synthErr != nil {
+			return nil, synthErr
+		}
 		analyzer.SetSecondaryClient(secondaryClient)
 	}
 
 	return analyzer, nil
 }
 
+// applyBaseURL points OpenAI-compatible clients at a custom endpoint (e.g. a
+// self-hosted vLLM server). Other providers ignore the setting.
+func applyBaseURL(client ai.Client, baseURL string) {
+	if oc, ok := client.(*ai.OpenAIClient); ok && baseURL != "" {
+		oc.BaseURL = baseURL
+	}
+}
+
+// withFallbacks chains the configured ai.fallbacks behind the primary client. Each
+// entry's provider and key are detected from its model name unless set explicitly.
+func withFallbacks(primary ai.Client, cfg *config.Config, temperature float64, maxTokens int) (ai.Client, error) {
+	if len(cfg.AI.Fallbacks) == 0 {
+		return primary, nil
+	}
+	clients := []ai.Client{primary}
+	for _, fb := range cfg.AI.Fallbacks {
+		provider, apiKey := detectProviderAndKey(fb.Model, fb.Provider, fb.APIKey)
+		if fb.Provider != "" {
+			provider = fb.Provider
+		}
+		if fb.APIKey != "" {
+			apiKey = fb.APIKey
+		}
+		client, err := ai.NewClientFromConfig(provider, apiKey, fb.Model, temperature, maxTokens)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create fallback client %s: %w", fb.Model, err)
+		}
+		applyBaseURL(client, fb.BaseURL)
+		clients = append(clients, client)
+	}
+	return ai.NewFallbackClient(clients...), nil
+}
+
+// fallbackOf returns the fallback chain behind client, if any.
+func fallbackOf(client ai.Client) *ai.FallbackClient {
+	if cassette := ai.CassetteOf(client); cassette != nil {
+		return fallbackOf(cassette.Inner)
+	}
+	return ai.FallbackOf(client)
+}
+
+// wrapCassette wraps client in a record/replay cassette when --ai-cassette is set.
+// Primary and synthesis clients get separate sub-directories so their recordings
+// replay independently.
+func wrapCassette(client ai.Client, cfg *config.Config, name string) (ai.Client, error) {
+	mode, err := ai.ParseCassetteMode(cfg.AI.Cassette)
+	if err != nil || mode == "" {
+		return client, err
+	}
+	dir := cfg.AI.CassetteDir
+	if dir == "" {
+		dir = defaultCassetteDir
+	}
+	return ai.NewCassetteClient(client, filepath.Join(dir, name), mode)
+}
+
+// addCassetteFlags registers --ai-cassette and --ai-cassette-dir. Their defaults come
+// from WIZARDS_QA_AI_CASSETTE and WIZARDS_QA_AI_CASSETTE_DIR so the web backend can
+// enable recording or replay for the CLI processes it spawns.
+func addCassetteFlags(cmd *cobra.Command, mode, dir *string) {
+	cmd.Flags().StringVar(mode, "ai-cassette", os.Getenv("WIZARDS_QA_AI_CASSETTE"), "Record or replay AI responses for offline runs (record|replay)")
+	cmd.Flags().StringVar(dir, "ai-cassette-dir", os.Getenv("WIZARDS_QA_AI_CASSETTE_DIR"), "Directory for recorded AI responses (default "+defaultCassetteDir+")")
+}
+
+// applyCassetteFlags copies the cassette flags into the AI config when set.
+func applyCassetteFlags(cfg *config.Config, mode, dir string) error {
+	if _, err := ai.ParseCassetteMode(mode); err != nil {
+		return err
+	}
+	if mode != "" {
+		cfg.AI.Cassette = mode
+	}
+	if dir != "" {
+		cfg.AI.CassetteDir = dir
+	}
+	return nil
+}
+
 // deriveGameName extracts a game name from a URL, falling back to "game".
 func deriveGameName(gameURL string) string {
 	gameName := filepath.Base(filepath.Dir(gameURL))
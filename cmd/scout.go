@@ -45,6 +45,25 @@ func newScoutCmd() *cobra.Command {
 		viewport         string
 		synthesisModel   string
 		noNavMap         bool
+		cassetteMode     string
+		cassetteDir      string
+		budgetUSD        float64
+		budgetTokens     int
+		thinkingBudget   int
+		contextTokens    int
+		aiContextSummary bool
+		probesPath       string
+		harPath          string
+		storageStatePath string
+		viewportsPath    string
+		networkName      string
+		networkLatency   float64
+		networkDown      float64
+		networkUp        float64
+		randomSeed       uint32
+		locale           string
+		timezone         string
+		geolocation      string
 	)
 
 	cmd := &cobra.Command{
@@ -72,16 +91,80 @@ Example:
 			if err != nil {
 				return err
 			}
+			if err := applyCassetteFlags(cfg, cassetteMode, cassetteDir); err != nil {
+				return err
+			}
+			if thinkingBudget != 0 && (thinkingBudget < 1024 || thinkingBudget > 64000) {
+				return fmt.Errorf("--thinking-budget must be 0 (off) or between 1024 and 64000, got %d", thinkingBudget)
+			}
+			var probes ai.GameStateProbes
+			if probesPath != "" {
+				if probes, err = ai.LoadGameStateProbes(probesPath); err != nil {
+					return err
+				}
+			}
+
+			var initialStorage *scout.StorageState
+			if storageStatePath != "" {
+				if initialStorage, err = scout.LoadStorageState(storageStatePath); err != nil {
+					return err
+				}
+			}
+
+			var network *scout.NetworkProfile
+			if networkName != "" {
+				np, err := scout.ResolveNetworkProfile(networkName, networkLatency, networkDown, networkUp)
+				if err != nil {
+					return fmt.Errorf("--network: %w", err)
+				}
+				if !np.IsOnline() {
+					network = &np
+				}
+			}
+
+			var seed *uint32
+			if cmd.Flags().Changed("seed") {
+				seed = &randomSeed
+			}
+
+			if locale != "" {
+				if locale, err = scout.NormalizeLocale(locale); err != nil {
+					return fmt.Errorf("--locale: %w", err)
+				}
+			}
+			if timezone != "" {
+				if err := scout.ValidateTimezone(timezone); err != nil {
+					return fmt.Errorf("--timezone: %w", err)
+				}
+			}
+			var geo *scout.Geolocation
+			if geolocation != "" {
+				if geo, err = scout.ParseGeolocation(geolocation); err != nil {
+					return fmt.Errorf("--geolocation: %w", err)
+				}
+			}
 
 			// Resolve viewport preset
 			// Default to smaller viewport in agent mode for SwiftShader performance
 			if viewport == "" && agentMode {
 				viewport = "agent-default"
 			}
+			var projectViewports []scout.ViewportPreset
+			if viewportsPath != "" {
+				data, err := os.ReadFile(viewportsPath)
+				if err != nil {
+					return fmt.Errorf("--viewports: %w", err)
+				}
+				if projectViewports, err = scout.ParseViewportPresets(data); err != nil {
+					return fmt.Errorf("--viewports: %w", err)
+				}
+			}
 			var viewportDPR float64
-			var viewportCategory string
+			var viewportCategory, viewportUserAgent string
+			var viewportTouch bool
+			var viewportPreset scout.ViewportPreset
 			if viewport != "" {
-				vp := scout.GetViewportByName(viewport)
+				vp := scout.GetViewportByName(viewport, projectViewports...)
 				if vp == nil {
 					return fmt.Errorf("unknown viewport preset: %q (use e.g. desktop-std, iphone-16-pro, samsung-s24)", viewport)
 				}
@@ -89,6 +172,9 @@ Example:
 				cfg.Browser.Viewport.Height = vp.Height
 				viewportDPR = vp.DevicePixelRatio
 				viewportCategory = vp.Category
+				viewportUserAgent = vp.UserAgent
+				viewportTouch = vp.Touch
+				viewportPreset = *vp
 			}
 
 			if !jsonOutput {
@@ -111,6 +197,10 @@ Example:
 						Height:           cfg.Browser.Viewport.Height,
 						DevicePixelRatio: viewportDPR,
 						Timeout:          timeoutDur,
+						UserAgent:        viewportUserAgent,
+						Locale:           locale,
+						Timezone:         timezone,
+						Geolocation:      geo,
 					})
 				} else {
 					pageMeta, err = scout.ScoutURL(ctx, gameURL, timeoutDur)
@@ -132,6 +222,10 @@ Example:
 						Height:           cfg.Browser.Viewport.Height,
 						DevicePixelRatio: viewportDPR,
 						Timeout:          timeoutDur,
+						UserAgent:        viewportUserAgent,
+						Locale:           locale,
+						Timezone:         timezone,
+						Geolocation:      geo,
 					})
 					if headlessErr == nil {
 						pageMeta = headlessMeta
@@ -189,6 +283,10 @@ Example:
 				GLI:              !noGLI,
 				GLIJurisdictions: parsedGLIJurisdictions,
 				NavigationMap:    !noNavMap,
+				Locale:           locale,
+			}
+			if geo != nil {
+				modules.Geolocation = fmt.Sprintf("%g,%g", geo.Latitude, geo.Longitude)
 			}
 
 			// Emit scouting progress for --json mode
@@ -226,6 +324,16 @@ Example:
 					fmt.Fprintf(os.Stderr, "PROGRESS:%s:%s\n", step, message)
 				}
 			}
+			if fc := fallbackOf(analyzer.Client); fc != nil {
+				fc.OnFailover = func(from, to string, err error) {
+					msg := fmt.Sprintf("%s unavailable (%v), switching to %s", from, err, to)
+					if onProgress != nil {
+						onProgress("ai_failover", msg)
+					} else {
+						fmt.Printf("   %s %s\n", util.EmojiWarning, msg)
+					}
+				}
+			}
 
 			var result *ai.AnalysisResult
 			var flows []*ai.MaestroFlow
@@ -284,11 +392,27 @@ Example:
 					DevicePixelRatio: agentDPR,
 					Timeout:          timeoutDur,
 					DeviceCategory:   viewportCategory,
+					Touch:            viewportTouch,
+					UserAgent:        viewportUserAgent,
+					Network:          network,
+					InitialStorage:   initialStorage,
+					RandomSeed:       seed,
+					Locale:           locale,
+					Timezone:         timezone,
+					Geolocation:      geo,
 				})
 				if agentErr != nil {
 					return fmt.Errorf("agent scout failed: %w", agentErr)
 				}
 				defer cleanup()
+				if harPath != "" {
+					// Registered after cleanup so it runs first, while the page is still open
+					defer func() {
+						if err := browserPage.SaveHAR(harPath); err != nil {
+							fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+						}
+					}()
+				}
 
 				// Use the agent-scouted pageMeta (has initial screenshot)
 				pageMeta = agentPageMeta
@@ -347,6 +471,13 @@ Example:
 					MaxTotalTimeout:     time.Duration(maxTotalTimeout) * time.Minute,
 					ViewportWidth:       cfg.Browser.Viewport.Width,
 					ViewportHeight:      cfg.Browser.Viewport.Height,
+					Budget:              ai.SpendBudget{USD: budgetUSD, Tokens: budgetTokens},
+					ThinkingBudget:      thinkingBudget,
+					ContextMaxTokens:    contextTokens,
+					ContextSummaryAI:    aiContextSummary,
+					TouchInput:          browserPage.TouchEnabled(),
+					Viewport:            viewportPreset,
+					Probes:              probes,
 				}
 
 				// When launched by the backend (--json + --agent), read user hints from stdin
@@ -416,6 +547,9 @@ Example:
 				fmt.Printf("   Mechanics: %d\n", len(result.Mechanics))
 				fmt.Printf("   UI Elements: %d\n", len(result.UIElements))
 				fmt.Printf("   User Flows: %d\n\n", len(result.UserFlows))
+				if result.BudgetExhausted {
+					fmt.Printf("%s Exploration stopped early: spend budget exhausted\n\n", util.EmojiWarning)
+				}
 				fmt.Printf("%s Generating %d test flow(s)...\n", util.EmojiHammer, len(flows))
 			}
 
@@ -490,8 +624,26 @@ Example:
 	cmd.Flags().StringVar(&resumeFrom, "resume-from", "", "Resume from checkpoint step (internal)")
 	cmd.Flags().StringVar(&resumeDataPath, "resume-data", "", "Path to checkpoint data file (internal)")
 	cmd.Flags().StringVar(&viewport, "viewport", "", "Device viewport preset (e.g. desktop-std, iphone-16-pro, samsung-s24)")
+	cmd.Flags().StringVar(&viewportsPath, "viewports", "", "YAML/JSON file of project viewport presets consulted before the configured and built-in ones")
 	cmd.Flags().StringVar(&synthesisModel, "synthesis-model", "", "Secondary model for synthesis/flow generation (e.g. gemini-3-flash-preview)")
 	cmd.Flags().BoolVar(&noNavMap, "no-nav-map", false, "Disable navigation map generation")
+	cmd.Flags().Float64Var(&budgetUSD, "budget-usd", 0, "Hard spend cap in USD for agent exploration (0 = unlimited)")
+	cmd.Flags().IntVar(&budgetTokens, "budget-tokens", 0, "Hard token cap for agent exploration (0 = unlimited)")
+	cmd.Flags().IntVar(&contextTokens, "context-max-tokens", 0, "Estimated history tokens at which older agent turns are summarised (0 = default 120000, -1 = never)")
+	cmd.Flags().BoolVar(&aiContextSummary, "ai-context-summary", false, "Summarise older agent turns with the AI instead of rule-based digests")
+	cmd.Flags().IntVar(&thinkingBudget, "thinking-budget", 0, "Extended thinking budget tokens per agent step (0 = off, 1024-64000; Claude only)")
+	cmd.Flags().StringVar(&probesPath, "probes", "", "JSON file of game-state probes (name → JS getter) offered to the agent as read_game_state")
+	cmd.Flags().StringVar(&storageStatePath, "storage-state", "", "JSON file of localStorage, sessionStorage, cookies and IndexedDB seeded before the game loads in agent mode")
+	cmd.Flags().StringVar(&harPath, "har", "", "Write the agent session's network traffic to this HAR file")
+	cmd.Flags().StringVar(&networkName, "network", "", "Emulated connection in agent mode: online, offline, slow-3g, fast-3g or custom")
+	cmd.Flags().Float64Var(&networkLatency, "network-latency", 0, "Latency in ms added to every request with --network custom")
+	cmd.Flags().Float64Var(&networkDown, "network-down", 0, "Download limit in kbps with --network custom (0 = unlimited)")
+	cmd.Flags().Float64Var(&networkUp, "network-up", 0, "Upload limit in kbps with --network custom (0 = unlimited)")
+	cmd.Flags().StringVar(&locale, "locale", "", "Browser locale for Accept-Language, navigator.language and Intl (e.g. de-DE); the wording check expects text in it")
+	cmd.Flags().StringVar(&timezone, "timezone", "", "IANA timezone the browser reports (e.g. Europe/Berlin)")
+	cmd.Flags().StringVar(&geolocation, "geolocation", "", "Position reported to navigator.geolocation as latitude,longitude[,accuracy meters]")
+	cmd.Flags().Uint32Var(&randomSeed, "seed", 0, "Seed Math.random and crypto.getRandomValues in agent mode so a run can be replayed (default: browser randomness)")
+	addCassetteFlags(cmd, &cassetteMode, &cassetteDir)
 
 	cmd.MarkFlagRequired("game")
 
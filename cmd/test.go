@@ -14,11 +14,13 @@ import (
 
 func newTestCmd() *cobra.Command {
 	var (
-		gameURL    string
-		specFile   string
-		output     string
-		browser    string
-		configPath string
+		gameURL      string
+		specFile     string
+		output       string
+		browser      string
+		configPath   string
+		cassetteMode string
+		cassetteDir  string
 	)
 
 	cmd := &cobra.Command{
@@ -48,6 +50,9 @@ Example:
 			if err != nil {
 				return err
 			}
+			if err := applyCassetteFlags(cfg, cassetteMode, cassetteDir); err != nil {
+				return err
+			}
 
 			if browser != "" {
 				cfg.Maestro.Browser = browser
@@ -130,6 +135,7 @@ Example:
 	cmd.Flags().StringVarP(&output, "output", "o", "./flows", "Output directory for generated flows")
 	cmd.Flags().StringVarP(&browser, "browser", "b", "", "Browser to use (overrides config)")
 	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Config file path")
+	addCassetteFlags(cmd, &cassetteMode, &cassetteDir)
 
 	cmd.MarkFlagRequired("game")
 	cmd.MarkFlagRequired("spec")
@@ -9,6 +9,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -35,7 +36,7 @@ func (a *Analyzer) AgentExplore(
 
 	agent, ok := a.Client.(ToolUseAgent)
 	if !ok {
-		return nil, nil, fmt.Errorf("AI client does not support tool use (agent mode requires Claude)")
+		return nil, nil, fmt.Errorf("AI client does not support tool use (agent mode requires Claude, Gemini or OpenAI)")
 	}
 
 	model := ""
@@ -44,9 +45,20 @@ func (a *Analyzer) AgentExplore(
 	}
 
 	tools := AgentTools(cfg)
-	executor := &BrowserToolExecutor{Page: browserPage}
+	executor := &BrowserToolExecutor{Page: browserPage, Probes: cfg.Probes, Viewport: cfg.Viewport}
+	if pageMeta != nil {
+		executor.Framework, executor.JSGlobals = pageMeta.Framework, pageMeta.JSGlobals
+	}
 	systemPrompt := BuildAgentSystemPrompt(cfg)
 
+	// Extended thinking applies to exploration calls only; synthesis runs without it.
+	restoreThinking := enableThinking(a.Client, cfg.ThinkingBudget)
+	defer restoreThinking()
+	// Stream every exploration step so the model's reasoning shows up while it thinks
+	stream := newStreamProgress(onProgress)
+	stream.exploring = true
+	defer streamTo(a.Client, stream.onStream)()
+
 	progress("agent_start", fmt.Sprintf("Starting agent exploration of %s (max %d steps)", gameURL, cfg.MaxSteps))
 
 	// Take initial screenshot
@@ -117,6 +129,32 @@ When done exploring, include EXPLORATION_COMPLETE in your response.`, gameURL, s
 	var allScreenshots []string
 	allScreenshots = append(allScreenshots, initialScreenshot)
 
+	// Spend so far, checked against cfg.Budget after every AI call
+	var runUsage TokenUsage
+	budgetExhausted := false
+
+	// Summarise older turns once the history outgrows the context window
+	contextMgr := newContextManager(cfg.ContextMaxTokens)
+	if cfg.ContextSummaryAI {
+		contextMgr.summarize = func(ctx context.Context, prompt string) (string, error) {
+			sumCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+			defer cancel()
+			// The summary is bookkeeping, not exploration output: don't stream it
+			defer streamTo(a.Client, nil)()
+			before := a.Usage
+			text, err := a.Client.Generate(sumCtx, prompt, nil)
+			// Count the summary call against the spend budget like exploration calls,
+			// priced for the model that answered (a fallback chain may have switched)
+			sumModel := model
+			if a.Usage.APICallCount > before.APICallCount && a.Usage.Model != "" {
+				sumModel = a.Usage.Model
+			}
+			runUsage.AddForModel(sumModel, a.Usage.InputTokens-before.InputTokens, a.Usage.OutputTokens-before.OutputTokens,
+				a.Usage.CacheCreationInputTokens-before.CacheCreationInputTokens, a.Usage.CacheReadInputTokens-before.CacheReadInputTokens)
+			return text, err
+		}
+	}
+
 	totalStart := time.Now()
 
 	// Reserve time for synthesis + flow generation (with retries) so exploration can't starve them
@@ -199,12 +237,30 @@ When done exploring, include EXPLORATION_COMPLETE in your response.`, gameURL, s
 			OutputTokens:             resp.Usage.OutputTokens,
 			CacheCreationInputTokens: resp.Usage.CacheCreationInputTokens,
 			CacheReadInputTokens:     resp.Usage.CacheReadInputTokens,
+			ThinkingTokens:           resp.Usage.ThinkingTokens,
+		}
+		stepModel := model
+		if resp.Model != "" {
+			stepModel = resp.Model // a fallback chain may have answered with another model
 		}
-		stepCostUSD := stepUsage.EstimatedCost(model)
+		stepCostUSD := stepUsage.EstimatedCost(stepModel)
 		stepCredits := int(math.Ceil(stepCostUSD * 100))
 		tokensEmittedThisIteration := false
 
-		// Append assistant response to messages
+		// Enforce the hard spend budget: drop this response (its tools never run) and go
+		// straight to synthesis with what was gathered so far.
+		runUsage.AddForModel(stepModel, stepUsage.InputTokens, stepUsage.OutputTokens,
+			stepUsage.CacheCreationInputTokens, stepUsage.CacheReadInputTokens)
+		runUsage.AddThinking(stepModel, stepUsage.ThinkingTokens)
+		a.Usage.AddThinking(stepModel, stepUsage.ThinkingTokens)
+		if exhausted, reason := cfg.Budget.Exceeded(&runUsage, stepModel); exhausted {
+			budgetExhausted = true
+			progress("budget_exhausted", fmt.Sprintf("Step %d: %s, stopping exploration", step, reason))
+			break
+		}
+
+		// Append assistant response to messages. Thinking blocks stay in as returned:
+		// the API rejects tool results whose preceding turn lost its thinking.
 		messages = append(messages, AgentMessage{Role: "assistant", Content: resp.Content})
 
 		// Emit AI reasoning text for live streaming
@@ -213,6 +269,7 @@ When done exploring, include EXPLORATION_COMPLETE in your response.`, gameURL, s
 				progress("agent_reasoning", block.Text)
 			}
 		}
+		reasoning := stepReasoning(resp.Content)
 
 		// Check for EXPLORATION_COMPLETE in text blocks
 		explorationComplete := false
@@ -246,6 +303,7 @@ When done exploring, include EXPLORATION_COMPLETE in your response.`, gameURL, s
 
 		// Execute each tool_use block
 		var toolResults []interface{}
+		firstStep := len(steps)
 		for _, block := range resp.Content {
 			if block.Type != "tool_use" {
 				continue
@@ -307,7 +365,9 @@ When done exploring, include EXPLORATION_COMPLETE in your response.`, gameURL, s
 					detail["outputTokens"] = stepUsage.OutputTokens
 					detail["cacheReadTokens"] = stepUsage.CacheReadInputTokens
 					detail["cacheCreateTokens"] = stepUsage.CacheCreationInputTokens
+					detail["thinkingTokens"] = stepUsage.ThinkingTokens
 					detail["credits"] = stepCredits
+					detail["reasoning"] = reasoning
 					tokensEmittedThisIteration = true
 				}
 				if detailJSON, jsonErr := json.Marshal(detail); jsonErr == nil {
@@ -379,7 +439,9 @@ When done exploring, include EXPLORATION_COMPLETE in your response.`, gameURL, s
 					detail["outputTokens"] = stepUsage.OutputTokens
 					detail["cacheReadTokens"] = stepUsage.CacheReadInputTokens
 					detail["cacheCreateTokens"] = stepUsage.CacheCreationInputTokens
+					detail["thinkingTokens"] = stepUsage.ThinkingTokens
 					detail["credits"] = stepCredits
+					detail["reasoning"] = reasoning
 					tokensEmittedThisIteration = true
 				}
 				if detailJSON, jsonErr := json.Marshal(detail); jsonErr == nil {
@@ -449,6 +511,12 @@ When done exploring, include EXPLORATION_COMPLETE in your response.`, gameURL, s
 			}
 
 			stepRecord.DurationMs = int(time.Since(toolStart).Milliseconds())
+			if len(cfg.Probes) > 0 {
+				// Capture the game state after every action so each step can be audited
+				if state, stateErr := ReadGameState(browserPage, cfg.Probes, nil); stateErr == nil {
+					stepRecord.GameState = state.Values
+				}
+			}
 			steps = append(steps, stepRecord)
 
 			// Write screenshot to tmpDir for live streaming
@@ -476,12 +544,17 @@ When done exploring, include EXPLORATION_COMPLETE in your response.`, gameURL, s
 				"durationMs": stepRecord.DurationMs,
 				"thinkingMs": thinkingMs,
 			}
+			if stepRecord.GameState != nil {
+				detail["gameState"] = stepRecord.GameState
+			}
 			if !tokensEmittedThisIteration {
 				detail["inputTokens"] = stepUsage.InputTokens
 				detail["outputTokens"] = stepUsage.OutputTokens
 				detail["cacheReadTokens"] = stepUsage.CacheReadInputTokens
 				detail["cacheCreateTokens"] = stepUsage.CacheCreationInputTokens
+				detail["thinkingTokens"] = stepUsage.ThinkingTokens
 				detail["credits"] = stepCredits
+				detail["reasoning"] = reasoning
 				tokensEmittedThisIteration = true
 			}
 			if detailJSON, jsonErr := json.Marshal(detail); jsonErr == nil {
@@ -495,6 +568,11 @@ When done exploring, include EXPLORATION_COMPLETE in your response.`, gameURL, s
 		// earlier in this loop, so stripping here only affects the LLM context.
 		StripIntermediateScreenshots(toolResults)
 
+		// Attribute the response's reasoning to the first step it produced
+		if firstStep < len(steps) {
+			steps[firstStep].Reasoning = reasoning
+		}
+
 		// Append tool results as a user message
 		messages = append(messages, AgentMessage{Role: "user", Content: toolResults})
 
@@ -502,9 +580,18 @@ When done exploring, include EXPLORATION_COMPLETE in your response.`, gameURL, s
 		// Each base64 screenshot is ~100-200KB; without pruning, API calls escalate from
 		// ~10s to 70s+ as screenshots accumulate, consuming the entire timeout budget.
 		PruneOldScreenshots(messages, 3)
+
+		// Screenshots alone don't bound the history on long adaptive runs
+		before := estimateHistoryTokens(messages)
+		if compacted, dropped := contextMgr.compact(ctx, messages); dropped > 0 {
+			messages = compacted
+			progress("agent_context_compact", fmt.Sprintf("Step %d: summarised %d older messages to free context (~%dK -> ~%dK tokens)",
+				step, dropped, before/1000, estimateHistoryTokens(messages)/1000))
+		}
 	}
 
 	progress("agent_done", fmt.Sprintf("Agent exploration complete: %d steps, %d screenshots", len(steps), len(allScreenshots)))
+	restoreThinking()
 
 	// Strip ALL screenshots before synthesis — the AI already observed them during
 	// exploration and doesn't need them for structured JSON output. This reduces
@@ -514,11 +601,19 @@ When done exploring, include EXPLORATION_COMPLETE in your response.`, gameURL, s
 	// Emit synthesis progress BEFORE the call so UI updates immediately
 	progress("agent_synthesize", "Synthesizing analysis from exploration...")
 
+	// Performance is measured, not judged by the model: the report is attached
+	// to the result as is and only summarised for synthesis
+	session, _ := browserPage.PerformanceSession()
+	perfReport := EvaluatePerformance(cfg.Viewport, session, executor.perf)
+
 	// --- Synthesis call ---
-	parsed, synthErr := a.synthesizeFromExploration(ctx, pageMeta, gameURL, steps, messages, modules, cfg, onProgress)
+	observed := joinSections(failedRequestsSection(browserPage), performanceSection(perfReport))
+	parsed, synthErr := a.synthesizeFromExploration(ctx, pageMeta, gameURL, steps, messages, modules, cfg, observed, onProgress)
 	if synthErr != nil {
 		return nil, steps, synthErr
 	}
+	parsed.BudgetExhausted = budgetExhausted
+	parsed.Performance = perfReport
 
 	return parsed, steps, nil
 }
@@ -526,6 +621,8 @@ When done exploring, include EXPLORATION_COMPLETE in your response.`, gameURL, s
 // synthesizeFromExploration runs the synthesis LLM call on exploration results and parses the JSON output.
 // It accepts either the full message history (messages) or reconstructs context from steps alone.
 // When messages is nil (e.g. resume path), it builds plaintext from steps via flattenStepsForSynthesis.
+// observed holds sections measured during exploration (failed requests, performance
+// issues); it may be empty and is prepended to the synthesis prompt.
 func (a *Analyzer) synthesizeFromExploration(
 	ctx context.Context,
 	pageMeta *scout.PageMeta,
@@ -534,6 +631,7 @@ func (a *Analyzer) synthesizeFromExploration(
 	messages []AgentMessage,
 	modules AnalysisModules,
 	cfg AgentConfig,
+	observed string,
 	onProgress ProgressFunc,
 ) (*ComprehensiveAnalysisResult, error) {
 	progress := func(step, message string) {
@@ -549,20 +647,21 @@ func (a *Analyzer) synthesizeFromExploration(
 	defer synthCancel()
 
 	synthesisPrompt := BuildSynthesisPrompt(modules)
+	if observed != "" {
+		synthesisPrompt = observed + "\n\n" + synthesisPrompt
+	}
 	synthClient := a.synthesisClient()
 
 	// Ensure synthesis has enough token budget for full JSON output.
-	if bc := baseClientOf(synthClient); bc != nil {
-		minTokens := 16384
-		if cfg.SynthesisMaxTokens > minTokens {
-			minTokens = cfg.SynthesisMaxTokens
-		}
-		if bc.MaxTokens < minTokens {
-			origMaxTokens := bc.MaxTokens
-			bc.MaxTokens = minTokens
-			defer func() { bc.MaxTokens = origMaxTokens }()
-		}
+	minTokens := 16384
+	if cfg.SynthesisMaxTokens > minTokens {
+		minTokens = cfg.SynthesisMaxTokens
 	}
+	defer raiseMaxTokens(synthClient, minTokens)()
+	// Stream the synthesis so a long response shows live progress and a max_tokens
+	// cut-off is reported as soon as the API signals it.
+	stream := newStreamProgress(onProgress)
+	defer streamTo(synthClient, stream.onStream)()
 
 	retryCfg := &retry.Config{
 		MaxAttempts:  3,
@@ -571,12 +670,38 @@ func (a *Analyzer) synthesizeFromExploration(
 		Multiplier:   2.0,
 	}
 
+	// Branch: if synthesis client supports tool use AND is the primary client AND we have messages,
+	// continue the conversation; otherwise send the exploration as plaintext.
+	synthAgent, isToolUse := synthClient.(ToolUseAgent)
+	useHistory := isToolUse && synthClient == a.Client && messages != nil
+	if useHistory {
+		// Synthesis runs without extended thinking, so drop the exploration's thinking blocks
+		messages = withoutThinking(messages)
+	}
+
+	// Prefer native structured output, which constrains the response to the schema
+	// instead of scraping JSON out of free text.
+	if structured, ok := synthClient.(StructuredOutputAgent); ok {
+		var systemPrompt string
+		var synthMessages []AgentMessage
+		if useHistory {
+			systemPrompt = AgentSystemPrompt
+			synthMessages = append(messages[:len(messages):len(messages)], AgentMessage{Role: "user", Content: synthesisPrompt})
+		} else {
+			synthMessages = []AgentMessage{{Role: "user", Content: explorationForSynthesis(messages, steps) + "\n\n" + synthesisPrompt}}
+		}
+		parsed, err := a.structuredSynthesis(synthCtx, structured, systemPrompt, synthMessages, modules, retryCfg, progress)
+		if err == nil {
+			return parsed, nil
+		}
+		log.Printf("Structured synthesis failed, falling back to free-text JSON: %v", err)
+		progress("synthesis_fallback", "Structured output unavailable, requesting synthesis as plain JSON...")
+	}
+
 	var synthesisText string
 	var stopReason string
 
-	// Branch: if synthesis client supports tool use AND is the primary client AND we have messages, use CallWithTools
-	synthAgent, isToolUse := synthClient.(ToolUseAgent)
-	if isToolUse && synthClient == a.Client && messages != nil {
+	if useHistory {
 		messages = append(messages, AgentMessage{Role: "user", Content: synthesisPrompt})
 
 		var synthResp *ToolUseResponse
@@ -604,18 +729,7 @@ func (a *Analyzer) synthesizeFromExploration(
 			}
 		}
 	} else {
-		// Flatten to plaintext — either from messages or from saved steps
-		var explorationHistory string
-		if messages != nil {
-			explorationHistory = flattenMessagesForSynthesis(messages)
-		} else {
-			explorationHistory = flattenStepsForSynthesis(steps)
-		}
-		// Cap exploration text to ~100K chars (~25K tokens) to stay within Gemini limits
-		if len(explorationHistory) > 100000 {
-			explorationHistory = explorationHistory[:100000] + "\n[... exploration truncated for synthesis ...]"
-		}
-		fullPrompt := explorationHistory + "\n\n" + synthesisPrompt
+		fullPrompt := explorationForSynthesis(messages, steps) + "\n\n" + synthesisPrompt
 
 		synthAttempt := 0
 		synthErr := retry.DoWithRetryable(synthCtx, retryCfg, IsRetryableAPIError, func() error {
@@ -631,12 +745,25 @@ func (a *Analyzer) synthesizeFromExploration(
 			return nil, fmt.Errorf("synthesis call failed: %w", synthErr)
 		}
 		stopReason = "end_turn"
+		if stream.stopReason != "" {
+			stopReason = stream.stopReason
+		}
+		if stopReason == "max_tokens" {
+			log.Printf("WARNING: Synthesis truncated (stop_reason=max_tokens, %d chars)", len(synthesisText))
+		}
 	}
 
 	if strings.TrimSpace(synthesisText) == "" {
 		return nil, fmt.Errorf("synthesis returned empty response (stop_reason=%s)", stopReason)
 	}
 
+	// A response cut off at max_tokens can't parse as-is; close it up before the first attempt
+	if stopReason == "max_tokens" {
+		if repaired, repairErr := repairTruncatedJSON(synthesisText); repairErr == nil {
+			synthesisText = repaired
+		}
+	}
+
 	// Parse as ComprehensiveAnalysisResult
 	parsed, parseErr := parseComprehensiveJSON(synthesisText)
 	if parseErr != nil {
@@ -676,6 +803,83 @@ func (a *Analyzer) synthesizeFromExploration(
 	return parsed, nil
 }
 
+// structuredSynthesis requests the synthesis through StructuredOutputAgent and
+// validates it against the schema. A response with validation problems gets one
+// repair round: the problems are sent back and the corrected document is used.
+func (a *Analyzer) structuredSynthesis(
+	ctx context.Context,
+	agent StructuredOutputAgent,
+	systemPrompt string,
+	messages []AgentMessage,
+	modules AnalysisModules,
+	retryCfg *retry.Config,
+	progress ProgressFunc,
+) (*ComprehensiveAnalysisResult, error) {
+	schema := SynthesisSchema(modules)
+	const repairRounds = 1
+
+	for round := 0; ; round++ {
+		var doc string
+		err := retry.DoWithRetryable(ctx, retryCfg, IsRetryableAPIError, func() error {
+			var err error
+			doc, err = agent.GenerateStructured(ctx, systemPrompt, messages, schema)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !json.Valid([]byte(doc)) {
+			// Cut off at max_tokens: close the open strings and brackets
+			if repaired, repairErr := repairTruncatedJSON(doc); repairErr == nil {
+				doc = repaired
+			}
+		}
+
+		var generic interface{}
+		if err := json.Unmarshal([]byte(doc), &generic); err != nil {
+			return nil, fmt.Errorf("structured output is not valid JSON: %w", err)
+		}
+		problems := validateJSONSchema(generic, schema.Schema)
+		if len(problems) > 0 && round < repairRounds {
+			log.Printf("Structured synthesis: %d schema problems, requesting repair: %s", len(problems), strings.Join(problems, "; "))
+			progress("synthesis_repair", fmt.Sprintf("Synthesis output had %d schema problems, asking the AI to correct them...", len(problems)))
+			messages = append(messages[:len(messages):len(messages)],
+				AgentMessage{Role: "assistant", Content: doc},
+				AgentMessage{Role: "user", Content: schemaRepairPrompt(problems)},
+			)
+			continue
+		}
+		if len(problems) > 0 {
+			log.Printf("Structured synthesis: keeping output with %d schema problems after repair: %s", len(problems), strings.Join(problems, "; "))
+		}
+
+		var result ComprehensiveAnalysisResult
+		if err := json.Unmarshal([]byte(doc), &result); err != nil {
+			return nil, fmt.Errorf("structured output does not match the analysis: %w", err)
+		}
+		if len(result.Mechanics) == 0 {
+			return nil, fmt.Errorf("structured output has no mechanics")
+		}
+		return &result, nil
+	}
+}
+
+// explorationForSynthesis flattens the exploration to plaintext, from the message
+// history when available or else from saved steps, capped to ~100K chars (~25K tokens)
+// to stay within Gemini limits.
+func explorationForSynthesis(messages []AgentMessage, steps []AgentStep) string {
+	var explorationHistory string
+	if messages != nil {
+		explorationHistory = flattenMessagesForSynthesis(messages)
+	} else {
+		explorationHistory = flattenStepsForSynthesis(steps)
+	}
+	if len(explorationHistory) > 100000 {
+		explorationHistory = explorationHistory[:100000] + "\n[... exploration truncated for synthesis ...]"
+	}
+	return explorationHistory
+}
+
 // flattenStepsForSynthesis converts saved AgentStep data to plaintext for synthesis.
 // This is the resume-path equivalent of flattenMessagesForSynthesis.
 func flattenStepsForSynthesis(steps []AgentStep) string {
@@ -739,7 +943,7 @@ func (a *Analyzer) AnalyzeFromURLWithAgent(
 		if err := json.Unmarshal(opts.resumeData.AgentSteps, &savedSteps); err == nil {
 			progress("agent_done", fmt.Sprintf("Resumed from exploration checkpoint — %d steps, re-running synthesis", len(savedSteps)))
 
-			comprehensiveResult, synthErr := a.synthesizeFromExploration(ctx, pageMeta, gameURL, savedSteps, nil, modules, agentCfg, onProgress)
+			comprehensiveResult, synthErr := a.synthesizeFromExploration(ctx, pageMeta, gameURL, savedSteps, nil, modules, agentCfg, "", onProgress)
 			if synthErr != nil {
 				return pageMeta, nil, nil, savedSteps, fmt.Errorf("synthesis failed on resume: %w", synthErr)
 			}
@@ -827,6 +1031,64 @@ func formatToolAction(toolName string, inputJSON json.RawMessage) string {
 		var p struct{ X, Y int }
 		json.Unmarshal(inputJSON, &p)
 		return fmt.Sprintf("click at (%d, %d)", p.X, p.Y)
+	case "hold_keys":
+		var p struct{ Keys []holdKeyStep }
+		json.Unmarshal(inputJSON, &p)
+		parts := make([]string, 0, len(p.Keys))
+		for _, k := range p.Keys {
+			if k.DurationMs == 0 {
+				k.DurationMs = 100
+			}
+			parts = append(parts, fmt.Sprintf("%s %d-%dms", k.Key, k.AtMs, k.AtMs+k.DurationMs))
+		}
+		return "hold " + strings.Join(parts, ", ")
+	case "hover":
+		var p struct{ X, Y int }
+		json.Unmarshal(inputJSON, &p)
+		return fmt.Sprintf("hover at (%d, %d)", p.X, p.Y)
+	case "drag":
+		var p struct {
+			FromX int `json:"from_x"`
+			FromY int `json:"from_y"`
+			ToX   int `json:"to_x"`
+			ToY   int `json:"to_y"`
+		}
+		json.Unmarshal(inputJSON, &p)
+		return fmt.Sprintf("drag (%d, %d) → (%d, %d)", p.FromX, p.FromY, p.ToX, p.ToY)
+	case "swipe":
+		var p struct {
+			X, Y      int
+			Direction string
+		}
+		json.Unmarshal(inputJSON, &p)
+		return fmt.Sprintf("swipe %s from (%d, %d)", p.Direction, p.X, p.Y)
+	case "long_press":
+		var p struct{ X, Y int }
+		json.Unmarshal(inputJSON, &p)
+		return fmt.Sprintf("long press at (%d, %d)", p.X, p.Y)
+	case "touch_gesture":
+		var p struct {
+			Gesture      string
+			Scale, Angle float64
+			DX, DY       int
+		}
+		json.Unmarshal(inputJSON, &p)
+		switch p.Gesture {
+		case "pinch":
+			return fmt.Sprintf("pinch x%.2g", p.Scale)
+		case "rotate":
+			return fmt.Sprintf("rotate %.0f°", p.Angle)
+		case "pan":
+			return fmt.Sprintf("two-finger pan (%+d, %+d)", p.DX, p.DY)
+		}
+		return "touch gesture " + p.Gesture
+	case "rotate_device":
+		var p struct{ Orientation string }
+		json.Unmarshal(inputJSON, &p)
+		if p.Orientation == "" {
+			return "rotate device"
+		}
+		return "rotate to " + p.Orientation
 	case "type_text":
 		var p struct{ Text string }
 		json.Unmarshal(inputJSON, &p)
@@ -854,8 +1116,121 @@ func formatToolAction(toolName string, inputJSON json.RawMessage) string {
 		return fmt.Sprintf("wait %dms", p.Milliseconds)
 	case "get_page_info":
 		return "get page info"
+	case "read_game_state":
+		var p struct{ Probes []string }
+		json.Unmarshal(inputJSON, &p)
+		if len(p.Probes) == 0 {
+			return "read game state"
+		}
+		return "read game state: " + strings.Join(p.Probes, ", ")
 	case "console_logs":
 		return "get console logs"
+	case "network_log":
+		var p struct {
+			FailedOnly  bool   `json:"failed_only"`
+			URLContains string `json:"url_contains"`
+		}
+		json.Unmarshal(inputJSON, &p)
+		desc := "get network log"
+		if p.FailedOnly {
+			desc = "get failed requests"
+		}
+		if p.URLContains != "" {
+			desc += fmt.Sprintf(" matching %q", Truncate(p.URLContains, 40))
+		}
+		return desc
+	case "measure_performance":
+		var p struct {
+			DurationMs int    `json:"duration_ms"`
+			Label      string `json:"label"`
+		}
+		json.Unmarshal(inputJSON, &p)
+		if p.DurationMs <= 0 {
+			p.DurationMs = defaultPerfWindowMs
+		}
+		if p.Label != "" {
+			return fmt.Sprintf("measure performance %dms: %s", p.DurationMs, Truncate(p.Label, 40))
+		}
+		return fmt.Sprintf("measure performance %dms", p.DurationMs)
+	case "set_network":
+		var p struct {
+			Profile    string `json:"profile"`
+			DurationMs int    `json:"duration_ms"`
+		}
+		json.Unmarshal(inputJSON, &p)
+		if p.DurationMs > 0 {
+			return fmt.Sprintf("set network %s for %dms", p.Profile, p.DurationMs)
+		}
+		return "set network " + p.Profile
+	case "get_storage":
+		var p struct {
+			Area string `json:"area"`
+		}
+		json.Unmarshal(inputJSON, &p)
+		if p.Area != "" {
+			return "read " + p.Area
+		}
+		return "read storage"
+	case "set_storage":
+		var p struct {
+			LocalStorage   map[string]json.RawMessage `json:"local_storage"`
+			SessionStorage map[string]json.RawMessage `json:"session_storage"`
+			Cookies        []json.RawMessage          `json:"cookies"`
+			IndexedDB      []json.RawMessage          `json:"indexed_db"`
+			Reload         bool                       `json:"reload"`
+		}
+		json.Unmarshal(inputJSON, &p)
+		var keys []string
+		for k := range p.LocalStorage {
+			keys = append(keys, k)
+		}
+		for k := range p.SessionStorage {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		desc := "set storage"
+		if len(keys) > 0 {
+			desc += " " + Truncate(strings.Join(keys, ", "), 60)
+		}
+		if n := len(p.Cookies); n > 0 {
+			desc += fmt.Sprintf(" +%d cookie(s)", n)
+		}
+		if n := len(p.IndexedDB); n > 0 {
+			desc += fmt.Sprintf(" +%d IndexedDB database(s)", n)
+		}
+		if p.Reload {
+			desc += " and reload"
+		}
+		return desc
+	case "clear_storage":
+		var p struct {
+			Areas  []string `json:"areas"`
+			Reload bool     `json:"reload"`
+		}
+		json.Unmarshal(inputJSON, &p)
+		desc := "clear all storage"
+		if len(p.Areas) > 0 {
+			desc = "clear " + strings.Join(p.Areas, ", ")
+		}
+		if p.Reload {
+			desc += " and reload"
+		}
+		return desc
+	case "advance_time":
+		var p struct {
+			Ms int `json:"ms"`
+		}
+		json.Unmarshal(inputJSON, &p)
+		return fmt.Sprintf("advance time %dms", p.Ms)
+	case "pause_game":
+		var p struct {
+			Paused bool `json:"paused"`
+		}
+		json.Unmarshal(inputJSON, &p)
+		if p.Paused {
+			return "pause game time"
+		}
+		return "resume game time"
 	case "navigate":
 		var p struct{ URL string }
 		json.Unmarshal(inputJSON, &p)
@@ -882,6 +1257,7 @@ func formatToolAction(toolName string, inputJSON json.RawMessage) string {
 // PruneOldScreenshots walks messages from newest to oldest and replaces base64 image
 // data in screenshots beyond the keepRecent most recent ones. This prevents the API
 // payload from growing unboundedly as screenshots accumulate in the conversation.
+// Assistant content, including thinking blocks and their signatures, is left as is.
 func PruneOldScreenshots(messages []AgentMessage, keepRecent int) {
 	imageCount := 0
 	for i := len(messages) - 1; i >= 0; i-- {
@@ -922,6 +1298,42 @@ func stripImages(v interface{}, count *int, keep int) interface{} {
 	}
 }
 
+// stepReasoning joins the thinking and text blocks of an agent response, thinking
+// first, for recording alongside the steps the response produced.
+func stepReasoning(content []ResponseContentBlock) string {
+	var thinking, text []string
+	for _, block := range content {
+		switch {
+		case block.Type == "thinking" && block.Thinking != "":
+			thinking = append(thinking, block.Thinking)
+		case block.Type == "text" && block.Text != "":
+			text = append(text, block.Text)
+		}
+	}
+	return strings.Join(append(thinking, text...), "\n\n")
+}
+
+// withoutThinking returns messages with thinking blocks removed from assistant turns,
+// for requests that run with extended thinking off. The input is not modified.
+func withoutThinking(messages []AgentMessage) []AgentMessage {
+	out := make([]AgentMessage, len(messages))
+	for i, msg := range messages {
+		out[i] = msg
+		blocks, ok := msg.Content.([]ResponseContentBlock)
+		if !ok {
+			continue
+		}
+		kept := make([]ResponseContentBlock, 0, len(blocks))
+		for _, b := range blocks {
+			if b.Type != "thinking" && b.Type != "redacted_thinking" {
+				kept = append(kept, b)
+			}
+		}
+		out[i].Content = kept
+	}
+	return out
+}
+
 // Truncate shortens a string to maxLen, appending "..." if truncated.
 func Truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
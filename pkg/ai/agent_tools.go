@@ -2,9 +2,13 @@ package ai
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
 )
 
 // BrowserTools returns the tool definitions for browser interaction in agent mode.
@@ -13,7 +17,7 @@ func BrowserTools(viewportWidth, viewportHeight int) []ToolDefinition {
 	return []ToolDefinition{
 		{
 			Name:        "screenshot",
-			Description: "Capture a screenshot of the current page state. The click, type_text, scroll, and navigate tools already return screenshots automatically — use this tool only when you need to observe the page without interacting.",
+			Description: "Capture a screenshot of the current page state. The click, hover, drag, swipe, long_press, type_text, scroll, and navigate tools already return screenshots automatically — use this tool only when you need to observe the page without interacting.",
 			InputSchema: map[string]interface{}{
 				"type":       "object",
 				"properties": map[string]interface{}{},
@@ -38,6 +42,123 @@ func BrowserTools(viewportWidth, viewportHeight int) []ToolDefinition {
 				"required": []string{"x", "y"},
 			},
 		},
+		{
+			Name:        "hover",
+			Description: fmt.Sprintf("Move the mouse pointer to the given coordinates without clicking, then wait for hover effects (tooltips, popovers, highlighted buttons) to appear. Optionally pass a path to move through first. The viewport is %dx%d. Returns a screenshot of the result.", viewportWidth, viewportHeight),
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"x": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("X coordinate in pixels (0-%d)", viewportWidth),
+					},
+					"y": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Y coordinate in pixels (0-%d)", viewportHeight),
+					},
+					"path": map[string]interface{}{
+						"type":        "array",
+						"description": "Optional points to move through before reaching (x, y)",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"x": map[string]interface{}{"type": "integer"},
+								"y": map[string]interface{}{"type": "integer"},
+							},
+							"required": []string{"x", "y"},
+						},
+					},
+					"wait_ms": map[string]interface{}{
+						"type":        "integer",
+						"description": "How long to wait for hover effects before the screenshot, in milliseconds (default 500, max 5000)",
+					},
+				},
+				"required": []string{"x", "y"},
+			},
+		},
+		{
+			Name:        "drag",
+			Description: fmt.Sprintf("Press at one point, move to another while holding, and release. Use for sliders, drag-and-drop and panning. The viewport is %dx%d. Returns a screenshot of the result.", viewportWidth, viewportHeight),
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"from_x": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Start X coordinate in pixels (0-%d)", viewportWidth),
+					},
+					"from_y": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Start Y coordinate in pixels (0-%d)", viewportHeight),
+					},
+					"to_x": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("End X coordinate in pixels (0-%d)", viewportWidth),
+					},
+					"to_y": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("End Y coordinate in pixels (0-%d)", viewportHeight),
+					},
+					"duration_ms": map[string]interface{}{
+						"type":        "integer",
+						"description": "How long the movement takes in milliseconds (default 600, max 10000)",
+					},
+				},
+				"required": []string{"from_x", "from_y", "to_x", "to_y"},
+			},
+		},
+		{
+			Name:        "swipe",
+			Description: "Swipe quickly from a point in a direction, like a finger flick. Use for carousels, reels, card stacks and swipe-to-navigate screens. Returns a screenshot of the result.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"x": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Start X coordinate in pixels (0-%d)", viewportWidth),
+					},
+					"y": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Start Y coordinate in pixels (0-%d)", viewportHeight),
+					},
+					"direction": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"up", "down", "left", "right"},
+						"description": "Direction the finger moves",
+					},
+					"distance": map[string]interface{}{
+						"type":        "integer",
+						"description": "Distance to swipe in pixels (default 300)",
+					},
+					"duration_ms": map[string]interface{}{
+						"type":        "integer",
+						"description": "How long the swipe takes in milliseconds (default 300)",
+					},
+				},
+				"required": []string{"x", "y", "direction"},
+			},
+		},
+		{
+			Name:        "long_press",
+			Description: "Press and hold at the given coordinates, then release without clicking. Use for hold-to-reveal info, hold-to-spin and context menus. Returns a screenshot of the result.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"x": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("X coordinate in pixels (0-%d)", viewportWidth),
+					},
+					"y": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Y coordinate in pixels (0-%d)", viewportHeight),
+					},
+					"duration_ms": map[string]interface{}{
+						"type":        "integer",
+						"description": "How long to hold in milliseconds (default 1000, max 10000)",
+					},
+				},
+				"required": []string{"x", "y"},
+			},
+		},
 		{
 			Name:        "type_text",
 			Description: "Type text using the keyboard. Optionally click at coordinates first to focus an element. Returns a screenshot of the result.",
@@ -128,6 +249,14 @@ func BrowserTools(viewportWidth, viewportHeight int) []ToolDefinition {
 				"required":   []string{},
 			},
 		},
+		networkLogTool,
+		setNetworkTool,
+		measurePerformanceTool,
+		getStorageTool,
+		setStorageTool,
+		clearStorageTool,
+		advanceTimeTool,
+		pauseGameTool,
 		{
 			Name:        "navigate",
 			Description: "Navigate to a URL or reload the current page. Use this to retry loading a game that failed to initialize, or to navigate to a different URL.",
@@ -150,15 +279,47 @@ func BrowserTools(viewportWidth, viewportHeight int) []ToolDefinition {
 				"properties": map[string]interface{}{
 					"key": map[string]interface{}{
 						"type":        "string",
-						"description": "Key to press: Enter, Space, Escape, Tab, ArrowUp, ArrowDown, ArrowLeft, ArrowRight, Backspace, Delete, or a single character (a-z, 0-9)",
+						"description": "Key to press: Enter, Space, Escape, Tab, ArrowUp, ArrowDown, ArrowLeft, ArrowRight, Backspace, Delete, Shift, Control, Alt, Meta, or a single character (a-z, 0-9). Combine modifiers with + for a chord, e.g. Control+a or Shift+ArrowRight",
 					},
 				},
 				"required": []string{"key"},
 			},
 		},
+		{
+			Name:        "hold_keys",
+			Description: "Hold keyboard keys down for a time, following a timed sequence that may overlap — e.g. hold ArrowRight for 2000ms while pressing Space at 500ms to jump while running. All keys are released at the end (max 10s). Returns a screenshot taken right after the sequence.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"keys": map[string]interface{}{
+						"type":        "array",
+						"description": "Key presses in the sequence",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"key": map[string]interface{}{
+									"type":        "string",
+									"description": "Key or chord, with the same names as press_key (e.g. ArrowRight, Space, Shift+ArrowLeft)",
+								},
+								"at_ms": map[string]interface{}{
+									"type":        "integer",
+									"description": "When to press the key, in milliseconds from the start of the sequence (default 0)",
+								},
+								"duration_ms": map[string]interface{}{
+									"type":        "integer",
+									"description": "How long to hold the key in milliseconds (default 100)",
+								},
+							},
+							"required": []string{"key"},
+						},
+					},
+				},
+				"required": []string{"keys"},
+			},
+		},
 		{
 			Name:        "inspect_game_objects",
-			Description: "List interactive game objects from the Phaser/PixiJS scene graph with their screen coordinates. Use this to find clickable buttons, their exact positions, and their current state. Only works with Phaser 3 and PixiJS games.",
+			Description: "List interactive game objects from the game engine's scene graph with their screen coordinates: x,y is the centre of each object (click there), w,h its size. Use this to find clickable buttons, their exact positions, and their current state. Supports Phaser 3/4, PixiJS, Cocos Creator, Babylon.js, Three.js, PlayCanvas, Construct 3 (when the runtime is exposed) and games that define window.__WIZARDS_QA__.inspect(), such as Unity WebGL builds.",
 			InputSchema: map[string]interface{}{
 				"type":       "object",
 				"properties": map[string]interface{}{},
@@ -168,10 +329,88 @@ func BrowserTools(viewportWidth, viewportHeight int) []ToolDefinition {
 	}
 }
 
-// AgentTools returns browser tools, optionally including request_more_steps and request_more_time
-// for adaptive exploration and dynamic timeout.
+// TouchGestureTool returns the two-finger gesture tool definition. It is only
+// offered on touch viewports, where the page dispatches touch events.
+func TouchGestureTool(viewportWidth, viewportHeight int) ToolDefinition {
+	return ToolDefinition{
+		Name:        "touch_gesture",
+		Description: fmt.Sprintf("Perform a two-finger touch gesture centred on a point: pinch to zoom in or out, rotate, or pan with two fingers. Use for maps, zoomable boards and puzzles. The viewport is %dx%d. Returns a screenshot of the result.", viewportWidth, viewportHeight),
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"gesture": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"pinch", "rotate", "pan"},
+					"description": "Gesture to perform",
+				},
+				"x": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("X coordinate of the point between the fingers (0-%d)", viewportWidth),
+				},
+				"y": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Y coordinate of the point between the fingers (0-%d)", viewportHeight),
+				},
+				"scale": map[string]interface{}{
+					"type":        "number",
+					"description": "pinch: final finger spread relative to the start (2 = zoom in 2x, 0.5 = zoom out)",
+				},
+				"angle": map[string]interface{}{
+					"type":        "number",
+					"description": "rotate: degrees to turn, positive is clockwise",
+				},
+				"dx": map[string]interface{}{
+					"type":        "integer",
+					"description": "pan: horizontal distance in pixels",
+				},
+				"dy": map[string]interface{}{
+					"type":        "integer",
+					"description": "pan: vertical distance in pixels",
+				},
+				"spread": map[string]interface{}{
+					"type":        "integer",
+					"description": "Starting distance between the fingers in pixels (default 200)",
+				},
+				"duration_ms": map[string]interface{}{
+					"type":        "integer",
+					"description": "How long the gesture takes in milliseconds (default 500)",
+				},
+			},
+			"required": []string{"gesture", "x", "y"},
+		},
+	}
+}
+
+// RotateDeviceTool returns the device rotation tool definition. Like
+// touch_gesture it is only offered on phone and tablet viewports.
+func RotateDeviceTool() ToolDefinition {
+	return ToolDefinition{
+		Name:        "rotate_device",
+		Description: "Rotate the phone or tablet between portrait and landscape. The viewport width and height swap and the game receives resize and orientationchange events, as on a real device. Coordinates for later actions use the new viewport size reported in the result. Returns a screenshot of the rotated layout.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"orientation": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{scout.OrientationPortrait, scout.OrientationLandscape},
+					"description": "Orientation to rotate to (default: the other one)",
+				},
+			},
+			"required": []string{},
+		},
+	}
+}
+
+// AgentTools returns browser tools, optionally including touch_gesture and rotate_device on touch viewports and
+// request_more_steps and request_more_time for adaptive exploration and dynamic timeout.
 func AgentTools(cfg AgentConfig) []ToolDefinition {
 	tools := BrowserTools(cfg.ViewportWidth, cfg.ViewportHeight)
+	if cfg.TouchInput {
+		tools = append(tools, TouchGestureTool(cfg.ViewportWidth, cfg.ViewportHeight), RotateDeviceTool())
+	}
+	if len(cfg.Probes) > 0 {
+		tools = append(tools, ReadGameStateTool(cfg.Probes.Names()))
+	}
 	if cfg.AdaptiveExploration {
 		tools = append(tools, ToolDefinition{
 			Name:        "request_more_steps",
@@ -224,6 +463,37 @@ const (
 	screenshotToolTimeout = 30 * time.Second // explicit screenshot tool
 )
 
+// maxGestureDuration caps how long a drag, swipe, long press or key sequence may
+// hold input.
+const maxGestureDuration = 10 * time.Second
+
+// gestureDuration converts a requested duration in milliseconds, using def when
+// unset and capping at maxGestureDuration.
+func gestureDuration(ms int, def time.Duration) time.Duration {
+	if ms <= 0 {
+		return def
+	}
+	if d := time.Duration(ms) * time.Millisecond; d < maxGestureDuration {
+		return d
+	}
+	return maxGestureDuration
+}
+
+// swipeEnd returns where a swipe of the given distance from (x,y) ends.
+func swipeEnd(x, y int, direction string, distance int) (int, int, error) {
+	switch direction {
+	case "up":
+		return x, y - distance, nil
+	case "down":
+		return x, y + distance, nil
+	case "left":
+		return x - distance, y, nil
+	case "right":
+		return x + distance, y, nil
+	}
+	return 0, 0, fmt.Errorf("invalid direction %q", direction)
+}
+
 type ssResult struct {
 	b64 string
 	err error
@@ -265,16 +535,125 @@ func CaptureScreenshotWithTimeout(page BrowserPage, timeout time.Duration) (stri
 	return captureScreenshotOnce(page, timeout)
 }
 
+// holdKeyStep is one key press in a hold_keys sequence.
+type holdKeyStep struct {
+	Key        string `json:"key"`
+	AtMs       int    `json:"at_ms"`
+	DurationMs int    `json:"duration_ms"`
+}
+
+// keyEvent is a key press or release at an offset from the start of a sequence.
+type keyEvent struct {
+	at   time.Duration
+	key  string
+	down bool
+}
+
+// keyTimeline turns hold_keys steps into press and release events ordered by
+// time. At the same instant releases come first, so a key can be pressed again
+// as soon as it is let go.
+func keyTimeline(steps []holdKeyStep) ([]keyEvent, error) {
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("no keys given")
+	}
+	events := make([]keyEvent, 0, 2*len(steps))
+	for _, st := range steps {
+		if st.Key == "" {
+			return nil, fmt.Errorf("missing key")
+		}
+		if st.AtMs < 0 || st.DurationMs < 0 {
+			return nil, fmt.Errorf("%s: at_ms and duration_ms must not be negative", st.Key)
+		}
+		if st.DurationMs == 0 {
+			st.DurationMs = 100
+		}
+		at := time.Duration(st.AtMs) * time.Millisecond
+		end := at + time.Duration(st.DurationMs)*time.Millisecond
+		if end > maxGestureDuration {
+			return nil, fmt.Errorf("%s: sequence runs past %s", st.Key, maxGestureDuration)
+		}
+		events = append(events, keyEvent{at, st.Key, true}, keyEvent{end, st.Key, false})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].at != events[j].at {
+			return events[i].at < events[j].at
+		}
+		return !events[i].down && events[j].down
+	})
+	return events, nil
+}
+
+// runKeyTimeline plays key events in real time. Keys still held when an event
+// fails are released before returning.
+func runKeyTimeline(page BrowserPage, events []keyEvent) error {
+	var held []string
+	start := time.Now()
+	for _, ev := range events {
+		if wait := ev.at - time.Since(start); wait > 0 {
+			time.Sleep(wait)
+		}
+		var err error
+		if ev.down {
+			err = page.KeyDown(ev.key)
+			if err == nil {
+				held = append(held, ev.key)
+			}
+		} else {
+			err = page.KeyUp(ev.key)
+			for i := len(held) - 1; i >= 0; i-- {
+				if held[i] == ev.key {
+					held = append(held[:i], held[i+1:]...)
+					break
+				}
+			}
+		}
+		if err != nil {
+			for i := len(held) - 1; i >= 0; i-- {
+				_ = page.KeyUp(held[i])
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 // BrowserToolExecutor executes browser tool calls against a BrowserPage.
 type BrowserToolExecutor struct {
-	Page         BrowserPage
-	recentClicks []clickRecord // tracks recent click coordinates for dedup detection
+	Page BrowserPage
+	// Framework and JSGlobals come from the scouted PageMeta and pick the engine
+	// adapter inspect_game_objects tries first.
+	Framework string
+	JSGlobals []string
+	// Probes are the project's game-state getters read by read_game_state.
+	Probes GameStateProbes
+	// Viewport picks the limits measure_performance checks against.
+	Viewport     scout.ViewportPreset
+	recentClicks []clickRecord            // tracks recent click coordinates for dedup detection
+	perf         []PerformanceMeasurement // measure_performance results, for the analysis report
 }
 
 type clickRecord struct {
 	x, y int
 }
 
+// ResetPage returns the page to the state every test scenario and flow starts
+// from, so none inherits what the previous one left behind: a clock paused
+// with pause_game runs again, and a device turned with rotate_device is back
+// in the orientation of its viewport preset.
+func (e *BrowserToolExecutor) ResetPage() error {
+	if e.Page.TimePaused() {
+		if err := e.Page.SetTimePaused(false); err != nil {
+			return fmt.Errorf("resuming game time: %w", err)
+		}
+	}
+	if e.Viewport.Width > 0 && e.Page.Orientation() != e.Viewport.Orientation() {
+		if _, _, err := e.Page.Rotate(e.Viewport.Orientation()); err != nil && !errors.Is(err, scout.ErrRotateUnsupported) {
+			return fmt.Errorf("restoring %s orientation: %w", e.Viewport.Orientation(), err)
+		}
+	}
+	return nil
+}
+
 // checkClickRepetition tracks a click and returns a warning if the same
 // coordinates have been clicked 3+ times in a row (within 30px tolerance).
 func (e *BrowserToolExecutor) checkClickRepetition(x, y int) string {
@@ -337,6 +716,144 @@ func (e *BrowserToolExecutor) Execute(toolName string, inputJSON json.RawMessage
 		}
 		return msg, b64, nil
 
+	case "hover":
+		var params struct {
+			X      int           `json:"x"`
+			Y      int           `json:"y"`
+			Path   []scout.Point `json:"path"`
+			WaitMs int           `json:"wait_ms"`
+		}
+		if err := json.Unmarshal(inputJSON, &params); err != nil {
+			return "", "", fmt.Errorf("hover: invalid params: %w", err)
+		}
+		var moveErr error
+		if len(params.Path) > 0 {
+			moveErr = e.Page.MoveMouse(append(params.Path, scout.Point{X: params.X, Y: params.Y}))
+		} else {
+			moveErr = e.Page.Hover(params.X, params.Y)
+		}
+		if moveErr != nil {
+			return "", "", fmt.Errorf("hover: %w", moveErr)
+		}
+		// Tooltips and popovers usually appear after a short delay
+		wait := 500 * time.Millisecond
+		if params.WaitMs > 0 {
+			wait = time.Duration(params.WaitMs) * time.Millisecond
+		}
+		if wait > 5*time.Second {
+			wait = 5 * time.Second
+		}
+		time.Sleep(wait)
+		b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
+		return fmt.Sprintf("Hovering at (%d, %d).", params.X, params.Y), b64, nil
+
+	case "drag":
+		var params struct {
+			FromX      int `json:"from_x"`
+			FromY      int `json:"from_y"`
+			ToX        int `json:"to_x"`
+			ToY        int `json:"to_y"`
+			DurationMs int `json:"duration_ms"`
+		}
+		if err := json.Unmarshal(inputJSON, &params); err != nil {
+			return "", "", fmt.Errorf("drag: invalid params: %w", err)
+		}
+		duration := gestureDuration(params.DurationMs, 600*time.Millisecond)
+		if err := e.Page.Drag(params.FromX, params.FromY, params.ToX, params.ToY, duration); err != nil {
+			return "", "", fmt.Errorf("drag: %w", err)
+		}
+		time.Sleep(150 * time.Millisecond)
+		b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
+		return fmt.Sprintf("Dragged from (%d, %d) to (%d, %d) over %dms.", params.FromX, params.FromY, params.ToX, params.ToY, duration.Milliseconds()), b64, nil
+
+	case "swipe":
+		var params struct {
+			X          int    `json:"x"`
+			Y          int    `json:"y"`
+			Direction  string `json:"direction"`
+			Distance   int    `json:"distance"`
+			DurationMs int    `json:"duration_ms"`
+		}
+		if err := json.Unmarshal(inputJSON, &params); err != nil {
+			return "", "", fmt.Errorf("swipe: invalid params: %w", err)
+		}
+		if params.Distance <= 0 {
+			params.Distance = 300
+		}
+		toX, toY, dirErr := swipeEnd(params.X, params.Y, params.Direction, params.Distance)
+		if dirErr != nil {
+			return "", "", fmt.Errorf("swipe: %w", dirErr)
+		}
+		duration := gestureDuration(params.DurationMs, 300*time.Millisecond)
+		if err := e.Page.Drag(params.X, params.Y, toX, toY, duration); err != nil {
+			return "", "", fmt.Errorf("swipe: %w", err)
+		}
+		time.Sleep(150 * time.Millisecond)
+		b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
+		return fmt.Sprintf("Swiped %s from (%d, %d) to (%d, %d).", params.Direction, params.X, params.Y, toX, toY), b64, nil
+
+	case "long_press":
+		var params struct {
+			X          int `json:"x"`
+			Y          int `json:"y"`
+			DurationMs int `json:"duration_ms"`
+		}
+		if err := json.Unmarshal(inputJSON, &params); err != nil {
+			return "", "", fmt.Errorf("long_press: invalid params: %w", err)
+		}
+		duration := gestureDuration(params.DurationMs, time.Second)
+		if err := e.Page.LongPress(params.X, params.Y, duration); err != nil {
+			return "", "", fmt.Errorf("long_press: %w", err)
+		}
+		time.Sleep(150 * time.Millisecond)
+		b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
+		return fmt.Sprintf("Long-pressed at (%d, %d) for %dms.", params.X, params.Y, duration.Milliseconds()), b64, nil
+
+	case "touch_gesture":
+		var params struct {
+			Gesture    string  `json:"gesture"`
+			X          int     `json:"x"`
+			Y          int     `json:"y"`
+			Scale      float64 `json:"scale"`
+			Angle      float64 `json:"angle"`
+			DX         int     `json:"dx"`
+			DY         int     `json:"dy"`
+			Spread     int     `json:"spread"`
+			DurationMs int     `json:"duration_ms"`
+		}
+		if err := json.Unmarshal(inputJSON, &params); err != nil {
+			return "", "", fmt.Errorf("touch_gesture: invalid params: %w", err)
+		}
+		g := scout.TouchGesture{
+			Kind: params.Gesture, X: params.X, Y: params.Y,
+			Scale: params.Scale, Angle: params.Angle, DX: params.DX, DY: params.DY,
+			Spread: params.Spread, Duration: gestureDuration(params.DurationMs, 500*time.Millisecond),
+		}
+		if err := e.Page.MultiTouch(g); err != nil {
+			return "", "", fmt.Errorf("touch_gesture: %w", err)
+		}
+		time.Sleep(150 * time.Millisecond)
+		b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
+		return fmt.Sprintf("Performed %s at (%d, %d).", formatToolAction("touch_gesture", inputJSON), params.X, params.Y), b64, nil
+
+	case "rotate_device":
+		var params struct {
+			Orientation string `json:"orientation"`
+		}
+		if len(inputJSON) > 0 {
+			if err := json.Unmarshal(inputJSON, &params); err != nil {
+				return "", "", fmt.Errorf("rotate_device: invalid params: %w", err)
+			}
+		}
+		w, h, err := e.Page.Rotate(params.Orientation)
+		if err != nil {
+			return "", "", fmt.Errorf("rotate_device: %w", err)
+		}
+		// Give the game a moment to relayout after the resize.
+		time.Sleep(300 * time.Millisecond)
+		b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
+		return fmt.Sprintf("Device is now %s: the viewport is %dx%d, so coordinates range x 0-%d, y 0-%d.", e.Page.Orientation(), w, h, w, h), b64, nil
+
 	case "type_text":
 		var params struct {
 			Text string `json:"text"`
@@ -430,6 +947,9 @@ func (e *BrowserToolExecutor) Execute(toolName string, inputJSON json.RawMessage
 				params.Milliseconds = 10000 // cap at 10s
 			}
 			time.Sleep(time.Duration(params.Milliseconds) * time.Millisecond)
+			if e.Page.TimePaused() {
+				return fmt.Sprintf("Waited %dms, but game time is paused: use advance_time to move it or pause_game to resume.", params.Milliseconds), "", nil
+			}
 			return fmt.Sprintf("Waited %dms.", params.Milliseconds), "", nil
 		}
 		return "No wait parameters specified.", "", nil
@@ -461,6 +981,222 @@ func (e *BrowserToolExecutor) Execute(toolName string, inputJSON json.RawMessage
 		}
 		return strings.Join(logs, "\n"), "", nil
 
+	case "network_log":
+		var params struct {
+			FailedOnly   bool   `json:"failed_only"`
+			URLContains  string `json:"url_contains"`
+			ResourceType string `json:"resource_type"`
+			MinStatus    int    `json:"min_status"`
+			Limit        int    `json:"limit"`
+		}
+		if len(inputJSON) > 0 {
+			if err := json.Unmarshal(inputJSON, &params); err != nil {
+				return "", "", fmt.Errorf("network_log: invalid params: %w", err)
+			}
+		}
+		entries, logErr := e.Page.GetNetworkLog()
+		if logErr != nil {
+			return "", "", fmt.Errorf("network_log: %w", logErr)
+		}
+		return formatNetworkLog(entries, scout.NetworkFilter{
+			FailedOnly:   params.FailedOnly,
+			URLContains:  params.URLContains,
+			ResourceType: params.ResourceType,
+			MinStatus:    params.MinStatus,
+			Limit:        params.Limit,
+		}), "", nil
+
+	case "measure_performance":
+		var params struct {
+			DurationMs int    `json:"duration_ms"`
+			Label      string `json:"label"`
+		}
+		if len(inputJSON) > 0 {
+			if err := json.Unmarshal(inputJSON, &params); err != nil {
+				return "", "", fmt.Errorf("measure_performance: invalid params: %w", err)
+			}
+		}
+		if params.DurationMs <= 0 {
+			params.DurationMs = defaultPerfWindowMs
+		}
+		if params.DurationMs < minPerfWindowMs {
+			params.DurationMs = minPerfWindowMs
+		}
+		if params.DurationMs > maxPerfWindowMs {
+			params.DurationMs = maxPerfWindowMs
+		}
+		// No frames render while the clock is paused, which would read as a frozen game
+		resumed := e.Page.TimePaused()
+		if resumed {
+			if err := e.Page.SetTimePaused(false); err != nil {
+				return "", "", fmt.Errorf("measure_performance: resuming game time: %w", err)
+			}
+		}
+		sample, err := e.Page.MeasurePerformance(time.Duration(params.DurationMs) * time.Millisecond)
+		if err != nil {
+			return "", "", fmt.Errorf("measure_performance: %w", err)
+		}
+		e.perf = append(e.perf, PerformanceMeasurement{Label: params.Label, Sample: *sample})
+		text := formatPerformanceSample(sample, e.Viewport)
+		if resumed {
+			text = "Game time was paused; resumed it to measure.\n" + text
+		}
+		return text, "", nil
+
+	case "set_network":
+		var params struct {
+			Profile      string  `json:"profile"`
+			LatencyMs    float64 `json:"latency_ms"`
+			DownloadKbps float64 `json:"download_kbps"`
+			UploadKbps   float64 `json:"upload_kbps"`
+			DurationMs   int     `json:"duration_ms"`
+		}
+		if err := json.Unmarshal(inputJSON, &params); err != nil {
+			return "", "", fmt.Errorf("set_network: invalid params: %w", err)
+		}
+		profile, err := scout.ResolveNetworkProfile(params.Profile, params.LatencyMs, params.DownloadKbps, params.UploadKbps)
+		if err != nil {
+			return "", "", fmt.Errorf("set_network: %w", err)
+		}
+		prev := e.Page.NetworkProfile()
+		if err := e.Page.SetNetwork(profile); err != nil {
+			return "", "", fmt.Errorf("set_network: %w", err)
+		}
+		if params.DurationMs <= 0 {
+			time.Sleep(300 * time.Millisecond)
+			b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
+			return fmt.Sprintf("Network set to %s (was %s).", profile, prev), b64, nil
+		}
+		if params.DurationMs > maxNetworkDropMs {
+			params.DurationMs = maxNetworkDropMs
+		}
+		time.Sleep(time.Duration(params.DurationMs) * time.Millisecond)
+		b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
+		if err := e.Page.SetNetwork(prev); err != nil {
+			return "", "", fmt.Errorf("set_network: restoring %s: %w", prev.Name, err)
+		}
+		return fmt.Sprintf("Network set to %s for %dms, then restored to %s. The screenshot was taken just before restoring.", profile, params.DurationMs, prev), b64, nil
+
+	case "get_storage":
+		var params struct {
+			Area string `json:"area"`
+		}
+		if len(inputJSON) > 0 {
+			if err := json.Unmarshal(inputJSON, &params); err != nil {
+				return "", "", fmt.Errorf("get_storage: invalid params: %w", err)
+			}
+		}
+		state, err := e.Page.GetStorage()
+		if err != nil {
+			return "", "", fmt.Errorf("get_storage: %w", err)
+		}
+		return formatStorageState(state, params.Area), "", nil
+
+	case "set_storage":
+		var params struct {
+			LocalStorage   map[string]interface{}    `json:"local_storage"`
+			SessionStorage map[string]interface{}    `json:"session_storage"`
+			Cookies        []scout.Cookie            `json:"cookies"`
+			IndexedDB      []scout.IndexedDBDatabase `json:"indexed_db"`
+			Reload         bool                      `json:"reload"`
+		}
+		if err := json.Unmarshal(inputJSON, &params); err != nil {
+			return "", "", fmt.Errorf("set_storage: invalid params: %w", err)
+		}
+		state := scout.StorageState{Cookies: params.Cookies, IndexedDB: params.IndexedDB}
+		var err error
+		if state.LocalStorage, err = storageStrings(params.LocalStorage); err != nil {
+			return "", "", fmt.Errorf("set_storage: local_storage: %w", err)
+		}
+		if state.SessionStorage, err = storageStrings(params.SessionStorage); err != nil {
+			return "", "", fmt.Errorf("set_storage: session_storage: %w", err)
+		}
+		if state.IsEmpty() {
+			return "", "", fmt.Errorf("set_storage: nothing to write")
+		}
+		if err := e.Page.SetStorage(state); err != nil {
+			return "", "", fmt.Errorf("set_storage: %w", err)
+		}
+		msg := fmt.Sprintf("Wrote %s.", state.Summary())
+		if !params.Reload {
+			return msg + " The game reads it on its next load: reload (reload=true or navigate) to apply it.", "", nil
+		}
+		if err := reloadPage(e.Page); err != nil {
+			return "", "", fmt.Errorf("set_storage: reload: %w", err)
+		}
+		b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
+		return msg + " Reloaded the page.", b64, nil
+
+	case "clear_storage":
+		var params struct {
+			Areas  []string `json:"areas"`
+			Reload bool     `json:"reload"`
+		}
+		if len(inputJSON) > 0 {
+			if err := json.Unmarshal(inputJSON, &params); err != nil {
+				return "", "", fmt.Errorf("clear_storage: invalid params: %w", err)
+			}
+		}
+		if err := e.Page.ClearStorage(params.Areas...); err != nil {
+			return "", "", fmt.Errorf("clear_storage: %w", err)
+		}
+		cleared := "all storage"
+		if len(params.Areas) > 0 {
+			cleared = strings.Join(params.Areas, ", ")
+		}
+		if !params.Reload {
+			return fmt.Sprintf("Cleared %s.", cleared), "", nil
+		}
+		if err := reloadPage(e.Page); err != nil {
+			return "", "", fmt.Errorf("clear_storage: reload: %w", err)
+		}
+		b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
+		return fmt.Sprintf("Cleared %s and reloaded the page.", cleared), b64, nil
+
+	case "advance_time":
+		var params struct {
+			Ms int `json:"ms"`
+		}
+		if err := json.Unmarshal(inputJSON, &params); err != nil {
+			return "", "", fmt.Errorf("advance_time: invalid params: %w", err)
+		}
+		if params.Ms <= 0 {
+			return "", "", fmt.Errorf("advance_time: ms must be positive")
+		}
+		if params.Ms > maxAdvanceTimeMs {
+			params.Ms = maxAdvanceTimeMs
+		}
+		start := time.Now()
+		if err := e.Page.AdvanceTime(time.Duration(params.Ms) * time.Millisecond); err != nil {
+			return "", "", fmt.Errorf("advance_time: %w", err)
+		}
+		elapsed := time.Since(start)
+		b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
+		msg := fmt.Sprintf("Advanced game time by %dms in %.1fs of real time.", params.Ms, elapsed.Seconds())
+		if e.Page.TimePaused() {
+			msg += " Time is paused again."
+		}
+		return msg, b64, nil
+
+	case "pause_game":
+		var params struct {
+			Paused *bool `json:"paused"`
+		}
+		if err := json.Unmarshal(inputJSON, &params); err != nil {
+			return "", "", fmt.Errorf("pause_game: invalid params: %w", err)
+		}
+		if params.Paused == nil {
+			return "", "", fmt.Errorf("pause_game: paused is required")
+		}
+		if err := e.Page.SetTimePaused(*params.Paused); err != nil {
+			return "", "", fmt.Errorf("pause_game: %w", err)
+		}
+		b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
+		if *params.Paused {
+			return "Game time paused. Use advance_time to step forward or pause_game with paused=false to resume.", b64, nil
+		}
+		return "Game time resumed.", b64, nil
+
 	case "navigate":
 		var params struct {
 			URL string `json:"url"`
@@ -492,56 +1228,41 @@ func (e *BrowserToolExecutor) Execute(toolName string, inputJSON json.RawMessage
 		b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
 		return fmt.Sprintf("Pressed key %q.", params.Key), b64, nil
 
-	case "inspect_game_objects":
-		result, err := e.Page.EvalJS(`(() => {
-			// Phaser 3
-			if (window.game && window.game.scene) {
-				const scenes = window.game.scene.scenes.filter(s => s.sys.settings.status >= 5);
-				const canvas = document.querySelector('canvas');
-				const rect = canvas ? canvas.getBoundingClientRect() : {left:0, top:0, width:1920, height:1080};
-				const scaleX = rect.width / (window.game.scale ? window.game.scale.width : canvas.width);
-				const scaleY = rect.height / (window.game.scale ? window.game.scale.height : canvas.height);
-				const objects = [];
-				for (const scene of scenes) {
-					scene.children.list.forEach(obj => {
-						if (!obj.active || !obj.visible) return;
-						const hasInput = obj.input && obj.input.enabled;
-						const name = obj.name || obj.type || obj.constructor.name;
-						const sx = Math.round(obj.x * scaleX + rect.left);
-						const sy = Math.round(obj.y * scaleY + rect.top);
-						const w = obj.displayWidth ? Math.round(obj.displayWidth * scaleX) : 0;
-						const h = obj.displayHeight ? Math.round(obj.displayHeight * scaleY) : 0;
-						if (hasInput || obj.type === 'Text' || obj.type === 'Sprite' || obj.type === 'Image') {
-							objects.push({
-								scene: scene.sys.settings.key,
-								name: name,
-								type: obj.type,
-								interactive: hasInput,
-								x: sx, y: sy, w: w, h: h,
-								text: obj.text ? obj.text.substring(0, 50) : undefined
-							});
-						}
-					});
-				}
-				return JSON.stringify({engine: 'phaser3', scenes: scenes.map(s => s.sys.settings.key), objects: objects}, null, 2);
-			}
-			// PixiJS
-			if (window.__PIXI_APP__ || window.app) {
-				const app = window.__PIXI_APP__ || window.app;
-				const objects = [];
-				function walk(node, depth) {
-					if (depth > 5) return;
-					if (node.interactive || node.buttonMode) {
-						const b = node.getBounds();
-						objects.push({name: node.name || node.constructor.name, interactive: true, x: Math.round(b.x), y: Math.round(b.y), w: Math.round(b.width), h: Math.round(b.height)});
-					}
-					if (node.children) node.children.forEach(c => walk(c, depth+1));
-				}
-				walk(app.stage, 0);
-				return JSON.stringify({engine: 'pixi', objects: objects}, null, 2);
+	case "hold_keys":
+		var params struct {
+			Keys []holdKeyStep `json:"keys"`
+		}
+		if err := json.Unmarshal(inputJSON, &params); err != nil {
+			return "", "", fmt.Errorf("hold_keys: invalid params: %w", err)
+		}
+		events, tlErr := keyTimeline(params.Keys)
+		if tlErr != nil {
+			return "", "", fmt.Errorf("hold_keys: %w", tlErr)
+		}
+		if err := runKeyTimeline(e.Page, events); err != nil {
+			return "", "", fmt.Errorf("hold_keys: %w", err)
+		}
+		b64, _ := captureScreenshotWithTimeout(e.Page, screenshotTimeout)
+		return fmt.Sprintf("Played key sequence: %s.", formatToolAction("hold_keys", inputJSON)), b64, nil
+
+	case "read_game_state":
+		var params struct {
+			Probes []string `json:"probes"`
+		}
+		if len(inputJSON) > 0 {
+			if err := json.Unmarshal(inputJSON, &params); err != nil {
+				return "", "", fmt.Errorf("read_game_state: invalid params: %w", err)
 			}
-			return JSON.stringify({error: 'No supported game engine detected (need Phaser 3 or PixiJS)'});
-		})()`)
+		}
+		state, err := ReadGameState(e.Page, e.Probes, params.Probes)
+		if err != nil {
+			return "", "", fmt.Errorf("read_game_state: %w", err)
+		}
+		out, _ := json.MarshalIndent(state, "", "  ")
+		return string(out), "", nil
+
+	case "inspect_game_objects":
+		result, err := e.Page.EvalJS(inspectGameObjectsScript(SelectEngineAdapters(e.Framework, e.JSGlobals)))
 		if err != nil {
 			return "", "", fmt.Errorf("inspect_game_objects: %w", err)
 		}
@@ -0,0 +1,266 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+// fakePage records the input it receives and returns a fixed screenshot.
+type fakePage struct {
+	calls     []string
+	touch     bool
+	eval      string // result returned by EvalJS
+	network   []scout.NetworkEntry
+	netProf   scout.NetworkProfile
+	perf      *scout.PerformanceSample
+	storage   scout.StorageState
+	paused    bool
+	landscape bool
+}
+
+func (p *fakePage) record(format string, args ...interface{}) error {
+	p.calls = append(p.calls, fmt.Sprintf(format, args...))
+	return nil
+}
+
+func (p *fakePage) CaptureScreenshot() (string, error) { return "c2NyZWVu", nil }
+func (p *fakePage) Click(x, y int) error               { return p.record("click %d,%d", x, y) }
+func (p *fakePage) Drag(fromX, fromY, toX, toY int, d time.Duration) error {
+	return p.record("drag %d,%d->%d,%d %s", fromX, fromY, toX, toY, d)
+}
+func (p *fakePage) LongPress(x, y int, d time.Duration) error {
+	return p.record("long_press %d,%d %s", x, y, d)
+}
+func (p *fakePage) MultiTouch(g scout.TouchGesture) error {
+	if !p.touch {
+		return scout.ErrTouchUnsupported
+	}
+	return p.record("%s %d,%d scale=%v angle=%v pan=%d,%d %s", g.Kind, g.X, g.Y, g.Scale, g.Angle, g.DX, g.DY, g.Duration)
+}
+func (p *fakePage) Rotate(orientation string) (int, int, error) {
+	if !p.touch {
+		return 0, 0, scout.ErrRotateUnsupported
+	}
+	p.landscape = orientation == scout.OrientationLandscape || (orientation == "" && !p.landscape)
+	if p.landscape {
+		return 844, 390, p.record("rotate landscape")
+	}
+	return 390, 844, p.record("rotate portrait")
+}
+func (p *fakePage) Orientation() string {
+	if p.landscape {
+		return scout.OrientationLandscape
+	}
+	return scout.OrientationPortrait
+}
+func (p *fakePage) Hover(x, y int) error { return p.record("hover %d,%d", x, y) }
+func (p *fakePage) MoveMouse(path []scout.Point) error {
+	return p.record("move %v", path)
+}
+func (p *fakePage) TypeText(text string) error                               { return p.record("type %s", text) }
+func (p *fakePage) Scroll(dx, dy float64) error                              { return p.record("scroll %.0f,%.0f", dx, dy) }
+func (p *fakePage) EvalJS(expr string) (string, error)                       { return p.eval, p.record("eval") }
+func (p *fakePage) WaitVisible(selector string, timeout time.Duration) error { return nil }
+func (p *fakePage) GetPageInfo() (string, string, string, error)             { return "", "", "", nil }
+func (p *fakePage) GetConsoleLogs() ([]string, error)                        { return nil, nil }
+func (p *fakePage) GetNetworkLog() ([]scout.NetworkEntry, error)             { return p.network, nil }
+func (p *fakePage) SetNetwork(np scout.NetworkProfile) error {
+	p.netProf = np
+	return p.record("network %s", np)
+}
+func (p *fakePage) NetworkProfile() scout.NetworkProfile { return p.netProf }
+func (p *fakePage) MeasurePerformance(window time.Duration) (*scout.PerformanceSample, error) {
+	if p.perf == nil {
+		return nil, errors.New("performance sampler is not installed on this page")
+	}
+	s := *p.perf
+	s.DurationMs = float64(window.Milliseconds())
+	return &s, p.record("measure %s", window)
+}
+func (p *fakePage) PerformanceSession() (*scout.PerformanceSample, error) { return p.perf, nil }
+func (p *fakePage) GetStorage() (*scout.StorageState, error) {
+	s := p.storage
+	return &s, nil
+}
+func (p *fakePage) SetStorage(s scout.StorageState) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	return p.record("storage %s", s.Summary())
+}
+func (p *fakePage) ClearStorage(areas ...string) error {
+	return p.record("clear %s", strings.Join(areas, ","))
+}
+func (p *fakePage) AdvanceTime(d time.Duration) error { return p.record("advance %s", d) }
+func (p *fakePage) SetTimePaused(paused bool) error {
+	p.paused = paused
+	return p.record("paused %v", paused)
+}
+func (p *fakePage) TimePaused() bool          { return p.paused }
+func (p *fakePage) Navigate(url string) error { return p.record("navigate %s", url) }
+func (p *fakePage) PressKey(key string) error { return p.record("key %s", key) }
+func (p *fakePage) KeyDown(key string) error  { return p.record("down %s", key) }
+func (p *fakePage) KeyUp(key string) error    { return p.record("up %s", key) }
+func (p *fakePage) HoldKey(key string, d time.Duration) error {
+	return p.record("hold %s %s", key, d)
+}
+
+func TestGestureTools(t *testing.T) {
+	tests := []struct {
+		tool, input, want string
+	}{
+		{"drag", `{"from_x": 100, "from_y": 400, "to_x": 600, "to_y": 400}`, "drag 100,400->600,400 600ms"},
+		{"drag", `{"from_x": 1, "from_y": 2, "to_x": 3, "to_y": 4, "duration_ms": 60000}`, "drag 1,2->3,4 10s"},
+		{"swipe", `{"x": 400, "y": 300, "direction": "left"}`, "drag 400,300->100,300 300ms"},
+		{"swipe", `{"x": 400, "y": 500, "direction": "up", "distance": 200, "duration_ms": 150}`, "drag 400,500->400,300 150ms"},
+		{"long_press", `{"x": 50, "y": 60}`, "long_press 50,60 1s"},
+		{"long_press", `{"x": 50, "y": 60, "duration_ms": 2500}`, "long_press 50,60 2.5s"},
+	}
+	for _, tt := range tests {
+		page := &fakePage{}
+		exec := &BrowserToolExecutor{Page: page}
+		text, ss, err := exec.Execute(tt.tool, json.RawMessage(tt.input))
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.tool, tt.input, err)
+			continue
+		}
+		if strings.Join(page.calls, "|") != tt.want {
+			t.Errorf("%s %s: page got %q, want %q", tt.tool, tt.input, page.calls, tt.want)
+		}
+		if text == "" || ss == "" {
+			t.Errorf("%s: expected a result message and a screenshot, got %q, %q", tt.tool, text, ss)
+		}
+	}
+
+	exec := &BrowserToolExecutor{Page: &fakePage{}}
+	if _, _, err := exec.Execute("swipe", json.RawMessage(`{"x": 1, "y": 1, "direction": "sideways"}`)); err == nil {
+		t.Error("expected an error for an invalid swipe direction")
+	}
+}
+
+func TestTouchGestureTool(t *testing.T) {
+	page := &fakePage{touch: true}
+	exec := &BrowserToolExecutor{Page: page}
+	for _, input := range []string{
+		`{"gesture": "pinch", "x": 200, "y": 400, "scale": 2}`,
+		`{"gesture": "rotate", "x": 200, "y": 400, "angle": -45, "duration_ms": 800}`,
+		`{"gesture": "pan", "x": 200, "y": 400, "dx": 0, "dy": -150}`,
+	} {
+		if _, ss, err := exec.Execute("touch_gesture", json.RawMessage(input)); err != nil || ss == "" {
+			t.Errorf("touch_gesture %s: err=%v screenshot=%q", input, err, ss)
+		}
+	}
+	want := "pinch 200,400 scale=2 angle=0 pan=0,0 500ms|rotate 200,400 scale=0 angle=-45 pan=0,0 800ms|pan 200,400 scale=0 angle=0 pan=0,-150 500ms"
+	if got := strings.Join(page.calls, "|"); got != want {
+		t.Errorf("page got %q, want %q", got, want)
+	}
+
+	exec = &BrowserToolExecutor{Page: &fakePage{}}
+	if _, _, err := exec.Execute("touch_gesture", json.RawMessage(`{"gesture": "pinch", "x": 1, "y": 1, "scale": 2}`)); !errors.Is(err, scout.ErrTouchUnsupported) {
+		t.Errorf("expected ErrTouchUnsupported on a desktop page, got %v", err)
+	}
+
+	names := func(tools []ToolDefinition) string {
+		var out []string
+		for _, tool := range tools {
+			out = append(out, tool.Name)
+		}
+		return strings.Join(out, ",")
+	}
+	if strings.Contains(names(AgentTools(AgentConfig{})), "touch_gesture") {
+		t.Error("touch_gesture must not be offered on desktop viewports")
+	}
+	if !strings.Contains(names(AgentTools(AgentConfig{TouchInput: true})), "touch_gesture") {
+		t.Error("touch_gesture missing on a touch viewport")
+	}
+}
+
+func TestRotateDeviceTool(t *testing.T) {
+	page := &fakePage{touch: true}
+	exec := &BrowserToolExecutor{Page: page}
+	text, ss, err := exec.Execute("rotate_device", json.RawMessage(`{}`))
+	if err != nil || ss == "" {
+		t.Fatalf("rotate_device: err=%v screenshot=%q", err, ss)
+	}
+	if !strings.Contains(text, "landscape") || !strings.Contains(text, "844x390") {
+		t.Errorf("result should report the new orientation and viewport, got %q", text)
+	}
+	if _, _, err := exec.Execute("rotate_device", json.RawMessage(`{"orientation": "portrait"}`)); err != nil {
+		t.Fatalf("rotate_device portrait: %v", err)
+	}
+	if got := strings.Join(page.calls, "|"); got != "rotate landscape|rotate portrait" {
+		t.Errorf("page got %q", got)
+	}
+
+	exec = &BrowserToolExecutor{Page: &fakePage{}}
+	if _, _, err := exec.Execute("rotate_device", json.RawMessage(`{}`)); !errors.Is(err, scout.ErrRotateUnsupported) {
+		t.Errorf("expected ErrRotateUnsupported on a desktop page, got %v", err)
+	}
+	for _, tool := range AgentTools(AgentConfig{}) {
+		if tool.Name == "rotate_device" {
+			t.Error("rotate_device must not be offered on desktop viewports")
+		}
+	}
+}
+
+func TestHoverTool(t *testing.T) {
+	page := &fakePage{}
+	exec := &BrowserToolExecutor{Page: page}
+	for _, input := range []string{
+		`{"x": 120, "y": 80, "wait_ms": 1}`,
+		`{"x": 120, "y": 80, "path": [{"x": 10, "y": 10}, {"x": 60, "y": 40}], "wait_ms": 1}`,
+	} {
+		if _, ss, err := exec.Execute("hover", json.RawMessage(input)); err != nil || ss == "" {
+			t.Errorf("hover %s: err=%v screenshot=%q", input, err, ss)
+		}
+	}
+	if got := strings.Join(page.calls, "|"); got != "hover 120,80|move [{10 10} {60 40} {120 80}]" {
+		t.Errorf("page got %q", got)
+	}
+}
+
+func TestKeyTimelineOrdersOverlappingKeys(t *testing.T) {
+	events, err := keyTimeline([]holdKeyStep{
+		{Key: "ArrowRight", DurationMs: 200},
+		{Key: "Space", AtMs: 50},
+		{Key: "ArrowRight", AtMs: 200, DurationMs: 20},
+	})
+	if err != nil {
+		t.Fatalf("keyTimeline: %v", err)
+	}
+	var got []string
+	for _, ev := range events {
+		got = append(got, fmt.Sprintf("%v %s %v", ev.at.Milliseconds(), ev.key, ev.down))
+	}
+	want := "0 ArrowRight true|50 Space true|150 Space false|200 ArrowRight false|200 ArrowRight true|220 ArrowRight false"
+	if strings.Join(got, "|") != want {
+		t.Errorf("events = %q", got)
+	}
+
+	for _, bad := range [][]holdKeyStep{nil, {{Key: ""}}, {{Key: "a", AtMs: -1}}, {{Key: "a", AtMs: 9000, DurationMs: 2000}}} {
+		if _, err := keyTimeline(bad); err == nil {
+			t.Errorf("keyTimeline(%+v) should fail", bad)
+		}
+	}
+}
+
+func TestHoldKeysTool(t *testing.T) {
+	page := &fakePage{}
+	exec := &BrowserToolExecutor{Page: page}
+	text, ss, err := exec.Execute("hold_keys", json.RawMessage(`{"keys": [{"key": "ArrowRight", "duration_ms": 60}, {"key": "Shift+Space", "at_ms": 20, "duration_ms": 10}]}`))
+	if err != nil || ss == "" {
+		t.Fatalf("hold_keys: err=%v screenshot=%q", err, ss)
+	}
+	if got := strings.Join(page.calls, "|"); got != "down ArrowRight|down Shift+Space|up Shift+Space|up ArrowRight" {
+		t.Errorf("page got %q", got)
+	}
+	if !strings.Contains(text, "ArrowRight 0-60ms, Shift+Space 20-30ms") {
+		t.Errorf("result = %q", text)
+	}
+}
@@ -24,30 +24,34 @@ import (
 type Analyzer struct {
 	Client          Client
 	Usage           TokenUsage
+	StartedAt       time.Time // usage is priced as of the start of the analysis; zero means now
 	secondaryClient Client
 	secondaryUsage  TokenUsage
 }
 
 // NewAnalyzer creates a new game analyzer
 func NewAnalyzer(client Client) *Analyzer {
-	a := &Analyzer{Client: client}
-	if bc := baseClientOf(client); bc != nil {
+	a := &Analyzer{Client: client, StartedAt: time.Now()}
+	trackUsage(client, &a.Usage)
+	return a
+}
+
+// trackUsage points the usage callback of every BaseClient behind client at usage,
+// attributing each call to the model of the client that made it.
+func trackUsage(client Client, usage *TokenUsage) {
+	for _, bc := range baseClientsOf(client) {
+		model := bc.Model
 		bc.OnUsage = func(input, output, cacheCreate, cacheRead int) {
-			a.Usage.Add(input, output, cacheCreate, cacheRead)
+			usage.AddForModel(model, input, output, cacheCreate, cacheRead)
 		}
 	}
-	return a
 }
 
 // SetSecondaryClient configures a secondary AI client used for synthesis and
 // flow generation (text-only stages that don't require tool use).
 func (a *Analyzer) SetSecondaryClient(client Client) {
 	a.secondaryClient = client
-	if bc := baseClientOf(client); bc != nil {
-		bc.OnUsage = func(input, output, cacheCreate, cacheRead int) {
-			a.secondaryUsage.Add(input, output, cacheCreate, cacheRead)
-		}
-	}
+	trackUsage(client, &a.secondaryUsage)
 }
 
 // synthesisClient returns the secondary client if set, otherwise the primary.
@@ -61,29 +65,185 @@ func (a *Analyzer) synthesisClient() Client {
 // synthesisGenerate calls Generate on the synthesis client with a guaranteed
 // minimum output token budget. This prevents truncation when using secondary
 // models (e.g. Gemini Flash) that may have low default maxOutputTokens.
-func (a *Analyzer) synthesisGenerate(ctx context.Context, prompt string, ctxMap map[string]interface{}) (string, error) {
+//
+// The response is streamed where the client supports it, reporting output through
+// onProgress (may be nil). A response cut off at max_tokens is passed through
+// repairTruncatedJSON when that yields valid JSON.
+func (a *Analyzer) synthesisGenerate(ctx context.Context, prompt string, ctxMap map[string]interface{}, onProgress ProgressFunc) (string, error) {
 	client := a.synthesisClient()
 	const minTokens = 16384
-	if bc := baseClientOf(client); bc != nil && bc.MaxTokens < minTokens {
-		orig := bc.MaxTokens
-		bc.MaxTokens = minTokens
-		defer func() { bc.MaxTokens = orig }()
+	defer raiseMaxTokens(client, minTokens)()
+	stream := newStreamProgress(onProgress)
+	defer streamTo(client, stream.onStream)()
+
+	response, err := client.Generate(ctx, prompt, ctxMap)
+	if err != nil || stream.stopReason != "max_tokens" {
+		return response, err
+	}
+	if repaired, repairErr := repairTruncatedJSON(response); repairErr == nil && json.Valid([]byte(repaired)) {
+		log.Printf("synthesisGenerate: repaired truncated JSON response (%d -> %d chars)", len(response), len(repaired))
+		return repaired, nil
+	}
+	return response, nil
+}
+
+// enableThinking sets the extended thinking budget on every BaseClient behind client
+// and returns a function that restores the original values. It may be called more
+// than once. A budget of 0 leaves the clients untouched.
+func enableThinking(client Client, budget int) (restore func()) {
+	if budget <= 0 {
+		return func() {}
+	}
+	var restores []func()
+	for _, bc := range baseClientsOf(client) {
+		bc, orig := bc, bc.ThinkingBudget
+		bc.ThinkingBudget = budget
+		restores = append(restores, func() { bc.ThinkingBudget = orig })
+	}
+	return func() {
+		for _, r := range restores {
+			r()
+		}
+	}
+}
+
+// raiseMaxTokens raises MaxTokens to at least minTokens on every BaseClient behind
+// client and returns a function that restores the original values.
+func raiseMaxTokens(client Client, minTokens int) (restore func()) {
+	var restores []func()
+	for _, bc := range baseClientsOf(client) {
+		if bc.MaxTokens < minTokens {
+			bc, orig := bc, bc.MaxTokens
+			bc.MaxTokens = minTokens
+			restores = append(restores, func() { bc.MaxTokens = orig })
+		}
+	}
+	return func() {
+		for _, r := range restores {
+			r()
+		}
 	}
-	return client.Generate(ctx, prompt, ctxMap)
 }
 
-// baseClientOf extracts the *BaseClient from a Client implementation.
+// streamTo sets the OnStream callback on every BaseClient behind client (nil detaches
+// it) and returns a function that restores the previous callbacks, so calls nest.
+func streamTo(client Client, onStream func(kind, delta string)) (restore func()) {
+	var restores []func()
+	for _, bc := range baseClientsOf(client) {
+		bc, orig := bc, bc.OnStream
+		bc.OnStream = onStream
+		restores = append(restores, func() { bc.OnStream = orig })
+	}
+	return func() {
+		for _, r := range restores {
+			r()
+		}
+	}
+}
+
+// streamProgressInterval is how often buffered stream deltas are flushed as progress.
+const streamProgressInterval = 250 * time.Millisecond
+
+// streamProgress turns streamed response deltas into "ai_stream" progress events.
+// Deltas are buffered and flushed at most every streamProgressInterval so a long
+// response doesn't flood the progress pipe; each event is a JSON object with the
+// delta kind ("text", "thinking" or "json"), the new text, and the characters streamed so far.
+// When the stop reason is max_tokens an "ai_truncated" event is emitted immediately.
+type streamProgress struct {
+	onProgress  ProgressFunc
+	pendingKind string
+	pending     strings.Builder
+	chars       int
+	lastFlush   time.Time
+	stopReason  string // of the most recent response
+	exploring   bool   // streaming exploration steps, whose truncated output is not repaired
+}
+
+func newStreamProgress(onProgress ProgressFunc) *streamProgress {
+	return &streamProgress{onProgress: onProgress, lastFlush: time.Now()}
+}
+
+func (sp *streamProgress) onStream(kind, delta string) {
+	if kind == "stop" {
+		sp.flush()
+		sp.stopReason = delta
+		if delta == "max_tokens" && sp.onProgress != nil {
+			if sp.exploring {
+				sp.onProgress("ai_truncated", fmt.Sprintf("Response hit the output token limit after %d chars", sp.chars))
+			} else {
+				sp.onProgress("ai_truncated", fmt.Sprintf("Response hit the output token limit after %d chars; repairing partial output", sp.chars))
+			}
+		}
+		sp.chars = 0
+		return
+	}
+	if kind != sp.pendingKind {
+		sp.flush()
+		sp.pendingKind = kind
+	}
+	sp.pending.WriteString(delta)
+	sp.chars += len(delta)
+	if time.Since(sp.lastFlush) >= streamProgressInterval {
+		sp.flush()
+	}
+}
+
+func (sp *streamProgress) flush() {
+	sp.lastFlush = time.Now()
+	if sp.pending.Len() == 0 {
+		return
+	}
+	if sp.onProgress != nil {
+		data, _ := json.Marshal(map[string]interface{}{
+			"kind":  sp.pendingKind,
+			"text":  sp.pending.String(),
+			"chars": sp.chars,
+		})
+		sp.onProgress("ai_stream", string(data))
+	}
+	sp.pending.Reset()
+}
+
+// baseClientOf extracts the *BaseClient from a Client implementation. For fallback
+// chains this is the client that answered the most recent call.
 func baseClientOf(client Client) *BaseClient {
+	if cassette := CassetteOf(client); cassette != nil {
+		return baseClientOf(cassette.Inner)
+	}
+	if fb := FallbackOf(client); fb != nil {
+		return baseClientOf(fb.Active())
+	}
 	switch c := client.(type) {
 	case *ClaudeClient:
 		return &c.BaseClient
 	case *GeminiClient:
 		return &c.BaseClient
+	case *OpenAIClient:
+		return &c.BaseClient
 	default:
 		return nil
 	}
 }
 
+// baseClientsOf returns every *BaseClient behind client, unwrapping cassettes and
+// fallback chains.
+func baseClientsOf(client Client) []*BaseClient {
+	if cassette := CassetteOf(client); cassette != nil {
+		return baseClientsOf(cassette.Inner)
+	}
+	if fb := FallbackOf(client); fb != nil {
+		var all []*BaseClient
+		for _, inner := range fb.Clients {
+			all = append(all, baseClientsOf(inner)...)
+		}
+		return all
+	}
+	if bc := baseClientOf(client); bc != nil {
+		return []*BaseClient{bc}
+	}
+	return nil
+}
+
 // NewClientFromConfig creates an AI client from provider configuration.
 func NewClientFromConfig(provider, apiKey, model string, temperature float64, maxTokens int) (Client, error) {
 	switch provider {
@@ -91,9 +251,31 @@ func NewClientFromConfig(provider, apiKey, model string, temperature float64, ma
 		return NewClaudeClient(apiKey, model, temperature, maxTokens), nil
 	case "google", "gemini":
 		return NewGeminiClient(apiKey, model, temperature, maxTokens), nil
+	case "openai":
+		return NewOpenAIClient(apiKey, model, temperature, maxTokens), nil
 	default:
-		return nil, fmt.Errorf("unsupported AI provider: %s (use 'anthropic' or 'google')", provider)
+		return nil, fmt.Errorf("unsupported AI provider: %s (use 'anthropic', 'google' or 'openai')", provider)
+	}
+}
+
+// APIKeyEnvVar returns the environment variable holding the API key of an AI
+// provider, or "" for providers NewClientFromConfig does not support.
+func APIKeyEnvVar(provider string) string {
+	switch provider {
+	case "anthropic", "claude":
+		return "ANTHROPIC_API_KEY"
+	case "google", "gemini":
+		return "GEMINI_API_KEY"
+	case "openai":
+		return "OPENAI_API_KEY"
 	}
+	return ""
+}
+
+// APIKeyRequired reports whether a provider needs an API key. Only openai
+// pointed at a self-hosted server (baseURL set) may run without one.
+func APIKeyRequired(provider, baseURL string) bool {
+	return provider != "openai" || baseURL == ""
 }
 
 // NewAnalyzerFromConfig creates an analyzer from configuration
@@ -110,20 +292,27 @@ func (a *Analyzer) emitCostEstimate(progress func(step, message string)) {
 	if a.Usage.APICallCount == 0 && a.secondaryUsage.APICallCount == 0 {
 		return
 	}
-	model := ""
-	if bc := baseClientOf(a.Client); bc != nil {
+	model := a.Usage.Model // the model that actually answered, which differs from the primary after a failover
+	if bc := baseClientOf(a.Client); bc != nil && model == "" {
 		model = bc.Model
 	}
-	cost := a.Usage.EstimatedCost(model)
+	// Price at the start so the cost matches the record created then, even if
+	// prices change mid-run
+	pricedAt := a.StartedAt
+	if pricedAt.IsZero() {
+		pricedAt = time.Now()
+	}
+	cost := a.Usage.EstimatedCostAt(model, pricedAt)
 
 	// Combine secondary usage if present
 	totalCost := cost
 	secondaryModel := ""
 	if a.secondaryClient != nil && a.secondaryUsage.APICallCount > 0 {
-		if bc := baseClientOf(a.secondaryClient); bc != nil {
+		secondaryModel = a.secondaryUsage.Model
+		if bc := baseClientOf(a.secondaryClient); bc != nil && secondaryModel == "" {
 			secondaryModel = bc.Model
 		}
-		totalCost += a.secondaryUsage.EstimatedCost(secondaryModel)
+		totalCost += a.secondaryUsage.EstimatedCostAt(secondaryModel, pricedAt)
 	}
 
 	data := map[string]interface{}{
@@ -131,6 +320,7 @@ func (a *Analyzer) emitCostEstimate(progress func(step, message string)) {
 		"outputTokens":      a.Usage.OutputTokens + a.secondaryUsage.OutputTokens,
 		"cacheReadTokens":   a.Usage.CacheReadInputTokens + a.secondaryUsage.CacheReadInputTokens,
 		"cacheCreateTokens": a.Usage.CacheCreationInputTokens + a.secondaryUsage.CacheCreationInputTokens,
+		"thinkingTokens":    a.Usage.ThinkingTokens + a.secondaryUsage.ThinkingTokens,
 		"totalTokens":       a.Usage.TotalTokens + a.secondaryUsage.TotalTokens,
 		"apiCallCount":      a.Usage.APICallCount + a.secondaryUsage.APICallCount,
 		"costUsd":           totalCost,
@@ -140,6 +330,21 @@ func (a *Analyzer) emitCostEstimate(progress func(step, message string)) {
 	if secondaryModel != "" {
 		data["secondaryModel"] = secondaryModel
 	}
+	// Per-model breakdown when a fallback chain spread calls over several models
+	if models := usageByModel(pricedAt, a.Usage, a.secondaryUsage); len(models) > 1 {
+		data["models"] = models
+	}
+	// Unpriced models are reported as $0; say so instead of under-reporting silently
+	answered := []string{model, secondaryModel}
+	for _, u := range []TokenUsage{a.Usage, a.secondaryUsage} {
+		for m := range u.ByModel {
+			answered = append(answered, m)
+		}
+	}
+	if unpriced := unpricedModels(answered...); len(unpriced) > 0 {
+		data["unpricedModels"] = unpriced
+		progress("pricing_warning", fmt.Sprintf("No pricing for %s; their cost is not included (add them to the pricing file)", strings.Join(unpriced, ", ")))
+	}
 	jsonBytes, err := json.Marshal(data)
 	if err != nil {
 		log.Printf("Warning: failed to marshal cost estimate: %v", err)
@@ -148,6 +353,53 @@ func (a *Analyzer) emitCostEstimate(progress func(step, message string)) {
 	progress("cost_estimate", string(jsonBytes))
 }
 
+// usageByModel merges the per-model breakdowns of several usage totals into
+// model -> {apiCallCount, inputTokens, outputTokens, costUsd}, priced as of at.
+func usageByModel(at time.Time, usages ...TokenUsage) map[string]map[string]interface{} {
+	merged := map[string]*TokenUsage{}
+	for _, u := range usages {
+		for m, mu := range u.ByModel {
+			t := merged[m]
+			if t == nil {
+				t = &TokenUsage{}
+				merged[m] = t
+			}
+			t.InputTokens += mu.InputTokens
+			t.OutputTokens += mu.OutputTokens
+			t.CacheCreationInputTokens += mu.CacheCreationInputTokens
+			t.CacheReadInputTokens += mu.CacheReadInputTokens
+			t.APICallCount += mu.APICallCount
+		}
+	}
+	out := make(map[string]map[string]interface{}, len(merged))
+	for m, mu := range merged {
+		out[m] = map[string]interface{}{
+			"apiCallCount": mu.APICallCount,
+			"inputTokens":  mu.InputTokens,
+			"outputTokens": mu.OutputTokens,
+			"costUsd":      mu.EstimatedCostAt(m, at),
+		}
+	}
+	return out
+}
+
+// unpricedModels returns the distinct, non-empty models that have no known pricing.
+func unpricedModels(models ...string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, m := range models {
+		if m == "" || seen[m] {
+			continue
+		}
+		seen[m] = true
+		if !IsPriced(m) {
+			out = append(out, m)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
 // parseURLHints extracts game-relevant hints from URL parameters.
 func parseURLHints(gameURL string) map[string]string {
 	u, err := url.Parse(gameURL)
@@ -760,7 +1012,7 @@ func (a *Analyzer) generateFlowsStructured(ctx context.Context, gameURL, framewo
 		var err error
 		response, err = a.synthesisGenerate(ctx, prompt, map[string]interface{}{
 			"analysisJSON": string(analysisJSON),
-		})
+		}, onProgress)
 		if err != nil {
 			return nil, fmt.Errorf("flow generation failed: %w", err)
 		}
@@ -823,7 +1075,7 @@ func (a *Analyzer) GenerateScenarios(analysis *AnalysisResult) ([]TestScenario,
 	// Call AI (route through synthesis client if available)
 	response, err := a.synthesisGenerate(context.Background(), prompt, map[string]interface{}{
 		"analysis": analysisStr,
-	})
+	}, nil)
 	if err != nil {
 		return nil, fmt.Errorf("scenario generation failed: %w", err)
 	}
@@ -874,7 +1126,7 @@ func (a *Analyzer) GenerateFlows(scenarios []TestScenario) ([]*MaestroFlow, erro
 	// Call AI (route through synthesis client if available)
 	response, err := a.synthesisGenerate(context.Background(), prompt, map[string]interface{}{
 		"scenarios": scenariosStr,
-	})
+	}, nil)
 	if err != nil {
 		return nil, fmt.Errorf("flow generation failed: %w", err)
 	}
@@ -36,6 +36,14 @@ type BaseClient struct {
 	HTTPClient  *http.Client
 	caller      APICallerOnce
 	OnUsage     func(input, output, cacheCreate, cacheRead int) // optional callback for token usage tracking
+	// OnStream, when set, makes clients that support it stream their responses. It is
+	// called with kind "text", "thinking" or "json" (tool input) for each delta as it
+	// arrives, and with kind "stop" and the stop reason as soon as the API reports it.
+	OnStream func(kind, delta string)
+	// ThinkingBudget, when positive, enables extended thinking with that many budget
+	// tokens on tool-use calls of clients that support it (Claude). The API requires
+	// at least 1024.
+	ThinkingBudget int
 }
 
 // NewBaseClient creates a BaseClient with standard HTTP settings.
@@ -52,6 +60,27 @@ func NewBaseClient(apiKey, model string, temperature float64, maxTokens int, cal
 	}
 }
 
+// usageHookKey is the context key of a per-call usage hook.
+type usageHookKey struct{}
+
+// withUsageHook returns a context whose API calls also report their token usage to
+// hook. Unlike OnUsage, which is shared by every call on a client, the hook only
+// sees the calls made with this context.
+func withUsageHook(ctx context.Context, hook func(input, output, cacheCreate, cacheRead int)) context.Context {
+	return context.WithValue(ctx, usageHookKey{}, hook)
+}
+
+// reportUsage passes the token usage of one API call to OnUsage and to the usage
+// hook of ctx, if any.
+func (b *BaseClient) reportUsage(ctx context.Context, input, output, cacheCreate, cacheRead int) {
+	if b.OnUsage != nil {
+		b.OnUsage(input, output, cacheCreate, cacheRead)
+	}
+	if hook, ok := ctx.Value(usageHookKey{}).(func(input, output, cacheCreate, cacheRead int)); ok {
+		hook(input, output, cacheCreate, cacheRead)
+	}
+}
+
 // Analyze sends a prompt and returns structured analysis.
 func (b *BaseClient) Analyze(ctx context.Context, prompt string, ctxMap map[string]interface{}) (*AnalysisResult, error) {
 	fullPrompt := buildLegacyAnalysisPrompt(prompt, ctxMap)
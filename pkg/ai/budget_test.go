@@ -0,0 +1,24 @@
+package ai
+
+import "testing"
+
+func TestSpendBudgetExceeded(t *testing.T) {
+	var u TokenUsage
+	u.AddForModel("claude-sonnet-4-5-20250929", 100_000, 10_000, 0, 0) // $0.45
+
+	cases := []struct {
+		budget SpendBudget
+		want   bool
+	}{
+		{SpendBudget{}, false},
+		{SpendBudget{USD: 1}, false},
+		{SpendBudget{USD: 0.40}, true},
+		{SpendBudget{Tokens: 200_000}, false},
+		{SpendBudget{Tokens: 110_000}, true},
+	}
+	for _, c := range cases {
+		if got, reason := c.budget.Exceeded(&u, ""); got != c.want {
+			t.Errorf("%+v: Exceeded = %v (%s), want %v", c.budget, got, reason, c.want)
+		}
+	}
+}
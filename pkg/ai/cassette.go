@@ -0,0 +1,414 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CassetteMode selects whether a CassetteClient records live responses or replays them.
+type CassetteMode string
+
+const (
+	CassetteRecord CassetteMode = "record"
+	CassetteReplay CassetteMode = "replay"
+)
+
+// ParseCassetteMode validates a --ai-cassette flag value. An empty string means disabled.
+func ParseCassetteMode(s string) (CassetteMode, error) {
+	switch CassetteMode(s) {
+	case "", CassetteRecord, CassetteReplay:
+		return CassetteMode(s), nil
+	default:
+		return "", fmt.Errorf("invalid AI cassette mode %q (use 'record' or 'replay')", s)
+	}
+}
+
+// CassetteClient wraps an AI client and records every request/response pair to a
+// cassette directory, or serves them back from it without touching the network.
+//
+// Each interaction is stored as <seq>-<kind>-<key>.json, where key is a hash of the
+// prompt, system prompt, tools and message history. Image data is excluded from the
+// key because live screenshots never repeat byte-for-byte. Replay first looks for an
+// unplayed recording with the same key; if there is none (e.g. the agent history
+// embeds elapsed times) it falls back to the next unplayed recording of the same
+// kind, so a recorded agent loop replays in order.
+//
+// CassetteClient itself only implements Client. NewCassetteClient returns it
+// together with the image, tool-use and structured output methods of the
+// capabilities Inner has, so type assertions on the cassette answer the same as
+// on the client it wraps.
+type CassetteClient struct {
+	Inner Client
+	Dir   string
+	Mode  CassetteMode
+
+	mu      sync.Mutex
+	seq     int              // last sequence number written (record)
+	entries []*cassetteEntry // recordings in sequence order (replay)
+	played  map[int]bool     // sequence numbers already served (replay)
+}
+
+// cassetteEntry is the on-disk format of one recorded interaction.
+type cassetteEntry struct {
+	Seq      int             `json:"seq"`
+	Kind     string          `json:"kind"` // analyze, generate, image, images, tools, structured
+	Key      string          `json:"key"`
+	Model    string          `json:"model,omitempty"`
+	Response json.RawMessage `json:"response"`
+	Usage    *cassetteUsage  `json:"usage,omitempty"`
+}
+
+// cassetteUsage is the token usage reported by the wrapped client during a recorded call.
+type cassetteUsage struct {
+	InputTokens              int `json:"inputTokens"`
+	OutputTokens             int `json:"outputTokens"`
+	CacheCreationInputTokens int `json:"cacheCreationInputTokens"`
+	CacheReadInputTokens     int `json:"cacheReadInputTokens"`
+}
+
+// NewCassetteClient wraps inner with a recording or replaying cassette stored in dir.
+// Replay loads all recordings up front and fails if the directory has none.
+func NewCassetteClient(inner Client, dir string, mode CassetteMode) (Client, error) {
+	c := &CassetteClient{Inner: inner, Dir: dir, Mode: mode, played: map[int]bool{}}
+
+	switch mode {
+	case CassetteRecord:
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cassette dir: %w", err)
+		}
+		entries, err := loadCassette(dir)
+		if err != nil {
+			return nil, err
+		}
+		// Append after any existing recordings rather than overwriting them.
+		if n := len(entries); n > 0 {
+			c.seq = entries[n-1].Seq
+		}
+	case CassetteReplay:
+		entries, err := loadCassette(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("AI cassette %s has no recordings to replay", dir)
+		}
+		c.entries = entries
+	default:
+		return nil, fmt.Errorf("invalid AI cassette mode %q (use 'record' or 'replay')", mode)
+	}
+	return c.withCapabilities(), nil
+}
+
+// Capability mixins of a CassetteClient, combined by withCapabilities.
+type (
+	cassetteImages     struct{ c *CassetteClient }
+	cassetteTools      struct{ c *CassetteClient }
+	cassetteStructured struct{ c *CassetteClient }
+)
+
+// withCapabilities returns c with the optional interfaces its Inner implements.
+func (c *CassetteClient) withCapabilities() Client {
+	_, images := c.Inner.(ImageAnalyzer)
+	_, tools := c.Inner.(ToolUseAgent)
+	_, structured := c.Inner.(StructuredOutputAgent)
+	i, t, s := cassetteImages{c}, cassetteTools{c}, cassetteStructured{c}
+	switch {
+	case images && tools && structured:
+		return struct {
+			*CassetteClient
+			cassetteImages
+			cassetteTools
+			cassetteStructured
+		}{c, i, t, s}
+	case images && tools:
+		return struct {
+			*CassetteClient
+			cassetteImages
+			cassetteTools
+		}{c, i, t}
+	case images && structured:
+		return struct {
+			*CassetteClient
+			cassetteImages
+			cassetteStructured
+		}{c, i, s}
+	case tools && structured:
+		return struct {
+			*CassetteClient
+			cassetteTools
+			cassetteStructured
+		}{c, t, s}
+	case images:
+		return struct {
+			*CassetteClient
+			cassetteImages
+		}{c, i}
+	case tools:
+		return struct {
+			*CassetteClient
+			cassetteTools
+		}{c, t}
+	case structured:
+		return struct {
+			*CassetteClient
+			cassetteStructured
+		}{c, s}
+	}
+	return c
+}
+
+// cassette lets CassetteOf find the CassetteClient inside the capability wrappers.
+func (c *CassetteClient) cassette() *CassetteClient { return c }
+
+// CassetteOf returns the CassetteClient client is, or nil if it is not a cassette.
+func CassetteOf(client Client) *CassetteClient {
+	if w, ok := client.(interface{ cassette() *CassetteClient }); ok {
+		return w.cassette()
+	}
+	return nil
+}
+
+// loadCassette reads all recordings in dir, sorted by sequence number.
+func loadCassette(dir string) ([]*cassetteEntry, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cassette dir: %w", err)
+	}
+	var entries []*cassetteEntry
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette entry: %w", err)
+		}
+		var e cassetteEntry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette entry %s: %w", filepath.Base(f), err)
+		}
+		if e.Seq == 0 {
+			// Tolerate hand-written entries: take the sequence from the file name prefix.
+			e.Seq, _ = strconv.Atoi(strings.SplitN(filepath.Base(f), "-", 2)[0])
+		}
+		entries = append(entries, &e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Seq < entries[j].Seq })
+	return entries, nil
+}
+
+// cassetteKey hashes a request description. Base64 image payloads and prompt-caching
+// markers are stripped first so the key only depends on the textual request.
+func cassetteKey(kind string, parts ...interface{}) string {
+	raw, err := json.Marshal(parts)
+	if err != nil {
+		raw = []byte(fmt.Sprint(parts...))
+	}
+	var generic interface{}
+	if json.Unmarshal(raw, &generic) == nil {
+		stripCassetteVolatile(generic)
+		raw, _ = json.Marshal(generic)
+	}
+	sum := sha256.Sum256(append([]byte(kind+"\x00"), raw...))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// stripCassetteVolatile removes image data and cache_control fields in place.
+func stripCassetteVolatile(v interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		delete(t, "cache_control")
+		if t["type"] == "image" {
+			delete(t, "source")
+		}
+		for _, child := range t {
+			stripCassetteVolatile(child)
+		}
+	case []interface{}:
+		for _, child := range t {
+			stripCassetteVolatile(child)
+		}
+	}
+}
+
+// do records or replays one interaction. call performs the live request with the
+// given context and returns the value to store; out receives the stored value in
+// both modes.
+func (c *CassetteClient) do(ctx context.Context, kind, key string, out interface{}, call func(ctx context.Context) (interface{}, error)) error {
+	if c.Mode == CassetteReplay {
+		return c.replay(kind, key, out)
+	}
+
+	// Collect the usage of this call only; the wrapped clients still report it to
+	// their own OnUsage callbacks.
+	var usage *cassetteUsage
+	ctx = withUsageHook(ctx, func(input, output, cacheCreate, cacheRead int) {
+		if usage == nil {
+			usage = &cassetteUsage{}
+		}
+		usage.InputTokens += input
+		usage.OutputTokens += output
+		usage.CacheCreationInputTokens += cacheCreate
+		usage.CacheReadInputTokens += cacheRead
+	})
+	resp, err := call(ctx)
+	if err != nil {
+		// Failed calls are not recorded: replayed errors would lose their type and
+		// break retry classification, so the cassette only holds successful answers.
+		return err
+	}
+
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette response: %w", err)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("failed to decode cassette response: %w", err)
+	}
+	entry := &cassetteEntry{Kind: kind, Key: key, Model: c.model(), Response: raw, Usage: usage}
+	if err := c.write(entry); err != nil {
+		log.Printf("Warning: failed to record AI cassette entry: %v", err)
+	}
+	return nil
+}
+
+// write assigns the next sequence number and saves the entry to disk.
+func (c *CassetteClient) write(entry *cassetteEntry) error {
+	c.mu.Lock()
+	c.seq++
+	entry.Seq = c.seq
+	c.mu.Unlock()
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%04d-%s-%s.json", entry.Seq, entry.Kind, entry.Key)
+	return os.WriteFile(filepath.Join(c.Dir, name), data, 0644)
+}
+
+// replay serves the recording matching key, or the next unplayed one of the same kind.
+func (c *CassetteClient) replay(kind, key string, out interface{}) error {
+	c.mu.Lock()
+	var match *cassetteEntry
+	for _, e := range c.entries {
+		if !c.played[e.Seq] && e.Kind == kind && e.Key == key {
+			match = e
+			break
+		}
+	}
+	if match == nil {
+		for _, e := range c.entries {
+			if !c.played[e.Seq] && e.Kind == kind {
+				match = e
+				log.Printf("AI cassette: no exact match for %s request %s, replaying #%d in order", kind, key, e.Seq)
+				break
+			}
+		}
+	}
+	if match != nil {
+		c.played[match.Seq] = true
+	}
+	c.mu.Unlock()
+
+	if match == nil {
+		return fmt.Errorf("AI cassette %s has no recording left for %s request %s", c.Dir, kind, key)
+	}
+
+	if match.Usage != nil {
+		if bc := c.usageTarget(match.Model); bc != nil && bc.OnUsage != nil {
+			bc.OnUsage(match.Usage.InputTokens, match.Usage.OutputTokens,
+				match.Usage.CacheCreationInputTokens, match.Usage.CacheReadInputTokens)
+		}
+	}
+	if err := json.Unmarshal(match.Response, out); err != nil {
+		return fmt.Errorf("failed to decode cassette entry #%d: %w", match.Seq, err)
+	}
+	return nil
+}
+
+// usageTarget returns the BaseClient whose usage callback should receive replayed
+// usage: the one for the recorded model if it is in a fallback chain, else the primary.
+func (c *CassetteClient) usageTarget(model string) *BaseClient {
+	for _, bc := range baseClientsOf(c.Inner) {
+		if bc.Model == model {
+			return bc
+		}
+	}
+	return baseClientOf(c.Inner)
+}
+
+// model returns the wrapped client's model name, if known.
+func (c *CassetteClient) model() string {
+	if bc := baseClientOf(c.Inner); bc != nil {
+		return bc.Model
+	}
+	return ""
+}
+
+// Analyze records or replays Client.Analyze.
+func (c *CassetteClient) Analyze(ctx context.Context, prompt string, ctxMap map[string]interface{}) (*AnalysisResult, error) {
+	var result AnalysisResult
+	err := c.do(ctx, "analyze", cassetteKey("analyze", prompt, ctxMap), &result, func(ctx context.Context) (interface{}, error) {
+		return c.Inner.Analyze(ctx, prompt, ctxMap)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Generate records or replays Client.Generate.
+func (c *CassetteClient) Generate(ctx context.Context, prompt string, ctxMap map[string]interface{}) (string, error) {
+	var text string
+	err := c.do(ctx, "generate", cassetteKey("generate", prompt, ctxMap), &text, func(ctx context.Context) (interface{}, error) {
+		return c.Inner.Generate(ctx, prompt, ctxMap)
+	})
+	return text, err
+}
+
+// AnalyzeWithImage records or replays ImageAnalyzer.AnalyzeWithImage.
+func (w cassetteImages) AnalyzeWithImage(ctx context.Context, prompt string, imageB64 string) (string, error) {
+	var text string
+	err := w.c.do(ctx, "image", cassetteKey("image", prompt), &text, func(ctx context.Context) (interface{}, error) {
+		return w.c.Inner.(ImageAnalyzer).AnalyzeWithImage(ctx, prompt, imageB64)
+	})
+	return text, err
+}
+
+// AnalyzeWithImages records or replays ImageAnalyzer.AnalyzeWithImages.
+func (w cassetteImages) AnalyzeWithImages(ctx context.Context, systemPrompt string, prompt string, imagesB64 []string) (string, error) {
+	var text string
+	err := w.c.do(ctx, "images", cassetteKey("images", systemPrompt, prompt, len(imagesB64)), &text, func(ctx context.Context) (interface{}, error) {
+		return w.c.Inner.(ImageAnalyzer).AnalyzeWithImages(ctx, systemPrompt, prompt, imagesB64)
+	})
+	return text, err
+}
+
+// CallWithTools records or replays ToolUseAgent.CallWithTools.
+func (w cassetteTools) CallWithTools(ctx context.Context, systemPrompt string, messages []AgentMessage, tools []ToolDefinition) (*ToolUseResponse, error) {
+	var resp ToolUseResponse
+	err := w.c.do(ctx, "tools", cassetteKey("tools", systemPrompt, messages, tools), &resp, func(ctx context.Context) (interface{}, error) {
+		return w.c.Inner.(ToolUseAgent).CallWithTools(ctx, systemPrompt, messages, tools)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GenerateStructured records or replays StructuredOutputAgent.GenerateStructured.
+func (w cassetteStructured) GenerateStructured(ctx context.Context, systemPrompt string, messages []AgentMessage, schema OutputSchema) (string, error) {
+	var doc string
+	err := w.c.do(ctx, "structured", cassetteKey("structured", systemPrompt, messages, schema.Name, schema.Schema), &doc, func(ctx context.Context) (interface{}, error) {
+		return w.c.Inner.(StructuredOutputAgent).GenerateStructured(ctx, systemPrompt, messages, schema)
+	})
+	return doc, err
+}
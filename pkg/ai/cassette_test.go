@@ -0,0 +1,150 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeToolClient is a Client + ToolUseAgent that counts calls and returns canned answers.
+type fakeToolClient struct {
+	calls int
+}
+
+func (f *fakeToolClient) Analyze(ctx context.Context, prompt string, ctxMap map[string]interface{}) (*AnalysisResult, error) {
+	f.calls++
+	return &AnalysisResult{RawResponse: "analysis of " + prompt}, nil
+}
+
+func (f *fakeToolClient) Generate(ctx context.Context, prompt string, ctxMap map[string]interface{}) (string, error) {
+	f.calls++
+	return fmt.Sprintf("answer %d to %s", f.calls, prompt), nil
+}
+
+func (f *fakeToolClient) CallWithTools(ctx context.Context, systemPrompt string, messages []AgentMessage, tools []ToolDefinition) (*ToolUseResponse, error) {
+	f.calls++
+	resp := &ToolUseResponse{StopReason: "tool_use"}
+	resp.Content = []ResponseContentBlock{{Type: "tool_use", ID: fmt.Sprintf("call_%d", f.calls), Name: "click"}}
+	return resp, nil
+}
+
+func TestCassetteRecordThenReplay(t *testing.T) {
+	dir := t.TempDir()
+	live := &fakeToolClient{}
+	rec, err := NewCassetteClient(live, dir, CassetteRecord)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	ctx := context.Background()
+	first, _ := rec.Generate(ctx, "a", nil)
+	second, _ := rec.Generate(ctx, "b", nil)
+	history := []AgentMessage{{Role: "user", Content: []interface{}{
+		map[string]interface{}{"type": "image", "source": map[string]interface{}{"type": "base64", "data": "LIVE1"}},
+	}}}
+	toolResp, err := rec.(ToolUseAgent).CallWithTools(ctx, "sys", history, BrowserTools(800, 600))
+	if err != nil {
+		t.Fatalf("record CallWithTools: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 3 || live.calls != 3 {
+		t.Fatalf("expected 3 recordings and 3 live calls, got %d files, %d calls", len(files), live.calls)
+	}
+
+	offline := &fakeToolClient{}
+	rep, err := NewCassetteClient(offline, dir, CassetteReplay)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+
+	// Out of order: exact key match must win over recording order.
+	if got, _ := rep.Generate(ctx, "b", nil); got != second {
+		t.Errorf("replayed %q, want %q", got, second)
+	}
+	if got, _ := rep.Generate(ctx, "a", nil); got != first {
+		t.Errorf("replayed %q, want %q", got, first)
+	}
+	// A different screenshot must not change the key.
+	history[0].Content.([]interface{})[0].(map[string]interface{})["source"] = map[string]interface{}{"type": "base64", "data": "LIVE2"}
+	replayed, err := rep.(ToolUseAgent).CallWithTools(ctx, "sys", history, BrowserTools(800, 600))
+	if err != nil || replayed.Content[0].ID != toolResp.Content[0].ID {
+		t.Errorf("replayed tool response = %+v, %v", replayed, err)
+	}
+	if offline.calls != 0 {
+		t.Errorf("replay must not call the wrapped client, got %d calls", offline.calls)
+	}
+	if _, err := rep.Generate(ctx, "a", nil); err == nil {
+		t.Error("expected an error once the cassette is exhausted")
+	}
+}
+
+func TestCassetteReplayFallsBackToRecordingOrder(t *testing.T) {
+	dir := t.TempDir()
+	rec, _ := NewCassetteClient(&fakeToolClient{}, dir, CassetteRecord)
+	ctx := context.Background()
+	agent := rec.(ToolUseAgent)
+	agent.CallWithTools(ctx, "sys", []AgentMessage{{Role: "user", Content: "step 1, 3s elapsed"}}, nil)
+	agent.CallWithTools(ctx, "sys", []AgentMessage{{Role: "user", Content: "step 2, 9s elapsed"}}, nil)
+
+	rep, err := NewCassetteClient(&fakeToolClient{}, dir, CassetteReplay)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	for i, want := range []string{"call_1", "call_2"} {
+		resp, err := rep.(ToolUseAgent).CallWithTools(ctx, "sys", []AgentMessage{{Role: "user", Content: fmt.Sprintf("step %d, 4s elapsed", i+1)}}, nil)
+		if err != nil || resp.Content[0].ID != want {
+			t.Errorf("step %d: got %+v, %v; want %s", i+1, resp, err, want)
+		}
+	}
+}
+
+func TestCassetteReplayReportsUsage(t *testing.T) {
+	dir := t.TempDir()
+	entry := `{"seq":1,"kind":"generate","key":"x","model":"claude-sonnet-4-5","response":"{}","usage":{"inputTokens":100,"outputTokens":20}}`
+	if err := os.WriteFile(filepath.Join(dir, "0001-generate-x.json"), []byte(entry), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := NewCassetteClient(NewClaudeClient("", "claude-sonnet-4-5", 0, 1024), dir, CassetteReplay)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	analyzer := NewAnalyzer(rep)
+	if got, err := rep.Generate(context.Background(), "anything", nil); err != nil || got != "{}" {
+		t.Fatalf("Generate = %q, %v", got, err)
+	}
+	if analyzer.Usage.InputTokens != 100 || analyzer.Usage.OutputTokens != 20 {
+		t.Errorf("replayed usage not reported: %+v", analyzer.Usage)
+	}
+}
+
+func TestCassetteMatchesInnerCapabilities(t *testing.T) {
+	rec, err := NewCassetteClient(&fakeToolClient{}, t.TempDir(), CassetteRecord)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if _, ok := rec.(ToolUseAgent); !ok {
+		t.Error("cassette over a tool-use client must support tool use")
+	}
+	if _, ok := rec.(ImageAnalyzer); ok {
+		t.Error("cassette must not claim image analysis its client lacks")
+	}
+	if _, ok := rec.(StructuredOutputAgent); ok {
+		t.Error("cassette must not claim structured output its client lacks")
+	}
+	if CassetteOf(rec) == nil {
+		t.Error("CassetteOf must see through the capability wrapper")
+	}
+
+	claude := NewClaudeClient("", "claude-sonnet-4-5", 0, 1024)
+	full, _ := NewCassetteClient(claude, t.TempDir(), CassetteRecord)
+	if _, ok := full.(StructuredOutputAgent); !ok {
+		t.Error("cassette over Claude must support structured output")
+	}
+	if baseClientOf(full) != &claude.BaseClient {
+		t.Error("baseClientOf must unwrap the cassette")
+	}
+}
@@ -0,0 +1,162 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// claudeStreamEvent is one server-sent event of a streamed Messages API response.
+// Only the fields used by the event types we handle are declared.
+type claudeStreamEvent struct {
+	Type    string `json:"type"`
+	Index   int    `json:"index"`
+	Message struct {
+		Model string `json:"model"`
+		Usage struct {
+			InputTokens              int `json:"input_tokens"`
+			OutputTokens             int `json:"output_tokens"`
+			CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	ContentBlock ResponseContentBlock `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		Thinking    string `json:"thinking"`
+		Signature   string `json:"signature"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// doStreamRequest sends a request with "stream": true and assembles the server-sent
+// events into a ToolUseResponse, reporting deltas through OnStream as they arrive.
+func (c *ClaudeClient) doStreamRequest(ctx context.Context, reqBody []byte) (*ToolUseResponse, error) {
+	resp, err := c.send(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	emit := func(kind, delta string) {
+		if c.OnStream != nil && delta != "" {
+			c.OnStream(kind, delta)
+		}
+	}
+
+	var out ToolUseResponse
+	partialJSON := map[int]*strings.Builder{} // tool_use input fragments, by block index
+	done := false
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue // "event:" lines repeat the type carried in the data payload
+		}
+		var ev claudeStreamEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(line[len("data:"):])), &ev); err != nil {
+			return nil, fmt.Errorf("failed to parse stream event: %w", err)
+		}
+
+		switch ev.Type {
+		case "message_start":
+			out.Model = ev.Message.Model
+			out.Usage.InputTokens = ev.Message.Usage.InputTokens
+			out.Usage.OutputTokens = ev.Message.Usage.OutputTokens
+			out.Usage.CacheCreationInputTokens = ev.Message.Usage.CacheCreationInputTokens
+			out.Usage.CacheReadInputTokens = ev.Message.Usage.CacheReadInputTokens
+		case "content_block_start":
+			for len(out.Content) <= ev.Index {
+				out.Content = append(out.Content, ResponseContentBlock{})
+			}
+			out.Content[ev.Index] = ev.ContentBlock
+			partialJSON[ev.Index] = &strings.Builder{}
+			emit("text", ev.ContentBlock.Text)
+		case "content_block_delta":
+			if ev.Index >= len(out.Content) {
+				return nil, fmt.Errorf("stream delta for unknown content block %d", ev.Index)
+			}
+			switch ev.Delta.Type {
+			case "text_delta":
+				out.Content[ev.Index].Text += ev.Delta.Text
+				emit("text", ev.Delta.Text)
+			case "thinking_delta":
+				out.Content[ev.Index].Thinking += ev.Delta.Thinking
+				emit("thinking", ev.Delta.Thinking)
+			case "signature_delta":
+				out.Content[ev.Index].Signature += ev.Delta.Signature
+			case "input_json_delta":
+				partialJSON[ev.Index].WriteString(ev.Delta.PartialJSON)
+				emit("json", ev.Delta.PartialJSON)
+			}
+		case "content_block_stop":
+			if ev.Index < len(out.Content) && out.Content[ev.Index].Type == "tool_use" {
+				input := partialJSON[ev.Index].String()
+				if !json.Valid([]byte(input)) {
+					// Cut off by max_tokens: close it up so the response stays marshalable
+					if repaired, err := repairTruncatedJSON(input); err == nil && json.Valid([]byte(repaired)) {
+						input = repaired
+					} else {
+						input = "{}"
+					}
+				}
+				out.Content[ev.Index].Input = json.RawMessage(input)
+			}
+		case "message_delta":
+			out.StopReason = ev.Delta.StopReason
+			out.Usage.OutputTokens = ev.Usage.OutputTokens
+			if out.StopReason == "max_tokens" {
+				log.Printf("WARNING: Claude stream hit max_tokens (%d output tokens, maxTokens=%d)", out.Usage.OutputTokens, c.MaxTokens)
+			}
+			// Report the stop reason now rather than after message_stop so callers can
+			// start handling a truncated response straight away.
+			emit("stop", out.StopReason)
+		case "message_stop":
+			done = true
+		case "error":
+			// Mid-stream errors arrive as events on a 200 response; map the ones the
+			// API documents as transient to a retryable status.
+			status := 500
+			if ev.Error.Type == "overloaded_error" {
+				status = 529
+			} else if ev.Error.Type == "invalid_request_error" {
+				status = 400
+			}
+			return nil, &apiStatusError{StatusCode: status, Message: fmt.Sprintf("API stream error (%s): %s", ev.Error.Type, ev.Error.Message)}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response stream: %w", err)
+	}
+	if !done {
+		return nil, fmt.Errorf("response stream ended before message_stop")
+	}
+	// Thinking blocks stay in the content: with extended thinking on, the API requires
+	// them (signature included) to be sent back with the tool results.
+	return &out, nil
+}
+
+// responseText joins the text blocks of a response.
+func responseText(resp *ToolUseResponse) string {
+	var sb strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	return sb.String()
+}
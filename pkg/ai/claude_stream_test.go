@@ -0,0 +1,126 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc serves HTTP requests from a function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func sseResponse(events ...string) *http.Response {
+	var body strings.Builder
+	for _, ev := range events {
+		body.WriteString("data: " + ev + "\n\n")
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(strings.NewReader(body.String())),
+	}
+}
+
+func TestClaudeStreamAssemblesToolUse(t *testing.T) {
+	c := NewClaudeClient("key", "claude-sonnet-4-5-20250929", 0, 1024)
+	c.HTTPClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return sseResponse(
+			`{"type":"message_start","message":{"model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":100,"output_tokens":1,"cache_read_input_tokens":40}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Clicking "}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"play"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"click","input":{}}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"x\": 1"}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":", \"y\": 2}"}}`,
+			`{"type":"content_block_stop","index":1}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":25}}`,
+			`{"type":"message_stop"}`,
+		), nil
+	})
+
+	var deltas []string
+	c.OnStream = func(kind, delta string) { deltas = append(deltas, kind+":"+delta) }
+
+	resp, err := c.CallWithTools(context.Background(), "system", []AgentMessage{{Role: "user", Content: "go"}}, BrowserTools(800, 600))
+	if err != nil {
+		t.Fatalf("CallWithTools: %v", err)
+	}
+	if resp.StopReason != "tool_use" || resp.Usage.InputTokens != 100 || resp.Usage.OutputTokens != 25 || resp.Usage.CacheReadInputTokens != 40 {
+		t.Errorf("unexpected response metadata: %+v", resp)
+	}
+	if len(resp.Content) != 2 || resp.Content[0].Text != "Clicking play" {
+		t.Fatalf("unexpected content: %+v", resp.Content)
+	}
+	if tu := resp.Content[1]; tu.ID != "toolu_1" || tu.Name != "click" || string(tu.Input) != `{"x": 1, "y": 2}` {
+		t.Errorf("tool_use block = %+v (input %s)", tu, tu.Input)
+	}
+	if strings.Join(deltas, "|") != `text:Clicking |text:play|json:{"x": 1|json:, "y": 2}|stop:tool_use` {
+		t.Errorf("deltas = %q", deltas)
+	}
+}
+
+func TestClaudeStreamErrorEventIsRetryable(t *testing.T) {
+	c := NewClaudeClient("key", "claude-sonnet-4-5-20250929", 0, 1024)
+	c.HTTPClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return sseResponse(
+			`{"type":"message_start","message":{"usage":{"input_tokens":10}}}`,
+			`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+		), nil
+	})
+	c.OnStream = func(kind, delta string) {}
+
+	_, err := c.callAPIOnce(context.Background(), "hi")
+	if err == nil || !IsRetryableAPIError(err) {
+		t.Fatalf("expected a retryable error, got %v", err)
+	}
+}
+
+func TestStreamProgressReportsTruncation(t *testing.T) {
+	var events []string
+	sp := newStreamProgress(func(step, message string) { events = append(events, step) })
+
+	sp.onStream("text", `{"mechanics": [`)
+	sp.onStream("stop", "max_tokens")
+
+	if sp.stopReason != "max_tokens" {
+		t.Errorf("stopReason = %q", sp.stopReason)
+	}
+	if strings.Join(events, ",") != "ai_stream,ai_truncated" {
+		t.Errorf("events = %v, want buffered text flushed before the truncation notice", events)
+	}
+
+	// Exploration steps are streamed too, but their truncated output is not repaired
+	var message string
+	sp = newStreamProgress(func(step, m string) { message = m })
+	sp.exploring = true
+	sp.onStream("stop", "max_tokens")
+	if strings.Contains(message, "repairing") {
+		t.Errorf("exploration truncation should not promise a repair: %q", message)
+	}
+}
+
+func TestStreamToRestoresPreviousCallback(t *testing.T) {
+	c := NewClaudeClient("key", "claude-sonnet-4-5-20250929", 0, 1024)
+	var got []string
+	outer := func(kind, delta string) { got = append(got, "outer:"+delta) }
+
+	restoreOuter := streamTo(c, outer)
+	restoreInner := streamTo(c, nil) // detached, e.g. for a context summary call
+	if c.OnStream != nil {
+		t.Fatal("expected the stream to be detached")
+	}
+	restoreInner()
+	if c.OnStream == nil {
+		t.Fatal("inner restore cleared the outer callback")
+	}
+	c.OnStream("text", "x")
+	restoreOuter()
+	if c.OnStream != nil || strings.Join(got, ",") != "outer:x" {
+		t.Errorf("OnStream after restore = %v, calls = %v", c.OnStream != nil, got)
+	}
+}
@@ -36,6 +36,7 @@ type claudeRequest struct {
 	Temperature float64   `json:"temperature,omitempty"`
 	System      string    `json:"system,omitempty"`
 	Messages    []message `json:"messages"`
+	Stream      bool      `json:"stream,omitempty"`
 }
 
 // message represents a message in the conversation (text-only)
@@ -93,6 +94,22 @@ type claudeResponse struct {
 
 // doRequest sends a pre-marshalled request body to the Claude API and returns the raw response bytes.
 func (c *ClaudeClient) doRequest(ctx context.Context, reqBody []byte) ([]byte, error) {
+	resp, err := c.send(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
+}
+
+// send posts a pre-marshalled request body to the Claude API. Non-200 responses are
+// returned as *apiStatusError; on success the caller must close the response body.
+func (c *ClaudeClient) send(ctx context.Context, reqBody []byte) (*http.Response, error) {
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -107,18 +124,17 @@ func (c *ClaudeClient) doRequest(ctx context.Context, reqBody []byte) ([]byte, e
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &apiStatusError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("API returned status %d: %s", resp.StatusCode, string(body)),
+		}
 	}
 
-	return body, nil
+	return resp, nil
 }
 
 // unmarshalTextResponse parses a claudeResponse and returns the first text content block.
@@ -147,11 +163,21 @@ func (c *ClaudeClient) callAPIOnce(ctx context.Context, prompt string) (string,
 		MaxTokens:   c.MaxTokens,
 		Temperature: c.Temperature,
 		Messages:    []message{{Role: "user", Content: prompt}},
+		Stream:      c.OnStream != nil,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	if c.OnStream != nil {
+		resp, err := c.doStreamRequest(ctx, reqBody)
+		if err != nil {
+			return "", err
+		}
+		c.reportUsage(ctx, resp.Usage.InputTokens, resp.Usage.OutputTokens, 0, 0)
+		return responseText(resp), nil
+	}
+
 	body, err := c.doRequest(ctx, reqBody)
 	if err != nil {
 		return "", err
@@ -160,9 +186,7 @@ func (c *ClaudeClient) callAPIOnce(ctx context.Context, prompt string) (string,
 	if err != nil {
 		return "", err
 	}
-	if c.OnUsage != nil {
-		c.OnUsage(resp.Usage.InputTokens, resp.Usage.OutputTokens, 0, 0)
-	}
+	c.reportUsage(ctx, resp.Usage.InputTokens, resp.Usage.OutputTokens, 0, 0)
 	return text, nil
 }
 
@@ -196,9 +220,7 @@ func (c *ClaudeClient) AnalyzeWithImages(ctx context.Context, systemPrompt strin
 	if err != nil {
 		return "", err
 	}
-	if c.OnUsage != nil {
-		c.OnUsage(resp.Usage.InputTokens, resp.Usage.OutputTokens, 0, 0)
-	}
+	c.reportUsage(ctx, resp.Usage.InputTokens, resp.Usage.OutputTokens, 0, 0)
 	return text, nil
 }
 
@@ -223,24 +245,37 @@ type AgentMessage struct {
 	Content interface{} `json:"content"`
 }
 
-// ResponseContentBlock is a polymorphic content block: text or tool_use.
+// ResponseContentBlock is a polymorphic content block: text, tool_use, or one of the
+// extended thinking blocks (thinking, redacted_thinking).
 type ResponseContentBlock struct {
 	Type  string          `json:"type"`
 	Text  string          `json:"text,omitempty"`
 	ID    string          `json:"id,omitempty"`
 	Name  string          `json:"name,omitempty"`
 	Input json.RawMessage `json:"input,omitempty"`
+	// Thinking and Signature carry a Claude thinking block; Data carries a
+	// redacted_thinking block. Both must be sent back unmodified on later turns.
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Data      string `json:"data,omitempty"`
+	// ThoughtSignature is Gemini's opaque reasoning token, echoed back on later turns.
+	// It is never set on Claude responses.
+	ThoughtSignature string `json:"thoughtSignature,omitempty"`
 }
 
 // ToolUseResponse extends response to include StopReason for tool_use detection.
 type ToolUseResponse struct {
 	Content    []ResponseContentBlock `json:"content"`
 	StopReason string                 `json:"stop_reason"`
+	Model      string                 `json:"model,omitempty"` // model that produced the response
 	Usage      struct {
 		InputTokens              int `json:"input_tokens"`
 		OutputTokens             int `json:"output_tokens"`
 		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
 		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+		// ThinkingTokens is the part of OutputTokens spent on extended thinking. Claude
+		// does not report it, so it is estimated there (see estimateThinkingTokens).
+		ThinkingTokens int `json:"thinking_tokens,omitempty"`
 	} `json:"usage"`
 }
 
@@ -255,16 +290,52 @@ type ToolResultBlock struct {
 
 // claudeToolUseRequest is the request body for tool use calls.
 type claudeToolUseRequest struct {
-	Model       string           `json:"model"`
-	MaxTokens   int              `json:"max_tokens"`
-	Temperature float64          `json:"temperature,omitempty"`
-	System      interface{}      `json:"system,omitempty"` // string or []map[string]interface{} for prompt caching
-	Tools       []ToolDefinition `json:"tools,omitempty"`
-	Messages    []AgentMessage   `json:"messages"`
+	Model       string            `json:"model"`
+	MaxTokens   int               `json:"max_tokens"`
+	Temperature float64           `json:"temperature,omitempty"`
+	System      interface{}       `json:"system,omitempty"` // string or []map[string]interface{} for prompt caching
+	Tools       []ToolDefinition  `json:"tools,omitempty"`
+	ToolChoice  *claudeToolChoice `json:"tool_choice,omitempty"`
+	Thinking    *claudeThinking   `json:"thinking,omitempty"`
+	Messages    []AgentMessage    `json:"messages"`
+	Stream      bool              `json:"stream,omitempty"`
+}
+
+// claudeThinking enables extended thinking ({"type": "enabled", "budget_tokens": N}).
+type claudeThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
+}
+
+// claudeToolChoice forces the model to call a specific tool ({"type": "tool", "name": ...}).
+type claudeToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
 }
 
 // CallWithTools sends a tool-use request to the Claude API.
 func (c *ClaudeClient) CallWithTools(ctx context.Context, systemPrompt string, messages []AgentMessage, tools []ToolDefinition) (*ToolUseResponse, error) {
+	return c.callTools(ctx, systemPrompt, messages, tools, nil)
+}
+
+// GenerateStructured implements StructuredOutputAgent by offering the schema as the
+// only tool and forcing the model to call it; the tool input is the document.
+func (c *ClaudeClient) GenerateStructured(ctx context.Context, systemPrompt string, messages []AgentMessage, schema OutputSchema) (string, error) {
+	tool := ToolDefinition{Name: schema.Name, Description: schema.Description, InputSchema: schema.Schema}
+	resp, err := c.callTools(ctx, systemPrompt, messages, []ToolDefinition{tool}, &claudeToolChoice{Type: "tool", Name: schema.Name})
+	if err != nil {
+		return "", err
+	}
+	for _, block := range resp.Content {
+		if block.Type == "tool_use" && block.Name == schema.Name {
+			return string(block.Input), nil
+		}
+	}
+	return "", fmt.Errorf("response did not call %s (stop_reason=%s)", schema.Name, resp.StopReason)
+}
+
+// callTools sends a tool-use request, optionally forcing the tool choice.
+func (c *ClaudeClient) callTools(ctx context.Context, systemPrompt string, messages []AgentMessage, tools []ToolDefinition, toolChoice *claudeToolChoice) (*ToolUseResponse, error) {
 	// Wrap system prompt as cacheable content block for Anthropic prompt caching.
 	// This caches the system prompt prefix across turns, saving ~80% on cached input tokens.
 	var systemContent interface{}
@@ -290,39 +361,161 @@ func (c *ClaudeClient) CallWithTools(ctx context.Context, systemPrompt string, m
 	// This caches the entire conversation prefix, so only the latest tool result is new.
 	addConversationCacheBreakpoint(messages)
 
-	reqBody, err := json.Marshal(claudeToolUseRequest{
+	req := claudeToolUseRequest{
 		Model:       c.Model,
 		MaxTokens:   c.MaxTokens,
 		Temperature: c.Temperature,
 		System:      systemContent,
 		Tools:       toolsCopy,
-		Messages:    messages,
-	})
+		ToolChoice:  toolChoice,
+		Messages:    withoutThoughtSignatures(messages),
+		Stream:      c.OnStream != nil,
+	}
+	// Extended thinking is incompatible with a forced tool choice and with any
+	// temperature but the default. The thinking budget counts towards max_tokens,
+	// so add it on top to leave the response its usual room.
+	if c.ThinkingBudget > 0 && toolChoice == nil {
+		req.Thinking = &claudeThinking{Type: "enabled", BudgetTokens: c.ThinkingBudget}
+		req.Temperature = 0
+		req.MaxTokens += c.ThinkingBudget
+	}
+
+	reqBody, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal tool use request: %w", err)
 	}
 
 	log.Printf("CallWithTools: sending %d bytes (%d messages, %d tools)", len(reqBody), len(messages), len(tools))
 	start := time.Now()
-	body, err := c.doRequest(ctx, reqBody)
+	var toolResp ToolUseResponse
+	if c.OnStream != nil {
+		resp, err := c.doStreamRequest(ctx, reqBody)
+		if err != nil {
+			log.Printf("CallWithTools: stream failed after %s: %v", time.Since(start), err)
+			return nil, err
+		}
+		toolResp = *resp
+	} else {
+		body, err := c.doRequest(ctx, reqBody)
+		if err != nil {
+			log.Printf("CallWithTools: failed after %s: %v", time.Since(start), err)
+			return nil, err
+		}
+		if err := json.Unmarshal(body, &toolResp); err != nil {
+			return nil, fmt.Errorf("failed to parse tool use response: %w", err)
+		}
+	}
 	elapsed := time.Since(start)
-	if err != nil {
-		log.Printf("CallWithTools: failed after %s: %v", elapsed, err)
-		return nil, err
+	toolResp.Usage.ThinkingTokens = estimateThinkingTokens(&toolResp)
+	log.Printf("CallWithTools: completed in %s (in=%d out=%d thinking~%d cache_create=%d cache_read=%d tokens, stop=%s)",
+		elapsed, toolResp.Usage.InputTokens, toolResp.Usage.OutputTokens, toolResp.Usage.ThinkingTokens,
+		toolResp.Usage.CacheCreationInputTokens, toolResp.Usage.CacheReadInputTokens, toolResp.StopReason)
+	c.reportUsage(ctx, toolResp.Usage.InputTokens, toolResp.Usage.OutputTokens,
+		toolResp.Usage.CacheCreationInputTokens, toolResp.Usage.CacheReadInputTokens)
+	return &toolResp, nil
+}
+
+// estimateThinkingTokens estimates how many of a response's output tokens went to
+// extended thinking. Claude bills thinking as output but does not break it out, and
+// Claude 4 models return only a summary of it, so the visible output (text and tool
+// input, at ~4 characters per token) is subtracted from the total instead.
+func estimateThinkingTokens(resp *ToolUseResponse) int {
+	visibleChars, thinking := 0, false
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "thinking", "redacted_thinking":
+			thinking = true
+		case "text":
+			visibleChars += len(block.Text)
+		case "tool_use":
+			visibleChars += len(block.Name) + len(block.Input)
+		}
 	}
+	if !thinking {
+		return 0
+	}
+	n := resp.Usage.OutputTokens - visibleChars/4
+	if n < 0 {
+		return 0
+	}
+	return n
+}
 
-	var toolResp ToolUseResponse
-	if err := json.Unmarshal(body, &toolResp); err != nil {
-		return nil, fmt.Errorf("failed to parse tool use response: %w", err)
+// withoutThoughtSignatures returns messages with Gemini's thoughtSignature removed
+// from every content block. Histories started on Gemini (fallback chains, a
+// different synthesis provider) carry it, and Claude rejects unknown block fields.
+// The caller's blocks are left untouched so Gemini can still echo them back.
+func withoutThoughtSignatures(messages []AgentMessage) []AgentMessage {
+	var out []AgentMessage
+	for i, msg := range messages {
+		content, changed := stripThoughtSignatures(msg.Content)
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = append([]AgentMessage(nil), messages...)
+		}
+		out[i].Content = content
 	}
-	log.Printf("CallWithTools: completed in %s (in=%d out=%d cache_create=%d cache_read=%d tokens, stop=%s)",
-		elapsed, toolResp.Usage.InputTokens, toolResp.Usage.OutputTokens,
-		toolResp.Usage.CacheCreationInputTokens, toolResp.Usage.CacheReadInputTokens, toolResp.StopReason)
-	if c.OnUsage != nil {
-		c.OnUsage(toolResp.Usage.InputTokens, toolResp.Usage.OutputTokens,
-			toolResp.Usage.CacheCreationInputTokens, toolResp.Usage.CacheReadInputTokens)
+	if out == nil {
+		return messages
 	}
-	return &toolResp, nil
+	return out
+}
+
+func stripThoughtSignatures(content interface{}) (interface{}, bool) {
+	switch c := content.(type) {
+	case []ResponseContentBlock:
+		var blocks []ResponseContentBlock
+		for i, b := range c {
+			if b.ThoughtSignature == "" {
+				continue
+			}
+			if blocks == nil {
+				blocks = append([]ResponseContentBlock(nil), c...)
+			}
+			blocks[i].ThoughtSignature = ""
+		}
+		if blocks == nil {
+			return content, false
+		}
+		return blocks, true
+	case []interface{}:
+		var blocks []interface{}
+		for i, b := range c {
+			var stripped interface{}
+			switch block := b.(type) {
+			case ResponseContentBlock:
+				if block.ThoughtSignature == "" {
+					continue
+				}
+				block.ThoughtSignature = ""
+				stripped = block
+			case map[string]interface{}:
+				if _, ok := block["thoughtSignature"]; !ok {
+					continue
+				}
+				m := make(map[string]interface{}, len(block))
+				for k, v := range block {
+					if k != "thoughtSignature" {
+						m[k] = v
+					}
+				}
+				stripped = m
+			default:
+				continue
+			}
+			if blocks == nil {
+				blocks = append([]interface{}(nil), c...)
+			}
+			blocks[i] = stripped
+		}
+		if blocks == nil {
+			return content, false
+		}
+		return blocks, true
+	}
+	return content, false
 }
 
 // addConversationCacheBreakpoint adds cache_control to the second-to-last user message
@@ -413,8 +606,6 @@ func (c *ClaudeClient) AnalyzeWithImage(ctx context.Context, prompt string, imag
 	if err != nil {
 		return "", err
 	}
-	if c.OnUsage != nil {
-		c.OnUsage(resp.Usage.InputTokens, resp.Usage.OutputTokens, 0, 0)
-	}
+	c.reportUsage(ctx, resp.Usage.InputTokens, resp.Usage.OutputTokens, 0, 0)
 	return text, nil
 }
@@ -0,0 +1,380 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+const (
+	// defaultContextMaxTokens is the estimated history size at which older exploration
+	// turns are summarised. It leaves room under a 200K-token window for the system
+	// prompt, tool definitions and the response.
+	defaultContextMaxTokens = 120_000
+
+	// contextKeepMessages is how many of the most recent messages are kept verbatim.
+	contextKeepMessages = 12
+
+	// imageTokenEstimate is the approximate input cost of one screenshot.
+	imageTokenEstimate = 1600
+
+	// maxContextSummaryChars caps the summary carried in the first message, so
+	// repeated compaction over hundreds of steps cannot grow it without bound.
+	maxContextSummaryChars = 12_000
+)
+
+// contextSummaryHeader marks the summary text block appended to the first message.
+const contextSummaryHeader = "[EXPLORATION SO FAR — older turns were summarised to save context]"
+
+// contextManager keeps the agent message history under a token threshold. Past the
+// threshold it replaces every turn between the initial message and the most recent
+// ones (about half the threshold's worth) with a summary of screens seen, coordinates
+// found and open questions, appended to the initial message. The kept turns start at
+// an assistant message, so each remaining tool_result still follows its tool_use.
+type contextManager struct {
+	maxTokens int
+	keep      int
+	// summarize, when set, condenses the rule-based digest of the dropped turns with
+	// the AI. Errors fall back to the rule-based summary.
+	summarize func(ctx context.Context, prompt string) (string, error)
+
+	initial     interface{} // content of the first message before any summary was added
+	summary     string
+	compactions int
+}
+
+// newContextManager returns a manager for the given threshold (0 = default, negative
+// disables compaction).
+func newContextManager(maxTokens int) *contextManager {
+	if maxTokens == 0 {
+		maxTokens = defaultContextMaxTokens
+	}
+	return &contextManager{maxTokens: maxTokens, keep: contextKeepMessages}
+}
+
+// compact returns messages unchanged while they fit the threshold; otherwise it
+// returns a new history with the older turns summarised, and how many messages
+// were dropped.
+func (cm *contextManager) compact(ctx context.Context, messages []AgentMessage) ([]AgentMessage, int) {
+	if cm.maxTokens < 0 || estimateHistoryTokens(messages) <= cm.maxTokens {
+		return messages, 0
+	}
+
+	// Keep the most recent messages that fit in half the threshold (at least cm.keep,
+	// fewer when those alone outgrow it), so the next compaction is many steps away,
+	// starting at an assistant message.
+	start := len(messages) - cm.keep
+	if start < 1 {
+		start = 1
+	}
+	tail := estimateHistoryTokens(messages[start:])
+	for ; tail > cm.maxTokens/2 && start < len(messages)-2; start++ {
+		tail -= estimateContentTokens(messages[start].Content)
+	}
+	for ; start > 1; start-- {
+		grown := tail + estimateContentTokens(messages[start-1].Content)
+		if grown > cm.maxTokens/2 {
+			break
+		}
+		tail = grown
+	}
+	cut := -1
+	for i := start; i < len(messages); i++ {
+		if messages[i].Role == "assistant" {
+			cut = i
+			break
+		}
+	}
+	if cut <= 1 {
+		return messages, 0
+	}
+
+	// Leave the history alone when even the compacted one would not fit (the latest
+	// turns are too large): compacting would repeat every step, each time paying for
+	// an AI summary when one is configured.
+	initial := cm.initial
+	if initial == nil {
+		initial = messages[0].Content
+	}
+	if estimateContentTokens(initial)+maxContextSummaryChars/4+estimateHistoryTokens(messages[cut:]) > cm.maxTokens {
+		return messages, 0
+	}
+
+	cm.initial = initial
+	cm.summary = cm.summarise(ctx, messages[1:cut])
+	cm.compactions++
+
+	out := make([]AgentMessage, 0, len(messages)-cut+1)
+	out = append(out, AgentMessage{Role: messages[0].Role, Content: withSummary(cm.initial, cm.summary)})
+	out = append(out, messages[cut:]...)
+	return out, cut - 1
+}
+
+// summarise builds the summary that replaces dropped, merged with the previous one.
+func (cm *contextManager) summarise(ctx context.Context, dropped []AgentMessage) string {
+	digest := digestTurns(dropped)
+	if cm.summarize != nil {
+		text, err := cm.summarize(ctx, contextSummaryPrompt(cm.summary, digest))
+		if err == nil && strings.TrimSpace(text) != "" {
+			return capSummary(strings.TrimSpace(text))
+		}
+		log.Printf("Context summary via AI failed, using rule-based summary: %v", err)
+	}
+	if cm.summary == "" {
+		return capSummary(digest)
+	}
+	return capSummary(cm.summary + "\n\n" + digest)
+}
+
+// withSummary returns a copy of the first message's content with the summary
+// appended as a text block.
+func withSummary(content interface{}, summary string) interface{} {
+	block := map[string]interface{}{"type": "text", "text": contextSummaryHeader + "\n" + summary}
+	switch c := content.(type) {
+	case []interface{}:
+		out := make([]interface{}, len(c), len(c)+1)
+		copy(out, c)
+		return append(out, block)
+	case string:
+		return []interface{}{map[string]interface{}{"type": "text", "text": c}, block}
+	default:
+		return []interface{}{block}
+	}
+}
+
+// capSummary keeps the most recent part of a summary that grew past the cap.
+func capSummary(s string) string {
+	if len(s) <= maxContextSummaryChars {
+		return s
+	}
+	s = s[len(s)-maxContextSummaryChars:]
+	if i := strings.Index(s, "\n"); i >= 0 {
+		s = s[i+1:]
+	}
+	return "(earlier summary truncated)\n" + s
+}
+
+// contextSummaryPrompt asks the AI to fold the digest of dropped turns into the
+// running summary.
+func contextSummaryPrompt(previous, digest string) string {
+	var b strings.Builder
+	b.WriteString("You are compressing the history of an automated QA exploration of a web game so it can continue with less context.\n\n")
+	if previous != "" {
+		b.WriteString("Summary of the exploration before these turns:\n")
+		b.WriteString(previous)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("Digest of the turns being removed:\n")
+	b.WriteString(digest)
+	b.WriteString(`
+
+Write one updated summary covering everything above, in plain text with these sections:
+Screens seen: each distinct screen or game state and how it was reached.
+Coordinates found: every interactive element with its (x, y) coordinates and what it does.
+Open questions: features not yet explored, errors, and anything still unclear.
+Be specific and concise; keep all coordinates. Do not add anything else.`)
+	return b.String()
+}
+
+// digestTurns extracts a rule-based summary from a run of agent messages: the
+// agent's observations, the coordinates it acted on, errors, questions and hints.
+func digestTurns(messages []AgentMessage) string {
+	type action struct {
+		name  string
+		input json.RawMessage
+	}
+	pending := map[string]action{} // tool_use ID -> action awaiting its result
+	toolCounts := map[string]int{}
+	var screens, coords, questions, hints []string
+	seen := map[string]bool{}
+	add := func(list *[]string, s string) {
+		if s != "" && !seen[s] {
+			seen[s] = true
+			*list = append(*list, s)
+		}
+	}
+
+	for _, msg := range messages {
+		switch c := msg.Content.(type) {
+		case string:
+			if msg.Role == "user" && strings.HasPrefix(c, "[USER HINT]") {
+				hint, _, _ := strings.Cut(strings.TrimPrefix(c, "[USER HINT]:"), "\n")
+				add(&hints, Truncate(strings.TrimSpace(hint), 200))
+			}
+		case []ResponseContentBlock:
+			for _, b := range c {
+				switch b.Type {
+				case "text":
+					add(&screens, Truncate(firstSentence(b.Text), 160))
+					for _, q := range questionsIn(b.Text) {
+						add(&questions, Truncate(q, 160))
+					}
+				case "tool_use":
+					toolCounts[b.Name]++
+					pending[b.ID] = action{b.Name, b.Input}
+				}
+			}
+		case []interface{}:
+			for _, item := range c {
+				tr, ok := item.(ToolResultBlock)
+				if !ok {
+					continue
+				}
+				act, ok := pending[tr.ToolUseID]
+				if !ok {
+					continue
+				}
+				delete(pending, tr.ToolUseID)
+				result := Truncate(toolResultText(tr.Content), 80)
+				if tr.IsError {
+					add(&questions, fmt.Sprintf("%s failed: %s", formatToolAction(act.name, act.input), result))
+					continue
+				}
+				var p struct {
+					X *int `json:"x"`
+					Y *int `json:"y"`
+				}
+				if json.Unmarshal(act.input, &p) == nil && p.X != nil && p.Y != nil {
+					add(&coords, fmt.Sprintf("%s (%d, %d) → %s", act.name, *p.X, *p.Y, result))
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	names := make([]string, 0, len(toolCounts))
+	for name := range toolCounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b.WriteString("Actions:")
+	for _, name := range names {
+		fmt.Fprintf(&b, " %s=%d", name, toolCounts[name])
+	}
+	b.WriteString("\n")
+	writeSection(&b, "Screens seen", lastN(screens, 15))
+	writeSection(&b, "Coordinates found", lastN(coords, 30))
+	writeSection(&b, "Open questions", lastN(questions, 10))
+	writeSection(&b, "User hints", hints)
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func writeSection(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	b.WriteString(title + ":\n")
+	for _, item := range items {
+		b.WriteString("- " + item + "\n")
+	}
+}
+
+func lastN(items []string, n int) []string {
+	if len(items) > n {
+		return items[len(items)-n:]
+	}
+	return items
+}
+
+// firstSentence returns the first sentence (or line) of s.
+func firstSentence(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, "\n"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.Index(s, ". "); i >= 0 {
+		s = s[:i+1]
+	}
+	return s
+}
+
+// questionsIn returns the sentences of s that end with a question mark.
+func questionsIn(s string) []string {
+	var out []string
+	for _, line := range strings.Split(s, "\n") {
+		for _, sentence := range strings.SplitAfter(line, "? ") {
+			sentence = strings.TrimSpace(sentence)
+			if strings.HasSuffix(sentence, "?") {
+				if i := strings.LastIndex(sentence, ". "); i >= 0 {
+					sentence = sentence[i+2:]
+				}
+				out = append(out, sentence)
+			}
+		}
+	}
+	return out
+}
+
+// toolResultText returns the text parts of a tool result's content.
+func toolResultText(content interface{}) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []interface{}:
+		var parts []string
+		for _, item := range c {
+			if m, ok := item.(map[string]interface{}); ok && m["type"] == "text" {
+				if text, ok := m["text"].(string); ok {
+					parts = append(parts, text)
+				}
+			}
+		}
+		return strings.Join(parts, " ")
+	default:
+		return ""
+	}
+}
+
+// estimateHistoryTokens estimates the input tokens of a message history at ~4
+// characters per token, counting each image at a flat rate.
+func estimateHistoryTokens(messages []AgentMessage) int {
+	total := 0
+	for _, msg := range messages {
+		total += estimateContentTokens(msg.Content)
+	}
+	return total
+}
+
+func estimateContentTokens(v interface{}) int {
+	switch c := v.(type) {
+	case nil:
+		return 0
+	case string:
+		return len(c)/4 + 1
+	case []interface{}:
+		n := 0
+		for _, item := range c {
+			n += estimateContentTokens(item)
+		}
+		return n
+	case map[string]interface{}:
+		if c["type"] == "image" {
+			return imageTokenEstimate
+		}
+		n := 0
+		for _, item := range c {
+			n += estimateContentTokens(item)
+		}
+		return n
+	case []ResponseContentBlock:
+		n := 0
+		for _, b := range c {
+			n += (len(b.Text)+len(b.Thinking)+len(b.Signature)+len(b.Data)+len(b.Name)+len(b.Input))/4 + 1
+		}
+		return n
+	case ToolResultBlock:
+		return estimateContentTokens(c.Content) + 1
+	case []ToolResultBlock:
+		n := 0
+		for _, b := range c {
+			n += estimateContentTokens(b.Content) + 1
+		}
+		return n
+	default:
+		b, _ := json.Marshal(c)
+		return len(b) / 4
+	}
+}
@@ -0,0 +1,164 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// explorationHistory builds an initial message followed by n click/result turns,
+// each padded with text so the history is large.
+func explorationHistory(n int) []AgentMessage {
+	messages := []AgentMessage{{Role: "user", Content: []interface{}{
+		map[string]interface{}{"type": "text", "text": "Explore https://game.example.com"},
+	}}}
+	for i := 1; i <= n; i++ {
+		id := fmt.Sprintf("toolu_%d", i)
+		messages = append(messages,
+			AgentMessage{Role: "assistant", Content: []ResponseContentBlock{
+				{Type: "text", Text: fmt.Sprintf("Screen %d shows the lobby. Is the bonus round reachable? %s", i, strings.Repeat("x", 2000))},
+				{Type: "tool_use", ID: id, Name: "click", Input: json.RawMessage(fmt.Sprintf(`{"x": %d, "y": 300}`, i*10))},
+			}},
+			AgentMessage{Role: "user", Content: []interface{}{
+				ToolResultBlock{Type: "tool_result", ToolUseID: id, Content: "Clicked"},
+			}},
+		)
+	}
+	return messages
+}
+
+// checkToolPairing fails the test if any tool_result does not answer a tool_use in
+// the assistant message right before it.
+func checkToolPairing(t *testing.T, messages []AgentMessage) {
+	t.Helper()
+	for i, msg := range messages {
+		blocks, ok := msg.Content.([]interface{})
+		if !ok || msg.Role != "user" {
+			continue
+		}
+		for _, item := range blocks {
+			tr, ok := item.(ToolResultBlock)
+			if !ok {
+				continue
+			}
+			found := false
+			if i > 0 {
+				if prev, ok := messages[i-1].Content.([]ResponseContentBlock); ok {
+					for _, b := range prev {
+						found = found || (b.Type == "tool_use" && b.ID == tr.ToolUseID)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("message %d: tool_result %s has no matching tool_use before it", i, tr.ToolUseID)
+			}
+		}
+	}
+}
+
+func TestContextManagerLeavesSmallHistory(t *testing.T) {
+	cm := newContextManager(0)
+	messages := explorationHistory(5)
+	if out, dropped := cm.compact(context.Background(), messages); dropped != 0 || len(out) != len(messages) {
+		t.Errorf("compact dropped %d messages below the threshold", dropped)
+	}
+}
+
+func TestContextManagerSummarisesOldTurns(t *testing.T) {
+	cm := newContextManager(20_000)
+	messages := explorationHistory(60)
+
+	out, dropped := cm.compact(context.Background(), messages)
+	if dropped == 0 {
+		t.Fatal("expected older turns to be dropped")
+	}
+	if got := estimateHistoryTokens(out); got > 20_000 {
+		t.Errorf("compacted history is still ~%d tokens", got)
+	}
+	if out[0].Role != "user" || out[1].Role != "assistant" {
+		t.Errorf("roles after compaction: %s, %s", out[0].Role, out[1].Role)
+	}
+	checkToolPairing(t, out)
+
+	first := out[0].Content.([]interface{})
+	summary := first[len(first)-1].(map[string]interface{})["text"].(string)
+	for _, want := range []string{contextSummaryHeader, "Coordinates found:", "300) → Clicked", "Screens seen:", "Open questions:", "Is the bonus round reachable?"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+	if len(messages[0].Content.([]interface{})) != 1 {
+		t.Error("compact modified the original first message")
+	}
+
+	// A second compaction replaces the summary instead of stacking another one
+	out = append(out, explorationHistory(60)[1:]...)
+	out, _ = cm.compact(context.Background(), out)
+	if n := len(out[0].Content.([]interface{})); n != 2 {
+		t.Errorf("first message has %d blocks after two compactions, want 2", n)
+	}
+	checkToolPairing(t, out)
+}
+
+func TestContextManagerAISummary(t *testing.T) {
+	cm := newContextManager(20_000)
+	var prompts []string
+	cm.summarize = func(ctx context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return "Screens seen: lobby\nCoordinates found: play (400, 300)\nOpen questions: bonus round", nil
+	}
+
+	out, _ := cm.compact(context.Background(), explorationHistory(60))
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "Coordinates found:\n- click (") {
+		t.Fatalf("summary prompt should carry the rule-based digest, got %q", prompts)
+	}
+	first := out[0].Content.([]interface{})
+	if text := first[len(first)-1].(map[string]interface{})["text"].(string); !strings.Contains(text, "play (400, 300)") {
+		t.Errorf("AI summary not used: %s", text)
+	}
+
+	cm.summarize = func(ctx context.Context, prompt string) (string, error) { return "", errors.New("overloaded") }
+	out = append(out, explorationHistory(60)[1:]...)
+	out, _ = cm.compact(context.Background(), out)
+	first = out[0].Content.([]interface{})
+	if text := first[len(first)-1].(map[string]interface{})["text"].(string); !strings.Contains(text, "Coordinates found:\n- click") {
+		t.Errorf("expected the rule-based fallback to be merged in: %s", text)
+	}
+}
+
+func TestContextManagerLargeRecentTurns(t *testing.T) {
+	cm := newContextManager(20_000)
+	calls := 0
+	cm.summarize = func(ctx context.Context, prompt string) (string, error) {
+		calls++
+		return "Screens seen: lobby", nil
+	}
+
+	// The last 12 messages alone are ~45K tokens: keep fewer of them
+	messages := explorationHistory(20)
+	for i := len(messages) - 12; i < len(messages); i++ {
+		if blocks, ok := messages[i].Content.([]ResponseContentBlock); ok {
+			blocks[0].Text += strings.Repeat("y", 28_000)
+		}
+	}
+	out, dropped := cm.compact(context.Background(), messages)
+	if dropped == 0 || calls != 1 {
+		t.Fatalf("expected one compaction, got %d dropped and %d summary calls", dropped, calls)
+	}
+	if got := estimateHistoryTokens(out); got > 20_000 {
+		t.Errorf("compacted history is still ~%d tokens", got)
+	}
+	checkToolPairing(t, out)
+
+	// A latest turn over the threshold cannot be compacted away: leave the history
+	// as it is rather than summarising on every step
+	huge := append(out, AgentMessage{Role: "assistant", Content: []ResponseContentBlock{
+		{Type: "text", Text: strings.Repeat("z", 100_000)},
+	}})
+	if _, dropped := cm.compact(context.Background(), huge); dropped != 0 || calls != 1 {
+		t.Errorf("expected no compaction, got %d dropped and %d summary calls", dropped, calls)
+	}
+}
@@ -0,0 +1,462 @@
+package ai
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EngineAdapter reads the interactive objects of one game engine's scene graph
+// for the inspect_game_objects tool.
+//
+// Script is a JavaScript arrow function evaluated inside the inspection wrapper
+// (see inspectGameObjectsScript), with these helpers in scope:
+//
+//	rect             the game canvas's bounding client rect
+//	add(obj)         records an object; obj = {name, type, interactive, left, top, w, h, text?, scene?}
+//	                 in CSS pixels relative to the viewport
+//	findGlobal(test) returns the first window property (or property of one) passing test
+//
+// It returns null when its engine is not on the page, otherwise
+// {engine?, version?, scenes?, error?}.
+type EngineAdapter struct {
+	Name       string   // engine id reported in the result, e.g. "phaser3"
+	Frameworks []string // PageMeta.Framework values that select this adapter
+	Globals    []string // lower-case PageMeta.JSGlobals prefixes that select it
+	Script     string
+}
+
+// matches reports whether the adapter handles the detected framework or globals.
+func (a EngineAdapter) matches(framework string, jsGlobals []string) bool {
+	for _, f := range a.Frameworks {
+		if f == framework {
+			return true
+		}
+	}
+	for _, g := range jsGlobals {
+		gl := strings.ToLower(g)
+		for _, prefix := range a.Globals {
+			if strings.HasPrefix(gl, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// maxInspectedObjects caps the objects returned by one inspection.
+const maxInspectedObjects = 150
+
+var (
+	engineAdaptersMu sync.RWMutex
+	engineAdapters   = []EngineAdapter{
+		hookAdapter,
+		phaserAdapter("phaser3", "3", []string{"phaser 3"}),
+		phaserAdapter("phaser4", "4", []string{"phaser 4"}),
+		pixiAdapter,
+		cocosAdapter,
+		babylonAdapter,
+		threeAdapter,
+		playCanvasAdapter,
+		constructAdapter,
+		unityAdapter,
+	}
+)
+
+// RegisterEngineAdapter adds an adapter for another engine. Registered adapters
+// are tried after the built-in ones unless the page's framework selects them.
+func RegisterEngineAdapter(a EngineAdapter) {
+	engineAdaptersMu.Lock()
+	defer engineAdaptersMu.Unlock()
+	engineAdapters = append(engineAdapters, a)
+}
+
+// SelectEngineAdapters returns every registered adapter in the order they should
+// be tried: the page hook first, then the adapters matching the detected
+// framework and JS globals, then the rest in case detection missed the engine.
+func SelectEngineAdapters(framework string, jsGlobals []string) []EngineAdapter {
+	engineAdaptersMu.RLock()
+	defer engineAdaptersMu.RUnlock()
+
+	var matched, rest []EngineAdapter
+	for _, a := range engineAdapters {
+		switch {
+		case a.Name == hookAdapter.Name:
+			continue
+		case a.matches(framework, jsGlobals):
+			matched = append(matched, a)
+		default:
+			rest = append(rest, a)
+		}
+	}
+	out := append([]EngineAdapter{hookAdapter}, matched...)
+	return append(out, rest...)
+}
+
+// inspectGameObjectsScript builds the inspection expression trying each adapter
+// in turn. The result is JSON: {engine, version, scenes, objects, truncated}
+// where each object has its centre (x,y), size (w,h) and top-left corner in CSS
+// pixels, or {error, tried} when no adapter recognised the page.
+func inspectGameObjectsScript(adapters []EngineAdapter) string {
+	var b strings.Builder
+	b.WriteString(`(() => {
+	const canvas = document.querySelector('canvas');
+	const rect = canvas ? canvas.getBoundingClientRect() : {left: 0, top: 0, width: window.innerWidth, height: window.innerHeight};
+	const objects = [];
+	const max = ` + strconv.Itoa(maxInspectedObjects) + `;
+	let truncated = false;
+	const add = (o) => {
+		if (!o || !isFinite(o.left) || !isFinite(o.top)) return;
+		if (objects.length >= max) { truncated = true; return; }
+		const w = Math.max(0, o.w || 0), h = Math.max(0, o.h || 0);
+		objects.push({
+			scene: o.scene || undefined,
+			name: String(o.name || o.type || 'object'),
+			type: o.type || undefined,
+			interactive: !!o.interactive,
+			x: Math.round(o.left + w / 2), y: Math.round(o.top + h / 2),
+			w: Math.round(w), h: Math.round(h),
+			left: Math.round(o.left), top: Math.round(o.top),
+			text: o.text ? String(o.text).substring(0, 50) : undefined
+		});
+	};
+	const findGlobal = (test) => {
+		const check = v => { try { return v && typeof v === 'object' && !(v instanceof Node) && v !== window && test(v); } catch (e) { return false; } };
+		const keys = Object.keys(window);
+		for (const k of keys) { let v; try { v = window[k]; } catch (e) { continue; } if (check(v)) return v; }
+		for (const k of keys) {
+			let v; try { v = window[k]; } catch (e) { continue; }
+			if (!v || typeof v !== 'object' || v instanceof Node || v === window) continue;
+			let inner; try { inner = Object.keys(v); } catch (e) { continue; }
+			if (inner.length > 200) continue;
+			for (const ik of inner) { let iv; try { iv = v[ik]; } catch (e) { continue; } if (check(iv)) return iv; }
+		}
+		return null;
+	};
+	const adapters = [
+`)
+	names := make([]string, len(adapters))
+	for i, a := range adapters {
+		names[i] = a.Name
+		b.WriteString("\t\t['" + a.Name + "', " + strings.TrimSpace(a.Script) + "],\n")
+	}
+	b.WriteString(`	];
+	const errors = [];
+	for (const [name, run] of adapters) {
+		let info;
+		try { info = run(); } catch (e) { errors.push(name + ': ' + e.message); objects.length = 0; truncated = false; continue; }
+		if (!info) { objects.length = 0; truncated = false; continue; }
+		if (info.error) return JSON.stringify({engine: info.engine || name, error: info.error}, null, 2);
+		return JSON.stringify({engine: info.engine || name, version: info.version || undefined, scenes: info.scenes, objects, truncated: truncated || undefined}, null, 2);
+	}
+	return JSON.stringify({error: 'No supported game engine detected', tried: '` + strings.Join(names, ", ") + `', errors: errors.length ? errors : undefined});
+})()`)
+	return b.String()
+}
+
+// hookAdapter reads objects from window.__WIZARDS_QA__.inspect(), which a game
+// can define to describe its own scene. It is how engines without a scriptable
+// scene graph, such as Unity WebGL, are inspected. inspect returns an array of
+// objects or {engine, objects, space}; positions are viewport CSS pixels, or
+// relative to the canvas when space is "canvas". x,y may be given as the
+// centre instead of left/top.
+var hookAdapter = EngineAdapter{
+	Name: "hook",
+	Script: `() => {
+		const hook = window.__WIZARDS_QA__;
+		if (!hook || typeof hook.inspect !== 'function') return null;
+		const res = hook.inspect();
+		const list = Array.isArray(res) ? res : (res && res.objects) || [];
+		const ox = res && res.space === 'canvas' ? rect.left : 0;
+		const oy = res && res.space === 'canvas' ? rect.top : 0;
+		for (const o of list) {
+			const w = o.w || o.width || 0, h = o.h || o.height || 0;
+			const left = o.left !== undefined ? o.left : o.x - w / 2;
+			const top = o.top !== undefined ? o.top : o.y - h / 2;
+			add({...o, left: left + ox, top: top + oy, w, h});
+		}
+		return {engine: (res && res.engine) || 'hook', version: res && res.version, scenes: res && res.scenes};
+	}`,
+}
+
+// phaserAdapter handles one Phaser major version. Bounds come from getBounds,
+// which includes container transforms; camera scroll is applied but not zoom.
+func phaserAdapter(name, major string, globals []string) EngineAdapter {
+	return EngineAdapter{
+		Name:       name,
+		Frameworks: []string{"phaser"},
+		Globals:    globals,
+		Script: `() => {
+		const game = window.game || window.__PHASER_GAME__ || (window.Phaser && window.Phaser.GAMES && window.Phaser.GAMES[0]) ||
+			findGlobal(v => v.scene && Array.isArray(v.scene.scenes) && v.config);
+		if (!game || !game.scene || !Array.isArray(game.scene.scenes)) return null;
+		const version = (window.Phaser && window.Phaser.VERSION) || '';
+		if (String(version || '` + major + `').charAt(0) !== '` + major + `') return null;
+		const sx = rect.width / ((game.scale && game.scale.width) || (game.canvas && game.canvas.width) || rect.width);
+		const sy = rect.height / ((game.scale && game.scale.height) || (game.canvas && game.canvas.height) || rect.height);
+		const scenes = game.scene.scenes.filter(s => s.sys && s.sys.settings.status >= 5);
+		const visit = (scene, cam, obj, depth) => {
+			if (depth > 8 || !obj.active || !obj.visible) return;
+			const interactive = !!(obj.input && obj.input.enabled);
+			const shown = interactive || ['Text', 'BitmapText', 'Sprite', 'Image', 'NineSlice'].includes(obj.type);
+			if (shown) {
+				let bx, by, bw, bh;
+				if (typeof obj.getBounds === 'function') {
+					const b = obj.getBounds();
+					bx = b.x; by = b.y; bw = b.width; bh = b.height;
+				} else {
+					bw = obj.displayWidth || 0; bh = obj.displayHeight || 0;
+					bx = obj.x - bw * (obj.originX || 0); by = obj.y - bh * (obj.originY || 0);
+				}
+				const fx = obj.scrollFactorX !== undefined ? obj.scrollFactorX : 1;
+				const fy = obj.scrollFactorY !== undefined ? obj.scrollFactorY : 1;
+				add({
+					scene: scene.sys.settings.key, name: obj.name || obj.type, type: obj.type, interactive,
+					left: rect.left + (bx - (cam ? cam.scrollX * fx : 0)) * sx,
+					top: rect.top + (by - (cam ? cam.scrollY * fy : 0)) * sy,
+					w: bw * sx, h: bh * sy, text: obj.text
+				});
+			}
+			if (Array.isArray(obj.list)) obj.list.forEach(c => visit(scene, cam, c, depth + 1));
+		};
+		for (const scene of scenes) {
+			const cam = scene.cameras && scene.cameras.main;
+			scene.children.list.forEach(obj => visit(scene, cam, obj, 0));
+		}
+		return {engine: '` + name + `', version, scenes: scenes.map(s => s.sys.settings.key)};
+	}`,
+	}
+}
+
+// pixiAdapter walks the PixiJS stage. Objects count as interactive when their
+// eventMode is static or dynamic (v7+) or interactive/buttonMode is set.
+var pixiAdapter = EngineAdapter{
+	Name:       "pixi",
+	Frameworks: []string{"pixi"},
+	Globals:    []string{"pixi"},
+	Script: `() => {
+		const app = window.__PIXI_APP__ || (window.app && window.app.stage ? window.app : null) ||
+			findGlobal(v => v.stage && v.renderer && Array.isArray(v.stage.children));
+		if (!app || !app.stage) return null;
+		const screen = (app.renderer && app.renderer.screen) || {width: rect.width, height: rect.height};
+		const sx = rect.width / screen.width, sy = rect.height / screen.height;
+		const walk = (node, depth) => {
+			if (depth > 10 || node.visible === false) return;
+			const interactive = node.eventMode === 'static' || node.eventMode === 'dynamic' || node.interactive === true || node.buttonMode === true;
+			if (interactive || typeof node.text === 'string') {
+				const b = node.getBounds();
+				add({
+					name: node.label || node.name || node.constructor.name, type: node.constructor.name, interactive,
+					left: rect.left + b.x * sx, top: rect.top + b.y * sy, w: b.width * sx, h: b.height * sy,
+					text: typeof node.text === 'string' ? node.text : undefined
+				});
+			}
+			(node.children || []).forEach(c => walk(c, depth + 1));
+		};
+		walk(app.stage, 0);
+		return {engine: 'pixi', version: (window.PIXI && window.PIXI.VERSION) || ''};
+	}`,
+}
+
+// cocosAdapter walks the Cocos Creator scene (2.x and 3.x). Buttons, toggles,
+// edit boxes and sliders are interactive; labels are listed for their text.
+// World space is y-up over the visible design size.
+var cocosAdapter = EngineAdapter{
+	Name:       "cocos",
+	Frameworks: []string{"cocos"},
+	Globals:    []string{"cocos"},
+	Script: `() => {
+		const cc = window.cc;
+		if (!cc || !cc.director || typeof cc.director.getScene !== 'function') return null;
+		const scene = cc.director.getScene();
+		if (!scene) return null;
+		const vis = cc.view && cc.view.getVisibleSize ? cc.view.getVisibleSize() : {width: rect.width, height: rect.height};
+		const sx = rect.width / vis.width, sy = rect.height / vis.height;
+		const comp = (n, name) => cc[name] && n.getComponent ? n.getComponent(cc[name]) : null;
+		const walk = (n, depth) => {
+			if (depth > 15 || n.active === false) return;
+			const ui = comp(n, 'UITransform');
+			const box = ui ? ui.getBoundingBoxToWorld() : (n.getBoundingBoxToWorld ? n.getBoundingBoxToWorld() : null);
+			const control = comp(n, 'Button') || comp(n, 'Toggle') || comp(n, 'EditBox') || comp(n, 'Slider');
+			const label = comp(n, 'Label') || comp(n, 'RichText');
+			if (box && (control || label)) {
+				add({
+					name: n.name, type: control ? control.constructor.name : label.constructor.name,
+					interactive: !!control && control.interactable !== false,
+					left: rect.left + box.x * sx, top: rect.top + rect.height - (box.y + box.height) * sy,
+					w: box.width * sx, h: box.height * sy, text: label && label.string
+				});
+			}
+			(n.children || []).forEach(c => walk(c, depth + 1));
+		};
+		walk(scene, 0);
+		return {engine: 'cocos', version: cc.ENGINE_VERSION || '', scenes: [scene.name]};
+	}`,
+}
+
+// babylonAdapter projects meshes with an action manager to the screen and lists
+// Babylon GUI controls that have pointer observers or text.
+var babylonAdapter = EngineAdapter{
+	Name:       "babylon",
+	Frameworks: []string{"babylon"},
+	Globals:    []string{"babylon"},
+	Script: `() => {
+		const B = window.BABYLON;
+		if (!B) return null;
+		const store = B.EngineStore || B.Engine;
+		const engine = store && store.LastCreatedEngine;
+		const scene = store && store.LastCreatedScene;
+		if (!engine || !scene || !scene.activeCamera) return null;
+		const rw = engine.getRenderWidth(), rh = engine.getRenderHeight();
+		const sx = rect.width / rw, sy = rect.height / rh;
+		const viewport = scene.activeCamera.viewport.toGlobal(rw, rh);
+		const transform = scene.getTransformMatrix();
+		for (const m of scene.meshes) {
+			if (!m.isVisible || !m.isEnabled() || !m.actionManager || !m.getBoundingInfo) continue;
+			const pts = m.getBoundingInfo().boundingBox.vectorsWorld.map(v => B.Vector3.Project(v, B.Matrix.Identity(), transform, viewport));
+			if (pts.some(p => p.z < 0 || p.z > 1)) continue;
+			const xs = pts.map(p => p.x), ys = pts.map(p => p.y);
+			const x0 = Math.min(...xs), y0 = Math.min(...ys);
+			add({
+				name: m.name, type: m.getClassName(), interactive: true,
+				left: rect.left + x0 * sx, top: rect.top + y0 * sy,
+				w: (Math.max(...xs) - x0) * sx, h: (Math.max(...ys) - y0) * sy
+			});
+		}
+		for (const t of scene.textures || []) {
+			if (typeof t.getDescendants !== 'function' || t.getClassName() !== 'AdvancedDynamicTexture') continue;
+			for (const c of t.getDescendants(false)) {
+				if (!c.isVisible || !c._currentMeasure) continue;
+				const observed = o => o && o.hasObservers();
+				const interactive = c.isHitTestVisible !== false && (observed(c.onPointerClickObservable) || observed(c.onPointerUpObservable));
+				const text = c.text || (c.textBlock && c.textBlock.text);
+				if (!interactive && !text) continue;
+				const cm = c._currentMeasure;
+				add({name: c.name, type: c.getClassName(), interactive, left: rect.left + cm.left * sx, top: rect.top + cm.top * sy, w: cm.width * sx, h: cm.height * sy, text});
+			}
+		}
+		return {engine: 'babylon', version: (B.Engine && B.Engine.Version) || ''};
+	}`,
+}
+
+// threeAdapter finds a THREE.Scene and camera on window (or one level down,
+// e.g. window.app.scene) and projects visible meshes and sprites. Three.js has
+// no notion of interactivity, so objects whose userData sets interactive,
+// clickable or onClick are marked interactive; other named objects are listed.
+var threeAdapter = EngineAdapter{
+	Name:       "threejs",
+	Frameworks: []string{"threejs"},
+	Globals:    []string{"three"},
+	Script: `() => {
+		const scene = (window.scene && window.scene.isScene) ? window.scene : findGlobal(v => v.isScene === true);
+		const camera = (window.camera && window.camera.isCamera) ? window.camera : findGlobal(v => v.isCamera === true);
+		if (!scene || !camera) return null;
+		camera.updateMatrixWorld();
+		scene.traverseVisible(o => {
+			if (!o.isMesh && !o.isSprite) return;
+			const ud = o.userData || {};
+			const interactive = !!(ud.interactive || ud.clickable || ud.onClick);
+			if (!interactive && !o.name) return;
+			const g = o.geometry;
+			if (!g) return;
+			if (!g.boundingBox) g.computeBoundingBox();
+			const bb = g.boundingBox, v = bb.min.clone();
+			const xs = [], ys = [];
+			for (const px of [bb.min.x, bb.max.x]) for (const py of [bb.min.y, bb.max.y]) for (const pz of [bb.min.z, bb.max.z]) {
+				v.set(px, py, pz).applyMatrix4(o.matrixWorld).project(camera);
+				if (v.z < -1 || v.z > 1) return;
+				xs.push(rect.left + (v.x + 1) / 2 * rect.width);
+				ys.push(rect.top + (1 - v.y) / 2 * rect.height);
+			}
+			const x0 = Math.min(...xs), y0 = Math.min(...ys);
+			add({name: o.name || o.type, type: o.type, interactive, left: x0, top: y0, w: Math.max(...xs) - x0, h: Math.max(...ys) - y0});
+		});
+		return {engine: 'threejs', version: window.__THREE__ || (window.THREE && window.THREE.REVISION) || ''};
+	}`,
+}
+
+// playCanvasAdapter lists element entities (screen corners, y-up in device
+// pixels) and projects rendered entities with the first camera. Buttons,
+// input-enabled elements and entities with collision are interactive.
+var playCanvasAdapter = EngineAdapter{
+	Name:       "playcanvas",
+	Frameworks: []string{"playcanvas"},
+	Globals:    []string{"playcanvas"},
+	Script: `() => {
+		const pc = window.pc;
+		if (!pc) return null;
+		const app = pc.app || (pc.AppBase && pc.AppBase.getApplication && pc.AppBase.getApplication()) ||
+			(pc.Application && pc.Application.getApplication && pc.Application.getApplication());
+		if (!app || !app.root) return null;
+		const cr = app.graphicsDevice.canvas.getBoundingClientRect();
+		const sx = cr.width / app.graphicsDevice.width, sy = cr.height / app.graphicsDevice.height;
+		const cams = app.root.findComponents ? app.root.findComponents('camera').filter(c => c.enabled) : [];
+		const cam = cams.length ? cams[0] : null;
+		for (const e of app.root.find(e => e.enabled && (e.element || e.button || e.render || e.model))) {
+			const interactive = !!e.button || !!(e.element && e.element.useInput) || !!e.collision;
+			const text = e.element && e.element.text;
+			if (e.element && e.element.screenCorners && e.element.screen && e.element.screen.screen && e.element.screen.screen.screenSpace) {
+				const c = e.element.screenCorners;
+				const xs = c.map(p => p.x), ys = c.map(p => p.y);
+				const x0 = Math.min(...xs), y1 = Math.max(...ys);
+				add({name: e.name, type: 'element', interactive, text, left: cr.left + x0 * sx, top: cr.top + cr.height - y1 * sy, w: (Math.max(...xs) - x0) * sx, h: (y1 - Math.min(...ys)) * sy});
+				continue;
+			}
+			const mi = (e.render && e.render.meshInstances) || (e.model && e.model.meshInstances);
+			if (!cam || !mi || !mi.length || (!interactive && !e.name)) continue;
+			const aabb = mi[0].aabb, c = aabb.center, he = aabb.halfExtents;
+			const xs = [], ys = [];
+			for (const dx of [-1, 1]) for (const dy of [-1, 1]) for (const dz of [-1, 1]) {
+				const p = cam.worldToScreen(new pc.Vec3(c.x + dx * he.x, c.y + dy * he.y, c.z + dz * he.z));
+				xs.push(cr.left + p.x); ys.push(cr.top + p.y);
+			}
+			const x0 = Math.min(...xs), y0 = Math.min(...ys);
+			add({name: e.name, type: e.render ? 'render' : 'model', interactive, left: x0, top: y0, w: Math.max(...xs) - x0, h: Math.max(...ys) - y0});
+		}
+		return {engine: 'playcanvas', version: pc.version || ''};
+	}`,
+}
+
+// constructAdapter reads a Construct 3 runtime the project exposes as
+// globalThis.runtime or window.c3runtime (from runOnStartup). Instances with an
+// isEnabled property (buttons, inputs) or named like buttons are interactive.
+var constructAdapter = EngineAdapter{
+	Name:       "construct",
+	Frameworks: []string{"construct"},
+	Globals:    []string{"construct"},
+	Script: `() => {
+		const isRuntime = v => v && v.objects && typeof v.getInstanceByUid === 'function';
+		const runtime = [window.c3runtime, window.runtime].find(isRuntime);
+		if (!runtime) {
+			if (window.C3 || window.c3_runtimeInterface) return {engine: 'construct', error: 'Construct runtime is not exposed to the page. Add globalThis.runtime = runtime in runOnStartup to allow inspection.'};
+			return null;
+		}
+		const layout = runtime.layout;
+		for (const name of Object.keys(runtime.objects)) {
+			const cls = runtime.objects[name];
+			if (!cls || typeof cls.getAllInstances !== 'function') continue;
+			for (const inst of cls.getAllInstances()) {
+				if (!inst.layer || inst.isVisible === false || typeof inst.getBoundingBox !== 'function') continue;
+				const bb = inst.getBoundingBox();
+				const [x0, y0] = inst.layer.layerToCssPx(bb.left, bb.top);
+				const [x1, y1] = inst.layer.layerToCssPx(bb.right, bb.bottom);
+				const interactive = 'isEnabled' in inst ? inst.isEnabled !== false : /btn|button/i.test(name);
+				add({scene: layout && layout.name, name, type: name, interactive, left: rect.left + x0, top: rect.top + y0, w: x1 - x0, h: y1 - y0, text: typeof inst.text === 'string' ? inst.text : undefined});
+			}
+		}
+		return {engine: 'construct', scenes: layout ? [layout.name] : undefined};
+	}`,
+}
+
+// unityAdapter recognises Unity WebGL builds. Unity does not expose its scene
+// to JavaScript, so objects come from the page hook; without one it explains
+// how to add it.
+var unityAdapter = EngineAdapter{
+	Name:       "unity",
+	Frameworks: []string{"unity"},
+	Globals:    []string{"unity"},
+	Script: `() => {
+		if (!(window.unityInstance || window.gameInstance || window.createUnityInstance || window.UnityLoader)) return null;
+		return {engine: 'unity', error: 'Unity WebGL does not expose its scene graph. Define window.__WIZARDS_QA__.inspect() in a .jslib plugin returning [{name, type, interactive, x, y, w, h, text}] in CSS pixels to enable inspection.'};
+	}`,
+}
@@ -0,0 +1,57 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func adapterNames(adapters []EngineAdapter) string {
+	var out []string
+	for _, a := range adapters {
+		out = append(out, a.Name)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestSelectEngineAdapters(t *testing.T) {
+	tests := []struct {
+		framework string
+		globals   []string
+		prefix    string
+	}{
+		{"phaser", []string{"Phaser 3.80.1"}, "hook,phaser3,phaser4,"},
+		{"unknown", []string{"Phaser 4.0.0"}, "hook,phaser4,phaser3,pixi,"},
+		{"babylon", nil, "hook,babylon,phaser3,"},
+		{"unknown", []string{"Unity", "canvas:1"}, "hook,unity,phaser3,"},
+		{"", nil, "hook,phaser3,phaser4,pixi,cocos,babylon,threejs,playcanvas,construct,unity"},
+	}
+	for _, tt := range tests {
+		adapters := SelectEngineAdapters(tt.framework, tt.globals)
+		got := adapterNames(adapters)
+		if !strings.HasPrefix(got+",", tt.prefix) {
+			t.Errorf("SelectEngineAdapters(%q, %v) = %s, want prefix %s", tt.framework, tt.globals, got, tt.prefix)
+		}
+		if len(adapters) != len(engineAdapters) {
+			t.Errorf("SelectEngineAdapters(%q, %v) returned %d adapters, want all %d", tt.framework, tt.globals, len(adapters), len(engineAdapters))
+		}
+	}
+}
+
+func TestInspectGameObjectsTool(t *testing.T) {
+	page := &fakePage{}
+	exec := &BrowserToolExecutor{Page: page, Framework: "cocos"}
+	if _, _, err := exec.Execute("inspect_game_objects", nil); err != nil {
+		t.Fatalf("inspect_game_objects: %v", err)
+	}
+	if len(page.calls) != 1 || page.calls[0] != "eval" {
+		t.Fatalf("page got %q", page.calls)
+	}
+
+	script := inspectGameObjectsScript(SelectEngineAdapters("cocos", nil))
+	if strings.Index(script, "['cocos',") > strings.Index(script, "['phaser3',") {
+		t.Error("the detected engine's adapter should run before the others")
+	}
+	if strings.Count(script, "(() => {") != 1 || !strings.HasSuffix(script, "})()") {
+		t.Error("script should be a single immediately invoked function")
+	}
+}
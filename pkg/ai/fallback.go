@@ -0,0 +1,262 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/retry"
+)
+
+// FallbackClient tries an ordered chain of AI clients, failing over to the next one
+// when a call still fails with a retryable error (5xx/429) after that client's own
+// retries are exhausted. Non-retryable errors (bad request, auth) are returned as-is,
+// since another provider would not fix them.
+type FallbackClient struct {
+	Clients []Client
+
+	// OnFailover is called when a client is abandoned for the next one in the chain.
+	OnFailover func(from, to string, err error)
+
+	mu     sync.Mutex
+	active int // index of the client that answered the most recent call
+}
+
+// NewFallbackClient creates a fallback chain. The first client is the primary, and
+// the chain implements the optional interfaces (ImageAnalyzer, ToolUseAgent,
+// StructuredOutputAgent) the primary implements, so callers pick the same path
+// they would for the primary alone. Use FallbackOf to reach the *FallbackClient.
+func NewFallbackClient(clients ...Client) Client {
+	f := &FallbackClient{Clients: clients}
+	return f.withCapabilities()
+}
+
+// Capability mixins of a FallbackClient, combined by withCapabilities.
+type (
+	fallbackImages     struct{ f *FallbackClient }
+	fallbackTools      struct{ f *FallbackClient }
+	fallbackStructured struct{ f *FallbackClient }
+)
+
+// withCapabilities returns f with the optional interfaces its primary implements.
+func (f *FallbackClient) withCapabilities() Client {
+	primary := f.Clients[0]
+	images, tools, structured := supportsImages(primary), supportsTools(primary), supportsStructured(primary)
+	i, t, s := fallbackImages{f}, fallbackTools{f}, fallbackStructured{f}
+	switch {
+	case images && tools && structured:
+		return struct {
+			*FallbackClient
+			fallbackImages
+			fallbackTools
+			fallbackStructured
+		}{f, i, t, s}
+	case images && tools:
+		return struct {
+			*FallbackClient
+			fallbackImages
+			fallbackTools
+		}{f, i, t}
+	case images && structured:
+		return struct {
+			*FallbackClient
+			fallbackImages
+			fallbackStructured
+		}{f, i, s}
+	case tools && structured:
+		return struct {
+			*FallbackClient
+			fallbackTools
+			fallbackStructured
+		}{f, t, s}
+	case images:
+		return struct {
+			*FallbackClient
+			fallbackImages
+		}{f, i}
+	case tools:
+		return struct {
+			*FallbackClient
+			fallbackTools
+		}{f, t}
+	case structured:
+		return struct {
+			*FallbackClient
+			fallbackStructured
+		}{f, s}
+	}
+	return f
+}
+
+// fallback lets FallbackOf find the FallbackClient inside the capability wrappers.
+func (f *FallbackClient) fallback() *FallbackClient { return f }
+
+// FallbackOf returns the FallbackClient client is, or nil if it is not a fallback chain.
+func FallbackOf(client Client) *FallbackClient {
+	if w, ok := client.(interface{ fallback() *FallbackClient }); ok {
+		return w.fallback()
+	}
+	return nil
+}
+
+// Active returns the client that answered the most recent call (the primary before any call).
+func (f *FallbackClient) Active() Client {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Clients[f.active]
+}
+
+// clientModel returns the model name of a client in the chain, if known.
+func clientModel(client Client) string {
+	if bc := baseClientOf(client); bc != nil {
+		return bc.Model
+	}
+	return ""
+}
+
+// try runs call against the primary and, after retryable errors, each later client
+// in order until one succeeds or fails with a non-retryable error. supports may be
+// nil when every client qualifies; otherwise the primary must support the
+// capability, and later clients that do not are passed over when failing over.
+func (f *FallbackClient) try(ctx context.Context, capability string, supports func(Client) bool, call func(Client) error) error {
+	if supports != nil && !supports(f.Clients[0]) {
+		return fmt.Errorf("AI model %s does not support %s", clientModel(f.Clients[0]), capability)
+	}
+	var chain []int
+	for i, client := range f.Clients {
+		if supports == nil || supports(client) {
+			chain = append(chain, i)
+		}
+	}
+
+	var lastErr error
+	for n, i := range chain {
+		client := f.Clients[i]
+		err := call(client)
+		if err == nil {
+			f.mu.Lock()
+			f.active = i
+			f.mu.Unlock()
+			return nil
+		}
+		lastErr = err
+		if !IsRetryableAPIError(err) || ctx.Err() != nil || n == len(chain)-1 {
+			break
+		}
+
+		from, to := clientModel(client), clientModel(f.Clients[chain[n+1]])
+		log.Printf("AI fallback: %s failed (%v), failing over to %s", from, err, to)
+		if f.OnFailover != nil {
+			f.OnFailover(from, to, err)
+		}
+	}
+	return lastErr
+}
+
+func supportsImages(c Client) bool {
+	_, ok := c.(ImageAnalyzer)
+	return ok
+}
+
+func supportsTools(c Client) bool {
+	_, ok := c.(ToolUseAgent)
+	return ok
+}
+
+func supportsStructured(c Client) bool {
+	_, ok := c.(StructuredOutputAgent)
+	return ok
+}
+
+// retryOnce wraps a single-shot call (tool use, image analysis) in the standard retry
+// policy, matching what BaseClient.callAPI already does for Analyze and Generate.
+func retryOnce(ctx context.Context, fn func() error) error {
+	return retry.DoWithRetryable(ctx, retry.DefaultConfig(), IsRetryableAPIError, fn)
+}
+
+// Analyze implements Client.
+func (f *FallbackClient) Analyze(ctx context.Context, prompt string, ctxMap map[string]interface{}) (*AnalysisResult, error) {
+	var result *AnalysisResult
+	err := f.try(ctx, "analysis", nil, func(c Client) error {
+		var err error
+		result, err = c.Analyze(ctx, prompt, ctxMap)
+		return err
+	})
+	return result, err
+}
+
+// Generate implements Client.
+func (f *FallbackClient) Generate(ctx context.Context, prompt string, ctxMap map[string]interface{}) (string, error) {
+	var text string
+	err := f.try(ctx, "generation", nil, func(c Client) error {
+		var err error
+		text, err = c.Generate(ctx, prompt, ctxMap)
+		return err
+	})
+	return text, err
+}
+
+// AnalyzeWithImage implements ImageAnalyzer, passing over fallbacks without image support.
+func (w fallbackImages) AnalyzeWithImage(ctx context.Context, prompt string, imageB64 string) (string, error) {
+	var text string
+	err := w.f.try(ctx, "image analysis", supportsImages, func(c Client) error {
+		ia := c.(ImageAnalyzer)
+		return retryOnce(ctx, func() error {
+			var err error
+			text, err = ia.AnalyzeWithImage(ctx, prompt, imageB64)
+			return err
+		})
+	})
+	return text, err
+}
+
+// AnalyzeWithImages implements ImageAnalyzer, passing over fallbacks without image support.
+func (w fallbackImages) AnalyzeWithImages(ctx context.Context, systemPrompt string, prompt string, imagesB64 []string) (string, error) {
+	var text string
+	err := w.f.try(ctx, "image analysis", supportsImages, func(c Client) error {
+		ia := c.(ImageAnalyzer)
+		return retryOnce(ctx, func() error {
+			var err error
+			text, err = ia.AnalyzeWithImages(ctx, systemPrompt, prompt, imagesB64)
+			return err
+		})
+	})
+	return text, err
+}
+
+// CallWithTools implements ToolUseAgent, passing over fallbacks without tool use. The
+// response's Model field records which model in the chain answered.
+func (w fallbackTools) CallWithTools(ctx context.Context, systemPrompt string, messages []AgentMessage, tools []ToolDefinition) (*ToolUseResponse, error) {
+	var resp *ToolUseResponse
+	err := w.f.try(ctx, "tool use", supportsTools, func(c Client) error {
+		agent := c.(ToolUseAgent)
+		return retryOnce(ctx, func() error {
+			var err error
+			resp, err = agent.CallWithTools(ctx, systemPrompt, messages, tools)
+			if err == nil && resp.Model == "" {
+				resp.Model = clientModel(c)
+			}
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GenerateStructured implements StructuredOutputAgent, passing over fallbacks without
+// native structured output.
+func (w fallbackStructured) GenerateStructured(ctx context.Context, systemPrompt string, messages []AgentMessage, schema OutputSchema) (string, error) {
+	var doc string
+	err := w.f.try(ctx, "structured output", supportsStructured, func(c Client) error {
+		agent := c.(StructuredOutputAgent)
+		return retryOnce(ctx, func() error {
+			var err error
+			doc, err = agent.GenerateStructured(ctx, systemPrompt, messages, schema)
+			return err
+		})
+	})
+	return doc, err
+}
@@ -0,0 +1,123 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// failingClient is a Client + ToolUseAgent whose every call fails with err.
+type failingClient struct {
+	err   error
+	calls int
+}
+
+func (f *failingClient) Analyze(ctx context.Context, prompt string, ctxMap map[string]interface{}) (*AnalysisResult, error) {
+	f.calls++
+	return nil, f.err
+}
+
+func (f *failingClient) Generate(ctx context.Context, prompt string, ctxMap map[string]interface{}) (string, error) {
+	f.calls++
+	return "", f.err
+}
+
+func (f *failingClient) CallWithTools(ctx context.Context, systemPrompt string, messages []AgentMessage, tools []ToolDefinition) (*ToolUseResponse, error) {
+	f.calls++
+	return nil, f.err
+}
+
+func TestFallbackFailsOverOnRetryableError(t *testing.T) {
+	primary := &failingClient{err: &apiStatusError{StatusCode: 529, Message: "overloaded"}}
+	secondary := &fakeToolClient{}
+	fc := FallbackOf(NewFallbackClient(primary, secondary))
+
+	var from, to string
+	fc.OnFailover = func(f, t string, err error) { from, to = f, t }
+
+	got, err := fc.Generate(context.Background(), "hello", nil)
+	if err != nil || got != "answer 1 to hello" {
+		t.Fatalf("Generate = %q, %v", got, err)
+	}
+	if primary.calls != 1 || secondary.calls != 1 {
+		t.Errorf("calls: primary %d, secondary %d", primary.calls, secondary.calls)
+	}
+	if fc.Active() != Client(secondary) {
+		t.Error("Active should report the client that answered")
+	}
+	if from != "" || to != "" {
+		t.Errorf("fake clients have no model, got %q -> %q", from, to)
+	}
+}
+
+func TestFallbackKeepsNonRetryableError(t *testing.T) {
+	badRequest := errors.New("API returned status 400: invalid request")
+	primary := &failingClient{err: badRequest}
+	secondary := &fakeToolClient{}
+	fc := NewFallbackClient(primary, secondary)
+
+	if _, err := fc.Generate(context.Background(), "hello", nil); !errors.Is(err, badRequest) {
+		t.Fatalf("expected the primary's error, got %v", err)
+	}
+	if secondary.calls != 0 {
+		t.Errorf("non-retryable errors must not fail over, secondary got %d calls", secondary.calls)
+	}
+}
+
+// textOnlyClient supports neither images, tools nor structured output.
+type textOnlyClient struct{ calls int }
+
+func (c *textOnlyClient) Analyze(ctx context.Context, prompt string, ctxMap map[string]interface{}) (*AnalysisResult, error) {
+	c.calls++
+	return &AnalysisResult{}, nil
+}
+
+func (c *textOnlyClient) Generate(ctx context.Context, prompt string, ctxMap map[string]interface{}) (string, error) {
+	c.calls++
+	return "text", nil
+}
+
+func TestFallbackMirrorsPrimaryCapabilities(t *testing.T) {
+	// A primary without tool use is not bypassed for a fallback that has it
+	textOnly := &textOnlyClient{}
+	if _, ok := NewFallbackClient(textOnly, &fakeToolClient{}).(ToolUseAgent); ok {
+		t.Error("the chain should only claim tool use when the primary has it")
+	}
+	if err := FallbackOf(NewFallbackClient(textOnly)).try(context.Background(), "tool use", supportsTools, nil); err == nil || IsRetryableAPIError(err) {
+		t.Errorf("expected a non-retryable error when the primary lacks the capability, got %v", err)
+	}
+
+	// Failing over passes over fallbacks without the capability
+	primary := &failingClient{err: &apiStatusError{StatusCode: 529, Message: "overloaded"}}
+	tools := &fakeToolClient{}
+	chain := NewFallbackClient(primary, textOnly, tools)
+	if _, ok := chain.(ToolUseAgent); !ok {
+		t.Fatal("the chain should claim the primary's tool use")
+	}
+	var answered []Client
+	err := FallbackOf(chain).try(context.Background(), "tool use", supportsTools, func(c Client) error {
+		answered = append(answered, c)
+		_, err := c.(ToolUseAgent).CallWithTools(context.Background(), "", nil, nil)
+		return err
+	})
+	if err != nil || len(answered) != 2 || answered[1] != Client(tools) || FallbackOf(chain).Active() != Client(tools) {
+		t.Errorf("expected the primary then the tool-use fallback, got %v, %v", answered, err)
+	}
+}
+
+func TestTokenUsagePricesEachModel(t *testing.T) {
+	var u TokenUsage
+	u.AddForModel("claude-sonnet-4-5-20250929", 1_000_000, 0, 0, 0)
+	u.AddForModel("gemini-2.5-flash", 1_000_000, 0, 0, 0)
+
+	if u.InputTokens != 2_000_000 || u.APICallCount != 2 {
+		t.Errorf("totals not accumulated: %+v", u)
+	}
+	if u.Model != "gemini-2.5-flash" {
+		t.Errorf("Model = %q, want the last answering model", u.Model)
+	}
+	if cost := u.EstimatedCost("claude-sonnet-4-5-20250929"); math.Abs(cost-3.30) > 1e-9 {
+		t.Errorf("EstimatedCost = %v, want 3.30", cost)
+	}
+}
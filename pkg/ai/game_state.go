@@ -0,0 +1,144 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// GameStateProbes maps probe names (score, balance, scene, reelResult) to
+// JavaScript getters that read a value from the running game. A getter is an
+// expression such as `window.game.registry.get('score')`, or a function
+// expression returning the value. Getters must be synchronous and return
+// JSON-serialisable values.
+type GameStateProbes map[string]string
+
+// ParseGameStateProbes decodes probes from a JSON object of name to getter.
+func ParseGameStateProbes(data []byte) (GameStateProbes, error) {
+	var probes GameStateProbes
+	if err := json.Unmarshal(data, &probes); err != nil {
+		return nil, fmt.Errorf("parsing game state probes: %w", err)
+	}
+	if err := probes.Validate(); err != nil {
+		return nil, err
+	}
+	return probes, nil
+}
+
+// LoadGameStateProbes reads probes from a JSON file.
+func LoadGameStateProbes(path string) (GameStateProbes, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading game state probes: %w", err)
+	}
+	return ParseGameStateProbes(data)
+}
+
+// Validate checks that every probe has a name and a getter.
+func (p GameStateProbes) Validate() error {
+	for name, getter := range p {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("game state probe with an empty name")
+		}
+		if strings.TrimSpace(getter) == "" {
+			return fmt.Errorf("game state probe %q has an empty getter", name)
+		}
+	}
+	return nil
+}
+
+// Names returns the probe names in sorted order.
+func (p GameStateProbes) Names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GameState holds the values read by a set of probes. Probes that threw or
+// returned something unusable are reported in Errors instead of Values.
+type GameState struct {
+	Values map[string]interface{} `json:"values"`
+	Errors map[string]string      `json:"errors,omitempty"`
+}
+
+// ReadGameState evaluates the named probes (all of them when names is empty)
+// in a single script on the page.
+func ReadGameState(page BrowserPage, probes GameStateProbes, names []string) (*GameState, error) {
+	if len(probes) == 0 {
+		return nil, fmt.Errorf("no game state probes are defined for this project")
+	}
+	if len(names) == 0 {
+		names = probes.Names()
+	}
+	for _, name := range names {
+		if _, ok := probes[name]; !ok {
+			return nil, fmt.Errorf("unknown game state probe %q (defined: %s)", name, strings.Join(probes.Names(), ", "))
+		}
+	}
+
+	result, err := page.EvalJS(gameStateScript(probes, names))
+	if err != nil {
+		return nil, fmt.Errorf("reading game state: %w", err)
+	}
+	var state GameState
+	if err := json.Unmarshal([]byte(result), &state); err != nil {
+		return nil, fmt.Errorf("reading game state: %s", Truncate(result, 200))
+	}
+	if state.Values == nil {
+		state.Values = map[string]interface{}{}
+	}
+	return &state, nil
+}
+
+// gameStateScript builds the expression that runs each getter in isolation and
+// returns {values, errors} as JSON.
+func gameStateScript(probes GameStateProbes, names []string) string {
+	var b strings.Builder
+	b.WriteString("(() => {\n\tconst values = {}, errors = {};\n\tconst probes = [\n")
+	for _, name := range names {
+		quoted, _ := json.Marshal(name)
+		fmt.Fprintf(&b, "\t\t[%s, () => (\n%s\n)],\n", quoted, strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(probes[name]), ";")))
+	}
+	b.WriteString(`	];
+	for (const [name, get] of probes) {
+		try {
+			let v = get();
+			if (typeof v === 'function') v = v();
+			if (v && typeof v.then === 'function') { errors[name] = 'getter returned a Promise; probes must be synchronous'; continue; }
+			values[name] = v === undefined ? null : JSON.parse(JSON.stringify(v));
+		} catch (e) {
+			errors[name] = String((e && e.message) || e);
+		}
+	}
+	return JSON.stringify({values, errors});
+})()`)
+	return b.String()
+}
+
+// ReadGameStateTool returns the read_game_state tool definition for the given
+// probe names. It is only offered when the project defines probes.
+func ReadGameStateTool(names []string) ToolDefinition {
+	return ToolDefinition{
+		Name:        "read_game_state",
+		Description: fmt.Sprintf("Read the game's internal state through the probes defined for this project: %s. Returns each probe's current value as JSON. Use it to check scores, balances, the current scene or round results exactly instead of reading them from screenshots, e.g. before and after a bet or spin.", strings.Join(names, ", ")),
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"probes": map[string]interface{}{
+					"type":        "array",
+					"description": "Names of the probes to read (default: all)",
+					"items": map[string]interface{}{
+						"type": "string",
+						"enum": names,
+					},
+				},
+			},
+			"required": []string{},
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseGameStateProbes(t *testing.T) {
+	probes, err := ParseGameStateProbes([]byte(`{"score": "window.game.registry.get('score')", "balance": "() => wallet.balance"}`))
+	if err != nil {
+		t.Fatalf("ParseGameStateProbes: %v", err)
+	}
+	if got := strings.Join(probes.Names(), ","); got != "balance,score" {
+		t.Errorf("Names() = %s", got)
+	}
+	for _, bad := range []string{`{"score": ""}`, `{"": "1"}`, `["score"]`} {
+		if _, err := ParseGameStateProbes([]byte(bad)); err == nil {
+			t.Errorf("ParseGameStateProbes(%s) should fail", bad)
+		}
+	}
+}
+
+func TestReadGameState(t *testing.T) {
+	probes := GameStateProbes{"balance": "wallet.balance;", "scene": "game.scene.key"}
+	page := &fakePage{eval: `{"values": {"balance": 990}, "errors": {"scene": "game is not defined"}}`}
+	state, err := ReadGameState(page, probes, nil)
+	if err != nil {
+		t.Fatalf("ReadGameState: %v", err)
+	}
+	if state.Values["balance"] != float64(990) || state.Errors["scene"] != "game is not defined" {
+		t.Errorf("state = %+v", state)
+	}
+
+	if _, err := ReadGameState(page, probes, []string{"lives"}); err == nil || !strings.Contains(err.Error(), "balance, scene") {
+		t.Errorf("expected an unknown probe error listing the probes, got %v", err)
+	}
+	if _, err := ReadGameState(&fakePage{eval: "Error: boom"}, probes, nil); err == nil {
+		t.Error("expected an error for a non-JSON result")
+	}
+	if _, err := ReadGameState(page, nil, nil); err == nil {
+		t.Error("expected an error without probes")
+	}
+
+	script := gameStateScript(probes, []string{"balance"})
+	if !strings.Contains(script, "[\"balance\", () => (\nwallet.balance\n)]") || strings.Contains(script, "game.scene") {
+		t.Errorf("unexpected script:\n%s", script)
+	}
+}
+
+func TestReadGameStateTool(t *testing.T) {
+	page := &fakePage{eval: `{"values": {"score": 12}}`}
+	exec := &BrowserToolExecutor{Page: page, Probes: GameStateProbes{"score": "score"}}
+	text, _, err := exec.Execute("read_game_state", json.RawMessage(`{"probes": ["score"]}`))
+	if err != nil {
+		t.Fatalf("read_game_state: %v", err)
+	}
+	if !strings.Contains(text, `"score": 12`) {
+		t.Errorf("result = %s", text)
+	}
+
+	if _, _, err := (&BrowserToolExecutor{Page: page}).Execute("read_game_state", nil); err == nil {
+		t.Error("expected an error when the project has no probes")
+	}
+
+	var names []string
+	for _, tool := range AgentTools(AgentConfig{Probes: exec.Probes}) {
+		names = append(names, tool.Name)
+	}
+	if !strings.Contains(strings.Join(names, ","), "read_game_state") {
+		t.Error("read_game_state missing when probes are configured")
+	}
+	if !strings.Contains(BuildAgentSystemPrompt(AgentConfig{Probes: exec.Probes}), "GAME STATE PROBES") {
+		t.Error("system prompt does not mention the probes")
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // apiStatusError wraps an HTTP status code for retry classification.
@@ -46,82 +49,108 @@ func NewGeminiClient(apiKey, model string, temperature float64, maxTokens int) *
 
 // geminiRequest represents the request to Gemini API
 type geminiRequest struct {
-	Contents         []geminiContent         `json:"contents"`
-	GenerationConfig geminiGenerationConfig `json:"generationConfig"`
+	Contents          []geminiContent        `json:"contents"`
+	SystemInstruction *geminiContent         `json:"systemInstruction,omitempty"`
+	Tools             []geminiTool           `json:"tools,omitempty"`
+	GenerationConfig  geminiGenerationConfig `json:"generationConfig"`
 }
 
 type geminiContent struct {
+	Role  string       `json:"role,omitempty"` // "user" or "model"
 	Parts []geminiPart `json:"parts"`
 }
 
 type geminiPart struct {
-	Text string `json:"text"`
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"`
+	InlineData       *geminiInlineData       `json:"inlineData,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+	ThoughtSignature string                  `json:"thoughtSignature,omitempty"`
+}
+
+type geminiInlineData struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"` // base64
+}
+
+type geminiFunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type geminiFunctionResponse struct {
+	ID       string                 `json:"id,omitempty"`
+	Name     string                 `json:"name"`
+	Response map[string]interface{} `json:"response"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+type geminiFunctionDeclaration struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
 }
 
 type geminiGenerationConfig struct {
-	Temperature     float64 `json:"temperature,omitempty"`
-	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
+	Temperature        float64                `json:"temperature,omitempty"`
+	MaxOutputTokens    int                    `json:"maxOutputTokens,omitempty"`
+	ResponseMimeType   string                 `json:"responseMimeType,omitempty"`
+	ResponseJSONSchema map[string]interface{} `json:"responseJsonSchema,omitempty"`
 }
 
 // geminiResponse represents the response from Gemini API
 type geminiResponse struct {
 	Candidates []struct {
 		Content struct {
-			Parts []struct {
-				Text string `json:"text"`
-			} `json:"parts"`
+			Parts []geminiPart `json:"parts"`
 		} `json:"content"`
 		FinishReason string `json:"finishReason"`
 	} `json:"candidates"`
 	UsageMetadata struct {
-		PromptTokenCount     int `json:"promptTokenCount"`
-		CandidatesTokenCount int `json:"candidatesTokenCount"`
-		TotalTokenCount      int `json:"totalTokenCount"`
+		PromptTokenCount        int `json:"promptTokenCount"`
+		CandidatesTokenCount    int `json:"candidatesTokenCount"`
+		CachedContentTokenCount int `json:"cachedContentTokenCount"`
+		ThoughtsTokenCount      int `json:"thoughtsTokenCount"`
+		TotalTokenCount         int `json:"totalTokenCount"`
 	} `json:"usageMetadata"`
 }
 
-// callAPIOnce makes a single API request to Gemini.
+// doRequest sends a generateContent request and returns the parsed response.
 // Note: The API key is passed as a query parameter as required by the Gemini API.
 // Ensure error messages from this method do not expose the full URL.
-func (g *GeminiClient) callAPIOnce(ctx context.Context, prompt string) (string, error) {
+func (g *GeminiClient) doRequest(ctx context.Context, req geminiRequest) (*geminiResponse, error) {
 	apiURL := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s",
 		g.Model, g.APIKey)
 
-	req := geminiRequest{
-		Contents: []geminiContent{
-			{
-				Parts: []geminiPart{
-					{Text: prompt},
-				},
-			},
-		},
-		GenerationConfig: geminiGenerationConfig{
-			Temperature:     g.Temperature,
-			MaxOutputTokens: g.MaxTokens,
-		},
-	}
+	req.GenerationConfig.Temperature = g.Temperature
+	req.GenerationConfig.MaxOutputTokens = g.MaxTokens
 
 	reqBody, err := json.Marshal(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(reqBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	resp, err := g.HTTPClient.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("HTTP request failed: %w", err)
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -129,7 +158,7 @@ func (g *GeminiClient) callAPIOnce(ctx context.Context, prompt string) (string,
 		if len(errBody) > 500 {
 			errBody = errBody[:500] + "..."
 		}
-		return "", &apiStatusError{
+		return nil, &apiStatusError{
 			StatusCode: resp.StatusCode,
 			Message:    fmt.Sprintf("Gemini API returned status %d: %s", resp.StatusCode, errBody),
 		}
@@ -137,11 +166,11 @@ func (g *GeminiClient) callAPIOnce(ctx context.Context, prompt string) (string,
 
 	var geminiResp geminiResponse
 	if err := json.Unmarshal(body, &geminiResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("empty response from API")
+		return nil, fmt.Errorf("empty response from API")
 	}
 
 	if finishReason := geminiResp.Candidates[0].FinishReason; finishReason == "MAX_TOKENS" {
@@ -149,9 +178,289 @@ func (g *GeminiClient) callAPIOnce(ctx context.Context, prompt string) (string,
 			geminiResp.UsageMetadata.CandidatesTokenCount, g.MaxTokens)
 	}
 
-	if g.OnUsage != nil {
-		g.OnUsage(geminiResp.UsageMetadata.PromptTokenCount, geminiResp.UsageMetadata.CandidatesTokenCount, 0, 0)
+	u := geminiResp.UsageMetadata
+	g.reportUsage(ctx, u.PromptTokenCount-u.CachedContentTokenCount, u.CandidatesTokenCount+u.ThoughtsTokenCount, 0, u.CachedContentTokenCount)
+
+	return &geminiResp, nil
+}
+
+// textOf concatenates the non-thought text parts of the first candidate.
+func (r *geminiResponse) textOf() string {
+	var sb strings.Builder
+	for _, p := range r.Candidates[0].Content.Parts {
+		if p.Text != "" && !p.Thought {
+			sb.WriteString(p.Text)
+		}
+	}
+	return sb.String()
+}
+
+// callAPIOnce makes a single API request to Gemini.
+func (g *GeminiClient) callAPIOnce(ctx context.Context, prompt string) (string, error) {
+	resp, err := g.doRequest(ctx, geminiRequest{
+		Contents: []geminiContent{{Role: "user", Parts: []geminiPart{{Text: prompt}}}},
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.textOf(), nil
+}
+
+// AnalyzeWithImage sends a multimodal request with an image and text prompt to the Gemini API.
+func (g *GeminiClient) AnalyzeWithImage(ctx context.Context, prompt string, imageB64 string) (string, error) {
+	return g.AnalyzeWithImages(ctx, "", prompt, []string{imageB64})
+}
+
+// AnalyzeWithImages sends a multimodal request with multiple images and an optional system prompt.
+func (g *GeminiClient) AnalyzeWithImages(ctx context.Context, systemPrompt string, prompt string, imagesB64 []string) (string, error) {
+	var parts []geminiPart
+	for _, imgB64 := range imagesB64 {
+		parts = append(parts, geminiImagePart(imageSource{MediaType: "image/jpeg", Data: imgB64}))
+	}
+	parts = append(parts, geminiPart{Text: prompt})
+
+	req := geminiRequest{Contents: []geminiContent{{Role: "user", Parts: parts}}}
+	if systemPrompt != "" {
+		req.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: systemPrompt}}}
+	}
+
+	resp, err := g.doRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return resp.textOf(), nil
+}
+
+// GenerateStructured implements StructuredOutputAgent using a JSON response schema,
+// so the reply text is the document itself.
+func (g *GeminiClient) GenerateStructured(ctx context.Context, systemPrompt string, messages []AgentMessage, schema OutputSchema) (string, error) {
+	contents, err := toGeminiContents(messages)
+	if err != nil {
+		return "", err
+	}
+
+	req := geminiRequest{Contents: contents}
+	if systemPrompt != "" {
+		req.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: systemPrompt}}}
+	}
+	req.GenerationConfig.ResponseMimeType = "application/json"
+	req.GenerationConfig.ResponseJSONSchema = schema.Schema
+
+	resp, err := g.doRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return resp.textOf(), nil
+}
+
+// geminiCallSeq numbers synthesised tool call IDs for models that don't return one.
+var geminiCallSeq atomic.Int64
+
+// CallWithTools sends a function-calling request to the Gemini API. The agent history
+// (Anthropic-shaped tool_use/tool_result blocks with inline screenshots) is translated
+// to Gemini functionCall/functionResponse parts, and the reply back into a ToolUseResponse.
+func (g *GeminiClient) CallWithTools(ctx context.Context, systemPrompt string, messages []AgentMessage, tools []ToolDefinition) (*ToolUseResponse, error) {
+	contents, err := toGeminiContents(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	req := geminiRequest{Contents: contents}
+	if systemPrompt != "" {
+		req.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: systemPrompt}}}
+	}
+	if len(tools) > 0 {
+		decls := make([]geminiFunctionDeclaration, 0, len(tools))
+		for _, t := range tools {
+			decls = append(decls, geminiFunctionDeclaration{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  geminiSchema(t.InputSchema),
+			})
+		}
+		req.Tools = []geminiTool{{FunctionDeclarations: decls}}
+	}
+
+	log.Printf("CallWithTools[gemini]: sending %d contents, %d tools", len(contents), len(tools))
+	start := time.Now()
+	resp, err := g.doRequest(ctx, req)
+	elapsed := time.Since(start)
+	if err != nil {
+		log.Printf("CallWithTools[gemini]: failed after %s: %v", elapsed, err)
+		return nil, err
+	}
+
+	candidate := resp.Candidates[0]
+	toolResp := &ToolUseResponse{StopReason: "end_turn", Model: g.Model}
+	if candidate.FinishReason == "MAX_TOKENS" {
+		toolResp.StopReason = "max_tokens"
+	}
+	for _, p := range candidate.Content.Parts {
+		switch {
+		case p.FunctionCall != nil:
+			id := p.FunctionCall.ID
+			if id == "" {
+				id = fmt.Sprintf("gemini_call_%d", geminiCallSeq.Add(1))
+			}
+			toolResp.Content = append(toolResp.Content, ResponseContentBlock{
+				Type:             "tool_use",
+				ID:               id,
+				Name:             p.FunctionCall.Name,
+				Input:            json.RawMessage(toolInputJSON(p.FunctionCall.Args)),
+				ThoughtSignature: p.ThoughtSignature,
+			})
+			toolResp.StopReason = "tool_use"
+		case p.Text != "" && !p.Thought:
+			toolResp.Content = append(toolResp.Content, ResponseContentBlock{
+				Type: "text", Text: p.Text, ThoughtSignature: p.ThoughtSignature,
+			})
+		}
+	}
+
+	u := resp.UsageMetadata
+	toolResp.Usage.InputTokens = u.PromptTokenCount - u.CachedContentTokenCount
+	toolResp.Usage.OutputTokens = u.CandidatesTokenCount + u.ThoughtsTokenCount
+	toolResp.Usage.CacheReadInputTokens = u.CachedContentTokenCount
+	toolResp.Usage.ThinkingTokens = u.ThoughtsTokenCount
+
+	log.Printf("CallWithTools[gemini]: completed in %s (in=%d out=%d cache_read=%d tokens, stop=%s)",
+		elapsed, toolResp.Usage.InputTokens, toolResp.Usage.OutputTokens, toolResp.Usage.CacheReadInputTokens, toolResp.StopReason)
+	return toolResp, nil
+}
+
+// geminiSkipSignature is the documented placeholder for function calls that have no
+// thought signature (e.g. history produced by another provider). Gemini 3 rejects
+// unsigned function calls in the current turn otherwise.
+const geminiSkipSignature = "skip_thought_signature_validator"
+
+// toGeminiContents converts the agent message history to Gemini contents. Tool results
+// are matched to their function names via the preceding tool_use blocks, and any
+// screenshots they carry are appended as inline image parts after the responses.
+func toGeminiContents(messages []AgentMessage) ([]geminiContent, error) {
+	toolNames := map[string]string{} // tool_use ID -> function name
+	var contents []geminiContent
+
+	for _, msg := range messages {
+		blocks, err := normalizeAgentContent(msg.Content)
+		if err != nil {
+			return nil, err
+		}
+
+		if msg.Role == "assistant" {
+			var parts []geminiPart
+			for _, b := range blocks {
+				switch b.Type {
+				case "text":
+					if b.Text != "" {
+						parts = append(parts, geminiPart{Text: b.Text, ThoughtSignature: b.ThoughtSignature})
+					}
+				case "tool_use":
+					toolNames[b.ID] = b.Name
+					sig := b.ThoughtSignature
+					if sig == "" {
+						sig = geminiSkipSignature
+					}
+					parts = append(parts, geminiPart{
+						FunctionCall:     &geminiFunctionCall{Name: b.Name, Args: json.RawMessage(toolInputJSON(b.Input))},
+						ThoughtSignature: sig,
+					})
+				}
+			}
+			if len(parts) > 0 {
+				contents = append(contents, geminiContent{Role: "model", Parts: parts})
+			}
+			continue
+		}
+
+		var responses, extra []geminiPart
+		for _, b := range blocks {
+			switch b.Type {
+			case "text":
+				if b.Text != "" {
+					extra = append(extra, geminiPart{Text: b.Text})
+				}
+			case "image":
+				if b.Source != nil {
+					extra = append(extra, geminiImagePart(*b.Source))
+				}
+			case "tool_result":
+				text, images := b.toolResultParts()
+				key := "content"
+				if b.IsError {
+					key = "error"
+				}
+				responses = append(responses, geminiPart{FunctionResponse: &geminiFunctionResponse{
+					Name:     toolNames[b.ToolUseID],
+					Response: map[string]interface{}{key: text},
+				}})
+				for _, img := range images {
+					extra = append(extra, geminiImagePart(img))
+				}
+			}
+		}
+		parts := append(responses, extra...)
+		if len(parts) == 0 {
+			continue
+		}
+		// Gemini requires alternating roles; merge consecutive user turns.
+		if n := len(contents); n > 0 && contents[n-1].Role == "user" {
+			contents[n-1].Parts = append(contents[n-1].Parts, parts...)
+			continue
+		}
+		contents = append(contents, geminiContent{Role: "user", Parts: parts})
+	}
+	return contents, nil
+}
+
+// geminiImagePart wraps a base64 image as an inline data part.
+func geminiImagePart(src imageSource) geminiPart {
+	mimeType := src.MediaType
+	if mimeType == "" {
+		mimeType = "image/jpeg"
 	}
+	return geminiPart{InlineData: &geminiInlineData{MimeType: mimeType, Data: src.Data}}
+}
 
-	return geminiResp.Candidates[0].Content.Parts[0].Text, nil
+// geminiSchema converts a JSON Schema tool definition to the OpenAPI subset Gemini
+// accepts: object schemas without properties are dropped and empty "required"
+// lists removed, since the API rejects both.
+func geminiSchema(schema map[string]interface{}) map[string]interface{} {
+	if len(schema) == 0 {
+		return nil
+	}
+	if props, ok := schema["properties"].(map[string]interface{}); schema["type"] == "object" && (!ok || len(props) == 0) {
+		return nil
+	}
+	out := make(map[string]interface{}, len(schema))
+	for k, v := range schema {
+		switch k {
+		case "properties":
+			in, ok := v.(map[string]interface{})
+			if !ok {
+				out[k] = v
+				continue
+			}
+			props := map[string]interface{}{}
+			for name, prop := range in {
+				if pm, ok := prop.(map[string]interface{}); ok {
+					if converted := geminiSchema(pm); converted != nil {
+						props[name] = converted
+						continue
+					}
+				}
+				props[name] = prop
+			}
+			out[k] = props
+		case "required":
+			if req, ok := v.([]string); ok && len(req) == 0 {
+				continue
+			}
+			out[k] = v
+		case "additionalProperties", "$schema":
+			// unsupported by Gemini
+		default:
+			out[k] = v
+		}
+	}
+	return out
 }
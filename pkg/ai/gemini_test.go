@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestToGeminiContentsToolRoundTrip(t *testing.T) {
+	messages := []AgentMessage{
+		{Role: "user", Content: "start"},
+		{Role: "assistant", Content: []ResponseContentBlock{
+			{Type: "text", Text: "Clicking play"},
+			{Type: "tool_use", ID: "gemini_call_1", Name: "click", Input: json.RawMessage(`{"x":1,"y":2}`), ThoughtSignature: "sig"},
+			{Type: "tool_use", ID: "toolu_2", Name: "screenshot"},
+		}},
+		{Role: "user", Content: []interface{}{
+			ToolResultBlock{Type: "tool_result", ToolUseID: "gemini_call_1", Content: []interface{}{
+				map[string]interface{}{"type": "text", "text": "Clicked."},
+				map[string]interface{}{"type": "image", "source": map[string]interface{}{
+					"type": "base64", "media_type": "image/jpeg", "data": "AAAA",
+				}},
+			}},
+			ToolResultBlock{Type: "tool_result", ToolUseID: "toolu_2", Content: "boom", IsError: true},
+		}},
+	}
+
+	contents, err := toGeminiContents(messages)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(contents) != 3 || contents[1].Role != "model" || contents[2].Role != "user" {
+		t.Fatalf("unexpected contents: %+v", contents)
+	}
+
+	model := contents[1].Parts
+	if len(model) != 3 || model[1].FunctionCall == nil || model[1].ThoughtSignature != "sig" {
+		t.Fatalf("unexpected model parts: %+v", model)
+	}
+	if model[2].ThoughtSignature != geminiSkipSignature || string(model[2].FunctionCall.Args) != "{}" {
+		t.Errorf("unsigned call should get skip signature and {} args, got %+v", model[2])
+	}
+
+	user := contents[2].Parts
+	if len(user) != 3 {
+		t.Fatalf("expected 2 function responses + 1 image, got %d parts", len(user))
+	}
+	if fr := user[0].FunctionResponse; fr == nil || fr.Name != "click" || fr.Response["content"] != "Clicked." {
+		t.Errorf("first response = %+v", user[0].FunctionResponse)
+	}
+	if fr := user[1].FunctionResponse; fr == nil || fr.Name != "screenshot" || fr.Response["error"] != "boom" {
+		t.Errorf("second response = %+v", user[1].FunctionResponse)
+	}
+	if user[2].InlineData == nil || user[2].InlineData.Data != "AAAA" {
+		t.Errorf("screenshot should follow the responses, got %+v", user[2])
+	}
+}
+
+func TestClaudeDropsGeminiThoughtSignatures(t *testing.T) {
+	// A history started on Gemini and continued on Claude after failover
+	messages := []AgentMessage{
+		{Role: "user", Content: "start"},
+		{Role: "assistant", Content: []ResponseContentBlock{
+			{Type: "tool_use", ID: "gemini_call_1", Name: "click", Input: json.RawMessage(`{"x":1,"y":2}`), ThoughtSignature: "sig"},
+		}},
+		{Role: "user", Content: []interface{}{
+			ToolResultBlock{Type: "tool_result", ToolUseID: "gemini_call_1", Content: "clicked"},
+		}},
+	}
+	c := NewClaudeClient("key", "claude-sonnet-4-5-20250929", 0, 1024)
+	var body string
+	c.HTTPClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		raw, _ := io.ReadAll(r.Body)
+		body = string(raw)
+		return sseResponse(
+			`{"type":"message_start","message":{"usage":{"input_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"done"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":1}}`,
+			`{"type":"message_stop"}`,
+		), nil
+	})
+	c.OnStream = func(kind, delta string) {}
+
+	if _, err := c.CallWithTools(context.Background(), "system", messages, BrowserTools(800, 600)); err != nil {
+		t.Fatalf("CallWithTools: %v", err)
+	}
+	if strings.Contains(body, "thoughtSignature") {
+		t.Errorf("Claude request carries a Gemini thought signature: %s", body)
+	}
+	if !strings.Contains(body, `"gemini_call_1"`) {
+		t.Errorf("tool_use block missing from the request: %s", body)
+	}
+	if got := messages[1].Content.([]ResponseContentBlock)[0].ThoughtSignature; got != "sig" {
+		t.Errorf("history lost its signature (%q); Gemini needs it on the next turn", got)
+	}
+}
+
+func TestGeminiSchemaDropsUnsupportedFields(t *testing.T) {
+	tools := BrowserTools(800, 600)
+	if geminiSchema(tools[0].InputSchema) != nil {
+		t.Error("schema without properties should be dropped")
+	}
+
+	click := geminiSchema(tools[1].InputSchema)
+	props, ok := click["properties"].(map[string]interface{})
+	if !ok || len(props) != 2 {
+		t.Fatalf("click properties = %+v", click["properties"])
+	}
+	if _, ok := click["required"]; !ok {
+		t.Error("non-empty required list should be kept")
+	}
+}
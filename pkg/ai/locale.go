@@ -0,0 +1,41 @@
+package ai
+
+import "fmt"
+
+// wordingLocaleNote tells the wording check which locale the game was shown
+// in, so untranslated text is reported. Empty when no locale was emulated.
+func wordingLocaleNote(locale string) string {
+	if locale == "" {
+		return ""
+	}
+	return fmt.Sprintf("  The browser locale is %s: visible text should be in that language. Report untranslated or mixed-language strings and dates, numbers or currencies not formatted for %s as 'translation' findings.\n", locale, locale)
+}
+
+// gliGeolocationNote tells the GLI check where the browser claimed to be, so
+// geo-blocking and location checks can be judged. Empty when no position was
+// emulated.
+func gliGeolocationNote(position string) string {
+	if position == "" {
+		return ""
+	}
+	return fmt.Sprintf("  The browser reported its position as %s (latitude,longitude). Judge the geolocation category against that location: whether the game verified it, and blocked or allowed play as the jurisdictions require.\n", position)
+}
+
+// MergeWordingFindings combines the wording findings of runs in several
+// locales into one list, in the order of locales, tagging each finding with
+// its locale. Repeated findings within a locale are kept once.
+func MergeWordingFindings(locales []string, byLocale map[string][]WordingFinding) []WordingFinding {
+	var merged []WordingFinding
+	for _, locale := range locales {
+		seen := make(map[WordingFinding]bool)
+		for _, f := range byLocale[locale] {
+			f.Locale = locale
+			if seen[f] {
+				continue
+			}
+			seen[f] = true
+			merged = append(merged, f)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,53 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeWordingFindings(t *testing.T) {
+	untranslated := WordingFinding{Category: "translation", Text: "Spin", Severity: "major", Location: "bottom bar"}
+	typo := WordingFinding{Category: "spelling", Text: "Gewinnn", Severity: "minor", Location: "win popup"}
+	merged := MergeWordingFindings([]string{"de-DE", "fr-FR", "es-ES"}, map[string][]WordingFinding{
+		"fr-FR": {untranslated},
+		"de-DE": {untranslated, typo, untranslated},
+	})
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 findings, got %+v", merged)
+	}
+	got := []string{}
+	for _, f := range merged {
+		got = append(got, f.Locale+":"+f.Text)
+	}
+	if strings.Join(got, " ") != "de-DE:Spin de-DE:Gewinnn fr-FR:Spin" {
+		t.Errorf("unexpected merge order %v", got)
+	}
+}
+
+func TestWordingPromptLocale(t *testing.T) {
+	modules := DefaultAnalysisModules()
+	if strings.Contains(BuildAnalysisPrompt(modules), "browser locale") {
+		t.Error("prompt mentions a locale although none was emulated")
+	}
+	modules.Locale = "de-DE"
+	for name, prompt := range map[string]string{"analysis": BuildAnalysisPrompt(modules), "synthesis": BuildSynthesisPrompt(modules)} {
+		if !strings.Contains(prompt, "The browser locale is de-DE") {
+			t.Errorf("%s prompt does not name the locale", name)
+		}
+	}
+	modules.Wording = false
+	if strings.Contains(BuildSynthesisPrompt(modules), "browser locale") {
+		t.Error("locale note added without the wording module")
+	}
+}
+
+func TestGLIPromptGeolocation(t *testing.T) {
+	modules := AnalysisModules{GLI: true, GLIJurisdictions: []string{"gb"}, Geolocation: "51.5072,-0.1276"}
+	if !strings.Contains(BuildAnalysisPrompt(modules), "position as 51.5072,-0.1276") {
+		t.Error("analysis prompt does not name the emulated position")
+	}
+	modules.GLIJurisdictions = nil
+	if strings.Contains(BuildSynthesisPrompt(modules), "reported its position") {
+		t.Error("position note added without GLI jurisdictions")
+	}
+}
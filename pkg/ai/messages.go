@@ -0,0 +1,79 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// agentBlock is a provider-neutral view of a single content block in an AgentMessage.
+// Agent histories mix map[string]interface{}, ResponseContentBlock and ToolResultBlock
+// values (all shaped like Anthropic content blocks), so non-Claude clients flatten them
+// into this struct before translating to their own wire format.
+type agentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	Source    *imageSource    `json:"source,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+
+	ThoughtSignature string `json:"thoughtSignature,omitempty"`
+}
+
+// normalizeAgentContent converts an AgentMessage.Content value (string or block slice)
+// into a list of agentBlocks by round-tripping through JSON.
+func normalizeAgentContent(content interface{}) ([]agentBlock, error) {
+	if s, ok := content.(string); ok {
+		return []agentBlock{{Type: "text", Text: s}}, nil
+	}
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("marshal agent content: %w", err)
+	}
+	var blocks []agentBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return nil, fmt.Errorf("unmarshal agent content: %w", err)
+	}
+	return blocks, nil
+}
+
+// toolResultParts splits a tool_result block's content into its text and base64 images.
+// The content may be a plain string or a list of text/image blocks.
+func (b agentBlock) toolResultParts() (text string, images []imageSource) {
+	if len(b.Content) == 0 {
+		return "", nil
+	}
+	var s string
+	if json.Unmarshal(b.Content, &s) == nil {
+		return s, nil
+	}
+	var inner []agentBlock
+	if json.Unmarshal(b.Content, &inner) != nil {
+		return string(b.Content), nil
+	}
+	for _, ib := range inner {
+		switch ib.Type {
+		case "text":
+			if text != "" {
+				text += "\n"
+			}
+			text += ib.Text
+		case "image":
+			if ib.Source != nil && ib.Source.Data != "" {
+				images = append(images, *ib.Source)
+			}
+		}
+	}
+	return text, images
+}
+
+// toolInputJSON returns the tool_use input as a JSON object string, defaulting to "{}".
+func toolInputJSON(input json.RawMessage) string {
+	if len(input) == 0 || string(input) == "null" {
+		return "{}"
+	}
+	return string(input)
+}
@@ -0,0 +1,215 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+// Limits for network listings sent to the model.
+const (
+	defaultNetworkLogLimit = 30
+	maxNetworkLogLimit     = 100
+	maxFailedForSynthesis  = 25
+	maxNetworkDropMs       = 30000
+)
+
+// networkLogTool is the network_log tool definition, part of BrowserTools.
+var networkLogTool = ToolDefinition{
+	Name:        "network_log",
+	Description: "List the network requests the page has made (URL, method, status, type, duration, size), most recent last. Use it to find failed asset loads, 4xx/5xx responses from the game server, slow API calls or requests that never finished. Filters can be combined.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"failed_only": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Only list failed loads and HTTP error responses (status >= 400)",
+			},
+			"url_contains": map[string]interface{}{
+				"type":        "string",
+				"description": "Only list requests whose URL contains this text (case-insensitive)",
+			},
+			"resource_type": map[string]interface{}{
+				"type":        "string",
+				"description": "Only list one resource type: Document, Script, Stylesheet, Image, Media, Font, XHR, Fetch, WebSocket, Other",
+			},
+			"min_status": map[string]interface{}{
+				"type":        "integer",
+				"description": "Only list responses with at least this HTTP status (e.g. 400)",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of requests to list (default %d, max %d)", defaultNetworkLogLimit, maxNetworkLogLimit),
+			},
+		},
+		"required": []string{},
+	},
+}
+
+// setNetworkTool is the set_network tool definition, part of BrowserTools.
+var setNetworkTool = ToolDefinition{
+	Name:        "set_network",
+	Description: fmt.Sprintf("Change the browser's network connection: go offline, throttle to slow-3g or fast-3g, set a custom latency/throughput, or go back online. Use it to test disconnects and slow connections — e.g. click spin, then set offline with duration_ms to drop the connection mid-round and see whether the game recovers, shows an error or loses the bet. With duration_ms the previous connection is restored afterwards (max %dms). Returns a screenshot.", maxNetworkDropMs),
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"profile": map[string]interface{}{
+				"type":        "string",
+				"enum":        scout.NetworkProfileNames(),
+				"description": "Connection to emulate; custom uses latency_ms, download_kbps and upload_kbps",
+			},
+			"latency_ms": map[string]interface{}{
+				"type":        "number",
+				"description": "Custom profile: latency added to every request, in milliseconds",
+			},
+			"download_kbps": map[string]interface{}{
+				"type":        "number",
+				"description": "Custom profile: download limit in kilobits per second (0 = unlimited)",
+			},
+			"upload_kbps": map[string]interface{}{
+				"type":        "number",
+				"description": "Custom profile: upload limit in kilobits per second (0 = unlimited)",
+			},
+			"duration_ms": map[string]interface{}{
+				"type":        "integer",
+				"description": "Restore the previous connection after this many milliseconds; the screenshot is taken just before restoring (omit to keep the new connection)",
+			},
+		},
+		"required": []string{"profile"},
+	},
+}
+
+// formatNetworkLog renders the entries matching f for the model, with a header
+// counting everything recorded.
+func formatNetworkLog(entries []scout.NetworkEntry, f scout.NetworkFilter) string {
+	if len(entries) == 0 {
+		return "No network requests recorded."
+	}
+	failed := len(scout.FilterNetworkEntries(entries, scout.NetworkFilter{FailedOnly: true}))
+	if f.Limit <= 0 {
+		f.Limit = defaultNetworkLogLimit
+	}
+	if f.Limit > maxNetworkLogLimit {
+		f.Limit = maxNetworkLogLimit
+	}
+	all := scout.FilterNetworkEntries(entries, scout.NetworkFilter{FailedOnly: f.FailedOnly, URLContains: f.URLContains, ResourceType: f.ResourceType, MinStatus: f.MinStatus})
+	shown := all
+	if len(shown) > f.Limit {
+		shown = shown[len(shown)-f.Limit:]
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d requests recorded, %d failed.", len(entries), failed)
+	if len(all) == 0 {
+		sb.WriteString(" No requests match the filter.")
+		return sb.String()
+	}
+	if len(shown) < len(all) {
+		fmt.Fprintf(&sb, " Showing the last %d of %d matching.", len(shown), len(all))
+	}
+	for _, e := range shown {
+		sb.WriteString("\n")
+		sb.WriteString(formatNetworkEntry(e))
+	}
+	return sb.String()
+}
+
+// formatNetworkEntry renders one request as a single line.
+func formatNetworkEntry(e scout.NetworkEntry) string {
+	var status string
+	switch {
+	case e.Pending:
+		status = "PENDING"
+	case e.Failed:
+		status = "FAILED " + e.ErrorText
+	default:
+		status = fmt.Sprintf("%d", e.Status)
+	}
+	details := []string{}
+	if e.ResourceType != "" {
+		details = append(details, e.ResourceType)
+	}
+	if !e.Pending {
+		details = append(details, fmt.Sprintf("%.0fms", e.DurationMs))
+	}
+	if e.Size > 0 {
+		details = append(details, formatBytes(e.Size))
+	}
+	if e.FromCache {
+		details = append(details, "cached")
+	}
+	return fmt.Sprintf("[%s] %s %s (%s)", status, e.Method, Truncate(e.URL, 200), strings.Join(details, ", "))
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+// failedRequestsSection lists the page's failed requests for the synthesis
+// prompt, so broken assets and server errors are reported even when the agent
+// never looked at the network log. Canceled requests are left out, and those
+// recorded while the agent had the connection offline are only counted as
+// expected. It returns "" when nothing failed.
+func failedRequestsSection(page BrowserPage) string {
+	entries, err := page.GetNetworkLog()
+	if err != nil {
+		return ""
+	}
+	var failed []scout.NetworkEntry
+	offline := 0
+	for _, e := range scout.FilterNetworkEntries(entries, scout.NetworkFilter{FailedOnly: true}) {
+		switch {
+		case e.Canceled:
+		case e.Offline:
+			offline++
+		default:
+			failed = append(failed, e)
+		}
+	}
+	if len(failed) == 0 && offline == 0 {
+		return ""
+	}
+	if len(failed) == 0 {
+		return fmt.Sprintf("FAILED NETWORK REQUESTS: none, apart from those expected during the offline test (%d, the agent switched the connection off). Do not report those as defects.", offline)
+	}
+
+	// Collapse repeats (polling, retried assets) into one line with a count
+	type key struct {
+		method, url, status string
+	}
+	counts := map[key]int{}
+	var order []scout.NetworkEntry
+	for _, e := range failed {
+		k := key{e.Method, e.URL, fmt.Sprintf("%d %s", e.Status, e.ErrorText)}
+		if counts[k] == 0 {
+			order = append(order, e)
+		}
+		counts[k]++
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "FAILED NETWORK REQUESTS (recorded automatically during exploration, %d total):\n", len(failed))
+	for i, e := range order {
+		if i == maxFailedForSynthesis {
+			fmt.Fprintf(&sb, "- ... and %d more\n", len(order)-i)
+			break
+		}
+		line := formatNetworkEntry(e)
+		if n := counts[key{e.Method, e.URL, fmt.Sprintf("%d %s", e.Status, e.ErrorText)}]; n > 1 {
+			line += fmt.Sprintf(" x%d", n)
+		}
+		sb.WriteString("- " + line + "\n")
+	}
+	if offline > 0 {
+		fmt.Fprintf(&sb, "Not listed: requests that failed as expected during the offline test (%d, the agent switched the connection off); do not report those as defects.\n", offline)
+	}
+	sb.WriteString("Cover failed requests that affect the game (missing assets, server errors, broken API calls) in edgeCases and scenarios, citing the URL and status.")
+	return sb.String()
+}
@@ -0,0 +1,107 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+func testNetworkEntries() []scout.NetworkEntry {
+	return []scout.NetworkEntry{
+		{URL: "https://game.test/index.html", Method: "GET", ResourceType: "Document", Status: 200, DurationMs: 80, Size: 2048},
+		{URL: "https://game.test/atlas.png", Method: "GET", ResourceType: "Image", Status: 404, DurationMs: 12},
+		{URL: "https://api.game.test/spin", Method: "POST", ResourceType: "XHR", Failed: true, ErrorText: "net::ERR_CONNECTION_RESET"},
+		{URL: "https://api.game.test/spin", Method: "POST", ResourceType: "XHR", Failed: true, ErrorText: "net::ERR_CONNECTION_RESET"},
+		{URL: "https://www.google-analytics.com/collect", Method: "GET", Failed: true, Blocked: true, ErrorText: "blocked: inspector"},
+		{URL: "https://api.game.test/balance", Method: "GET", ResourceType: "Fetch", Pending: true},
+	}
+}
+
+func TestNetworkLogTool(t *testing.T) {
+	exec := &BrowserToolExecutor{Page: &fakePage{network: testNetworkEntries()}}
+
+	text, _, err := exec.Execute("network_log", nil)
+	if err != nil {
+		t.Fatalf("network_log: %v", err)
+	}
+	if !strings.HasPrefix(text, "6 requests recorded, 3 failed.") || !strings.Contains(text, "[PENDING] GET https://api.game.test/balance (Fetch)") {
+		t.Errorf("unexpected log:\n%s", text)
+	}
+
+	text, _, _ = exec.Execute("network_log", json.RawMessage(`{"failed_only": true, "url_contains": "atlas"}`))
+	if !strings.Contains(text, "[404] GET https://game.test/atlas.png (Image, 12ms)") || strings.Contains(text, "spin") {
+		t.Errorf("unexpected filtered log:\n%s", text)
+	}
+
+	text, _, _ = exec.Execute("network_log", json.RawMessage(`{"resource_type": "xhr", "limit": 1}`))
+	if !strings.Contains(text, "Showing the last 1 of 2 matching") {
+		t.Errorf("expected the limit to be reported:\n%s", text)
+	}
+
+	text, _, _ = (&BrowserToolExecutor{Page: &fakePage{}}).Execute("network_log", nil)
+	if text != "No network requests recorded." {
+		t.Errorf("unexpected empty log: %q", text)
+	}
+}
+
+func TestFailedRequestsSection(t *testing.T) {
+	section := failedRequestsSection(&fakePage{network: testNetworkEntries()})
+	if !strings.HasPrefix(section, "FAILED NETWORK REQUESTS") {
+		t.Fatalf("unexpected section:\n%s", section)
+	}
+	if !strings.Contains(section, "[FAILED net::ERR_CONNECTION_RESET] POST https://api.game.test/spin (XHR, 0ms) x2") {
+		t.Errorf("repeated failures should be collapsed:\n%s", section)
+	}
+	if strings.Contains(section, "google-analytics") {
+		t.Errorf("blocked requests should be left out:\n%s", section)
+	}
+	if failedRequestsSection(&fakePage{network: testNetworkEntries()[:1]}) != "" {
+		t.Error("expected no section when nothing failed")
+	}
+
+	entries := append(testNetworkEntries(),
+		scout.NetworkEntry{URL: "https://api.game.test/jackpot", Method: "GET", Failed: true, Offline: true, ErrorText: "net::ERR_INTERNET_DISCONNECTED"},
+		scout.NetworkEntry{URL: "https://game.test/music.mp3", Method: "GET", Failed: true, Canceled: true, ErrorText: "canceled"},
+	)
+	section = failedRequestsSection(&fakePage{network: entries})
+	if strings.Contains(section, "jackpot") || strings.Contains(section, "music.mp3") {
+		t.Errorf("offline and canceled requests should not be listed as failures:\n%s", section)
+	}
+	if !strings.Contains(section, "Not listed: requests that failed as expected during the offline test (1,") {
+		t.Errorf("offline failures should be counted as expected:\n%s", section)
+	}
+	if section := failedRequestsSection(&fakePage{network: entries[len(entries)-2:]}); !strings.HasPrefix(section, "FAILED NETWORK REQUESTS: none, apart from those expected during the offline test (1,") {
+		t.Errorf("unexpected offline-only section:\n%s", section)
+	}
+}
+
+func TestSetNetworkTool(t *testing.T) {
+	page := &fakePage{}
+	exec := &BrowserToolExecutor{Page: page}
+
+	text, b64, err := exec.Execute("set_network", json.RawMessage(`{"profile": "slow-3g"}`))
+	if err != nil {
+		t.Fatalf("set_network: %v", err)
+	}
+	if b64 == "" || !strings.HasPrefix(text, "Network set to slow-3g (2000ms latency, 400/400 kbps) (was online).") {
+		t.Errorf("unexpected result %q", text)
+	}
+
+	page.calls = nil
+	text, _, err = exec.Execute("set_network", json.RawMessage(`{"profile": "offline", "duration_ms": 10}`))
+	if err != nil {
+		t.Fatalf("set_network offline: %v", err)
+	}
+	if got := strings.Join(page.calls, "; "); got != "network offline; network slow-3g (2000ms latency, 400/400 kbps)" {
+		t.Errorf("expected the previous profile to be restored, got %q", got)
+	}
+	if !strings.Contains(text, "then restored to slow-3g") {
+		t.Errorf("unexpected result %q", text)
+	}
+
+	if _, _, err := exec.Execute("set_network", json.RawMessage(`{"profile": "custom"}`)); err == nil {
+		t.Error("custom profile without limits should fail")
+	}
+}
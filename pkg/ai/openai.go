@@ -0,0 +1,382 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultOpenAIBaseURL is the OpenAI API endpoint used when no base URL is configured.
+const DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// OpenAIClient implements the Client interface for OpenAI and OpenAI-compatible
+// Chat Completions servers (vLLM, Ollama, LiteLLM, Azure proxies, etc.).
+type OpenAIClient struct {
+	BaseClient
+	BaseURL string // e.g. https://api.openai.com/v1 or http://localhost:8000/v1
+}
+
+// NewOpenAIClient creates a new OpenAI API client. The base URL defaults to
+// OPENAI_BASE_URL when set, otherwise the public OpenAI endpoint.
+func NewOpenAIClient(apiKey, model string, temperature float64, maxTokens int) *OpenAIClient {
+	if model == "" {
+		model = "gpt-4.1"
+	}
+	baseURL := os.Getenv("OPENAI_BASE_URL")
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+
+	o := &OpenAIClient{BaseURL: baseURL}
+	o.BaseClient = NewBaseClient(apiKey, model, temperature, maxTokens, o)
+	return o
+}
+
+// openaiRequest represents a Chat Completions request.
+type openaiRequest struct {
+	Model               string          `json:"model"`
+	Messages            []openaiMessage `json:"messages"`
+	Temperature         *float64        `json:"temperature,omitempty"` // nil for reasoning models, which only accept the default
+	MaxTokens           int             `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"`
+	Tools               []openaiTool    `json:"tools,omitempty"`
+}
+
+// openaiMessage is a single chat message. Content is a string or []openaiContentPart.
+type openaiMessage struct {
+	Role       string           `json:"role"`
+	Content    interface{}      `json:"content"`
+	ToolCalls  []openaiToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openaiContentPart struct {
+	Type     string          `json:"type"` // "text" or "image_url"
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openaiImageURL `json:"image_url,omitempty"`
+}
+
+type openaiImageURL struct {
+	URL string `json:"url"` // data:<media type>;base64,<data>
+}
+
+type openaiTool struct {
+	Type     string             `json:"type"` // "function"
+	Function openaiToolFunction `json:"function"`
+}
+
+type openaiToolFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type openaiToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"` // "function"
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// openaiResponse represents a Chat Completions response.
+type openaiResponse struct {
+	Choices []struct {
+		Message struct {
+			Content   *string          `json:"content"`
+			ToolCalls []openaiToolCall `json:"tool_calls"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens        int `json:"prompt_tokens"`
+		CompletionTokens    int `json:"completion_tokens"`
+		PromptTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"prompt_tokens_details"`
+	} `json:"usage"`
+}
+
+// newRequest builds a request with the model, temperature and token limit filled in.
+// The public OpenAI API rejects max_tokens for reasoning models, so it gets
+// max_completion_tokens; self-hosted servers mostly only understand max_tokens.
+// Reasoning models also reject any temperature but the default, so they get none.
+func (o *OpenAIClient) newRequest(messages []openaiMessage) openaiRequest {
+	req := openaiRequest{
+		Model:    o.Model,
+		Messages: messages,
+	}
+	if !isOpenAIReasoningModel(o.Model) {
+		temperature := o.Temperature
+		req.Temperature = &temperature
+	}
+	if strings.TrimRight(o.BaseURL, "/") == DefaultOpenAIBaseURL {
+		req.MaxCompletionTokens = o.MaxTokens
+	} else {
+		req.MaxTokens = o.MaxTokens
+	}
+	return req
+}
+
+// isOpenAIReasoningModel reports whether model is one of OpenAI's o-series
+// reasoning models (o1, o3, o4-mini, ...).
+func isOpenAIReasoningModel(model string) bool {
+	return len(model) > 1 && model[0] == 'o' && model[1] >= '0' && model[1] <= '9'
+}
+
+// doRequest sends a Chat Completions request and returns the parsed response.
+func (o *OpenAIClient) doRequest(ctx context.Context, req openaiRequest) (*openaiResponse, error) {
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := strings.TrimRight(o.BaseURL, "/") + "/chat/completions"
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if o.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+
+	resp, err := o.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errBody := string(body)
+		if len(errBody) > 500 {
+			errBody = errBody[:500] + "..."
+		}
+		return nil, &apiStatusError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("OpenAI API returned status %d: %s", resp.StatusCode, errBody),
+		}
+	}
+
+	var openaiResp openaiResponse
+	if err := json.Unmarshal(body, &openaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(openaiResp.Choices) == 0 {
+		return nil, fmt.Errorf("empty response from API")
+	}
+
+	if openaiResp.Choices[0].FinishReason == "length" {
+		log.Printf("WARNING: OpenAI response truncated (finish_reason=length, %d output tokens, maxTokens=%d)",
+			openaiResp.Usage.CompletionTokens, o.MaxTokens)
+	}
+
+	cached := openaiResp.Usage.PromptTokensDetails.CachedTokens
+	o.reportUsage(ctx, openaiResp.Usage.PromptTokens-cached, openaiResp.Usage.CompletionTokens, 0, cached)
+
+	return &openaiResp, nil
+}
+
+// textOf returns the text content of the first choice.
+func (r *openaiResponse) textOf() string {
+	if r.Choices[0].Message.Content == nil {
+		return ""
+	}
+	return *r.Choices[0].Message.Content
+}
+
+// callAPIOnce makes a single API request to OpenAI.
+func (o *OpenAIClient) callAPIOnce(ctx context.Context, prompt string) (string, error) {
+	resp, err := o.doRequest(ctx, o.newRequest([]openaiMessage{{Role: "user", Content: prompt}}))
+	if err != nil {
+		return "", err
+	}
+	return resp.textOf(), nil
+}
+
+// AnalyzeWithImage sends a multimodal request with an image and text prompt to the OpenAI API.
+func (o *OpenAIClient) AnalyzeWithImage(ctx context.Context, prompt string, imageB64 string) (string, error) {
+	return o.AnalyzeWithImages(ctx, "", prompt, []string{imageB64})
+}
+
+// AnalyzeWithImages sends a multimodal request with multiple images and an optional system prompt.
+func (o *OpenAIClient) AnalyzeWithImages(ctx context.Context, systemPrompt string, prompt string, imagesB64 []string) (string, error) {
+	var parts []openaiContentPart
+	for _, imgB64 := range imagesB64 {
+		parts = append(parts, openaiImagePart(imageSource{Type: "base64", MediaType: "image/webp", Data: imgB64}))
+	}
+	parts = append(parts, openaiContentPart{Type: "text", Text: prompt})
+
+	var messages []openaiMessage
+	if systemPrompt != "" {
+		messages = append(messages, openaiMessage{Role: "system", Content: systemPrompt})
+	}
+	messages = append(messages, openaiMessage{Role: "user", Content: parts})
+
+	resp, err := o.doRequest(ctx, o.newRequest(messages))
+	if err != nil {
+		return "", err
+	}
+	return resp.textOf(), nil
+}
+
+// CallWithTools sends a function-calling request to the OpenAI API, translating the
+// Anthropic-shaped agent history and tool definitions to Chat Completions format
+// and the response back into a ToolUseResponse.
+func (o *OpenAIClient) CallWithTools(ctx context.Context, systemPrompt string, messages []AgentMessage, tools []ToolDefinition) (*ToolUseResponse, error) {
+	var chat []openaiMessage
+	if systemPrompt != "" {
+		chat = append(chat, openaiMessage{Role: "system", Content: systemPrompt})
+	}
+	for _, msg := range messages {
+		converted, err := toOpenAIMessages(msg)
+		if err != nil {
+			return nil, err
+		}
+		chat = append(chat, converted...)
+	}
+
+	req := o.newRequest(chat)
+	for _, t := range tools {
+		req.Tools = append(req.Tools, openaiTool{
+			Type:     "function",
+			Function: openaiToolFunction{Name: t.Name, Description: t.Description, Parameters: t.InputSchema},
+		})
+	}
+
+	log.Printf("CallWithTools[openai]: sending %d messages, %d tools", len(chat), len(tools))
+	start := time.Now()
+	resp, err := o.doRequest(ctx, req)
+	elapsed := time.Since(start)
+	if err != nil {
+		log.Printf("CallWithTools[openai]: failed after %s: %v", elapsed, err)
+		return nil, err
+	}
+
+	choice := resp.Choices[0]
+	toolResp := &ToolUseResponse{
+		StopReason: openaiStopReason(choice.FinishReason, len(choice.Message.ToolCalls) > 0),
+		Model:      o.Model,
+	}
+	if text := resp.textOf(); text != "" {
+		toolResp.Content = append(toolResp.Content, ResponseContentBlock{Type: "text", Text: text})
+	}
+	for _, tc := range choice.Message.ToolCalls {
+		input := json.RawMessage(toolInputJSON(json.RawMessage(tc.Function.Arguments)))
+		if !json.Valid(input) {
+			input = json.RawMessage("{}")
+		}
+		toolResp.Content = append(toolResp.Content, ResponseContentBlock{
+			Type: "tool_use", ID: tc.ID, Name: tc.Function.Name, Input: input,
+		})
+	}
+	cached := resp.Usage.PromptTokensDetails.CachedTokens
+	toolResp.Usage.InputTokens = resp.Usage.PromptTokens - cached
+	toolResp.Usage.OutputTokens = resp.Usage.CompletionTokens
+	toolResp.Usage.CacheReadInputTokens = cached
+
+	log.Printf("CallWithTools[openai]: completed in %s (in=%d out=%d cache_read=%d tokens, stop=%s)",
+		elapsed, toolResp.Usage.InputTokens, toolResp.Usage.OutputTokens, cached, toolResp.StopReason)
+	return toolResp, nil
+}
+
+// openaiStopReason maps a Chat Completions finish_reason onto the Claude stop_reason
+// values the agent loop understands.
+func openaiStopReason(finishReason string, hasToolCalls bool) string {
+	switch {
+	case hasToolCalls || finishReason == "tool_calls":
+		return "tool_use"
+	case finishReason == "length":
+		return "max_tokens"
+	default:
+		return "end_turn"
+	}
+}
+
+// openaiImagePart wraps a base64 image as a data-URL content part.
+func openaiImagePart(src imageSource) openaiContentPart {
+	mediaType := src.MediaType
+	if mediaType == "" {
+		mediaType = "image/jpeg"
+	}
+	return openaiContentPart{
+		Type:     "image_url",
+		ImageURL: &openaiImageURL{URL: fmt.Sprintf("data:%s;base64,%s", mediaType, src.Data)},
+	}
+}
+
+// toOpenAIMessages converts one AgentMessage into one or more Chat Completions messages.
+// Tool results become "tool" role messages; because those can only carry text, any
+// screenshots they contain are forwarded in a trailing user message.
+func toOpenAIMessages(msg AgentMessage) ([]openaiMessage, error) {
+	blocks, err := normalizeAgentContent(msg.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.Role == "assistant" {
+		out := openaiMessage{Role: "assistant"}
+		var text []string
+		for _, b := range blocks {
+			switch b.Type {
+			case "text":
+				if b.Text != "" {
+					text = append(text, b.Text)
+				}
+			case "tool_use":
+				tc := openaiToolCall{ID: b.ID, Type: "function"}
+				tc.Function.Name = b.Name
+				tc.Function.Arguments = toolInputJSON(b.Input)
+				out.ToolCalls = append(out.ToolCalls, tc)
+			}
+		}
+		if len(text) > 0 {
+			out.Content = strings.Join(text, "\n")
+		}
+		return []openaiMessage{out}, nil
+	}
+
+	var out []openaiMessage
+	var parts []openaiContentPart
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			parts = append(parts, openaiContentPart{Type: "text", Text: b.Text})
+		case "image":
+			if b.Source != nil {
+				parts = append(parts, openaiImagePart(*b.Source))
+			}
+		case "tool_result":
+			text, images := b.toolResultParts()
+			if b.IsError && !strings.HasPrefix(text, "Error") {
+				text = "Error: " + text
+			}
+			if text == "" {
+				text = "(no output)"
+			}
+			out = append(out, openaiMessage{Role: "tool", ToolCallID: b.ToolUseID, Content: text})
+			for _, img := range images {
+				parts = append(parts, openaiContentPart{Type: "text", Text: "Screenshot returned by the previous tool call:"})
+				parts = append(parts, openaiImagePart(img))
+			}
+		}
+	}
+	if len(parts) > 0 {
+		out = append(out, openaiMessage{Role: "user", Content: parts})
+	}
+	return out, nil
+}
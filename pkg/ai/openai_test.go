@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestToOpenAIMessagesToolResultWithScreenshot(t *testing.T) {
+	msg := AgentMessage{Role: "user", Content: []interface{}{
+		ToolResultBlock{
+			Type:      "tool_result",
+			ToolUseID: "toolu_1",
+			Content: []interface{}{
+				map[string]interface{}{"type": "text", "text": "Clicked at (10, 20)."},
+				map[string]interface{}{"type": "image", "source": map[string]interface{}{
+					"type": "base64", "media_type": "image/jpeg", "data": "AAAA",
+				}},
+			},
+		},
+	}}
+
+	out, err := toOpenAIMessages(msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected tool + user message, got %d", len(out))
+	}
+	if out[0].Role != "tool" || out[0].ToolCallID != "toolu_1" || out[0].Content != "Clicked at (10, 20)." {
+		t.Errorf("tool message = %+v", out[0])
+	}
+	parts, ok := out[1].Content.([]openaiContentPart)
+	if !ok || len(parts) != 2 || parts[1].ImageURL == nil || parts[1].ImageURL.URL != "data:image/jpeg;base64,AAAA" {
+		t.Errorf("user message parts = %+v", out[1].Content)
+	}
+}
+
+func TestToOpenAIMessagesAssistantToolUse(t *testing.T) {
+	msg := AgentMessage{Role: "assistant", Content: []ResponseContentBlock{
+		{Type: "text", Text: "Clicking play"},
+		{Type: "tool_use", ID: "call_1", Name: "click", Input: json.RawMessage(`{"x":1,"y":2}`)},
+		{Type: "tool_use", ID: "call_2", Name: "screenshot"},
+	}}
+
+	out, err := toOpenAIMessages(msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out) != 1 || out[0].Content != "Clicking play" || len(out[0].ToolCalls) != 2 {
+		t.Fatalf("unexpected assistant message: %+v", out)
+	}
+	if out[0].ToolCalls[1].Function.Arguments != "{}" {
+		t.Errorf("empty input should become {}, got %q", out[0].ToolCalls[1].Function.Arguments)
+	}
+}
+
+func TestOpenAICallWithTools(t *testing.T) {
+	var got openaiRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"choices":[{"message":{"content":null,"tool_calls":[
+			{"id":"call_9","type":"function","function":{"name":"click","arguments":"{\"x\":5,\"y\":6}"}}]},
+			"finish_reason":"tool_calls"}],
+			"usage":{"prompt_tokens":100,"completion_tokens":20,"prompt_tokens_details":{"cached_tokens":40}}}`))
+	}))
+	defer srv.Close()
+
+	c := NewOpenAIClient("", "local-model", 0, 256)
+	c.BaseURL = srv.URL + "/v1"
+	resp, err := c.CallWithTools(context.Background(), "system", []AgentMessage{{Role: "user", Content: "go"}},
+		BrowserTools(800, 600))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.MaxTokens != 256 || got.MaxCompletionTokens != 0 {
+		t.Errorf("self-hosted endpoint should use max_tokens, got %+v", got)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" || len(got.Tools) == 0 {
+		t.Errorf("unexpected request: %d messages, %d tools", len(got.Messages), len(got.Tools))
+	}
+	if resp.StopReason != "tool_use" || len(resp.Content) != 1 || resp.Content[0].Name != "click" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if resp.Usage.InputTokens != 60 || resp.Usage.CacheReadInputTokens != 40 || resp.Usage.OutputTokens != 20 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestOpenAIRequestTemperature(t *testing.T) {
+	for _, tc := range []struct {
+		model string
+		want  string
+	}{
+		{"gpt-4.1", `"temperature":0,`}, // a configured 0 is sent, not dropped
+		{"o3", ""},
+		{"o4-mini", ""},
+	} {
+		o := NewOpenAIClient("key", tc.model, 0, 1024)
+		data, err := json.Marshal(o.newRequest(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := strings.Contains(string(data), `"temperature"`)
+		if got != (tc.want != "") || (tc.want != "" && !strings.Contains(string(data), tc.want)) {
+			t.Errorf("%s: request %s, want temperature %q", tc.model, data, tc.want)
+		}
+	}
+}
+
+func TestAPIKeyRequired(t *testing.T) {
+	if !APIKeyRequired("openai", "") {
+		t.Error("the public OpenAI API needs a key")
+	}
+	if APIKeyRequired("openai", "http://localhost:8000/v1") {
+		t.Error("a self-hosted OpenAI-compatible server may run without a key")
+	}
+	if !APIKeyRequired("anthropic", "http://localhost:8000/v1") {
+		t.Error("a base URL only exempts the openai provider")
+	}
+	if APIKeyEnvVar("gemini") != "GEMINI_API_KEY" || APIKeyEnvVar("mistral") != "" {
+		t.Errorf("unexpected key variables %q, %q", APIKeyEnvVar("gemini"), APIKeyEnvVar("mistral"))
+	}
+}
@@ -0,0 +1,252 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+// Limits for the measure_performance window.
+const (
+	defaultPerfWindowMs = 3000
+	minPerfWindowMs     = 500
+	maxPerfWindowMs     = 10000
+)
+
+// PerformanceThresholds are the limits a game should stay within on a class of
+// device. Zero disables a check.
+type PerformanceThresholds struct {
+	MinAvgFPS         float64 `json:"minAvgFps"`
+	MinFPS            float64 `json:"minFps"` // worst one-second window
+	MaxP95FrameMs     float64 `json:"maxP95FrameMs"`
+	MaxLongestTaskMs  float64 `json:"maxLongestTaskMs"`
+	MaxHeapMB         float64 `json:"maxHeapMb"`
+	MaxDrawCallsFrame float64 `json:"maxDrawCallsPerFrame"`
+}
+
+var (
+	desktopThresholds = PerformanceThresholds{MinAvgFPS: 50, MinFPS: 30, MaxP95FrameMs: 33, MaxLongestTaskMs: 250, MaxHeapMB: 1024, MaxDrawCallsFrame: 1000}
+	phoneThresholds   = PerformanceThresholds{MinAvgFPS: 30, MinFPS: 20, MaxP95FrameMs: 50, MaxLongestTaskMs: 200, MaxHeapMB: 256, MaxDrawCallsFrame: 200}
+	tabletThresholds  = PerformanceThresholds{MinAvgFPS: 30, MinFPS: 20, MaxP95FrameMs: 50, MaxLongestTaskMs: 200, MaxHeapMB: 384, MaxDrawCallsFrame: 400}
+)
+
+// tabletMinSide is the shorter viewport side, in CSS pixels, from which a
+// touch viewport gets the tablet limits rather than the phone ones.
+const tabletMinSide = 600
+
+// ThresholdsForViewport returns the performance limits for a viewport: desktop
+// limits for mouse viewports, tablet or phone limits for touch ones (see
+// scout.ViewportPreset.IsTouch), told apart by the shorter side so custom
+// presets and both orientations are classed like the built-in ones. Phones get
+// the tightest heap and draw-call budgets: low-end devices are where games run
+// out of memory and GPU time first.
+func ThresholdsForViewport(vp scout.ViewportPreset) PerformanceThresholds {
+	if !vp.IsTouch() {
+		return desktopThresholds
+	}
+	if min(vp.Width, vp.Height) >= tabletMinSide {
+		return tabletThresholds
+	}
+	return phoneThresholds
+}
+
+// PerformanceMeasurement is one measure_performance call during exploration.
+type PerformanceMeasurement struct {
+	Label  string                  `json:"label,omitempty"` // what was on screen, e.g. "spin animation"
+	Sample scout.PerformanceSample `json:"sample"`
+}
+
+// PerformanceIssue is a threshold exceeded by the session or a measurement.
+type PerformanceIssue struct {
+	Metric    string  `json:"metric"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Severity  string  `json:"severity"` // major (off by more than half the limit) or minor
+	Where     string  `json:"where"`    // "session" or the measurement label
+	Message   string  `json:"message"`
+}
+
+// PerformanceReport is the performance section of an analysis. In the headless
+// browser WebGL runs on SwiftShader (CPU), so frame rates say little about real
+// GPUs: they are reported but not checked against the limits, and
+// SoftwareRendering is set. Long tasks, heap and draw calls are still checked.
+type PerformanceReport struct {
+	DeviceCategory    string                   `json:"deviceCategory"`
+	Thresholds        PerformanceThresholds    `json:"thresholds"`
+	SoftwareRendering bool                     `json:"softwareRendering,omitempty"` // frame rates not judged
+	Session           *scout.PerformanceSample `json:"session,omitempty"`
+	Measurements      []PerformanceMeasurement `json:"measurements,omitempty"`
+	Issues            []PerformanceIssue       `json:"issues,omitempty"`
+	Status            string                   `json:"status"` // pass, warn or fail
+}
+
+// EvaluatePerformance checks the session and every measurement against the
+// viewport's thresholds. Each metric is reported once, at its worst value.
+// Frame rates of software-rendered samples are not checked. It returns nil
+// when nothing was sampled.
+func EvaluatePerformance(vp scout.ViewportPreset, session *scout.PerformanceSample, measurements []PerformanceMeasurement) *PerformanceReport {
+	if session == nil && len(measurements) == 0 {
+		return nil
+	}
+	category := vp.Category
+	if category == "" {
+		category = "Desktop"
+	}
+	r := &PerformanceReport{
+		DeviceCategory: category,
+		Thresholds:     ThresholdsForViewport(vp),
+		Session:        session,
+		Measurements:   measurements,
+		Status:         "pass",
+	}
+
+	worst := map[string]*PerformanceIssue{}
+	var order []string
+	check := func(where, metric string, value, limit float64, below bool, format string) {
+		if limit <= 0 {
+			return
+		}
+		over := value - limit
+		if below {
+			over = limit - value
+		}
+		if over <= 0 {
+			return
+		}
+		severity := "minor"
+		if over > limit/2 {
+			severity = "major"
+		}
+		prev, seen := worst[metric]
+		if seen && ((below && value >= prev.Value) || (!below && value <= prev.Value)) {
+			return
+		}
+		if !seen {
+			order = append(order, metric)
+		}
+		worst[metric] = &PerformanceIssue{
+			Metric: metric, Value: value, Threshold: limit, Severity: severity, Where: where,
+			Message: fmt.Sprintf(format, value, limit) + " (" + where + ")",
+		}
+	}
+	evaluate := func(where string, s *scout.PerformanceSample, windowed bool) {
+		t := r.Thresholds
+		if s.SoftwareRendering {
+			r.SoftwareRendering = true
+		} else if s.Frames > 0 {
+			check(where, "avgFps", s.AvgFPS, t.MinAvgFPS, true, "Average frame rate %.0f fps is below %.0f fps")
+			check(where, "minFps", s.MinFPS, t.MinFPS, true, "Frame rate dropped to %.0f fps, below %.0f fps")
+			if windowed {
+				check(where, "p95FrameMs", s.P95FrameMs, t.MaxP95FrameMs, false, "95th percentile frame time %.0fms exceeds %.0fms")
+			}
+		}
+		check(where, "longestTaskMs", s.LongestTaskMs, t.MaxLongestTaskMs, false, "Main thread blocked for %.0fms, over %.0fms")
+		check(where, "heapMb", s.HeapUsedMB, t.MaxHeapMB, false, "JS heap %.0fMB exceeds %.0fMB")
+		check(where, "drawCallsPerFrame", s.DrawCallsPerFrame, t.MaxDrawCallsFrame, false, "%.0f WebGL draw calls per frame, over %.0f")
+	}
+	if session != nil {
+		evaluate("session", session, false)
+	}
+	for i, m := range measurements {
+		where := m.Label
+		if where == "" {
+			where = fmt.Sprintf("measurement %d", i+1)
+		}
+		sample := m.Sample
+		evaluate(where, &sample, true)
+	}
+
+	for _, metric := range order {
+		issue := worst[metric]
+		r.Issues = append(r.Issues, *issue)
+		if issue.Severity == "major" {
+			r.Status = "fail"
+		} else if r.Status == "pass" {
+			r.Status = "warn"
+		}
+	}
+	return r
+}
+
+// measurePerformanceTool is the measure_performance tool definition, part of BrowserTools.
+var measurePerformanceTool = ToolDefinition{
+	Name:        "measure_performance",
+	Description: fmt.Sprintf("Measure rendering performance over the next duration_ms: frame rate (average and worst second), frame times, long main-thread tasks, JS heap size and WebGL draw calls per frame. Start an animation first (e.g. click spin), then call this while it plays; the results are checked against the limits for this device. Default %dms, max %dms.", defaultPerfWindowMs, maxPerfWindowMs),
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"duration_ms": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("How long to measure, in milliseconds (%d-%d)", minPerfWindowMs, maxPerfWindowMs),
+			},
+			"label": map[string]interface{}{
+				"type":        "string",
+				"description": "What is on screen while measuring, e.g. \"spin animation\" or \"bonus intro\"",
+			},
+		},
+		"required": []string{},
+	},
+}
+
+// formatPerformanceSample renders a sample and its threshold breaches for the model.
+func formatPerformanceSample(s *scout.PerformanceSample, vp scout.ViewportPreset) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Measured %.1fs: %d frames, %.1f fps average, %.0f fps worst second", s.DurationMs/1000, s.Frames, s.AvgFPS, s.MinFPS)
+	if s.P95FrameMs > 0 {
+		fmt.Fprintf(&sb, ", frame time p95 %.0fms / max %.0fms", s.P95FrameMs, s.MaxFrameMs)
+	}
+	fmt.Fprintf(&sb, ", %d jank frames (>50ms).", s.JankFrames)
+	fmt.Fprintf(&sb, "\nLong tasks: %d (%.0fms total, longest %.0fms).", s.LongTasks, s.LongTaskMs, s.LongestTaskMs)
+	if s.HeapUsedMB > 0 {
+		fmt.Fprintf(&sb, "\nJS heap: %.1fMB used of %.1fMB.", s.HeapUsedMB, s.HeapTotalMB)
+	}
+	if s.DrawCallsPerFrame > 0 {
+		fmt.Fprintf(&sb, "\nWebGL draw calls: %.0f per frame (max %d).", s.DrawCallsPerFrame, s.MaxDrawCalls)
+	} else {
+		sb.WriteString("\nWebGL draw calls: none recorded (canvas 2D, DOM or idle).")
+	}
+	if s.Frames == 0 {
+		sb.WriteString("\nNo frames were rendered: the page may be frozen or hidden.")
+	}
+
+	report := EvaluatePerformance(vp, nil, []PerformanceMeasurement{{Sample: *s, Label: "this measurement"}})
+	if report.SoftwareRendering {
+		sb.WriteString("\nWebGL is software-rendered (SwiftShader) here, so frame rates are not checked against the limits; do not report low FPS as a defect.")
+	}
+	if len(report.Issues) == 0 {
+		fmt.Fprintf(&sb, "\nWithin the %s limits.", report.DeviceCategory)
+	} else {
+		fmt.Fprintf(&sb, "\nOver the %s limits:", report.DeviceCategory)
+		for _, issue := range report.Issues {
+			fmt.Fprintf(&sb, "\n- [%s] %s", issue.Severity, strings.TrimSuffix(issue.Message, " (this measurement)"))
+		}
+	}
+	return sb.String()
+}
+
+// joinSections joins the non-empty prompt sections with blank lines.
+func joinSections(sections ...string) string {
+	var parts []string
+	for _, s := range sections {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// performanceSection summarises the performance report for the synthesis
+// prompt. It returns "" when there is nothing to report.
+func performanceSection(r *PerformanceReport) string {
+	if r == nil || len(r.Issues) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "PERFORMANCE ISSUES (measured automatically on the %s viewport):\n", r.DeviceCategory)
+	for _, issue := range r.Issues {
+		fmt.Fprintf(&sb, "- [%s] %s\n", issue.Severity, issue.Message)
+	}
+	sb.WriteString("These are reported in the performance section. Mention them in uiuxAnalysis (category animation) only where they visibly affect play, e.g. stuttering reels or frozen transitions.")
+	return sb.String()
+}
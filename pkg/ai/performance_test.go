@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+func TestEvaluatePerformance(t *testing.T) {
+	session := &scout.PerformanceSample{Frames: 1800, AvgFPS: 45, MinFPS: 12, LongestTaskMs: 180, HeapUsedMB: 300}
+	spin := PerformanceMeasurement{Label: "spin", Sample: scout.PerformanceSample{Frames: 60, AvgFPS: 20, MinFPS: 18, P95FrameMs: 70, LongestTaskMs: 90, DrawCallsPerFrame: 350}}
+
+	iphone := *scout.GetViewportByName("iphone-16-pro")
+	r := EvaluatePerformance(iphone, session, []PerformanceMeasurement{spin})
+	if r.Thresholds != phoneThresholds || r.Status != "fail" {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	got := map[string]PerformanceIssue{}
+	for _, issue := range r.Issues {
+		got[issue.Metric] = issue
+	}
+	if issue := got["avgFps"]; issue.Value != 20 || issue.Where != "spin" || issue.Severity != "minor" {
+		t.Errorf("avgFps should be reported at its worst, got %+v", issue)
+	}
+	if issue := got["minFps"]; issue.Value != 12 || issue.Where != "session" || issue.Severity != "minor" {
+		t.Errorf("unexpected minFps issue %+v", issue)
+	}
+	if issue := got["p95FrameMs"]; issue.Severity != "minor" {
+		t.Errorf("unexpected p95 issue %+v", issue)
+	}
+	if _, ok := got["heapMb"]; !ok {
+		t.Error("300MB heap should exceed the phone limit")
+	}
+	if issue := got["drawCallsPerFrame"]; issue.Severity != "major" {
+		t.Errorf("350 draw calls are over the phone limit by more than half, got %+v", issue)
+	}
+	if _, ok := got["longestTaskMs"]; ok {
+		t.Error("180ms long tasks are within the phone limit")
+	}
+
+	if r := EvaluatePerformance(*scout.GetViewportByName("desktop-std"), session, nil); r.Status != "fail" {
+		t.Errorf("desktop status = %s", r.Status)
+	}
+	if r := EvaluatePerformance(scout.ViewportPreset{}, &scout.PerformanceSample{Frames: 600, AvgFPS: 60, MinFPS: 58}, nil); r.Status != "pass" || r.DeviceCategory != "Desktop" || len(r.Issues) != 0 {
+		t.Errorf("expected a passing desktop report, got %+v", r)
+	}
+	if EvaluatePerformance(iphone, nil, nil) != nil {
+		t.Error("expected no report without samples")
+	}
+}
+
+func TestMeasurePerformanceTool(t *testing.T) {
+	page := &fakePage{perf: &scout.PerformanceSample{Frames: 90, AvgFPS: 30, MinFPS: 24, P95FrameMs: 45, MaxFrameMs: 80, JankFrames: 2, HeapUsedMB: 120, HeapTotalMB: 160, DrawCallsPerFrame: 320, MaxDrawCalls: 400}}
+	exec := &BrowserToolExecutor{Page: page, Viewport: *scout.GetViewportByName("samsung-s24")}
+
+	text, _, err := exec.Execute("measure_performance", json.RawMessage(`{"duration_ms": 60000, "label": "big win"}`))
+	if err != nil {
+		t.Fatalf("measure_performance: %v", err)
+	}
+	if page.calls[0] != "measure 10s" {
+		t.Errorf("window should be capped at 10s, got %v", page.calls)
+	}
+	if !strings.HasPrefix(text, "Measured 10.0s: 90 frames, 30.0 fps average, 24 fps worst second") ||
+		!strings.Contains(text, "Over the Android limits:\n- [major] 320 WebGL draw calls per frame, over 200") {
+		t.Errorf("unexpected result:\n%s", text)
+	}
+	if len(exec.perf) != 1 || exec.perf[0].Label != "big win" {
+		t.Errorf("measurement should be kept for the report, got %+v", exec.perf)
+	}
+
+	section := performanceSection(EvaluatePerformance(exec.Viewport, nil, exec.perf))
+	if !strings.HasPrefix(section, "PERFORMANCE ISSUES (measured automatically on the Android viewport):\n- [major] 320 WebGL draw calls per frame, over 200 (big win)") {
+		t.Errorf("unexpected section:\n%s", section)
+	}
+
+	if _, _, err := (&BrowserToolExecutor{Page: &fakePage{}}).Execute("measure_performance", nil); err == nil {
+		t.Error("expected an error without a sampler")
+	}
+
+	// A paused clock renders no frames, so measuring resumes it first
+	page = &fakePage{perf: page.perf, paused: true}
+	exec = &BrowserToolExecutor{Page: page, Viewport: exec.Viewport}
+	text, _, err = exec.Execute("measure_performance", nil)
+	if err != nil || page.paused || page.calls[0] != "paused false" || !strings.HasPrefix(text, "Game time was paused; resumed it to measure.\n") {
+		t.Errorf("paused clock should be resumed before measuring, got %q, %q, %v", page.calls, text, err)
+	}
+}
+
+func TestThresholdsForViewport(t *testing.T) {
+	for _, tc := range []struct {
+		vp   scout.ViewportPreset
+		want PerformanceThresholds
+	}{
+		{*scout.GetViewportByName("desktop-std"), desktopThresholds},
+		{*scout.GetViewportByName("iphone-16-pro-landscape"), phoneThresholds},
+		{*scout.GetViewportByName("ipad-air"), tabletThresholds},
+		{scout.ViewportPreset{Name: "steam-deck", Category: "Handheld", Width: 1280, Height: 800, Touch: true}, tabletThresholds},
+		{scout.ViewportPreset{Name: "kiosk", Category: "Custom", Width: 360, Height: 640, Touch: true}, phoneThresholds},
+	} {
+		if got := ThresholdsForViewport(tc.vp); got != tc.want {
+			t.Errorf("%s: thresholds = %+v, want %+v", tc.vp.Name, got, tc.want)
+		}
+	}
+}
+
+func TestSoftwareRenderedFrameRatesNotJudged(t *testing.T) {
+	slow := scout.PerformanceSample{Frames: 60, AvgFPS: 6, MinFPS: 3, P95FrameMs: 400, LongestTaskMs: 900, SoftwareRendering: true}
+	r := EvaluatePerformance(*scout.GetViewportByName("desktop-std"), &slow, []PerformanceMeasurement{{Label: "spin", Sample: slow}})
+	if !r.SoftwareRendering {
+		t.Error("report should be marked software-rendered")
+	}
+	if len(r.Issues) != 1 || r.Issues[0].Metric != "longestTaskMs" {
+		t.Errorf("only the long task should be reported, got %+v", r.Issues)
+	}
+	text := formatPerformanceSample(&slow, scout.ViewportPreset{})
+	if !strings.Contains(text, "6.0 fps average") || !strings.Contains(text, "frame rates are not checked") {
+		t.Errorf("unexpected result:\n%s", text)
+	}
+}
@@ -0,0 +1,188 @@
+package ai
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PricingEnvVar names the environment variable that points at a pricing file. It
+// takes precedence over the ai.pricingFile config setting.
+const PricingEnvVar = "WIZARDS_QA_PRICING"
+
+// pricingDateLayout is the format of PriceVersion.Effective.
+const pricingDateLayout = "2006-01-02"
+
+// PricingFile is the on-disk pricing table (YAML or JSON).
+//
+//	models:
+//	  - id: claude-sonnet-4-5-20250929
+//	    aliases: [claude-sonnet-4-5]
+//	    versions:
+//	      - effective: 2025-09-29
+//	        inputPerMTok: 3
+//	        outputPerMTok: 15
+type PricingFile struct {
+	Models []PricedModel `yaml:"models" json:"models"`
+}
+
+// PricedModel is a model's price history and the other names it is known by.
+type PricedModel struct {
+	ID       string         `yaml:"id" json:"id"`
+	Aliases  []string       `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	Versions []PriceVersion `yaml:"versions" json:"versions"`
+}
+
+// PriceVersion is a model's pricing from its effective date (YYYY-MM-DD) onwards.
+// An empty date applies from the beginning of time.
+type PriceVersion struct {
+	Effective    string `yaml:"effective,omitempty" json:"effective,omitempty"`
+	ModelPricing `yaml:",inline"`
+
+	from time.Time
+}
+
+var (
+	pricingMu    sync.RWMutex
+	pricedModels = builtinPricing()
+)
+
+// builtinPricing turns ModelPricingTable into single-version entries.
+func builtinPricing() map[string]*PricedModel {
+	models := make(map[string]*PricedModel, len(ModelPricingTable))
+	for id, p := range ModelPricingTable {
+		models[id] = &PricedModel{ID: id, Versions: []PriceVersion{{ModelPricing: p}}}
+	}
+	return models
+}
+
+// LoadPricingFile reads a YAML or JSON pricing table and merges it over the built-in
+// prices. A model listed in the file replaces the built-in entry with the same ID.
+func LoadPricingFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading pricing file: %w", err)
+	}
+	// JSON is valid YAML, so one decoder handles both formats
+	var file PricingFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("parsing pricing file %s: %w", path, err)
+	}
+
+	loaded := make(map[string]*PricedModel, len(file.Models))
+	for i := range file.Models {
+		m := file.Models[i]
+		if m.ID == "" {
+			return fmt.Errorf("pricing file %s: models[%d].id is required", path, i)
+		}
+		if len(m.Versions) == 0 {
+			return fmt.Errorf("pricing file %s: model %s has no price versions", path, m.ID)
+		}
+		for j := range m.Versions {
+			if m.Versions[j].Effective == "" {
+				continue
+			}
+			from, err := time.Parse(pricingDateLayout, m.Versions[j].Effective)
+			if err != nil {
+				return fmt.Errorf("pricing file %s: model %s: invalid effective date %q (want YYYY-MM-DD)", path, m.ID, m.Versions[j].Effective)
+			}
+			m.Versions[j].from = from
+		}
+		sort.Slice(m.Versions, func(a, b int) bool { return m.Versions[a].from.Before(m.Versions[b].from) })
+		loaded[m.ID] = &m
+	}
+
+	pricingMu.Lock()
+	defer pricingMu.Unlock()
+	merged := builtinPricing()
+	for id, m := range loaded {
+		merged[id] = m
+	}
+	pricedModels = merged
+	return nil
+}
+
+// LookupPricing returns the prices for model that were in effect at the given time.
+// Names match exactly (ID or alias) first, then by prefix, so "claude-sonnet-4-5"
+// resolves to the dated ID and "gemini-2.5-flash-001" to "gemini-2.5-flash".
+func LookupPricing(model string, at time.Time) (ModelPricing, bool) {
+	if model == "" {
+		return ModelPricing{}, false
+	}
+	pricingMu.RLock()
+	defer pricingMu.RUnlock()
+
+	m := matchPricedModel(model)
+	if m == nil {
+		return ModelPricing{}, false
+	}
+	// Versions are sorted by date: take the latest that had started by then, or the
+	// earliest known one for usage older than the whole history.
+	v := m.Versions[0]
+	for _, cand := range m.Versions[1:] {
+		if cand.from.After(at) {
+			break
+		}
+		v = cand
+	}
+	return v.ModelPricing, true
+}
+
+// IsPriced reports whether model has known pricing, so callers can warn instead
+// of silently reporting $0.
+func IsPriced(model string) bool {
+	_, ok := LookupPricing(model, time.Now())
+	return ok
+}
+
+// matchPricedModel finds the entry for model. Callers must hold pricingMu.
+func matchPricedModel(model string) *PricedModel {
+	var best *PricedModel
+	bestName := ""
+	for _, id := range sortedPricingIDs() {
+		m := pricedModels[id]
+		for _, name := range append([]string{m.ID}, m.Aliases...) {
+			if name == model {
+				return m
+			}
+			// Prefix match on versioned names: an undated alias picks up the dated ID,
+			// and a versioned model ("-001", "-2024-08-06") its base entry. The longest
+			// name wins; among dated IDs sharing a prefix the latest sorts last.
+			if isVersionOf(model, name) || isVersionOf(name, model) {
+				if len(name) >= len(bestName) {
+					best, bestName = m, name
+				}
+			}
+		}
+	}
+	return best
+}
+
+// isVersionOf reports whether long is short plus a version suffix such as "-001",
+// "-20250929" or "-2024-08-06", as opposed to a different model like "-lite" or "-6".
+func isVersionOf(long, short string) bool {
+	suffix, ok := strings.CutPrefix(long, short+"-")
+	if !ok {
+		return false
+	}
+	digits := 0
+	for digits < len(suffix) && suffix[digits] >= '0' && suffix[digits] <= '9' {
+		digits++
+	}
+	return digits >= 3
+}
+
+// sortedPricingIDs returns the model IDs in a stable order. Callers must hold pricingMu.
+func sortedPricingIDs() []string {
+	ids := make([]string, 0, len(pricedModels))
+	for id := range pricedModels {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,111 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLookupPricingMatchesAliasesAndPrefixes(t *testing.T) {
+	cases := []struct {
+		model string
+		want  float64 // input price per MTok; 0 = unpriced
+	}{
+		{"claude-sonnet-4-5-20250929", 3.0},
+		{"claude-sonnet-4-5", 3.0},     // undated alias -> dated ID
+		{"gemini-2.5-flash-001", 0.30}, // versioned name -> base entry
+		{"gpt-4o-2024-08-06", 2.50},    // dated snapshot -> base entry
+		{"gemini-2.5-flash-lite", 0},   // a different model, not a version
+		{"claude-opus-4", 0},           // "claude-opus-4-6" is a later generation, not a snapshot
+		{"gpt-4.1", 2.0},               // the OpenAI client's default
+		{"gpt-4.1-2025-04-14", 2.0},    // dated snapshot -> base entry
+		{"gpt-4.1-mini", 0.40},         // its own entry, not a version of gpt-4.1
+		{"o4-mini", 1.10},
+		{"some-local-model", 0},
+	}
+	for _, c := range cases {
+		p, ok := LookupPricing(c.model, time.Now())
+		if ok != (c.want > 0) || p.InputPerMTok != c.want {
+			t.Errorf("LookupPricing(%q) = %+v, %v; want input %v", c.model, p, ok, c.want)
+		}
+	}
+}
+
+func TestLoadPricingFileVersions(t *testing.T) {
+	t.Cleanup(func() { pricedModels = builtinPricing() })
+
+	path := filepath.Join(t.TempDir(), "pricing.yaml")
+	file := `models:
+  - id: acme-large-20260101
+    aliases: [acme-large]
+    versions:
+      - effective: 2026-06-01
+        inputPerMTok: 2
+        outputPerMTok: 8
+      - effective: 2026-01-01
+        inputPerMTok: 4
+        outputPerMTok: 16
+`
+	if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadPricingFile(path); err != nil {
+		t.Fatalf("LoadPricingFile: %v", err)
+	}
+
+	march := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	if p, _ := LookupPricing("acme-large", march); p.InputPerMTok != 4 {
+		t.Errorf("March price = %v, want the January version", p.InputPerMTok)
+	}
+	if p, _ := LookupPricing("acme-large", july); p.InputPerMTok != 2 {
+		t.Errorf("July price = %v, want the June version", p.InputPerMTok)
+	}
+
+	var u TokenUsage
+	u.AddForModel("acme-large", 1_000_000, 0, 0, 0)
+	if cost := u.EstimatedCostAt("", march); cost != 4 {
+		t.Errorf("EstimatedCostAt(March) = %v, want 4", cost)
+	}
+
+	// An analysis started in March is still priced at the January version
+	a := &Analyzer{Usage: u, StartedAt: march}
+	a.Usage.APICallCount = 1
+	var estimate string
+	a.emitCostEstimate(func(step, message string) {
+		if step == "cost_estimate" {
+			estimate = message
+		}
+	})
+	if !strings.Contains(estimate, `"costUsd":4`) {
+		t.Errorf("cost estimate should use the prices at the start of the analysis, got %s", estimate)
+	}
+	if !IsPriced("claude-sonnet-4-5") {
+		t.Error("built-in prices must survive loading a pricing file")
+	}
+}
+
+func TestLoadPricingFileJSON(t *testing.T) {
+	t.Cleanup(func() { pricedModels = builtinPricing() })
+
+	path := filepath.Join(t.TempDir(), "pricing.json")
+	file := `{"models": [{"id": "local-llm", "versions": [{"inputPerMTok": 0.5, "outputPerMTok": 1}]}]}`
+	if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadPricingFile(path); err != nil {
+		t.Fatalf("LoadPricingFile: %v", err)
+	}
+	if p, ok := LookupPricing("local-llm", time.Now()); !ok || p.OutputPerMTok != 1 {
+		t.Errorf("LookupPricing(local-llm) = %+v, %v", p, ok)
+	}
+}
+
+func TestUnpricedModels(t *testing.T) {
+	got := unpricedModels("claude-sonnet-4-5-20250929", "", "my-model", "my-model")
+	if len(got) != 1 || got[0] != "my-model" {
+		t.Errorf("unpricedModels = %v, want [my-model]", got)
+	}
+}
@@ -0,0 +1,237 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// StructuredOutputAgent is an optional interface for AI clients that can constrain a
+// response to a JSON schema natively (a forced tool call on Claude, a response schema
+// on Gemini) instead of relying on the prompt to describe the format. The returned
+// string is the JSON document; it may be incomplete if the response hit max_tokens.
+type StructuredOutputAgent interface {
+	GenerateStructured(ctx context.Context, systemPrompt string, messages []AgentMessage, schema OutputSchema) (string, error)
+}
+
+// OutputSchema names and describes a JSON schema for structured output. Name doubles
+// as the forced tool name on Claude, so it must be a valid tool name.
+type OutputSchema struct {
+	Name        string
+	Description string
+	Schema      map[string]interface{}
+}
+
+// SynthesisSchema returns the structured-output schema for agent synthesis: the
+// ComprehensiveAnalysisResult fields, with the optional sections of disabled modules
+// left out and those of enabled modules required.
+func SynthesisSchema(modules AnalysisModules) OutputSchema {
+	schema := jsonSchemaFor(reflect.TypeOf(ComprehensiveAnalysisResult{}))
+	props := schema["properties"].(map[string]interface{})
+	delete(props, "budgetExhausted") // set by the agent loop, not the model
+	delete(props, "performance")     // measured, not written by the model
+
+	required, _ := schema["required"].([]string)
+	sections := []struct {
+		field   string
+		enabled bool
+	}{
+		{"uiuxAnalysis", modules.UIUX},
+		{"wordingCheck", modules.Wording},
+		{"gameDesign", modules.GameDesign},
+		{"gliCompliance", modules.GLI && len(modules.GLIJurisdictions) > 0},
+		{"navigationMap", modules.NavigationMap},
+	}
+	for _, s := range sections {
+		if s.enabled {
+			required = append(required, s.field)
+		} else {
+			delete(props, s.field)
+		}
+	}
+	schema["required"] = required
+
+	return OutputSchema{
+		Name:        "submit_analysis",
+		Description: "Submit the complete QA analysis of the explored game.",
+		Schema:      schema,
+	}
+}
+
+// jsonSchemaFor builds a JSON schema for a Go type from its json tags. Fields without
+// omitempty are required, and string fields may list allowed values in an
+// `enum:"a|b|c"` tag.
+func jsonSchemaFor(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		props := map[string]interface{}{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			prop := jsonSchemaFor(f.Type)
+			if enum := f.Tag.Get("enum"); enum != "" {
+				prop["enum"] = strings.Split(enum, "|")
+			}
+			props[name] = prop
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		return map[string]interface{}{"type": "object", "properties": props, "required": required}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": jsonSchemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchemaFor(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// maxSchemaErrors caps how many validation problems are reported back to the model.
+const maxSchemaErrors = 20
+
+// validateJSONSchema checks a decoded JSON document (as produced by json.Unmarshal into
+// interface{}) against a schema built by jsonSchemaFor and returns one message per
+// problem, e.g. `mechanics[2].priority: "urgent" must be one of high, medium, low`.
+func validateJSONSchema(doc interface{}, schema map[string]interface{}) []string {
+	var problems []string
+	validateSchemaNode(doc, schema, "", &problems)
+	return problems
+}
+
+func validateSchemaNode(v interface{}, schema map[string]interface{}, path string, problems *[]string) {
+	report := func(format string, args ...interface{}) {
+		if len(*problems) < maxSchemaErrors {
+			at := path
+			if at == "" {
+				at = "(root)"
+			}
+			*problems = append(*problems, at+": "+fmt.Sprintf(format, args...))
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			report("expected an object, got %s", jsonTypeName(v))
+			return
+		}
+		required, _ := schema["required"].([]string)
+		isRequired := map[string]bool{}
+		for _, name := range required {
+			isRequired[name] = true
+			if _, ok := obj[name]; !ok {
+				report("missing required field %q", name)
+			}
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		extra, _ := schema["additionalProperties"].(map[string]interface{})
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := extra
+			if p, ok := props[k].(map[string]interface{}); ok {
+				child = p
+			}
+			if obj[k] == nil && !isRequired[k] {
+				continue // null is as good as absent for optional fields
+			}
+			if child != nil {
+				validateSchemaNode(obj[k], child, joinSchemaPath(path, k), problems)
+			}
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			report("expected an array, got %s", jsonTypeName(v))
+			return
+		}
+		items, _ := schema["items"].(map[string]interface{})
+		for i, item := range arr {
+			validateSchemaNode(item, items, fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			report("expected a string, got %s", jsonTypeName(v))
+			return
+		}
+		if enum, ok := schema["enum"].([]string); ok {
+			for _, allowed := range enum {
+				if s == allowed {
+					return
+				}
+			}
+			report("%q must be one of %s", s, strings.Join(enum, ", "))
+		}
+	case "integer":
+		if f, ok := v.(float64); !ok || f != float64(int64(f)) {
+			report("expected an integer, got %s", jsonTypeName(v))
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			report("expected a number, got %s", jsonTypeName(v))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			report("expected a boolean, got %s", jsonTypeName(v))
+		}
+	}
+}
+
+func joinSchemaPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// schemaRepairPrompt asks the model to resubmit a document that failed validation.
+func schemaRepairPrompt(problems []string) string {
+	return "Your analysis did not match the required schema:\n- " + strings.Join(problems, "\n- ") +
+		"\n\nSubmit the complete corrected analysis again, fixing every problem listed above."
+}
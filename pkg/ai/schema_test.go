@@ -0,0 +1,133 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/retry"
+)
+
+func TestSynthesisSchemaFollowsModules(t *testing.T) {
+	schema := SynthesisSchema(AnalysisModules{UIUX: true}).Schema
+	props := schema["properties"].(map[string]interface{})
+
+	if _, ok := props["uiuxAnalysis"]; !ok {
+		t.Error("enabled module section missing")
+	}
+	for _, field := range []string{"wordingCheck", "gameDesign", "gliCompliance", "navigationMap", "budgetExhausted"} {
+		if _, ok := props[field]; ok {
+			t.Errorf("%s should not be in the schema", field)
+		}
+	}
+	required := strings.Join(schema["required"].([]string), ",")
+	if !strings.Contains(required, "mechanics") || !strings.Contains(required, "uiuxAnalysis") {
+		t.Errorf("required = %s", required)
+	}
+
+	mechanic := props["mechanics"].(map[string]interface{})["items"].(map[string]interface{})
+	priority := mechanic["properties"].(map[string]interface{})["priority"].(map[string]interface{})
+	if enum, _ := priority["enum"].([]string); strings.Join(enum, "|") != "high|medium|low" {
+		t.Errorf("priority enum = %v", priority["enum"])
+	}
+}
+
+func TestValidateJSONSchemaReportsProblems(t *testing.T) {
+	schema := SynthesisSchema(AnalysisModules{}).Schema
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{
+		"gameInfo": {"name": "Slots", "description": "", "genre": "", "technology": "", "features": []},
+		"mechanics": [{"name": "Spin", "description": "", "actions": [], "expected": "", "priority": "urgent"}],
+		"uiElements": [], "userFlows": [], "edgeCases": "none",
+		"scenarios": [{"name": "s", "description": "", "type": "happy-path", "steps": [{"action": "click", "target": "spin", "value": null}], "priority": "high", "tags": []}]
+	}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	got := validateJSONSchema(doc, schema)
+	want := []string{
+		`mechanics[0].priority: "urgent" must be one of high, medium, low`,
+		`edgeCases: expected an array, got a string`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("problems = %q, want %q", got, want)
+	}
+	for _, w := range want {
+		if !strings.Contains(strings.Join(got, "\n"), w) {
+			t.Errorf("missing problem %q in %q", w, got)
+		}
+	}
+}
+
+// scriptedStructuredAgent returns its documents in order and records the messages it got.
+type scriptedStructuredAgent struct {
+	docs     []string
+	messages [][]AgentMessage
+}
+
+func (s *scriptedStructuredAgent) GenerateStructured(ctx context.Context, systemPrompt string, messages []AgentMessage, schema OutputSchema) (string, error) {
+	s.messages = append(s.messages, messages)
+	doc := s.docs[0]
+	s.docs = s.docs[1:]
+	return doc, nil
+}
+
+func TestStructuredSynthesisRepairsOnce(t *testing.T) {
+	valid := `{"gameInfo": {"name": "Slots", "description": "", "genre": "", "technology": "", "features": []},
+		"mechanics": [{"name": "Spin", "description": "", "actions": [], "expected": "", "priority": "high"}],
+		"uiElements": [], "userFlows": [], "edgeCases": [], "scenarios": []}`
+	agent := &scriptedStructuredAgent{docs: []string{`{"mechanics": []}`, valid}}
+
+	var steps []string
+	a := &Analyzer{}
+	result, err := a.structuredSynthesis(context.Background(), agent, "", []AgentMessage{{Role: "user", Content: "analyze"}},
+		AnalysisModules{}, &retry.Config{MaxAttempts: 1, InitialDelay: time.Millisecond},
+		func(step, message string) { steps = append(steps, step) })
+	if err != nil {
+		t.Fatalf("structuredSynthesis: %v", err)
+	}
+	if result.GameInfo.Name != "Slots" || len(result.Mechanics) != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(agent.messages) != 2 || len(agent.messages[1]) != 3 {
+		t.Fatalf("expected one repair round with the problems appended, got %d calls", len(agent.messages))
+	}
+	if repair := agent.messages[1][2].Content.(string); !strings.Contains(repair, `missing required field "gameInfo"`) {
+		t.Errorf("repair prompt = %q", repair)
+	}
+	if len(steps) != 1 || steps[0] != "synthesis_repair" {
+		t.Errorf("progress steps = %v", steps)
+	}
+}
+
+func TestClaudeGenerateStructuredForcesTool(t *testing.T) {
+	c := NewClaudeClient("key", "claude-sonnet-4-5-20250929", 0, 1024)
+	var sent claudeToolUseRequest
+	c.HTTPClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &sent)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(`{"content": [{"type": "tool_use", "id": "toolu_1", "name": "submit_analysis",
+				"input": {"mechanics": []}}], "stop_reason": "tool_use", "usage": {"input_tokens": 1, "output_tokens": 1}}`)),
+		}, nil
+	})
+
+	doc, err := c.GenerateStructured(context.Background(), "", []AgentMessage{{Role: "user", Content: "go"}}, SynthesisSchema(AnalysisModules{}))
+	if err != nil {
+		t.Fatalf("GenerateStructured: %v", err)
+	}
+	if doc != `{"mechanics": []}` {
+		t.Errorf("doc = %s", doc)
+	}
+	if sent.ToolChoice == nil || sent.ToolChoice.Type != "tool" || sent.ToolChoice.Name != "submit_analysis" {
+		t.Errorf("tool_choice = %+v", sent.ToolChoice)
+	}
+	if len(sent.Tools) != 1 || sent.Tools[0].Name != "submit_analysis" {
+		t.Errorf("tools = %+v", sent.Tools)
+	}
+}
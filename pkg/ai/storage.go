@@ -0,0 +1,176 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+// maxStorageValueChars caps each stored value shown to the model; game saves
+// are often large JSON or base64 blobs.
+const maxStorageValueChars = 500
+
+var storageAreaEnum = []string{scout.StorageLocal, scout.StorageSession, scout.StorageCookies, scout.StorageIndexedDB}
+
+// getStorageTool is the get_storage tool definition, part of BrowserTools.
+var getStorageTool = ToolDefinition{
+	Name:        "get_storage",
+	Description: "Read what the game has saved for this site: localStorage, sessionStorage, cookies and IndexedDB databases (up to 100 records per store). Use this to find settings, progress, balance or tutorial flags the game persists. Long values are truncated.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"area": map[string]interface{}{
+				"type":        "string",
+				"enum":        storageAreaEnum,
+				"description": "Only read this area (default: all)",
+			},
+		},
+		"required": []string{},
+	},
+}
+
+// setStorageTool is the set_storage tool definition, part of BrowserTools.
+var setStorageTool = ToolDefinition{
+	Name:        "set_storage",
+	Description: "Write saved state for this site, merged into what is already there: localStorage/sessionStorage keys, cookies and IndexedDB records. Use it to put the game into a state such as \"tutorial completed\" or \"returning player\", then reload so the game reads it. Use get_storage first to learn the game's keys and formats.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"local_storage": map[string]interface{}{
+				"type":        "object",
+				"description": "localStorage keys to set. Non-string values are stored as JSON.",
+			},
+			"session_storage": map[string]interface{}{
+				"type":        "object",
+				"description": "sessionStorage keys to set. Non-string values are stored as JSON.",
+			},
+			"cookies": map[string]interface{}{
+				"type":        "array",
+				"description": "Cookies to set: {name, value, domain?, path?, expires? (Unix seconds), httpOnly?, secure?, sameSite?}. Domain defaults to the current page.",
+				"items":       map[string]interface{}{"type": "object"},
+			},
+			"indexed_db": map[string]interface{}{
+				"type":        "array",
+				"description": "IndexedDB databases in the get_storage format: {name, version?, stores: [{name, keyPath?, autoIncrement?, records: [{key?, value}]}]}. Records are put, overwriting existing keys.",
+				"items":       map[string]interface{}{"type": "object"},
+			},
+			"reload": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Reload the page afterwards so the game reads the new state (default false)",
+			},
+		},
+		"required": []string{},
+	},
+}
+
+// clearStorageTool is the clear_storage tool definition, part of BrowserTools.
+var clearStorageTool = ToolDefinition{
+	Name:        "clear_storage",
+	Description: "Delete saved state for this site, e.g. to test a first-time player or recovery from a wiped save. Cookies are cleared for the whole browser.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"areas": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string", "enum": storageAreaEnum},
+				"description": "Areas to clear (default: all)",
+			},
+			"reload": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Reload the page afterwards so the game starts without the cleared state (default false)",
+			},
+		},
+		"required": []string{},
+	},
+}
+
+// storageStrings converts tool input values to storage strings, encoding
+// non-string values as JSON.
+func storageStrings(values map[string]interface{}) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(values))
+	for k, v := range values {
+		if s, ok := v.(string); ok {
+			out[k] = s
+			continue
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("value of %q: %w", k, err)
+		}
+		out[k] = string(data)
+	}
+	return out, nil
+}
+
+func truncateStorageValue(v string) string {
+	if len(v) <= maxStorageValueChars {
+		return v
+	}
+	return fmt.Sprintf("%s… (%d chars)", v[:maxStorageValueChars], len(v))
+}
+
+// formatStorageState renders one area, or all when area is "", as JSON for
+// the model, truncating long values.
+func formatStorageState(s *scout.StorageState, area string) string {
+	view := scout.StorageState{}
+	if area == "" || area == scout.StorageLocal {
+		view.LocalStorage = make(map[string]string, len(s.LocalStorage))
+		for k, v := range s.LocalStorage {
+			view.LocalStorage[k] = truncateStorageValue(v)
+		}
+	}
+	if area == "" || area == scout.StorageSession {
+		view.SessionStorage = make(map[string]string, len(s.SessionStorage))
+		for k, v := range s.SessionStorage {
+			view.SessionStorage[k] = truncateStorageValue(v)
+		}
+	}
+	if area == "" || area == scout.StorageCookies {
+		for _, c := range s.Cookies {
+			c.Value = truncateStorageValue(c.Value)
+			view.Cookies = append(view.Cookies, c)
+		}
+	}
+	if area == "" || area == scout.StorageIndexedDB {
+		for _, db := range s.IndexedDB {
+			stores := make([]scout.IndexedDBStore, len(db.Stores))
+			for i, st := range db.Stores {
+				records := make([]scout.IndexedDBRecord, len(st.Records))
+				for j, rec := range st.Records {
+					if len(rec.Value) > maxStorageValueChars {
+						rec.Value, _ = json.Marshal(truncateStorageValue(string(rec.Value)))
+					}
+					records[j] = rec
+				}
+				st.Records = records
+				stores[i] = st
+			}
+			db.Stores = stores
+			view.IndexedDB = append(view.IndexedDB, db)
+		}
+	}
+	if view.IsEmpty() {
+		if area == "" {
+			return "Nothing is stored for this page."
+		}
+		return fmt.Sprintf("No %s for this page.", area)
+	}
+	out, _ := json.MarshalIndent(view, "", "  ")
+	return fmt.Sprintf("Stored: %s.\n%s", view.Summary(), out)
+}
+
+// reloadPage navigates to the current URL again.
+func reloadPage(page BrowserPage) error {
+	_, url, _, err := page.GetPageInfo()
+	if err != nil {
+		return err
+	}
+	if url == "" {
+		return fmt.Errorf("current page URL is unknown")
+	}
+	return page.Navigate(url)
+}
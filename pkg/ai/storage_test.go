@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+func TestStorageTools(t *testing.T) {
+	page := &fakePage{storage: scout.StorageState{
+		LocalStorage: map[string]string{"tutorialDone": "false", "save": strings.Repeat("x", 600)},
+		Cookies:      []scout.Cookie{{Name: "session", Value: "abc", Domain: "game.test"}},
+	}}
+	exec := &BrowserToolExecutor{Page: page}
+
+	text, _, err := exec.Execute("get_storage", nil)
+	if err != nil {
+		t.Fatalf("get_storage: %v", err)
+	}
+	if !strings.HasPrefix(text, "Stored: 2 localStorage keys, 1 cookie.") || !strings.Contains(text, "… (600 chars)") {
+		t.Errorf("unexpected storage:\n%s", text)
+	}
+	text, _, _ = exec.Execute("get_storage", json.RawMessage(`{"area": "indexedDB"}`))
+	if text != "No indexedDB for this page." {
+		t.Errorf("unexpected empty area: %q", text)
+	}
+
+	text, _, err = exec.Execute("set_storage", json.RawMessage(`{"local_storage": {"tutorialDone": true, "level": "7"}}`))
+	if err != nil {
+		t.Fatalf("set_storage: %v", err)
+	}
+	if !strings.HasPrefix(text, "Wrote 2 localStorage keys. The game reads it on its next load") || strings.Join(page.calls, "; ") != "storage 2 localStorage keys" {
+		t.Errorf("unexpected result %q, calls %q", text, page.calls)
+	}
+
+	if _, _, err := exec.Execute("set_storage", json.RawMessage(`{}`)); err == nil {
+		t.Error("set_storage without values should fail")
+	}
+	if _, _, err := exec.Execute("set_storage", json.RawMessage(`{"indexed_db": [{"name": "save", "stores": [{"name": "slots", "records": [{"value": 1}]}]}]}`)); err == nil {
+		t.Error("records of a store without keyPath need a key")
+	}
+
+	page.calls = nil
+	text, _, err = exec.Execute("clear_storage", json.RawMessage(`{"areas": ["localStorage", "cookies"]}`))
+	if err != nil {
+		t.Fatalf("clear_storage: %v", err)
+	}
+	if text != "Cleared localStorage, cookies." || strings.Join(page.calls, "; ") != "clear localStorage,cookies" {
+		t.Errorf("unexpected clear: %q %q", text, page.calls)
+	}
+}
+
+func TestStorageStrings(t *testing.T) {
+	got, err := storageStrings(map[string]interface{}{"a": "text", "b": 3.5, "c": map[string]interface{}{"x": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["a"] != "text" || got["b"] != "3.5" || got["c"] != `{"x":true}` {
+		t.Errorf("unexpected values %v", got)
+	}
+}
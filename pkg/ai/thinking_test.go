@@ -0,0 +1,146 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestClaudeThinkingRequestAndStream(t *testing.T) {
+	c := NewClaudeClient("key", "claude-sonnet-4-5-20250929", 0.7, 4096)
+	c.ThinkingBudget = 2048
+	var sent claudeToolUseRequest
+	var rawSent map[string]interface{}
+	c.HTTPClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &sent)
+		json.Unmarshal(body, &rawSent)
+		return sseResponse(
+			`{"type":"message_start","message":{"model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":100,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"The play button "}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"is centred."}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig-abc"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"click","input":{}}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"x\": 400, \"y\": 300}"}}`,
+			`{"type":"content_block_stop","index":1}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":120}}`,
+			`{"type":"message_stop"}`,
+		), nil
+	})
+	c.OnStream = func(kind, delta string) {}
+
+	resp, err := c.CallWithTools(context.Background(), "system", []AgentMessage{{Role: "user", Content: "go"}}, BrowserTools(800, 600))
+	if err != nil {
+		t.Fatalf("CallWithTools: %v", err)
+	}
+
+	if sent.Thinking == nil || sent.Thinking.Type != "enabled" || sent.Thinking.BudgetTokens != 2048 {
+		t.Errorf("thinking = %+v", sent.Thinking)
+	}
+	if _, ok := rawSent["temperature"]; ok {
+		t.Errorf("temperature must not be sent with thinking enabled, got %v", rawSent["temperature"])
+	}
+	if sent.MaxTokens != 4096+2048 {
+		t.Errorf("max_tokens = %d, want the thinking budget on top of 4096", sent.MaxTokens)
+	}
+
+	if len(resp.Content) != 2 {
+		t.Fatalf("thinking block must be kept in the content, got %+v", resp.Content)
+	}
+	if th := resp.Content[0]; th.Type != "thinking" || th.Thinking != "The play button is centred." || th.Signature != "sig-abc" {
+		t.Errorf("thinking block = %+v", th)
+	}
+	// 120 output tokens minus ~7 for the visible tool call
+	if got := resp.Usage.ThinkingTokens; got < 100 || got >= 120 {
+		t.Errorf("ThinkingTokens = %d, want most of the 120 output tokens", got)
+	}
+}
+
+func TestClaudeForcedToolSkipsThinking(t *testing.T) {
+	c := NewClaudeClient("key", "claude-sonnet-4-5-20250929", 0, 1024)
+	c.ThinkingBudget = 2048
+	var sent claudeToolUseRequest
+	c.HTTPClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &sent)
+		return sseResponse(
+			`{"type":"message_start","message":{"usage":{"input_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"submit_analysis","input":{}}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{}"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":2}}`,
+			`{"type":"message_stop"}`,
+		), nil
+	})
+	c.OnStream = func(kind, delta string) {}
+
+	if _, err := c.GenerateStructured(context.Background(), "", []AgentMessage{{Role: "user", Content: "go"}}, SynthesisSchema(AnalysisModules{})); err != nil {
+		t.Fatalf("GenerateStructured: %v", err)
+	}
+	if sent.Thinking != nil || sent.MaxTokens != 1024 {
+		t.Errorf("forced tool choice must not enable thinking: thinking=%+v max_tokens=%d", sent.Thinking, sent.MaxTokens)
+	}
+}
+
+func TestPruneOldScreenshotsKeepsThinking(t *testing.T) {
+	assistant := []ResponseContentBlock{
+		{Type: "thinking", Thinking: "Spin looks disabled.", Signature: "sig-1"},
+		{Type: "redacted_thinking", Data: "opaque"},
+		{Type: "tool_use", ID: "toolu_1", Name: "screenshot", Input: json.RawMessage(`{}`)},
+	}
+	messages := []AgentMessage{
+		{Role: "assistant", Content: assistant},
+		{Role: "user", Content: []interface{}{ToolResultBlock{
+			Type: "tool_result", ToolUseID: "toolu_1",
+			Content: []interface{}{map[string]interface{}{"type": "image", "source": map[string]interface{}{"data": "..."}}},
+		}}},
+	}
+
+	PruneOldScreenshots(messages, 0)
+
+	got := messages[0].Content.([]ResponseContentBlock)
+	if len(got) != 3 || got[0].Thinking != "Spin looks disabled." || got[0].Signature != "sig-1" || got[1].Data != "opaque" {
+		t.Errorf("assistant content changed: %+v", got)
+	}
+	result := messages[1].Content.([]interface{})[0].(ToolResultBlock).Content.([]interface{})[0].(map[string]interface{})
+	if result["type"] != "text" {
+		t.Errorf("screenshot was not pruned: %+v", result)
+	}
+
+	stripped := withoutThinking(messages)
+	if blocks := stripped[0].Content.([]ResponseContentBlock); len(blocks) != 1 || blocks[0].Type != "tool_use" {
+		t.Errorf("withoutThinking = %+v", blocks)
+	}
+	if len(messages[0].Content.([]ResponseContentBlock)) != 3 {
+		t.Error("withoutThinking modified its input")
+	}
+}
+
+func TestStepReasoningPutsThinkingFirst(t *testing.T) {
+	got := stepReasoning([]ResponseContentBlock{
+		{Type: "text", Text: "Clicking play."},
+		{Type: "thinking", Thinking: "Play is at the centre."},
+		{Type: "tool_use", Name: "click"},
+	})
+	if got != "Play is at the centre.\n\nClicking play." {
+		t.Errorf("stepReasoning = %q", got)
+	}
+}
+
+func TestAddThinkingBreaksDownOutput(t *testing.T) {
+	var u TokenUsage
+	u.AddForModel("claude-sonnet-4-5-20250929", 1000, 500, 0, 0)
+	cost := u.EstimatedCost("")
+	u.AddThinking("claude-sonnet-4-5-20250929", 300)
+
+	if u.ThinkingTokens != 300 || u.ByModel["claude-sonnet-4-5-20250929"].ThinkingTokens != 300 {
+		t.Errorf("ThinkingTokens = %d (by model %d)", u.ThinkingTokens, u.ByModel["claude-sonnet-4-5-20250929"].ThinkingTokens)
+	}
+	if u.OutputTokens != 500 || u.EstimatedCost("") != cost {
+		t.Error("thinking tokens are already part of the output and must not change totals or cost")
+	}
+}
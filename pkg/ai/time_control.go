@@ -0,0 +1,42 @@
+package ai
+
+import (
+	"fmt"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+// maxAdvanceTimeMs caps advance_time; see scout.MaxAdvanceTime.
+var maxAdvanceTimeMs = int(scout.MaxAdvanceTime.Milliseconds())
+
+// advanceTimeTool is the advance_time tool definition, part of BrowserTools.
+var advanceTimeTool = ToolDefinition{
+	Name:        "advance_time",
+	Description: fmt.Sprintf("Fast-forward the game's clock by ms of game time instead of waiting in real time: timers, tweens and animations jump ahead and the call returns as soon as the page has caught up, with a screenshot. Use it for reel spins, win celebrations, loading bars and countdowns. Time waits for pending network requests, so loading only finishes once assets arrive. Max %dms.", maxAdvanceTimeMs),
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"ms": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Game time to skip, in milliseconds (1-%d)", maxAdvanceTimeMs),
+			},
+		},
+		"required": []string{"ms"},
+	},
+}
+
+// pauseGameTool is the pause_game tool definition, part of BrowserTools.
+var pauseGameTool = ToolDefinition{
+	Name:        "pause_game",
+	Description: "Freeze or resume the game's clock. While paused, the game loop, timers and animations stand still, so you can inspect a frame (e.g. mid-spin reel symbols or a short-lived win banner), then step through it with advance_time. Clicks and keys are only processed once time runs again. navigate resumes time.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"paused": map[string]interface{}{
+				"type":        "boolean",
+				"description": "true to freeze time, false to resume it",
+			},
+		},
+		"required": []string{"paused"},
+	},
+}
@@ -0,0 +1,87 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+func TestTimeControlTools(t *testing.T) {
+	page := &fakePage{}
+	exec := &BrowserToolExecutor{Page: page}
+
+	text, b64, err := exec.Execute("advance_time", json.RawMessage(`{"ms": 5000}`))
+	if err != nil {
+		t.Fatalf("advance_time: %v", err)
+	}
+	if b64 == "" || !strings.HasPrefix(text, "Advanced game time by 5000ms") || strings.Contains(text, "paused") {
+		t.Errorf("unexpected result %q", text)
+	}
+	if _, _, err := exec.Execute("advance_time", json.RawMessage(`{"ms": 0}`)); err == nil {
+		t.Error("advance_time without ms should fail")
+	}
+
+	if _, _, err := exec.Execute("pause_game", json.RawMessage(`{}`)); err == nil {
+		t.Error("pause_game without paused should fail")
+	}
+	if _, _, err := exec.Execute("pause_game", json.RawMessage(`{"paused": true}`)); err != nil {
+		t.Fatalf("pause_game: %v", err)
+	}
+	text, _, _ = exec.Execute("advance_time", json.RawMessage(`{"ms": 600000}`))
+	if !strings.HasPrefix(text, "Advanced game time by 120000ms") || !strings.HasSuffix(text, "Time is paused again.") {
+		t.Errorf("expected a capped advance that stays paused, got %q", text)
+	}
+	text, _, _ = exec.Execute("wait", json.RawMessage(`{"milliseconds": 1}`))
+	if !strings.Contains(text, "game time is paused") {
+		t.Errorf("wait should warn that time is paused, got %q", text)
+	}
+
+	if got := strings.Join(page.calls, "; "); got != "advance 5s; paused true; advance 2m0s" {
+		t.Errorf("unexpected calls %q", got)
+	}
+}
+
+func TestResetPage(t *testing.T) {
+	page := &fakePage{}
+	exec := &BrowserToolExecutor{Page: page}
+	if _, _, err := exec.Execute("pause_game", json.RawMessage(`{"paused": true}`)); err != nil {
+		t.Fatalf("pause_game: %v", err)
+	}
+
+	// Next scenario: reset, then seed and navigate, which would hang on a paused clock
+	if err := exec.ResetPage(); err != nil {
+		t.Fatalf("ResetPage: %v", err)
+	}
+	if page.TimePaused() {
+		t.Fatal("time should run again before the next scenario seeds storage and navigates")
+	}
+	if err := exec.ResetPage(); err != nil || strings.Join(page.calls, "; ") != "paused true; paused false" {
+		t.Errorf("a running clock should be left alone, got %q, %v", page.calls, err)
+	}
+}
+
+func TestResetPageRestoresOrientation(t *testing.T) {
+	page := &fakePage{touch: true}
+	exec := &BrowserToolExecutor{Page: page, Viewport: *scout.GetViewportByName("iphone-16")}
+	if _, _, err := exec.Execute("rotate_device", json.RawMessage(`{"orientation": "landscape"}`)); err != nil {
+		t.Fatalf("rotate_device: %v", err)
+	}
+	if err := exec.ResetPage(); err != nil {
+		t.Fatalf("ResetPage: %v", err)
+	}
+	if page.Orientation() != scout.OrientationPortrait {
+		t.Error("the next scenario should start in the preset's portrait orientation")
+	}
+
+	// A landscape preset comes back to landscape; desktops are never rotated
+	exec.Viewport = exec.Viewport.Landscape()
+	if err := exec.ResetPage(); err != nil || page.Orientation() != scout.OrientationLandscape {
+		t.Errorf("landscape preset should be restored to landscape, got %s, %v", page.Orientation(), err)
+	}
+	desktop := &BrowserToolExecutor{Page: &fakePage{}, Viewport: *scout.GetViewportByName("desktop-std")}
+	if err := desktop.ResetPage(); err != nil {
+		t.Errorf("ResetPage on a desktop: %v", err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
 )
 
 // TokenUsage tracks cumulative token consumption across API calls.
@@ -17,6 +19,15 @@ type TokenUsage struct {
 	CacheReadInputTokens     int
 	TotalTokens              int // InputTokens + OutputTokens (convenience)
 	APICallCount             int
+	// ThinkingTokens is the part of OutputTokens spent on extended thinking during
+	// agent exploration (set by AddThinking; estimated for Claude).
+	ThinkingTokens int
+
+	// Model is the model that answered the most recent call (set by AddForModel).
+	Model string
+	// ByModel breaks usage down per answering model, so fallback chains that mix
+	// providers are priced correctly.
+	ByModel map[string]*TokenUsage
 }
 
 // Add merges usage from a single API call into the running total.
@@ -29,9 +40,55 @@ func (u *TokenUsage) Add(input, output, cacheCreate, cacheRead int) {
 	u.APICallCount++
 }
 
-// EstimatedCost calculates the estimated cost in USD based on the model's pricing.
+// AddForModel is like Add but also attributes the usage to the model that answered.
+func (u *TokenUsage) AddForModel(model string, input, output, cacheCreate, cacheRead int) {
+	u.Add(input, output, cacheCreate, cacheRead)
+	if model == "" {
+		return
+	}
+	u.Model = model
+	if u.ByModel == nil {
+		u.ByModel = map[string]*TokenUsage{}
+	}
+	m, ok := u.ByModel[model]
+	if !ok {
+		m = &TokenUsage{}
+		u.ByModel[model] = m
+	}
+	m.Add(input, output, cacheCreate, cacheRead)
+}
+
+// AddThinking records thinking tokens already counted as output by Add or AddForModel.
+// It does not change the cost, only how the output tokens are broken down.
+func (u *TokenUsage) AddThinking(model string, tokens int) {
+	if tokens <= 0 {
+		return
+	}
+	u.ThinkingTokens += tokens
+	if m := u.ByModel[model]; m != nil {
+		m.ThinkingTokens += tokens
+	}
+}
+
+// EstimatedCost calculates the estimated cost in USD based on the model's current pricing.
+// When usage was recorded per model (AddForModel), each model is priced separately
+// and the model argument is ignored.
 func (u *TokenUsage) EstimatedCost(model string) float64 {
-	p, ok := ModelPricingTable[model]
+	return u.EstimatedCostAt(model, time.Now())
+}
+
+// EstimatedCostAt is like EstimatedCost but uses the prices that were in effect at
+// the given time, so usage recorded in the past is priced as it was billed.
+// Unpriced models cost $0 (see IsPriced).
+func (u *TokenUsage) EstimatedCostAt(model string, at time.Time) float64 {
+	if len(u.ByModel) > 0 {
+		var total float64
+		for m, mu := range u.ByModel {
+			total += mu.EstimatedCostAt(m, at)
+		}
+		return total
+	}
+	p, ok := LookupPricing(model, at)
 	if !ok {
 		return 0
 	}
@@ -42,15 +99,42 @@ func (u *TokenUsage) EstimatedCost(model string) float64 {
 	return cost
 }
 
+// SpendBudget is a hard cap on what a single run may spend on AI calls.
+// Zero fields are unlimited.
+type SpendBudget struct {
+	USD    float64
+	Tokens int
+}
+
+// IsZero reports whether the budget imposes no limit.
+func (b SpendBudget) IsZero() bool {
+	return b.USD <= 0 && b.Tokens <= 0
+}
+
+// Exceeded reports whether usage has reached the budget, with a human-readable reason.
+// model prices usage that was not recorded per model.
+func (b SpendBudget) Exceeded(u *TokenUsage, model string) (bool, string) {
+	if b.Tokens > 0 && u.TotalTokens >= b.Tokens {
+		return true, fmt.Sprintf("token budget exhausted (%d of %d tokens used)", u.TotalTokens, b.Tokens)
+	}
+	if b.USD > 0 {
+		if cost := u.EstimatedCost(model); cost >= b.USD {
+			return true, fmt.Sprintf("spend budget exhausted ($%.4f of $%.2f used)", cost, b.USD)
+		}
+	}
+	return false, ""
+}
+
 // ModelPricing holds per-million-token prices in USD.
 type ModelPricing struct {
-	InputPerMTok       float64
-	OutputPerMTok      float64
-	CacheCreatePerMTok float64
-	CacheReadPerMTok   float64
+	InputPerMTok       float64 `yaml:"inputPerMTok" json:"inputPerMTok"`
+	OutputPerMTok      float64 `yaml:"outputPerMTok" json:"outputPerMTok"`
+	CacheCreatePerMTok float64 `yaml:"cacheCreatePerMTok,omitempty" json:"cacheCreatePerMTok,omitempty"`
+	CacheReadPerMTok   float64 `yaml:"cacheReadPerMTok,omitempty" json:"cacheReadPerMTok,omitempty"`
 }
 
-// ModelPricingTable maps model IDs to their pricing.
+// ModelPricingTable holds the built-in prices keyed by model ID. They apply when no
+// pricing file overrides the model (see LoadPricingFile).
 // Prices updated 2026-02-14 from official sources.
 var ModelPricingTable = map[string]ModelPricing{
 	// Claude Sonnet 4.5 — $3/$15, cache write 1.25x, cache read 0.1x
@@ -67,6 +151,20 @@ var ModelPricingTable = map[string]ModelPricing{
 	"gemini-2.5-pro": {InputPerMTok: 1.25, OutputPerMTok: 10.0, CacheReadPerMTok: 0.125},
 	// Gemini 3 Flash Preview — $0.50/$3.00, cache read $0.05
 	"gemini-3-flash-preview": {InputPerMTok: 0.50, OutputPerMTok: 3.00, CacheReadPerMTok: 0.05},
+	// GPT-4.1 (OpenAI default) — $2.00/$8.00, cached input $0.50
+	"gpt-4.1": {InputPerMTok: 2.00, OutputPerMTok: 8.00, CacheReadPerMTok: 0.50},
+	// GPT-4.1 mini — $0.40/$1.60, cached input $0.10
+	"gpt-4.1-mini": {InputPerMTok: 0.40, OutputPerMTok: 1.60, CacheReadPerMTok: 0.10},
+	// GPT-4.1 nano — $0.10/$0.40, cached input $0.025
+	"gpt-4.1-nano": {InputPerMTok: 0.10, OutputPerMTok: 0.40, CacheReadPerMTok: 0.025},
+	// o3 — $2.00/$8.00, cached input $0.50
+	"o3": {InputPerMTok: 2.00, OutputPerMTok: 8.00, CacheReadPerMTok: 0.50},
+	// o4-mini — $1.10/$4.40, cached input $0.275
+	"o4-mini": {InputPerMTok: 1.10, OutputPerMTok: 4.40, CacheReadPerMTok: 0.275},
+	// GPT-4o — $2.50/$10.00, cached input $1.25
+	"gpt-4o": {InputPerMTok: 2.50, OutputPerMTok: 10.0, CacheReadPerMTok: 1.25},
+	// GPT-4o mini — $0.15/$0.60, cached input $0.075
+	"gpt-4o-mini": {InputPerMTok: 0.15, OutputPerMTok: 0.60, CacheReadPerMTok: 0.075},
 }
 
 // AnalysisResult represents the result of analyzing a game
@@ -82,6 +180,8 @@ type AnalysisResult struct {
 	GLICompliance []GLIFinding        `json:"gliCompliance,omitempty"`
 	NavigationMap *NavigationMap      `json:"navigationMap,omitempty"`
 	RawResponse   string              `json:"rawResponse,omitempty"`
+	BudgetExhausted bool              `json:"budgetExhausted,omitempty"` // exploration stopped early on the spend budget
+	Performance     *PerformanceReport `json:"performance,omitempty"`    // measured during agent exploration
 }
 
 // ComprehensiveAnalysisResult combines game analysis with test scenarios in a
@@ -99,6 +199,8 @@ type ComprehensiveAnalysisResult struct {
 	GameDesign    []GameDesignFinding `json:"gameDesign,omitempty"`
 	GLICompliance []GLIFinding        `json:"gliCompliance,omitempty"`
 	NavigationMap *NavigationMap      `json:"navigationMap,omitempty"`
+	BudgetExhausted bool              `json:"budgetExhausted,omitempty"`
+	Performance     *PerformanceReport `json:"performance,omitempty"`
 }
 
 // ToAnalysisResult converts a ComprehensiveAnalysisResult to the legacy AnalysisResult
@@ -115,6 +217,9 @@ func (c *ComprehensiveAnalysisResult) ToAnalysisResult() *AnalysisResult {
 		GameDesign:    c.GameDesign,
 		GLICompliance: c.GLICompliance,
 		NavigationMap: c.NavigationMap,
+
+		BudgetExhausted: c.BudgetExhausted,
+		Performance:     c.Performance,
 	}
 }
 
@@ -133,7 +238,7 @@ type Mechanic struct {
 	Description string   `json:"description"`
 	Actions     []string `json:"actions"`     // User actions required
 	Expected    string   `json:"expected"`    // Expected outcome
-	Priority    string   `json:"priority"`    // high, medium, low
+	Priority    string   `json:"priority" enum:"high|medium|low"`
 }
 
 // UIElement represents a UI element to interact with
@@ -163,40 +268,41 @@ type EdgeCase struct {
 
 // UIUXFinding represents a single UI/UX observation from the analysis.
 type UIUXFinding struct {
-	Category    string `json:"category"`    // alignment, spacing, color, typography, responsive, hierarchy, accessibility, animation
+	Category    string `json:"category" enum:"alignment|spacing|color|typography|responsive|hierarchy|accessibility|animation"`
 	Description string `json:"description"`
-	Severity    string `json:"severity"`    // critical, major, minor, suggestion, positive
+	Severity    string `json:"severity" enum:"critical|major|minor|suggestion|positive"`
 	Location    string `json:"location"`    // Where in the UI this was observed
 	Suggestion  string `json:"suggestion"`  // Recommended fix
 }
 
 // WordingFinding represents a single wording/translation issue.
 type WordingFinding struct {
-	Category    string `json:"category"`    // grammar, spelling, consistency, tone, truncation, placeholder, translation, overflow
+	Category    string `json:"category" enum:"grammar|spelling|consistency|tone|truncation|placeholder|translation|overflow"`
 	Text        string `json:"text"`        // The problematic text
 	Description string `json:"description"`
-	Severity    string `json:"severity"`    // critical, major, minor, suggestion, positive
+	Severity    string `json:"severity" enum:"critical|major|minor|suggestion|positive"`
 	Location    string `json:"location"`    // Where in the UI this text appears
 	Suggestion  string `json:"suggestion"`  // Corrected text or fix
+	Locale      string `json:"locale,omitempty"` // browser locale of the run that found it; set when locales are merged
 }
 
 // GameDesignFinding represents a single game design observation.
 type GameDesignFinding struct {
-	Category    string `json:"category"`    // rewards, balance, progression, psychology, difficulty, monetization, tutorial, feedback
+	Category    string `json:"category" enum:"rewards|balance|progression|psychology|difficulty|monetization|tutorial|feedback"`
 	Description string `json:"description"`
-	Severity    string `json:"severity"`    // critical, major, minor, positive
+	Severity    string `json:"severity" enum:"critical|major|minor|positive"`
 	Impact      string `json:"impact"`      // How this affects the player experience
 	Suggestion  string `json:"suggestion"`  // Recommended improvement
 }
 
 // GLIFinding represents a single GLI compliance observation.
 type GLIFinding struct {
-	ComplianceCategory string   `json:"complianceCategory"` // rng_fairness|rtp_accuracy|game_rules|responsible_gaming|age_verification|data_protection|aml|advertising|bonus_fairness|technical_security|ui_compliance|geolocation
+	ComplianceCategory string   `json:"complianceCategory" enum:"rng_fairness|rtp_accuracy|game_rules|responsible_gaming|age_verification|data_protection|aml|advertising|bonus_fairness|technical_security|ui_compliance|geolocation"`
 	Description        string   `json:"description"`
-	Status             string   `json:"status"`             // compliant|non_compliant|needs_review|not_applicable
+	Status             string   `json:"status" enum:"compliant|non_compliant|needs_review|not_applicable"`
 	Jurisdictions      []string `json:"jurisdictions"`      // jurisdiction IDs this applies to
 	GLIReference       string   `json:"gliReference"`       // e.g. "GLI-19 Section 3.4.1"
-	Severity           string   `json:"severity"`           // critical|major|minor|informational
+	Severity           string   `json:"severity" enum:"critical|major|minor|informational"`
 	Suggestion         string   `json:"suggestion"`
 }
 
@@ -205,7 +311,7 @@ type GameScreen struct {
 	ID          string   `json:"id"`          // e.g., "loading", "main-game", "info-popup"
 	Name        string   `json:"name"`        // Human-readable name
 	Description string   `json:"description"` // What the screen shows
-	ScreenType  string   `json:"screenType"`  // menu|gameplay|popup|overlay|loading|settings|bonus|error
+	ScreenType  string   `json:"screenType" enum:"menu|gameplay|popup|overlay|loading|settings|bonus|error"`
 	UIElements  []string `json:"uiElements"`  // Interactive elements visible
 	StepNumbers []int    `json:"stepNumbers"` // Agent steps where observed
 }
@@ -228,7 +334,7 @@ type NavigationMap struct {
 type TestScenario struct {
 	Name        string   `json:"name"`
 	Description string   `json:"description"`
-	Type        string   `json:"type"` // happy-path, edge-case, failure
+	Type        string   `json:"type" enum:"happy-path|edge-case|failure"`
 	Steps       []Step   `json:"steps"`
 	Priority    string   `json:"priority"`
 	Tags        []string `json:"tags"`
@@ -266,14 +372,35 @@ type PromptTemplate struct {
 type BrowserPage interface {
 	CaptureScreenshot() (b64 string, err error)
 	Click(x, y int) error
+	Drag(fromX, fromY, toX, toY int, duration time.Duration) error
+	LongPress(x, y int, duration time.Duration) error
+	MultiTouch(g scout.TouchGesture) error                    // scout.ErrTouchUnsupported on non-touch viewports
+	Rotate(orientation string) (width, height int, err error) // "" toggles; scout.ErrRotateUnsupported on non-touch viewports
+	Orientation() string
+	Hover(x, y int) error
+	MoveMouse(path []scout.Point) error
 	TypeText(text string) error
 	Scroll(dx, dy float64) error
 	EvalJS(expr string) (string, error)
 	WaitVisible(selector string, timeout time.Duration) error
 	GetPageInfo() (title, url, visibleText string, err error)
 	GetConsoleLogs() ([]string, error)
+	GetNetworkLog() ([]scout.NetworkEntry, error) // every request recorded so far, oldest first
+	MeasurePerformance(window time.Duration) (*scout.PerformanceSample, error)
+	PerformanceSession() (*scout.PerformanceSample, error) // everything sampled since the page loaded
+	SetNetwork(p scout.NetworkProfile) error
+	NetworkProfile() scout.NetworkProfile // connection currently emulated
+	GetStorage() (*scout.StorageState, error) // storage, cookies and IndexedDB of the current origin
+	SetStorage(s scout.StorageState) error     // merged into the current origin
+	ClearStorage(areas ...string) error        // every area when none are given
+	AdvanceTime(d time.Duration) error         // fast-forwards the page clock (CDP virtual time)
+	SetTimePaused(paused bool) error
+	TimePaused() bool
 	Navigate(url string) error
 	PressKey(key string) error
+	KeyDown(key string) error
+	KeyUp(key string) error
+	HoldKey(key string, duration time.Duration) error
 }
 
 // AnalysisModules controls which optional analysis sections are enabled.
@@ -285,6 +412,8 @@ type AnalysisModules struct {
 	GLI              bool
 	GLIJurisdictions []string // e.g. ["gb", "mt", "us-nj"]
 	NavigationMap    bool
+	Locale           string // browser locale the game is shown in, e.g. "de-DE"; the wording check expects text in it
+	Geolocation      string // emulated "latitude,longitude" reported to the game, for the GLI geolocation check
 }
 
 // DefaultAnalysisModules returns modules with everything enabled.
@@ -366,6 +495,7 @@ UI/UX ANALYSIS:
 WORDING/TRANSLATION CHECK:
 7. Examine all visible text for grammar, spelling, inconsistent terminology, tone, truncated text, placeholder text (e.g., "Lorem ipsum"), translation completeness, text overflow. Include 'positive' severity for well-written text, and 'suggestion' for non-bug improvements.
 `)
+		b.WriteString(wordingLocaleNote(modules.Locale))
 	}
 	if modules.GameDesign {
 		b.WriteString(`
@@ -394,6 +524,7 @@ Check these compliance categories based on what you can observe:
 
 For each finding, specify which jurisdictions it applies to, the relevant GLI standard reference, compliance status, and severity.
 `, strings.Join(modules.GLIJurisdictions, ", "))
+		b.WriteString(gliGeolocationNote(modules.Geolocation))
 	}
 
 	if modules.NavigationMap {
@@ -437,12 +568,14 @@ You interacted with the game and observed its behavior through screenshots. Now
 		}
 		if modules.Wording {
 			b.WriteString("- Wording/translation: grammar, spelling, consistency, tone, truncation, placeholder text, text overflow\n")
+			b.WriteString(wordingLocaleNote(modules.Locale))
 		}
 		if modules.GameDesign {
 			b.WriteString("- Game design: rewards, balance, progression, engagement, difficulty, monetization, tutorial, feedback\n")
 		}
 		if modules.GLI && len(modules.GLIJurisdictions) > 0 {
 			fmt.Fprintf(&b, "- GLI compliance: Evaluate visible UI against gaming regulation standards for jurisdictions: %s. Check categories: rng_fairness, rtp_accuracy, game_rules, responsible_gaming, age_verification, data_protection, aml, advertising, bonus_fairness, technical_security, ui_compliance, geolocation\n", strings.Join(modules.GLIJurisdictions, ", "))
+			b.WriteString(gliGeolocationNote(modules.Geolocation))
 		}
 	}
 
@@ -478,7 +611,10 @@ type AgentStep struct {
 	ScreenshotB64 string `json:"screenshotB64,omitempty"`
 	DurationMs    int    `json:"durationMs"`
 	ThinkingMs    int    `json:"thinkingMs,omitempty"`
+	Reasoning     string `json:"reasoning,omitempty"` // model's thinking and text preceding the tool call
 	Error         string `json:"error,omitempty"`
+	// GameState holds the project's probe values read right after the action
+	GameState map[string]interface{} `json:"gameState,omitempty"`
 }
 
 // AgentConfig controls the agent exploration loop.
@@ -495,6 +631,13 @@ type AgentConfig struct {
 	MaxTotalTimeout     time.Duration // Hard cap on total exploration time after extensions
 	ViewportWidth       int          // Browser viewport width (for tool descriptions)
 	ViewportHeight      int          // Browser viewport height (for tool descriptions)
+	Budget              SpendBudget  // Hard spend cap, checked after every AI call (zero = unlimited)
+	ThinkingBudget      int          // Extended thinking budget tokens per exploration call (0 = off, else >= 1024)
+	ContextMaxTokens    int          // Estimated history tokens at which older turns are summarised (0 = default, <0 = never)
+	ContextSummaryAI    bool         // Summarise older turns with the AI client instead of rules only
+	TouchInput          bool         // Touch viewport: offer the touch_gesture tool for pinch/rotate/pan
+	Probes              GameStateProbes // Project game-state getters: offer read_game_state and record values per step
+	Viewport            scout.ViewportPreset // Viewport preset: picks the performance thresholds
 }
 
 // CheckpointData wraps the state written to checkpoint files after each pipeline step.
@@ -610,15 +753,22 @@ SESSION-GATED GAMES:
 - In your synthesis, note that the game could not be analyzed due to expired session tokens and that fresh tokens are needed.
 
 RULES:
-1. The click, type_text, scroll, and navigate tools automatically return screenshots. You only need the screenshot tool for observing the page without interacting.
+1. The click, hover, drag, swipe, long_press, type_text, scroll, and navigate tools automatically return screenshots. You only need the screenshot tool for observing the page without interacting.
 2. Use get_page_info to understand the page structure when screenshots are ambiguous.
-3. Use console_logs when something seems wrong — errors often explain broken states.
+3. Use console_logs when something seems wrong — errors often explain broken states. Use network_log (failed_only: true) to find failed asset loads and error responses from the game server.
 4. Be systematic: don't click the same thing twice unless testing repeated interactions.
 5. When you have thoroughly explored the game (usually 10-20 steps), output EXPLORATION_COMPLETE on its own line to signal you are done.
 6. Focus on discovering testable behaviors through active interaction, not passive observation.
 7. IMPORTANT: To save time, always combine wait and screenshot into a single response. Call both tools together — they will execute sequentially. Never call wait alone without also calling screenshot in the same response.
-10. Use press_key for keyboard shortcuts: Space (spin/confirm), Enter (confirm/start), Escape (close dialogs), arrow keys (menu navigation).
-11. Use inspect_game_objects to discover clickable buttons with their exact coordinates instead of guessing from screenshots. This is especially useful when clicks aren't registering.`
+10. Use press_key for keyboard shortcuts: Space (spin/confirm), Enter (confirm/start), Escape (close dialogs), arrow keys (menu navigation). For action games that need keys held down (run, charge, move while jumping), use hold_keys with a timed sequence.
+11. Use inspect_game_objects to discover clickable buttons with their exact coordinates instead of guessing from screenshots. This is especially useful when clicks aren't registering.
+12. Use swipe for carousels, reels and page flicks, drag for sliders and drag-and-drop, and long_press for hold-to-reveal controls (e.g. paytable info, turbo/auto-spin buttons). On phone and tablet viewports, touch_gesture pinches, rotates and pans with two fingers (maps, zoomable boards).
+13. Use hover on buttons, icons and paytable symbols to reveal tooltips, popovers and hover states. Report missing or broken hover feedback in the UI/UX analysis.
+14. Use set_network once the main flows are covered to test connection loss: start a round (e.g. spin), then go offline with duration_ms to drop the connection mid-round. Check whether the game shows a reconnect message, resumes the round and keeps the balance consistent, and report what happened as edge cases.
+15. Use measure_performance while animations play (spins, big wins, bonus intros, scene transitions) to check frame rate, long tasks, heap and draw calls against this device's limits. Measure at least once during the busiest animation you find.
+16. Use get_storage to see what the game saves (settings, progress, tutorial flags). To test a returning player, change the saved state with set_storage or clear_storage and reload=true, then check that the game restores it correctly. Do not clear storage before the main flows are covered.
+17. Prefer advance_time over wait while the game is busy with something you only need to see finish (spins, win celebrations, loading bars, countdowns): it skips game time in far less real time. Use pause_game to freeze a short-lived frame, such as a win banner, and inspect it.
+18. On phone and tablet viewports, use rotate_device once the main flows are covered to check the other orientation: look for a rotate-device prompt, clipped or overlapping HUD elements and buttons pushed off screen, then rotate back and confirm the game recovers. After rotating, use the viewport size from the rotate_device result for coordinates.`
 
 // AdaptiveExplorationPromptSuffix returns the system prompt addition for adaptive exploration mode.
 func AdaptiveExplorationPromptSuffix(maxTotalSteps int) string {
@@ -672,6 +822,9 @@ func BuildAgentSystemPrompt(cfg AgentConfig) string {
 	if cfg.AdaptiveTimeout && cfg.MaxTotalTimeout > 0 {
 		prompt += DynamicTimeoutPromptSuffix(int(cfg.MaxTotalTimeout.Minutes()))
 	}
+	if len(cfg.Probes) > 0 {
+		prompt += fmt.Sprintf("\n\nGAME STATE PROBES: This project exposes the game's internal state through read_game_state (%s). Read it before and after actions that should change the state (bets, spins, purchases, level changes) and report mismatches between the probes and what the screen shows.", strings.Join(cfg.Probes.Names(), ", "))
+	}
 	return prompt
 }
 
@@ -25,11 +25,32 @@ type AIConfig struct {
 	APIKey      string  `yaml:"apiKey"`      // Can use ${ENV_VAR} syntax
 	Temperature float64 `yaml:"temperature"` // 0.0 - 1.0
 	MaxTokens   int     `yaml:"maxTokens"`   // Max response tokens
+	BaseURL     string  `yaml:"baseUrl,omitempty"` // OpenAI-compatible endpoint (openai provider only)
 
 	// Secondary model for synthesis/flow generation (text-only stages)
 	SynthesisProvider string `yaml:"synthesisProvider,omitempty"` // defaults to primary provider
 	SynthesisModel    string `yaml:"synthesisModel,omitempty"`    // if set, enables hybrid mode
 	SynthesisAPIKey   string `yaml:"synthesisApiKey,omitempty"`   // defaults to primary apiKey
+
+	// Ordered fallback chain tried after the primary provider/model fails with
+	// retryable errors (overloaded, rate limited, 5xx)
+	Fallbacks []AIFallbackConfig `yaml:"fallbacks,omitempty"`
+
+	// Record/replay AI responses for offline, deterministic runs
+	Cassette    string `yaml:"cassette,omitempty"`    // "", record, replay
+	CassetteDir string `yaml:"cassetteDir,omitempty"` // directory holding recorded responses
+
+	// YAML/JSON model pricing table merged over the built-in prices
+	// (WIZARDS_QA_PRICING overrides it)
+	PricingFile string `yaml:"pricingFile,omitempty"`
+}
+
+// AIFallbackConfig is one provider/model entry in the AI fallback chain
+type AIFallbackConfig struct {
+	Provider string `yaml:"provider,omitempty"` // detected from the model name when empty
+	Model    string `yaml:"model"`
+	APIKey   string `yaml:"apiKey,omitempty"`  // defaults to the provider's *_API_KEY env var
+	BaseURL  string `yaml:"baseUrl,omitempty"` // OpenAI-compatible endpoint (openai provider only)
 }
 
 // MaestroConfig contains Maestro CLI settings
@@ -65,6 +86,10 @@ type BrowserConfig struct {
 		Height int `yaml:"height"`
 	} `yaml:"viewport"`
 	Timeout time.Duration `yaml:"timeout"` // Page load timeout
+
+	// YAML/JSON viewport presets merged over the built-in ones
+	// (WIZARDS_QA_VIEWPORTS overrides it)
+	ViewportsFile string `yaml:"viewportsFile,omitempty"`
 }
 
 // DefaultConfig returns a config with sensible defaults
@@ -181,6 +206,11 @@ func (c *Config) Validate() error {
 	if c.AI.Model == "" {
 		return fmt.Errorf("ai.model is required")
 	}
+	for i, fb := range c.AI.Fallbacks {
+		if fb.Model == "" {
+			return fmt.Errorf("ai.fallbacks[%d].model is required", i)
+		}
+	}
 	if c.AI.Temperature < 0 || c.AI.Temperature > 1 {
 		return fmt.Errorf("ai.temperature must be between 0 and 1")
 	}
@@ -209,15 +239,23 @@ func (c *Config) Validate() error {
 // expandEnvVars expands environment variables in string fields
 func (c *Config) expandEnvVars() {
 	c.AI.APIKey = os.ExpandEnv(c.AI.APIKey)
+	c.AI.BaseURL = os.ExpandEnv(c.AI.BaseURL)
 	c.AI.SynthesisProvider = os.ExpandEnv(c.AI.SynthesisProvider)
 	c.AI.SynthesisModel = os.ExpandEnv(c.AI.SynthesisModel)
 	c.AI.SynthesisAPIKey = os.ExpandEnv(c.AI.SynthesisAPIKey)
+	c.AI.CassetteDir = os.ExpandEnv(c.AI.CassetteDir)
+	c.AI.PricingFile = os.ExpandEnv(c.AI.PricingFile)
+	for i := range c.AI.Fallbacks {
+		c.AI.Fallbacks[i].APIKey = os.ExpandEnv(c.AI.Fallbacks[i].APIKey)
+		c.AI.Fallbacks[i].BaseURL = os.ExpandEnv(c.AI.Fallbacks[i].BaseURL)
+	}
 	c.Maestro.Path = os.ExpandEnv(c.Maestro.Path)
 	c.Maestro.ScreenshotDir = os.ExpandEnv(c.Maestro.ScreenshotDir)
 	c.Flows.Directory = os.ExpandEnv(c.Flows.Directory)
 	c.Flows.Templates = os.ExpandEnv(c.Flows.Templates)
 	c.Flows.GitRepo = os.ExpandEnv(c.Flows.GitRepo)
 	c.Reporting.OutputDir = os.ExpandEnv(c.Reporting.OutputDir)
+	c.Browser.ViewportsFile = os.ExpandEnv(c.Browser.ViewportsFile)
 }
 
 // findConfigFile searches for wizards-qa.yaml in common locations
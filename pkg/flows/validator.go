@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
 	"gopkg.in/yaml.v3"
 )
 
@@ -20,12 +21,25 @@ func NewValidator() *Validator {
 		AllowedCommands: map[string]bool{
 			"launchApp":         true,
 			"tapOn":             true,
+			"longPressOn":       true,
 			"inputText":         true,
 			"assertVisible":     true,
 			"assertNotVisible":  true,
 			"extendedWaitUntil": true,
 			"scroll":            true,
 			"swipe":             true,
+			"pinch":             true,
+			"rotate":            true,
+			"twoFingerPan":      true,
+			"keyDown":           true,
+			"keyUp":             true,
+			"holdKey":           true,
+			"holdKeys":          true,
+			"assertState":       true,
+			"setNetwork":        true,
+			"advanceTime":       true,
+			"pauseGame":         true,
+			"rotateDevice":      true,
 			"back":              true,
 			"takeScreenshot":    true,
 			"openLink":          true,
@@ -186,32 +200,107 @@ func (v *Validator) ValidateCommands(flow *Flow, result *ValidationResult) {
 // validateSpecificCommand validates specific command parameters
 func (v *Validator) validateSpecificCommand(cmdName string, value interface{}, cmdNum int, result *ValidationResult) {
 	switch cmdName {
-	case "tapOn":
+	case "tapOn", "longPressOn":
 		// tapOn should have either a string (text) or map (point, etc.)
 		switch val := value.(type) {
 		case string:
 			if val == "" {
-				result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (tapOn): empty text selector", cmdNum))
+				result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (%s): empty text selector", cmdNum, cmdName))
 			}
 		case map[string]interface{}:
 			// Warn on invalid visible/notVisible fields (only valid in extendedWaitUntil)
 			if _, hasVisible := val["visible"]; hasVisible {
-				result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (tapOn): 'visible' is not a valid selector — use tapOn: \"text\" instead", cmdNum))
+				result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (%s): 'visible' is not a valid selector — use %s: \"text\" instead", cmdNum, cmdName, cmdName))
 			}
 			if _, hasNotVisible := val["notVisible"]; hasNotVisible {
-				result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (tapOn): 'notVisible' is not a valid selector — use tapOn: \"text\" instead", cmdNum))
+				result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (%s): 'notVisible' is not a valid selector — use %s: \"text\" instead", cmdNum, cmdName, cmdName))
 			}
 			// Check for point coordinates
 			if point, ok := val["point"]; ok {
 				if pointStr, ok := point.(string); ok {
 					// Validate point format (e.g., "50%,50%" or "100,200")
 					if !strings.Contains(pointStr, ",") {
-						result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (tapOn): point should be 'x,y' format", cmdNum))
+						result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (%s): point should be 'x,y' format", cmdNum, cmdName))
 					}
 				}
 			}
 		}
 
+	case "pinch", "rotate", "twoFingerPan":
+		// Browser-runner extensions, performed with two touch points
+		required := map[string]string{"pinch": "scale", "rotate": "degrees", "twoFingerPan": "end"}[cmdName]
+		m, _ := value.(map[string]interface{})
+		if _, ok := m[required]; !ok {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (%s): missing '%s'", cmdNum, cmdName, required))
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (%s): only supported by the browser runner on phone/tablet viewports", cmdNum, cmdName))
+
+	case "keyDown", "keyUp", "holdKey", "holdKeys":
+		// Browser-runner extensions for held keys and chords
+		result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (%s): only supported by the browser runner", cmdNum, cmdName))
+
+	case "assertState":
+		// Browser-runner extension: compares a project game-state probe
+		m, _ := value.(map[string]interface{})
+		if probe, _ := m["probe"].(string); probe == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("command %d (assertState): missing 'probe'", cmdNum))
+		}
+		_, hasEquals := m["equals"]
+		_, hasGt := m["gt"]
+		_, hasLt := m["lt"]
+		if !hasEquals && !hasGt && !hasLt {
+			result.Errors = append(result.Errors, fmt.Sprintf("command %d (assertState): requires 'equals', 'gt' or 'lt'", cmdNum))
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (assertState): only supported by the browser runner", cmdNum))
+
+	case "setNetwork":
+		// Browser-runner extension: emulates offline, slow-3g, fast-3g or a custom connection
+		profile, _ := value.(string)
+		if m, ok := value.(map[string]interface{}); ok {
+			profile, _ = m["profile"].(string)
+		}
+		if profile == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("command %d (setNetwork): missing profile (online, offline, slow-3g, fast-3g or custom)", cmdNum))
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (setNetwork): only supported by the browser runner", cmdNum))
+
+	case "advanceTime":
+		// Browser-runner extension: fast-forwards the page clock (CDP virtual time)
+		ms := 0
+		switch v := value.(type) {
+		case int:
+			ms = v
+		case float64:
+			ms = int(v)
+		case map[string]interface{}:
+			switch n := v["ms"].(type) {
+			case int:
+				ms = n
+			case float64:
+				ms = int(n)
+			}
+		}
+		if ms <= 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("command %d (advanceTime): requires a positive number of milliseconds", cmdNum))
+		} else if max := int(scout.MaxAdvanceTime.Milliseconds()); ms > max {
+			result.Errors = append(result.Errors, fmt.Sprintf("command %d (advanceTime): %dms exceeds the %dms maximum per command", cmdNum, ms, max))
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (advanceTime): only supported by the browser runner", cmdNum))
+
+	case "pauseGame":
+		// Browser-runner extension: freezes (true) or resumes (false) the page clock
+		if _, ok := value.(bool); !ok {
+			result.Errors = append(result.Errors, fmt.Sprintf("command %d (pauseGame): value must be true or false", cmdNum))
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (pauseGame): only supported by the browser runner", cmdNum))
+
+	case "rotateDevice":
+		// Browser-runner extension: turns a phone or tablet to portrait or landscape
+		if o, _ := value.(string); o != "portrait" && o != "landscape" {
+			result.Errors = append(result.Errors, fmt.Sprintf("command %d (rotateDevice): value must be portrait or landscape", cmdNum))
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("command %d (rotateDevice): only supported by the browser runner on phone and tablet viewports", cmdNum))
+
 	case "inputText":
 		// inputText should have a string value
 		if str, ok := value.(string); ok {
@@ -3,15 +3,24 @@ package scout
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
 )
 
-// ClickStrategy defines how clicks are dispatched to the page.
+// ClickStrategy defines how clicks and pointer gestures are dispatched to the page.
 // Different game types require different click mechanisms for reliable input.
 type ClickStrategy interface {
 	Click(page *rod.Page, x, y int) error
+	// Drag presses at (fromX,fromY), moves to (toX,toY) over duration and releases.
+	Drag(page *rod.Page, fromX, fromY, toX, toY int, duration time.Duration) error
+	// LongPress presses at (x,y) and releases after duration without moving.
+	LongPress(page *rod.Page, x, y int, duration time.Duration) error
+	// Hover moves the pointer to (x,y) without pressing.
+	Hover(page *rod.Page, x, y int) error
+	// MoveMouse moves the pointer through path without pressing.
+	MoveMouse(page *rod.Page, path []Point) error
 	Name() string
 }
 
@@ -271,12 +280,22 @@ func isCanvasFramework(framework string) bool {
 // events, canvas/WebGL games get trusted CDP mouse events, and pure HTML games
 // get JS dispatch.
 func SelectClickStrategy(meta *PageMeta, viewportWidth int, deviceCategory string) ClickStrategy {
+	return selectClickStrategy(meta, isTouchDevice(viewportWidth, deviceCategory, false))
+}
+
+// isTouchDevice returns true if a viewport emulates a touch device: its preset
+// forces touch, its category is a phone or tablet, or it has no category and
+// is at most 480px wide (e.g. CLI without preset). Pages decide this once at
+// launch, so rotating a narrow viewport to landscape keeps touch input.
+func isTouchDevice(viewportWidth int, deviceCategory string, touch bool) bool {
+	return touch || isTouchCategory(deviceCategory) || (deviceCategory == "" && viewportWidth <= 480)
+}
+
+// selectClickStrategy is SelectClickStrategy for a page whose touch input was
+// already decided by isTouchDevice.
+func selectClickStrategy(meta *PageMeta, touch bool) ClickStrategy {
 	// Touch devices: phones + tablets always use touch
-	if isTouchCategory(deviceCategory) {
-		return &CDPTouchStrategy{}
-	}
-	// Fallback width heuristic for unknown category (e.g. CLI without preset)
-	if deviceCategory == "" && viewportWidth <= 480 {
+	if touch {
 		return &CDPTouchStrategy{}
 	}
 	if meta.CanvasFound {
@@ -0,0 +1,138 @@
+package scout
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ViewportsEnvVar names the environment variable that points at a viewport
+// presets file. It takes precedence over the browser.viewportsFile config
+// setting.
+const ViewportsEnvVar = "WIZARDS_QA_VIEWPORTS"
+
+// customViewportCategory is the category of custom presets that leave it unset.
+const customViewportCategory = "Custom"
+
+const (
+	maxViewportSize = 7680 // pixels per side (8K)
+	maxViewportDPR  = 5
+)
+
+var viewportNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// ViewportFile is the on-disk viewport presets table (YAML or JSON). Presets
+// are merged over the built-in ones by name; portrait touch presets get a
+// "-landscape" variant like the built-in phones and tablets.
+//
+//	viewports:
+//	  - name: steam-deck
+//	    label: Steam Deck
+//	    category: Handheld
+//	    width: 1280
+//	    height: 800
+//	    devicePixelRatio: 1
+//	    touch: true
+//	    userAgent: "Mozilla/5.0 (X11; Linux x86_64) ..."
+type ViewportFile struct {
+	Viewports []ViewportPreset `yaml:"viewports" json:"viewports"`
+}
+
+var (
+	viewportMu      sync.RWMutex
+	customViewports []ViewportPreset
+)
+
+// Validate checks that the preset has a usable name, size and pixel ratio.
+func (p ViewportPreset) Validate() error {
+	if !viewportNameRe.MatchString(p.Name) {
+		return fmt.Errorf("viewport name %q must be lowercase letters, digits, '.', '_' or '-'", p.Name)
+	}
+	if p.Width <= 0 || p.Width > maxViewportSize || p.Height <= 0 || p.Height > maxViewportSize {
+		return fmt.Errorf("viewport %s: width and height must be between 1 and %d, got %dx%d", p.Name, maxViewportSize, p.Width, p.Height)
+	}
+	if p.DevicePixelRatio < 0 || p.DevicePixelRatio > maxViewportDPR {
+		return fmt.Errorf("viewport %s: devicePixelRatio must be between 0 and %d, got %g", p.Name, maxViewportDPR, p.DevicePixelRatio)
+	}
+	return nil
+}
+
+// ParseViewportPresets parses custom presets from YAML or JSON, either a
+// ViewportFile or a bare list, and adds their landscape variants. Unset
+// labels default to the name, categories to "Custom" and pixel ratios to 1.
+func ParseViewportPresets(data []byte) ([]ViewportPreset, error) {
+	// JSON is valid YAML, so one decoder handles both formats
+	var file ViewportFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		if listErr := yaml.Unmarshal(data, &file.Viewports); listErr != nil {
+			return nil, fmt.Errorf("parsing viewport presets: %w", err)
+		}
+	}
+	seen := make(map[string]bool, len(file.Viewports))
+	for i := range file.Viewports {
+		p := &file.Viewports[i]
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("viewport %s is defined twice", p.Name)
+		}
+		seen[p.Name] = true
+		if p.Label == "" {
+			p.Label = p.Name
+		}
+		if p.Category == "" {
+			p.Category = customViewportCategory
+		}
+		if p.DevicePixelRatio == 0 {
+			p.DevicePixelRatio = 1
+		}
+	}
+	return withLandscapeVariants(file.Viewports), nil
+}
+
+// LoadViewportFile reads a YAML or JSON viewport presets file and merges it
+// over the built-in presets. A preset in the file replaces the built-in one
+// with the same name.
+func LoadViewportFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading viewports file: %w", err)
+	}
+	presets, err := ParseViewportPresets(data)
+	if err != nil {
+		return fmt.Errorf("viewports file %s: %w", path, err)
+	}
+	viewportMu.Lock()
+	customViewports = presets
+	viewportMu.Unlock()
+	return nil
+}
+
+// ViewportPresets returns every preset available to a project: the built-in
+// ones, overridden by those loaded with LoadViewportFile, overridden by the
+// project's own. Overrides keep the position of the preset they replace.
+func ViewportPresets(project []ViewportPreset) []ViewportPreset {
+	viewportMu.RLock()
+	defer viewportMu.RUnlock()
+	return mergeViewportPresets(viewportPresets, customViewports, project)
+}
+
+func mergeViewportPresets(layers ...[]ViewportPreset) []ViewportPreset {
+	var out []ViewportPreset
+	index := make(map[string]int)
+	for _, layer := range layers {
+		for _, p := range layer {
+			if i, ok := index[p.Name]; ok {
+				out[i] = p
+				continue
+			}
+			index[p.Name] = len(out)
+			out = append(out, p)
+		}
+	}
+	return out
+}
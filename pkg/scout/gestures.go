@@ -0,0 +1,238 @@
+package scout
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// gestureFrame is the interval between intermediate pointer moves, roughly one
+// frame at 60fps so games polling input per frame see a continuous motion.
+const gestureFrame = 16 * time.Millisecond
+
+// gestureSteps returns how many intermediate moves a gesture of the given
+// duration is split into: one per frame, between 5 and 60.
+func gestureSteps(duration time.Duration) int {
+	n := int(duration / gestureFrame)
+	if n < 5 {
+		return 5
+	}
+	if n > 60 {
+		return 60
+	}
+	return n
+}
+
+// gesturePath returns n points evenly spaced from (x0,y0), exclusive, to
+// (x1,y1), inclusive.
+func gesturePath(x0, y0, x1, y1 float64, n int) [][2]float64 {
+	path := make([][2]float64, n)
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		path[i-1] = [2]float64{
+			math.Round(x0 + (x1-x0)*t),
+			math.Round(y0 + (y1-y0)*t),
+		}
+	}
+	return path
+}
+
+// cachedViewport returns the viewport size cached in w/h, fetching and caching
+// it on first use.
+func cachedViewport(page *rod.Page, w, h *int) (int, int) {
+	if *w != 0 {
+		return *w, *h
+	}
+	vpW, vpH, ok := evalViewportSize(page)
+	if ok {
+		*w, *h = vpW, vpH // cache only on success
+	}
+	return vpW, vpH
+}
+
+// Drag presses the left mouse button at (fromX,fromY), moves to (toX,toY) over
+// the given duration with the button held, and releases it there.
+func (s *CDPMouseStrategy) Drag(page *rod.Page, fromX, fromY, toX, toY int, duration time.Duration) error {
+	vpW, vpH := cachedViewport(page, &s.vpWidth, &s.vpHeight)
+	fx, fy := clampCoords(fromX, fromY, vpW, vpH)
+	tx, ty := clampCoords(toX, toY, vpW, vpH)
+
+	if err := mouseDown(page, float64(fx), float64(fy)); err != nil {
+		return fmt.Errorf("cdp mouse drag from (%d,%d): %w", fromX, fromY, err)
+	}
+	steps := gestureSteps(duration)
+	for _, p := range gesturePath(float64(fx), float64(fy), float64(tx), float64(ty), steps) {
+		time.Sleep(duration / time.Duration(steps))
+		if err := (proto.InputDispatchMouseEvent{
+			Type:    proto.InputDispatchMouseEventTypeMouseMoved,
+			X:       p[0],
+			Y:       p[1],
+			Button:  proto.InputMouseButtonLeft,
+			Buttons: intPtr(1),
+		}).Call(page); err != nil {
+			_ = mouseUp(page, p[0], p[1])
+			return fmt.Errorf("cdp mouse drag move to (%.0f,%.0f): %w", p[0], p[1], err)
+		}
+	}
+	if err := mouseUp(page, float64(tx), float64(ty)); err != nil {
+		return fmt.Errorf("cdp mouse drag release at (%d,%d): %w", toX, toY, err)
+	}
+	return nil
+}
+
+// LongPress holds the left mouse button at (x,y) for the given duration.
+func (s *CDPMouseStrategy) LongPress(page *rod.Page, x, y int, duration time.Duration) error {
+	vpW, vpH := cachedViewport(page, &s.vpWidth, &s.vpHeight)
+	cx, cy := clampCoords(x, y, vpW, vpH)
+	fx, fy := float64(cx), float64(cy)
+
+	if err := mouseDown(page, fx, fy); err != nil {
+		return fmt.Errorf("cdp mouse long press at (%d,%d): %w", x, y, err)
+	}
+	time.Sleep(duration)
+	if err := mouseUp(page, fx, fy); err != nil {
+		return fmt.Errorf("cdp mouse long press release at (%d,%d): %w", x, y, err)
+	}
+	return nil
+}
+
+// mouseDown moves the cursor to (x,y) and presses the left button there.
+func mouseDown(page *rod.Page, x, y float64) error {
+	_ = (proto.InputDispatchMouseEvent{
+		Type: proto.InputDispatchMouseEventTypeMouseMoved,
+		X:    x,
+		Y:    y,
+	}).Call(page)
+	return (proto.InputDispatchMouseEvent{
+		Type:       proto.InputDispatchMouseEventTypeMousePressed,
+		X:          x,
+		Y:          y,
+		Button:     proto.InputMouseButtonLeft,
+		Buttons:    intPtr(1),
+		ClickCount: 1,
+	}).Call(page)
+}
+
+// mouseUp releases the left button at (x,y).
+func mouseUp(page *rod.Page, x, y float64) error {
+	return (proto.InputDispatchMouseEvent{
+		Type:       proto.InputDispatchMouseEventTypeMouseReleased,
+		X:          x,
+		Y:          y,
+		Button:     proto.InputMouseButtonLeft,
+		Buttons:    intPtr(0),
+		ClickCount: 1,
+	}).Call(page)
+}
+
+// Drag touches (fromX,fromY), slides the touch point to (toX,toY) over the
+// given duration and lifts it there.
+func (s *CDPTouchStrategy) Drag(page *rod.Page, fromX, fromY, toX, toY int, duration time.Duration) error {
+	vpW, vpH := cachedViewport(page, &s.vpWidth, &s.vpHeight)
+	fx, fy := clampCoords(fromX, fromY, vpW, vpH)
+	tx, ty := clampCoords(toX, toY, vpW, vpH)
+
+	if err := touchEvent(page, proto.InputDispatchTouchEventTypeTouchStart, float64(fx), float64(fy)); err != nil {
+		return fmt.Errorf("cdp touch drag from (%d,%d): %w", fromX, fromY, err)
+	}
+	steps := gestureSteps(duration)
+	for _, p := range gesturePath(float64(fx), float64(fy), float64(tx), float64(ty), steps) {
+		time.Sleep(duration / time.Duration(steps))
+		if err := touchEvent(page, proto.InputDispatchTouchEventTypeTouchMove, p[0], p[1]); err != nil {
+			_ = touchEnd(page)
+			return fmt.Errorf("cdp touch drag move to (%.0f,%.0f): %w", p[0], p[1], err)
+		}
+	}
+	if err := touchEnd(page); err != nil {
+		return fmt.Errorf("cdp touch drag end at (%d,%d): %w", toX, toY, err)
+	}
+	return nil
+}
+
+// LongPress keeps a touch point down at (x,y) for the given duration.
+func (s *CDPTouchStrategy) LongPress(page *rod.Page, x, y int, duration time.Duration) error {
+	vpW, vpH := cachedViewport(page, &s.vpWidth, &s.vpHeight)
+	cx, cy := clampCoords(x, y, vpW, vpH)
+
+	if err := touchEvent(page, proto.InputDispatchTouchEventTypeTouchStart, float64(cx), float64(cy)); err != nil {
+		return fmt.Errorf("cdp touch long press at (%d,%d): %w", x, y, err)
+	}
+	time.Sleep(duration)
+	if err := touchEnd(page); err != nil {
+		return fmt.Errorf("cdp touch long press end at (%d,%d): %w", x, y, err)
+	}
+	return nil
+}
+
+// touchEvent dispatches a single-finger touch event at (x,y).
+func touchEvent(page *rod.Page, typ proto.InputDispatchTouchEventType, x, y float64) error {
+	return (proto.InputDispatchTouchEvent{
+		Type:        typ,
+		TouchPoints: []*proto.InputTouchPoint{{X: x, Y: y}},
+	}).Call(page)
+}
+
+// touchEnd lifts every active touch point.
+func touchEnd(page *rod.Page) error {
+	return (proto.InputDispatchTouchEvent{
+		Type:        proto.InputDispatchTouchEventTypeTouchEnd,
+		TouchPoints: []*proto.InputTouchPoint{},
+	}).Call(page)
+}
+
+// jsPointerGesture dispatches a press at (x0,y0), moves to (x1,y1) in the given
+// number of steps, holds for holdMs and releases. All events go to the element
+// pressed first, as with implicit pointer capture. No click is fired.
+const jsPointerGesture = `async (x0, y0, x1, y1, steps, stepMs, holdMs) => {
+	const clampX = x => Math.max(0, Math.min(x, window.innerWidth - 1));
+	const clampY = y => Math.max(0, Math.min(y, window.innerHeight - 1));
+	x0 = clampX(x0); y0 = clampY(y0); x1 = clampX(x1); y1 = clampY(y1);
+	const el = document.elementFromPoint(x0, y0) || document.querySelector('canvas') || document.body;
+	if (!el) return 'no_element';
+	const sleep = ms => new Promise(r => setTimeout(r, ms));
+	const fire = (ptrType, mouseType, x, y, buttons) => {
+		const shared = { clientX: x, clientY: y, bubbles: true, cancelable: true, view: window };
+		el.dispatchEvent(new PointerEvent(ptrType, { ...shared, pointerId: 1, pointerType: 'mouse', isPrimary: true, button: 0, buttons }));
+		el.dispatchEvent(new MouseEvent(mouseType, { ...shared, button: 0, buttons }));
+	};
+	fire('pointermove', 'mousemove', x0, y0, 0);
+	fire('pointerdown', 'mousedown', x0, y0, 1);
+	for (let i = 1; i <= steps; i++) {
+		await sleep(stepMs);
+		fire('pointermove', 'mousemove', Math.round(x0 + (x1 - x0) * i / steps), Math.round(y0 + (y1 - y0) * i / steps), 1);
+	}
+	if (holdMs > 0) await sleep(holdMs);
+	fire('pointerup', 'mouseup', x1, y1, 0);
+	return 'ok';
+}`
+
+// Drag dispatches a pointer/mouse down, move and up sequence via JavaScript.
+func (s *JSDispatchStrategy) Drag(page *rod.Page, fromX, fromY, toX, toY int, duration time.Duration) error {
+	steps := gestureSteps(duration)
+	stepMs := int(duration.Milliseconds()) / steps
+	return jsGesture(page, fmt.Sprintf("drag from (%d,%d) to (%d,%d)", fromX, fromY, toX, toY),
+		fromX, fromY, toX, toY, steps, stepMs, 0)
+}
+
+// LongPress dispatches a pointer/mouse down, waits, and releases via JavaScript.
+func (s *JSDispatchStrategy) LongPress(page *rod.Page, x, y int, duration time.Duration) error {
+	return jsGesture(page, fmt.Sprintf("long press at (%d,%d)", x, y),
+		x, y, x, y, 0, 0, int(duration.Milliseconds()))
+}
+
+func jsGesture(page *rod.Page, what string, x0, y0, x1, y1, steps, stepMs, holdMs int) error {
+	result, err := page.Eval(jsPointerGesture, x0, y0, x1, y1, steps, stepMs, holdMs)
+	if err != nil {
+		return fmt.Errorf("%s: %w", what, err)
+	}
+	if result == nil || result.Value.Str() == "no_element" {
+		return fmt.Errorf("%s: no element at coordinates", what)
+	}
+	return nil
+}
+
+// intPtr returns a pointer to n, for optional CDP integer fields.
+func intPtr(n int) *int { return &n }
@@ -0,0 +1,188 @@
+package scout
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HAR is an HTTP Archive (HAR 1.2) document built from a network log. It
+// carries request/response metadata only; bodies are not captured and
+// credentials (cookies, authorization headers) are redacted.
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ResourceType    string      `json:"_resourceType,omitempty"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+	Error       string         `json:"_error,omitempty"` // net::ERR_* text for failed loads
+}
+
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARTimings only splits out the total wait: CDP timing phases are not kept
+// in the network log.
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harRedacted replaces the value of credential headers in a HAR.
+const harRedacted = "[redacted]"
+
+// harSensitiveHeaders are the headers whose values BuildHAR redacts, keyed by
+// lower-case name. HARs are downloadable and often attached to bug reports, so
+// session cookies and tokens must not end up in them.
+var harSensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+}
+
+// BuildHAR converts network entries into a HAR document. Cookie and
+// authorization header values are replaced with "[redacted]".
+func BuildHAR(entries []NetworkEntry) *HAR {
+	h := &HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "wizards-qa", Version: "1.0"},
+		Entries: make([]HAREntry, 0, len(entries)),
+	}}
+	for _, e := range entries {
+		httpVersion := e.Protocol
+		if httpVersion == "" {
+			httpVersion = "HTTP/1.1"
+		}
+		contentSize := e.Size
+		if e.Failed {
+			contentSize = 0
+		}
+		h.Log.Entries = append(h.Log.Entries, HAREntry{
+			StartedDateTime: e.StartedAt.UTC().Format(time.RFC3339Nano),
+			Time:            e.DurationMs,
+			Request: HARRequest{
+				Method:      e.Method,
+				URL:         e.URL,
+				HTTPVersion: httpVersion,
+				Cookies:     []HARNameValue{},
+				Headers:     harHeaders(e.RequestHeaders),
+				QueryString: harQuery(e.URL),
+				HeadersSize: -1,
+				BodySize:    -1,
+			},
+			Response: HARResponse{
+				Status:      e.Status,
+				StatusText:  e.StatusText,
+				HTTPVersion: httpVersion,
+				Cookies:     []HARNameValue{},
+				Headers:     harHeaders(e.ResponseHeaders),
+				Content:     HARContent{Size: contentSize, MimeType: e.MimeType},
+				RedirectURL: e.RedirectURL,
+				HeadersSize: -1,
+				BodySize:    contentSize,
+				Error:       e.ErrorText,
+			},
+			Timings:      HARTimings{Wait: e.DurationMs},
+			ResourceType: strings.ToLower(e.ResourceType),
+		})
+	}
+	return h
+}
+
+// HAR returns the page's network log as a HAR document.
+func (r *RodBrowserPage) HAR() *HAR {
+	entries, _ := r.GetNetworkLog()
+	return BuildHAR(entries)
+}
+
+// SaveHAR writes the page's network log to path as a HAR file.
+func (r *RodBrowserPage) SaveHAR(path string) error {
+	data, err := json.MarshalIndent(r.HAR(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding HAR: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing HAR: %w", err)
+	}
+	return nil
+}
+
+func harHeaders(h map[string]string) []HARNameValue {
+	out := make([]HARNameValue, 0, len(h))
+	for k, v := range h {
+		if harSensitiveHeaders[strings.ToLower(k)] {
+			v = harRedacted
+		}
+		out = append(out, HARNameValue{Name: k, Value: v})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func harQuery(rawURL string) []HARNameValue {
+	out := []HARNameValue{}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return out
+	}
+	for k, vs := range u.Query() {
+		for _, v := range vs {
+			out = append(out, HARNameValue{Name: k, Value: v})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -17,13 +17,19 @@ import (
 
 // RodBrowserPage wraps a *rod.Page to implement the ai.BrowserPage interface.
 type RodBrowserPage struct {
-	page           *rod.Page
-	clickStrategy  ClickStrategy
-	consoleLogs    []string
-	mu             sync.Mutex
-	viewportWidth  int    // stored for strategy re-detection
-	viewportHeight int    // stored for CDP screenshot downscale
-	deviceCategory string // stored for strategy re-detection
+	page             *rod.Page
+	clickStrategy    ClickStrategy
+	consoleLogs      []string
+	network          *networkLog // nil for pages not set up by ScoutURLHeadlessKeepAlive
+	networkProfile   NetworkProfile
+	timeSession      *rod.Page // CDP session holding virtual time; nil while on real time
+	timePaused       bool
+	mu               sync.Mutex
+	viewportWidth    int     // stored for strategy re-detection
+	viewportHeight   int     // stored for CDP screenshot downscale
+	devicePixelRatio float64 // kept when rotating the viewport
+	touch            bool    // emulates a touch device; decided at launch, kept across rotations
+	modifiers        int     // CDP modifier flags of the modifier keys currently held
 }
 
 // NewRodBrowserPage creates a new RodBrowserPage wrapping the given rod page.
@@ -56,6 +62,8 @@ func (r *RodBrowserPage) CaptureScreenshot() (string, error) {
 		const c = document.querySelector('canvas');
 		if (c && c.width > 0 && c.height > 0) {
 			try {
+				// The read stalls the main thread; keep that frame out of the performance sampler
+				if (window.__wqaPerf) window.__wqaPerf.skipNext = true;
 				const g = window.game;
 				const paused = g && g.loop && typeof g.loop.sleep === 'function';
 				if (paused) g.loop.sleep();
@@ -138,6 +146,63 @@ func (r *RodBrowserPage) Click(x, y int) error {
 	return s.Click(r.page, x, y)
 }
 
+// Drag drags from one point to another over the given duration using the
+// configured click strategy (mouse for desktop, touch for mobile viewports).
+func (r *RodBrowserPage) Drag(fromX, fromY, toX, toY int, duration time.Duration) error {
+	s := r.clickStrategy
+	if s == nil {
+		s = &JSDispatchStrategy{}
+	}
+	return s.Drag(r.page, fromX, fromY, toX, toY, duration)
+}
+
+// LongPress presses and holds at the given coordinates for duration using the
+// configured click strategy.
+func (r *RodBrowserPage) LongPress(x, y int, duration time.Duration) error {
+	s := r.clickStrategy
+	if s == nil {
+		s = &JSDispatchStrategy{}
+	}
+	return s.LongPress(r.page, x, y, duration)
+}
+
+// Hover moves the pointer to the given coordinates without clicking, using the
+// configured click strategy, so hover states and tooltips appear.
+func (r *RodBrowserPage) Hover(x, y int) error {
+	s := r.clickStrategy
+	if s == nil {
+		s = &JSDispatchStrategy{}
+	}
+	return s.Hover(r.page, x, y)
+}
+
+// MoveMouse moves the pointer through the given points without clicking,
+// using the configured click strategy.
+func (r *RodBrowserPage) MoveMouse(path []Point) error {
+	s := r.clickStrategy
+	if s == nil {
+		s = &JSDispatchStrategy{}
+	}
+	return s.MoveMouse(r.page, path)
+}
+
+// MultiTouch performs a two-finger gesture (pinch, rotate or pan). It is only
+// available when the page uses touch input; otherwise it returns ErrTouchUnsupported.
+func (r *RodBrowserPage) MultiTouch(g TouchGesture) error {
+	touch, ok := r.clickStrategy.(*CDPTouchStrategy)
+	if !ok {
+		return ErrTouchUnsupported
+	}
+	return touch.MultiTouch(r.page, g)
+}
+
+// TouchEnabled reports whether input is dispatched as touch events, i.e. the
+// viewport is a phone or tablet.
+func (r *RodBrowserPage) TouchEnabled() bool {
+	_, ok := r.clickStrategy.(*CDPTouchStrategy)
+	return ok
+}
+
 // SetClickStrategy overrides the click strategy for this browser page.
 func (r *RodBrowserPage) SetClickStrategy(s ClickStrategy) {
 	r.clickStrategy = s
@@ -176,7 +241,9 @@ func (r *RodBrowserPage) RedetectClickStrategy() {
 		if (window.cc && window.cc.game) found.push("Cocos");
 		if (window.THREE) found.push("Three.js");
 		if (window.BABYLON) found.push("Babylon.js");
-		if (window.PlayCanvas) found.push("PlayCanvas");
+		if (window.PlayCanvas || (window.pc && window.pc.Application)) found.push("PlayCanvas " + ((window.pc && pc.version) || ""));
+		if (window.C3 || window.c3_runtimeInterface || window.cr_getC2Runtime) found.push("Construct");
+		if (window.unityInstance || window.createUnityInstance || window.UnityLoader) found.push("Unity");
 		return found;
 	}`)
 	if err == nil && globals != nil && globals.Value.Arr() != nil {
@@ -188,7 +255,7 @@ func (r *RodBrowserPage) RedetectClickStrategy() {
 		}
 		detectFrameworkFromGlobals(meta)
 	}
-	r.clickStrategy = SelectClickStrategy(meta, r.viewportWidth, r.deviceCategory)
+	r.clickStrategy = selectClickStrategy(meta, r.touch)
 }
 
 // TypeText types the given text by inserting it into the page.
@@ -216,6 +283,10 @@ var keyDefinitions = map[string]keyDef{
 	"ArrowRight": {Key: "ArrowRight", Code: "ArrowRight", KeyCode: 39},
 	"Backspace":  {Key: "Backspace", Code: "Backspace", KeyCode: 8},
 	"Delete":     {Key: "Delete", Code: "Delete", KeyCode: 46},
+	"Shift":      {Key: "Shift", Code: "ShiftLeft", KeyCode: 16},
+	"Control":    {Key: "Control", Code: "ControlLeft", KeyCode: 17},
+	"Alt":        {Key: "Alt", Code: "AltLeft", KeyCode: 18},
+	"Meta":       {Key: "Meta", Code: "MetaLeft", KeyCode: 91},
 }
 
 func init() {
@@ -231,32 +302,10 @@ func init() {
 	}
 }
 
-// PressKey dispatches a keyDown + keyUp event via CDP for the named key.
+// PressKey dispatches a keyDown + keyUp event via CDP for the named key or
+// chord (e.g. "Control+a").
 func (r *RodBrowserPage) PressKey(key string) error {
-	kd, ok := keyDefinitions[key]
-	if !ok {
-		return fmt.Errorf("unknown key: %q", key)
-	}
-	// keyDown
-	if err := (proto.InputDispatchKeyEvent{
-		Type:                  proto.InputDispatchKeyEventTypeKeyDown,
-		Key:                   kd.Key,
-		Code:                  kd.Code,
-		WindowsVirtualKeyCode: kd.KeyCode,
-		Text:                  kd.Text,
-	}).Call(r.page); err != nil {
-		return fmt.Errorf("key down %q: %w", key, err)
-	}
-	// keyUp
-	if err := (proto.InputDispatchKeyEvent{
-		Type:                  proto.InputDispatchKeyEventTypeKeyUp,
-		Key:                   kd.Key,
-		Code:                  kd.Code,
-		WindowsVirtualKeyCode: kd.KeyCode,
-	}).Call(r.page); err != nil {
-		return fmt.Errorf("key up %q: %w", key, err)
-	}
-	return nil
+	return r.HoldKey(key, 0)
 }
 
 // Scroll scrolls the page by the given delta in pixels.
@@ -327,9 +376,25 @@ func (r *RodBrowserPage) GetConsoleLogs() ([]string, error) {
 	return logs, nil
 }
 
+// GetNetworkLog returns the requests recorded since the page was opened, oldest
+// first. Unlike GetConsoleLogs it does not clear the buffer, so the full log can
+// still be exported as a HAR at the end of a run.
+func (r *RodBrowserPage) GetNetworkLog() ([]NetworkEntry, error) {
+	if r.network == nil {
+		return nil, nil
+	}
+	return r.network.snapshot(), nil
+}
+
 // Navigate navigates the page to the given URL and waits for load + idle.
 // After loading, re-detects the click strategy for the new page content.
+// A paused clock is resumed first, otherwise the page could never load.
 func (r *RodBrowserPage) Navigate(url string) error {
+	if r.TimePaused() {
+		if err := r.SetTimePaused(false); err != nil {
+			return err
+		}
+	}
 	if err := r.page.Navigate(url); err != nil {
 		return fmt.Errorf("navigate to %s: %w", url, err)
 	}
@@ -427,21 +492,41 @@ func ScoutURLHeadlessKeepAlive(ctx context.Context, gameURL string, cfg Headless
 	if dpr <= 0 {
 		dpr = 1
 	}
-	if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+	metrics := &proto.EmulationSetDeviceMetricsOverride{
 		Width:             width,
 		Height:            height,
 		DeviceScaleFactor: dpr,
-	}); err != nil {
+	}
+	if cfg.Touch || isTouchCategory(cfg.DeviceCategory) {
+		metrics.ScreenOrientation = screenOrientation(width, height)
+	}
+	if err := page.SetViewport(metrics); err != nil {
 		cleanup()
 		return nil, nil, nil, fmt.Errorf("setting viewport: %w", err)
 	}
+	if err := applyEmulation(browser, page, cfg); err != nil {
+		cleanup()
+		return nil, nil, nil, err
+	}
 
 	browserPage := &RodBrowserPage{
-		page:           page,
-		viewportWidth:  width,
-		viewportHeight: height,
-		deviceCategory: cfg.DeviceCategory,
-	}
+		page:             page,
+		viewportWidth:    width,
+		viewportHeight:   height,
+		devicePixelRatio: dpr,
+		touch:            isTouchDevice(width, cfg.DeviceCategory, cfg.Touch),
+		network:          newNetworkLog(),
+	}
+
+	// Record network traffic (URL, status, timing, size, failures) for the
+	// network_log tool and HAR export. EachEvent enables the Network domain
+	// before returning, so requests made by the navigation below are captured.
+	go page.EachEvent(
+		browserPage.network.onRequest,
+		browserPage.network.onResponse,
+		browserPage.network.onFinished,
+		browserPage.network.onFailed,
+	)()
 
 	// Block analytics/tracking network requests that waste CPU and bandwidth.
 	_ = proto.NetworkSetBlockedURLs{
@@ -469,6 +554,38 @@ func ScoutURLHeadlessKeepAlive(ctx context.Context, gameURL string, cfg Headless
 		};
 	`)
 
+	// Passive performance sampler (FPS, long tasks, WebGL draw calls) for
+	// measure_performance and the analysis performance section
+	_, _ = page.EvalOnNewDocument(perfSamplerScript)
+
+	// Seed the page's randomness so a run can be replayed with the same
+	// shuffles, spawns and rolls
+	if cfg.RandomSeed != nil {
+		if _, err := page.EvalOnNewDocument(seededRandomScript(*cfg.RandomSeed)); err != nil {
+			cleanup()
+			return nil, nil, nil, fmt.Errorf("seeding randomness: %w", err)
+		}
+	}
+
+	// Seed the saved player state (storage, cookies, IndexedDB) so the game
+	// boots as a returning player
+	if cfg.InitialStorage != nil {
+		if err := browserPage.SeedStorage(gameURL, *cfg.InitialStorage); err != nil {
+			cleanup()
+			return nil, nil, nil, err
+		}
+	}
+
+	// Throttle the connection before loading so slow profiles cover the game's
+	// boot. An offline profile is applied once the game has loaded instead,
+	// otherwise there would be nothing to explore.
+	if cfg.Network != nil && !cfg.Network.Offline {
+		if err := browserPage.SetNetwork(*cfg.Network); err != nil {
+			cleanup()
+			return nil, nil, nil, err
+		}
+	}
+
 	// Collect console logs for agent visibility (mirrors ScoutURLHeadless pattern).
 	// Cap at 2000 lines to prevent unbounded memory growth in long-running agent sessions.
 	const maxConsoleLines = 2000
@@ -557,7 +674,9 @@ func ScoutURLHeadlessKeepAlive(ctx context.Context, gameURL string, cfg Headless
 		if (window.cc && window.cc.game) found.push("Cocos");
 		if (window.THREE) found.push("Three.js");
 		if (window.BABYLON) found.push("Babylon.js");
-		if (window.PlayCanvas) found.push("PlayCanvas");
+		if (window.PlayCanvas || (window.pc && window.pc.Application)) found.push("PlayCanvas " + ((window.pc && pc.version) || ""));
+		if (window.C3 || window.c3_runtimeInterface || window.cr_getC2Runtime) found.push("Construct");
+		if (window.unityInstance || window.createUnityInstance || window.UnityLoader) found.push("Unity");
 		const canvases = document.querySelectorAll('canvas');
 		if (canvases.length > 0) found.push("canvas:" + canvases.length);
 		return found;
@@ -573,9 +692,16 @@ func ScoutURLHeadlessKeepAlive(ctx context.Context, gameURL string, cfg Headless
 	}
 
 	// Select click strategy based on detected framework, viewport, and device category
-	browserPage.clickStrategy = SelectClickStrategy(meta, width, cfg.DeviceCategory)
+	browserPage.clickStrategy = selectClickStrategy(meta, browserPage.touch)
 	meta.ClickStrategy = browserPage.clickStrategy.Name()
 
+	if cfg.Network != nil && cfg.Network.Offline {
+		if err := browserPage.SetNetwork(*cfg.Network); err != nil {
+			cleanup()
+			return nil, nil, nil, err
+		}
+	}
+
 	// Take initial screenshot via CaptureScreenshot (uses fast path + CDP fallback)
 	// Timeout prevents SwiftShader stalls from blocking the entire scout.
 	if shot := captureWithTimeout(browserPage, 20*time.Second); shot != "" {
@@ -612,6 +738,10 @@ func detectFrameworkFromGlobals(meta *PageMeta) {
 			meta.Framework = "babylon"
 		case strings.HasPrefix(gl, "playcanvas"):
 			meta.Framework = "playcanvas"
+		case strings.HasPrefix(gl, "construct"):
+			meta.Framework = "construct"
+		case strings.HasPrefix(gl, "unity"):
+			meta.Framework = "unity"
 		}
 	}
 }
@@ -659,13 +789,20 @@ func ScoutURLHeadless(ctx context.Context, gameURL string, cfg HeadlessConfig) (
 	if dpr <= 0 {
 		dpr = 1
 	}
-	if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+	metrics := &proto.EmulationSetDeviceMetricsOverride{
 		Width:             width,
 		Height:            height,
 		DeviceScaleFactor: dpr,
-	}); err != nil {
+	}
+	if cfg.Touch || isTouchCategory(cfg.DeviceCategory) {
+		metrics.ScreenOrientation = screenOrientation(width, height)
+	}
+	if err := page.SetViewport(metrics); err != nil {
 		return nil, fmt.Errorf("setting viewport: %w", err)
 	}
+	if err := applyEmulation(browser, page, cfg); err != nil {
+		return nil, err
+	}
 
 	// Collect console logs for framework clues (capped at 512KB)
 	const maxConsoleLogBytes = 512 * 1024
@@ -737,7 +874,9 @@ func ScoutURLHeadless(ctx context.Context, gameURL string, cfg HeadlessConfig) (
 		if (window.cc && window.cc.game) found.push("Cocos");
 		if (window.THREE) found.push("Three.js");
 		if (window.BABYLON) found.push("Babylon.js");
-		if (window.PlayCanvas) found.push("PlayCanvas");
+		if (window.PlayCanvas || (window.pc && window.pc.Application)) found.push("PlayCanvas " + ((window.pc && pc.version) || ""));
+		if (window.C3 || window.c3_runtimeInterface || window.cr_getC2Runtime) found.push("Construct");
+		if (window.unityInstance || window.createUnityInstance || window.UnityLoader) found.push("Unity");
 		const canvases = document.querySelectorAll('canvas');
 		if (canvases.length > 0) found.push("canvas:" + canvases.length);
 		return found;
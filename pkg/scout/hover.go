@@ -0,0 +1,142 @@
+package scout
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Point is a position on the page in CSS pixels.
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// hoverStepPixels is the spacing of the intermediate moves dispatched between
+// path points, so mouseenter/mouseleave fire for every element crossed.
+const hoverStepPixels = 20
+
+// pointerTrail expands a path into the positions to dispatch: every path point
+// plus intermediate positions at most hoverStepPixels apart.
+func pointerTrail(path []Point) []Point {
+	if len(path) == 0 {
+		return nil
+	}
+	trail := []Point{path[0]}
+	for i := 1; i < len(path); i++ {
+		from, to := path[i-1], path[i]
+		dist := math.Hypot(float64(to.X-from.X), float64(to.Y-from.Y))
+		steps := int(math.Ceil(dist / hoverStepPixels))
+		if steps < 1 {
+			steps = 1
+		}
+		for _, p := range gesturePath(float64(from.X), float64(from.Y), float64(to.X), float64(to.Y), steps) {
+			trail = append(trail, Point{int(p[0]), int(p[1])})
+		}
+	}
+	return trail
+}
+
+// moveMouse dispatches CDP mouseMoved events with no button pressed along the
+// path, one per frame, clamped to the viewport when its size is known.
+func moveMouse(page *rod.Page, path []Point, vpW, vpH int) error {
+	for i, p := range pointerTrail(path) {
+		if i > 0 {
+			time.Sleep(gestureFrame)
+		}
+		x, y := p.X, p.Y
+		if vpW > 0 {
+			x, y = clampCoords(x, y, vpW, vpH)
+		}
+		if err := (proto.InputDispatchMouseEvent{
+			Type: proto.InputDispatchMouseEventTypeMouseMoved,
+			X:    float64(x),
+			Y:    float64(y),
+		}).Call(page); err != nil {
+			return fmt.Errorf("cdp mouse move to (%d,%d): %w", x, y, err)
+		}
+	}
+	return nil
+}
+
+// Hover moves the mouse to (x,y) without pressing, triggering hover states.
+func (s *CDPMouseStrategy) Hover(page *rod.Page, x, y int) error {
+	return s.MoveMouse(page, []Point{{x, y}})
+}
+
+// MoveMouse moves the mouse through the path without pressing.
+func (s *CDPMouseStrategy) MoveMouse(page *rod.Page, path []Point) error {
+	vpW, vpH := cachedViewport(page, &s.vpWidth, &s.vpHeight)
+	return moveMouse(page, path, vpW, vpH)
+}
+
+// Hover moves a mouse pointer to (x,y). Touch devices have no hover, but the
+// mouse move still lets hover-only UI be inspected on mobile layouts.
+func (s *CDPTouchStrategy) Hover(page *rod.Page, x, y int) error {
+	return s.MoveMouse(page, []Point{{x, y}})
+}
+
+// MoveMouse moves a mouse pointer through the path without pressing.
+func (s *CDPTouchStrategy) MoveMouse(page *rod.Page, path []Point) error {
+	vpW, vpH := cachedViewport(page, &s.vpWidth, &s.vpHeight)
+	return moveMouse(page, path, vpW, vpH)
+}
+
+// jsHoverEvents dispatches pointer/mouse over, enter and move events on the
+// element at (x,y), and out/leave on the element hovered before it.
+const jsHoverEvents = `(x, y) => {
+	x = Math.max(0, Math.min(x, window.innerWidth - 1));
+	y = Math.max(0, Math.min(y, window.innerHeight - 1));
+	const el = document.elementFromPoint(x, y) || document.querySelector('canvas') || document.body;
+	if (!el) return 'no_element';
+	const shared = { clientX: x, clientY: y, bubbles: true, cancelable: true, view: window, button: 0, buttons: 0 };
+	const ptr = { ...shared, pointerId: 1, pointerType: 'mouse', isPrimary: true };
+	const prev = window.__wizardsHoverTarget;
+	if (prev && prev !== el) {
+		prev.dispatchEvent(new PointerEvent('pointerout', ptr));
+		prev.dispatchEvent(new PointerEvent('pointerleave', { ...ptr, bubbles: false }));
+		prev.dispatchEvent(new MouseEvent('mouseout', shared));
+		prev.dispatchEvent(new MouseEvent('mouseleave', { ...shared, bubbles: false }));
+	}
+	if (prev !== el) {
+		el.dispatchEvent(new PointerEvent('pointerover', ptr));
+		el.dispatchEvent(new PointerEvent('pointerenter', { ...ptr, bubbles: false }));
+		el.dispatchEvent(new MouseEvent('mouseover', shared));
+		el.dispatchEvent(new MouseEvent('mouseenter', { ...shared, bubbles: false }));
+		window.__wizardsHoverTarget = el;
+	}
+	el.dispatchEvent(new PointerEvent('pointermove', ptr));
+	el.dispatchEvent(new MouseEvent('mousemove', shared));
+	return 'ok';
+}`
+
+// Hover moves the mouse to (x,y) and dispatches hover events on the element
+// there via JavaScript.
+func (s *JSDispatchStrategy) Hover(page *rod.Page, x, y int) error {
+	return s.MoveMouse(page, []Point{{x, y}})
+}
+
+// MoveMouse moves the mouse through the path. Synthetic events cannot trigger
+// CSS :hover, so each position is sent as a CDP mouse move as well as JS
+// over/enter/move events on the element under it.
+func (s *JSDispatchStrategy) MoveMouse(page *rod.Page, path []Point) error {
+	for i, p := range pointerTrail(path) {
+		if i > 0 {
+			time.Sleep(gestureFrame)
+		}
+		if err := moveMouse(page, []Point{p}, 0, 0); err != nil {
+			return err
+		}
+		result, err := page.Eval(jsHoverEvents, p.X, p.Y)
+		if err != nil {
+			return fmt.Errorf("hover at (%d,%d): %w", p.X, p.Y, err)
+		}
+		if result == nil || result.Value.Str() == "no_element" {
+			return fmt.Errorf("hover at (%d,%d): no element at coordinates", p.X, p.Y)
+		}
+	}
+	return nil
+}
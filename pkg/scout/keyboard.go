@@ -0,0 +1,110 @@
+package scout
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// modifierBits maps modifier key names to their CDP modifier flags.
+var modifierBits = map[string]int{"Alt": 1, "Control": 2, "Meta": 4, "Shift": 8}
+
+// keyAliases maps common alternative names to keyDefinitions names.
+var keyAliases = map[string]string{"Ctrl": "Control", "Cmd": "Meta", "Command": "Meta", "Option": "Alt"}
+
+// parseChord splits a key or chord such as "Shift+ArrowRight" or "Control+a"
+// into key names. Every key but the last must be a modifier.
+func parseChord(chord string) ([]string, error) {
+	parts := strings.Split(chord, "+")
+	keys := make([]string, 0, len(parts))
+	for i, p := range parts {
+		p = strings.TrimSpace(p)
+		if alias, ok := keyAliases[p]; ok {
+			p = alias
+		}
+		if _, ok := keyDefinitions[p]; !ok {
+			return nil, fmt.Errorf("unknown key: %q", p)
+		}
+		if i < len(parts)-1 && modifierBits[p] == 0 {
+			return nil, fmt.Errorf("%q is not a modifier in %q (use Shift, Control, Alt or Meta)", p, chord)
+		}
+		keys = append(keys, p)
+	}
+	return keys, nil
+}
+
+// dispatchKey sends one keyDown or keyUp event carrying the held modifiers.
+// Printable keys type their text unless Alt, Control or Meta is held; Shift
+// upper-cases letters.
+func (r *RodBrowserPage) dispatchKey(name string, down bool) error {
+	kd := keyDefinitions[name]
+	bit := modifierBits[name]
+	if down {
+		r.modifiers |= bit
+	}
+	ev := proto.InputDispatchKeyEvent{
+		Type:                  proto.InputDispatchKeyEventTypeKeyUp,
+		Key:                   kd.Key,
+		Code:                  kd.Code,
+		WindowsVirtualKeyCode: kd.KeyCode,
+		Modifiers:             r.modifiers,
+	}
+	if r.modifiers&modifierBits["Shift"] != 0 && len(kd.Key) == 1 && kd.Key >= "a" && kd.Key <= "z" {
+		ev.Key = strings.ToUpper(kd.Key)
+	}
+	if down {
+		ev.Type = proto.InputDispatchKeyEventTypeKeyDown
+		if r.modifiers&(modifierBits["Alt"]|modifierBits["Control"]|modifierBits["Meta"]) == 0 && kd.Text != "" {
+			ev.Text = kd.Text
+			if ev.Key != kd.Key {
+				ev.Text = ev.Key
+			}
+		}
+	} else {
+		r.modifiers &^= bit
+	}
+	return ev.Call(r.page)
+}
+
+// KeyDown presses and holds a key or chord ("Shift+ArrowRight"), modifiers first.
+func (r *RodBrowserPage) KeyDown(key string) error {
+	keys, err := parseChord(key)
+	if err != nil {
+		return err
+	}
+	for i, k := range keys {
+		if err := r.dispatchKey(k, true); err != nil {
+			// Release what was already pressed so no key stays stuck
+			for j := i - 1; j >= 0; j-- {
+				_ = r.dispatchKey(keys[j], false)
+			}
+			return fmt.Errorf("key down %q: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// KeyUp releases a key or chord pressed with KeyDown, in reverse order.
+func (r *RodBrowserPage) KeyUp(key string) error {
+	keys, err := parseChord(key)
+	if err != nil {
+		return err
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		if err := r.dispatchKey(keys[i], false); err != nil {
+			return fmt.Errorf("key up %q: %w", keys[i], err)
+		}
+	}
+	return nil
+}
+
+// HoldKey presses a key or chord, holds it for duration and releases it.
+func (r *RodBrowserPage) HoldKey(key string, duration time.Duration) error {
+	if err := r.KeyDown(key); err != nil {
+		return err
+	}
+	time.Sleep(duration)
+	return r.KeyUp(key)
+}
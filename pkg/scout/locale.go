@@ -0,0 +1,162 @@
+package scout
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // timezone IDs validate the same on hosts without zoneinfo
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// defaultGeolocationAccuracy is the accuracy in meters reported when a
+// Geolocation leaves it unset.
+const defaultGeolocationAccuracy = 100
+
+var localeRe = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// Geolocation is the position the browser reports to navigator.geolocation.
+type Geolocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Accuracy  float64 `json:"accuracy,omitempty"` // meters; 0 = 100
+}
+
+// Validate checks that the coordinates are on Earth.
+func (g Geolocation) Validate() error {
+	if g.Latitude < -90 || g.Latitude > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90, got %g", g.Latitude)
+	}
+	if g.Longitude < -180 || g.Longitude > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180, got %g", g.Longitude)
+	}
+	if g.Accuracy < 0 {
+		return fmt.Errorf("accuracy must not be negative, got %g", g.Accuracy)
+	}
+	return nil
+}
+
+// String formats the position as accepted by ParseGeolocation.
+func (g Geolocation) String() string {
+	s := strconv.FormatFloat(g.Latitude, 'f', -1, 64) + "," + strconv.FormatFloat(g.Longitude, 'f', -1, 64)
+	if g.Accuracy > 0 {
+		s += "," + strconv.FormatFloat(g.Accuracy, 'f', -1, 64)
+	}
+	return s
+}
+
+// ParseGeolocation parses "latitude,longitude[,accuracy]".
+func ParseGeolocation(s string) (*Geolocation, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("geolocation must be latitude,longitude[,accuracy], got %q", s)
+	}
+	var values [3]float64
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("geolocation must be latitude,longitude[,accuracy], got %q", s)
+		}
+		values[i] = v
+	}
+	g := &Geolocation{Latitude: values[0], Longitude: values[1], Accuracy: values[2]}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// NormalizeLocale checks a BCP 47 locale such as "de", "pt-BR" or "pt_br" and
+// returns it in canonical case with hyphens ("pt-BR").
+func NormalizeLocale(locale string) (string, error) {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if !localeRe.MatchString(locale) {
+		return "", fmt.Errorf("invalid locale %q (use a language tag such as en-US or de)", locale)
+	}
+	parts := strings.Split(locale, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			parts[i] = strings.ToUpper(parts[i]) // region
+		case 4:
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:]) // script
+		}
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// ValidateTimezone checks an IANA timezone ID such as "Europe/Berlin".
+func ValidateTimezone(tz string) error {
+	if tz == "" || tz == "Local" {
+		return fmt.Errorf("invalid timezone %q (use an IANA ID such as Europe/Berlin)", tz)
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return fmt.Errorf("invalid timezone %q (use an IANA ID such as Europe/Berlin)", tz)
+	}
+	return nil
+}
+
+// acceptLanguage returns the Accept-Language header for a locale, falling
+// back to its base language ("pt-BR,pt;q=0.9"). Chrome derives
+// navigator.language and navigator.languages from it.
+func acceptLanguage(locale string) string {
+	base, _, found := strings.Cut(locale, "-")
+	if !found {
+		return locale
+	}
+	return locale + "," + base + ";q=0.9"
+}
+
+// applyEmulation applies the user agent, locale, timezone and geolocation of
+// cfg to page before it loads the game. The locale sets the Accept-Language
+// header, navigator.language(s) and the Intl default; geolocation is granted
+// so the game's position request succeeds without a prompt.
+func applyEmulation(browser *rod.Browser, page *rod.Page, cfg HeadlessConfig) error {
+	if cfg.Locale != "" || cfg.UserAgent != "" {
+		// One override carries both: the user agent (the browser's own unless
+		// the viewport preset sets one) and the Accept-Language of the locale.
+		override := proto.EmulationSetUserAgentOverride{UserAgent: cfg.UserAgent}
+		if override.UserAgent == "" {
+			version, err := proto.BrowserGetVersion{}.Call(browser)
+			if err != nil {
+				return fmt.Errorf("setting locale: %w", err)
+			}
+			override.UserAgent = version.UserAgent
+		}
+		if cfg.Locale != "" {
+			override.AcceptLanguage = acceptLanguage(cfg.Locale)
+		}
+		if err := override.Call(page); err != nil {
+			return fmt.Errorf("setting user agent: %w", err)
+		}
+	}
+	if cfg.Locale != "" {
+		if err := (proto.EmulationSetLocaleOverride{Locale: strings.ReplaceAll(cfg.Locale, "-", "_")}).Call(page); err != nil {
+			return fmt.Errorf("setting locale: %w", err)
+		}
+	}
+	if cfg.Timezone != "" {
+		if err := (proto.EmulationSetTimezoneOverride{TimezoneID: cfg.Timezone}).Call(page); err != nil {
+			return fmt.Errorf("setting timezone: %w", err)
+		}
+	}
+	if g := cfg.Geolocation; g != nil {
+		if err := (proto.BrowserGrantPermissions{
+			Permissions: []proto.BrowserPermissionType{proto.BrowserPermissionTypeGeolocation},
+		}).Call(browser); err != nil {
+			return fmt.Errorf("granting geolocation: %w", err)
+		}
+		lat, lon, accuracy := g.Latitude, g.Longitude, g.Accuracy
+		if accuracy <= 0 {
+			accuracy = defaultGeolocationAccuracy
+		}
+		if err := (proto.EmulationSetGeolocationOverride{Latitude: &lat, Longitude: &lon, Accuracy: &accuracy}).Call(page); err != nil {
+			return fmt.Errorf("setting geolocation: %w", err)
+		}
+	}
+	return nil
+}
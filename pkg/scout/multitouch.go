@@ -0,0 +1,123 @@
+package scout
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ErrTouchUnsupported is returned for multi-touch gestures on a page that does
+// not use touch input.
+var ErrTouchUnsupported = errors.New("multi-touch gestures need a touch viewport (phone or tablet)")
+
+const (
+	defaultTouchSpread   = 200 // starting distance between the two fingers, in pixels
+	defaultTouchDuration = 500 * time.Millisecond
+)
+
+// TouchGesture is a two-finger gesture centred on (X, Y). The fingers start
+// Spread pixels apart on a horizontal line through the centre.
+type TouchGesture struct {
+	Kind     string        // "pinch", "rotate" or "pan"
+	X, Y     int           // centre point between the fingers
+	Spread   int           // starting distance between the fingers (0 = 200px)
+	Scale    float64       // pinch: final spread relative to the start (>1 zooms in, <1 zooms out)
+	Angle    float64       // rotate: degrees to turn, clockwise
+	DX, DY   int           // pan: distance both fingers travel
+	Duration time.Duration // 0 = 500ms
+}
+
+// Validate reports whether the gesture describes any movement.
+func (g TouchGesture) Validate() error {
+	switch g.Kind {
+	case "pinch":
+		if g.Scale <= 0 || g.Scale == 1 {
+			return fmt.Errorf("pinch needs a positive scale other than 1, got %v", g.Scale)
+		}
+	case "rotate":
+		if g.Angle == 0 {
+			return fmt.Errorf("rotate needs a non-zero angle")
+		}
+	case "pan":
+		if g.DX == 0 && g.DY == 0 {
+			return fmt.Errorf("pan needs a non-zero dx or dy")
+		}
+	default:
+		return fmt.Errorf("unknown gesture %q (want pinch, rotate or pan)", g.Kind)
+	}
+	return nil
+}
+
+// fingers returns both finger positions at progress t (0 = start, 1 = end).
+func (g TouchGesture) fingers(t float64) [2][2]float64 {
+	spread := float64(g.Spread)
+	if spread <= 0 {
+		spread = defaultTouchSpread
+	}
+	r := spread / 2
+	cx, cy := float64(g.X), float64(g.Y)
+	theta := 0.0
+	switch g.Kind {
+	case "pinch":
+		r *= 1 + (g.Scale-1)*t
+	case "rotate":
+		theta = g.Angle * t * math.Pi / 180
+	case "pan":
+		cx += float64(g.DX) * t
+		cy += float64(g.DY) * t
+	}
+	dx, dy := r*math.Cos(theta), r*math.Sin(theta)
+	return [2][2]float64{
+		{math.Round(cx - dx), math.Round(cy - dy)},
+		{math.Round(cx + dx), math.Round(cy + dy)},
+	}
+}
+
+// MultiTouch performs a two-finger gesture as one touchStart with both points,
+// a touchMove per frame interpolating their positions, and a touchEnd.
+func (s *CDPTouchStrategy) MultiTouch(page *rod.Page, g TouchGesture) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	duration := g.Duration
+	if duration <= 0 {
+		duration = defaultTouchDuration
+	}
+	vpW, vpH := cachedViewport(page, &s.vpWidth, &s.vpHeight)
+	points := func(t float64) []*proto.InputTouchPoint {
+		f := g.fingers(t)
+		out := make([]*proto.InputTouchPoint, len(f))
+		for i, p := range f {
+			x, y := clampCoords(int(p[0]), int(p[1]), vpW, vpH)
+			id := float64(i)
+			out[i] = &proto.InputTouchPoint{X: float64(x), Y: float64(y), ID: &id}
+		}
+		return out
+	}
+
+	if err := (proto.InputDispatchTouchEvent{
+		Type:        proto.InputDispatchTouchEventTypeTouchStart,
+		TouchPoints: points(0),
+	}).Call(page); err != nil {
+		return fmt.Errorf("cdp %s start at (%d,%d): %w", g.Kind, g.X, g.Y, err)
+	}
+	steps := gestureSteps(duration)
+	for i := 1; i <= steps; i++ {
+		time.Sleep(duration / time.Duration(steps))
+		if err := (proto.InputDispatchTouchEvent{
+			Type:        proto.InputDispatchTouchEventTypeTouchMove,
+			TouchPoints: points(float64(i) / float64(steps)),
+		}).Call(page); err != nil {
+			_ = touchEnd(page)
+			return fmt.Errorf("cdp %s move: %w", g.Kind, err)
+		}
+	}
+	if err := touchEnd(page); err != nil {
+		return fmt.Errorf("cdp %s end: %w", g.Kind, err)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package scout
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// maxNetworkEntries caps the network ring buffer so long agent sessions don't
+// grow memory without bound. The oldest requests are dropped first.
+const maxNetworkEntries = 2000
+
+// NetworkEntry is one request recorded by RodBrowserPage, from the request
+// being sent until it finished, failed or was redirected.
+type NetworkEntry struct {
+	URL             string            `json:"url"`
+	Method          string            `json:"method"`
+	ResourceType    string            `json:"resourceType,omitempty"` // Document, Script, Image, XHR, Fetch, ...
+	Status          int               `json:"status,omitempty"`       // 0 until a response arrives
+	StatusText      string            `json:"statusText,omitempty"`
+	MimeType        string            `json:"mimeType,omitempty"`
+	Protocol        string            `json:"protocol,omitempty"`
+	StartedAt       time.Time         `json:"startedAt"`
+	DurationMs      float64           `json:"durationMs"`
+	Size            int64             `json:"size"` // bytes received over the wire
+	FromCache       bool              `json:"fromCache,omitempty"`
+	Failed          bool              `json:"failed,omitempty"`
+	Blocked         bool              `json:"blocked,omitempty"`  // blocked on purpose (analytics/tracking)
+	Canceled        bool              `json:"canceled,omitempty"` // aborted by the page or a navigation
+	Offline         bool              `json:"offline,omitempty"`  // sent or failed while an offline profile was emulated
+	ErrorText       string            `json:"errorText,omitempty"`
+	Pending         bool              `json:"pending,omitempty"` // still in flight
+	RedirectURL     string            `json:"redirectUrl,omitempty"`
+	RequestHeaders  map[string]string `json:"requestHeaders,omitempty"`
+	ResponseHeaders map[string]string `json:"responseHeaders,omitempty"`
+
+	requestID proto.NetworkRequestID
+	start     time.Duration // monotonic timestamp of the request
+}
+
+// IsError reports whether the request failed to load or got an HTTP error status.
+// Requests blocked on purpose are not errors.
+func (e NetworkEntry) IsError() bool {
+	if e.Blocked {
+		return false
+	}
+	return e.Failed || e.Status >= 400
+}
+
+// NetworkFilter selects entries from a network log. Zero values match everything.
+type NetworkFilter struct {
+	FailedOnly   bool   // only failed loads and HTTP errors (IsError)
+	URLContains  string // case-insensitive substring of the URL
+	ResourceType string // case-insensitive resource type, e.g. "xhr", "image"
+	MinStatus    int    // only responses with at least this status
+	Limit        int    // keep the most recent N matches (0 = all)
+}
+
+// FilterNetworkEntries returns the entries matching f, oldest first.
+func FilterNetworkEntries(entries []NetworkEntry, f NetworkFilter) []NetworkEntry {
+	urlPart := strings.ToLower(f.URLContains)
+	var out []NetworkEntry
+	for _, e := range entries {
+		if f.FailedOnly && !e.IsError() {
+			continue
+		}
+		if urlPart != "" && !strings.Contains(strings.ToLower(e.URL), urlPart) {
+			continue
+		}
+		if f.ResourceType != "" && !strings.EqualFold(e.ResourceType, f.ResourceType) {
+			continue
+		}
+		if f.MinStatus > 0 && e.Status < f.MinStatus {
+			continue
+		}
+		out = append(out, e)
+	}
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[len(out)-f.Limit:]
+	}
+	return out
+}
+
+// networkLog records CDP network events into a ring buffer of entries.
+type networkLog struct {
+	mu      sync.Mutex
+	entries []*NetworkEntry // oldest first
+	pending map[proto.NetworkRequestID]*NetworkEntry
+	offline bool // an offline profile is emulated; set by RodBrowserPage.SetNetwork
+}
+
+// setOffline records whether an offline profile is emulated, so requests the
+// emulation breaks are marked Offline rather than mistaken for game bugs.
+func (n *networkLog) setOffline(offline bool) {
+	n.mu.Lock()
+	n.offline = offline
+	n.mu.Unlock()
+}
+
+func newNetworkLog() *networkLog {
+	return &networkLog{pending: make(map[proto.NetworkRequestID]*NetworkEntry)}
+}
+
+func (n *networkLog) onRequest(e *proto.NetworkRequestWillBeSent) {
+	if e.Request == nil || strings.HasPrefix(e.Request.URL, "data:") || strings.HasPrefix(e.Request.URL, "blob:") ||
+		strings.HasSuffix(e.Request.URL, storageSeedPath) {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// A redirect reuses the request ID: close the previous hop with its response
+	if prev, ok := n.pending[e.RequestID]; ok && e.RedirectResponse != nil {
+		applyResponse(prev, e.RedirectResponse)
+		prev.RedirectURL = e.Request.URL
+		n.finish(prev, e.Timestamp.Duration())
+	}
+
+	entry := &NetworkEntry{
+		URL:            e.Request.URL,
+		Method:         e.Request.Method,
+		ResourceType:   string(e.Type),
+		StartedAt:      e.WallTime.Time(),
+		Pending:        true,
+		Offline:        n.offline,
+		RequestHeaders: flattenHeaders(e.Request.Headers),
+		requestID:      e.RequestID,
+		start:          e.Timestamp.Duration(),
+	}
+	if len(n.entries) >= maxNetworkEntries {
+		oldest := n.entries[0]
+		if n.pending[oldest.requestID] == oldest {
+			delete(n.pending, oldest.requestID)
+		}
+		n.entries = n.entries[1:]
+	}
+	n.entries = append(n.entries, entry)
+	n.pending[e.RequestID] = entry
+}
+
+func (n *networkLog) onResponse(e *proto.NetworkResponseReceived) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if entry, ok := n.pending[e.RequestID]; ok && e.Response != nil {
+		applyResponse(entry, e.Response)
+		if entry.ResourceType == "" {
+			entry.ResourceType = string(e.Type)
+		}
+	}
+}
+
+func (n *networkLog) onFinished(e *proto.NetworkLoadingFinished) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if entry, ok := n.pending[e.RequestID]; ok {
+		entry.Size = int64(e.EncodedDataLength)
+		n.finish(entry, e.Timestamp.Duration())
+	}
+}
+
+func (n *networkLog) onFailed(e *proto.NetworkLoadingFailed) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	entry, ok := n.pending[e.RequestID]
+	if !ok {
+		return
+	}
+	entry.Failed = true
+	entry.ErrorText = e.ErrorText
+	if n.offline {
+		// Going offline fails the requests still in flight too
+		entry.Offline = true
+	}
+	switch {
+	case e.BlockedReason != "":
+		entry.Blocked = true
+		entry.ErrorText = "blocked: " + string(e.BlockedReason)
+	case e.Canceled:
+		entry.Canceled = true
+		entry.ErrorText = "canceled"
+	}
+	n.finish(entry, e.Timestamp.Duration())
+}
+
+// finish marks an entry done and drops it from the pending set. Callers hold n.mu.
+func (n *networkLog) finish(entry *NetworkEntry, at time.Duration) {
+	entry.Pending = false
+	if at > entry.start {
+		entry.DurationMs = float64(at-entry.start) / float64(time.Millisecond)
+	}
+	delete(n.pending, entry.requestID)
+}
+
+// snapshot returns a copy of every recorded entry, oldest first.
+func (n *networkLog) snapshot() []NetworkEntry {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	out := make([]NetworkEntry, len(n.entries))
+	for i, e := range n.entries {
+		out[i] = *e
+	}
+	return out
+}
+
+func applyResponse(entry *NetworkEntry, r *proto.NetworkResponse) {
+	entry.Status = r.Status
+	entry.StatusText = r.StatusText
+	entry.MimeType = r.MIMEType
+	entry.Protocol = r.Protocol
+	entry.FromCache = r.FromDiskCache || r.FromServiceWorker || r.FromPrefetchCache
+	entry.ResponseHeaders = flattenHeaders(r.Headers)
+}
+
+func flattenHeaders(h proto.NetworkHeaders) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		out[k] = v.Str()
+	}
+	return out
+}
@@ -0,0 +1,154 @@
+package scout
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Network profile names. "custom" takes its latency and throughput from the
+// profile's own fields.
+const (
+	NetworkOnline  = "online"
+	NetworkOffline = "offline"
+	NetworkSlow3G  = "slow-3g"
+	NetworkFast3G  = "fast-3g"
+	NetworkCustom  = "custom"
+)
+
+// NetworkProfile describes the connection the browser emulates. Zero
+// throughput means unthrottled.
+type NetworkProfile struct {
+	Name         string  `json:"name"`
+	Offline      bool    `json:"offline,omitempty"`
+	LatencyMs    float64 `json:"latencyMs,omitempty"`    // added to every request
+	DownloadKbps float64 `json:"downloadKbps,omitempty"` // kilobits per second
+	UploadKbps   float64 `json:"uploadKbps,omitempty"`
+}
+
+// networkPresets mirror the Chrome DevTools throttling presets.
+var networkPresets = []NetworkProfile{
+	{Name: NetworkOnline},
+	{Name: NetworkOffline, Offline: true},
+	{Name: NetworkSlow3G, LatencyMs: 2000, DownloadKbps: 400, UploadKbps: 400},
+	{Name: NetworkFast3G, LatencyMs: 562.5, DownloadKbps: 1440, UploadKbps: 675},
+}
+
+// NetworkProfileNames lists the accepted profile names, presets first.
+func NetworkProfileNames() []string {
+	names := make([]string, 0, len(networkPresets)+1)
+	for _, p := range networkPresets {
+		names = append(names, p.Name)
+	}
+	return append(names, NetworkCustom)
+}
+
+// GetNetworkProfile returns the preset with the given name, or nil if not found.
+func GetNetworkProfile(name string) *NetworkProfile {
+	for i := range networkPresets {
+		if networkPresets[i].Name == name {
+			p := networkPresets[i]
+			return &p
+		}
+	}
+	return nil
+}
+
+// ResolveNetworkProfile builds the profile for a name. Presets ignore the
+// numbers; "custom" uses them and needs at least one. An empty name is online.
+func ResolveNetworkProfile(name string, latencyMs, downloadKbps, uploadKbps float64) (NetworkProfile, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = NetworkOnline
+	}
+	if p := GetNetworkProfile(name); p != nil {
+		return *p, nil
+	}
+	if name != NetworkCustom {
+		return NetworkProfile{}, fmt.Errorf("unknown network profile %q (use %s)", name, strings.Join(NetworkProfileNames(), ", "))
+	}
+	if latencyMs < 0 || downloadKbps < 0 || uploadKbps < 0 {
+		return NetworkProfile{}, fmt.Errorf("custom network profile: latency and throughput must not be negative")
+	}
+	if latencyMs == 0 && downloadKbps == 0 && uploadKbps == 0 {
+		return NetworkProfile{}, fmt.Errorf("custom network profile needs a latency or throughput limit")
+	}
+	return NetworkProfile{Name: NetworkCustom, LatencyMs: latencyMs, DownloadKbps: downloadKbps, UploadKbps: uploadKbps}, nil
+}
+
+// IsOnline reports whether the profile leaves the connection untouched.
+func (p NetworkProfile) IsOnline() bool {
+	return !p.Offline && p.LatencyMs == 0 && p.DownloadKbps == 0 && p.UploadKbps == 0
+}
+
+// String describes the profile, e.g. "slow-3g (2000ms latency, 400/400 kbps)".
+func (p NetworkProfile) String() string {
+	name := p.Name
+	if name == "" {
+		name = NetworkOnline
+	}
+	if p.Offline || p.IsOnline() {
+		return name
+	}
+	var parts []string
+	if p.LatencyMs > 0 {
+		parts = append(parts, fmt.Sprintf("%gms latency", p.LatencyMs))
+	}
+	if p.DownloadKbps > 0 || p.UploadKbps > 0 {
+		parts = append(parts, fmt.Sprintf("%s/%s kbps", kbpsLabel(p.DownloadKbps), kbpsLabel(p.UploadKbps)))
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(parts, ", "))
+}
+
+func kbpsLabel(kbps float64) string {
+	if kbps <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%g", kbps)
+}
+
+// emulation converts the profile into the CDP request. CDP takes bytes per
+// second, with -1 disabling throttling.
+func (p NetworkProfile) emulation() proto.NetworkEmulateNetworkConditions {
+	throughput := func(kbps float64) float64 {
+		if kbps <= 0 {
+			return -1
+		}
+		return kbps * 1000 / 8
+	}
+	return proto.NetworkEmulateNetworkConditions{
+		Offline:            p.Offline,
+		Latency:            p.LatencyMs,
+		DownloadThroughput: throughput(p.DownloadKbps),
+		UploadThroughput:   throughput(p.UploadKbps),
+	}
+}
+
+// SetNetwork switches the emulated connection. Going offline fails in-flight
+// requests and fires the page's "offline" event; navigator.onLine follows.
+func (r *RodBrowserPage) SetNetwork(p NetworkProfile) error {
+	if p.Name == "" {
+		p.Name = NetworkOnline
+	}
+	if err := p.emulation().Call(r.page); err != nil {
+		return fmt.Errorf("emulating network %s: %w", p.Name, err)
+	}
+	r.mu.Lock()
+	r.networkProfile = p
+	r.mu.Unlock()
+	if r.network != nil {
+		r.network.setOffline(p.Offline)
+	}
+	return nil
+}
+
+// NetworkProfile returns the connection currently emulated.
+func (r *RodBrowserPage) NetworkProfile() NetworkProfile {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.networkProfile.Name == "" {
+		return NetworkProfile{Name: NetworkOnline}
+	}
+	return r.networkProfile
+}
@@ -0,0 +1,102 @@
+package scout
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Device orientations accepted by Rotate.
+const (
+	OrientationPortrait  = "portrait"
+	OrientationLandscape = "landscape"
+)
+
+// ErrRotateUnsupported is returned when rotating a page that does not emulate
+// a phone or tablet.
+var ErrRotateUnsupported = errors.New("rotation needs a touch viewport (phone or tablet)")
+
+// orientationEventDelay is how long Rotate waits for Chrome's own
+// orientationchange before dispatching one itself.
+const orientationEventDelay = 100 * time.Millisecond
+
+func orientationOf(width, height int) string {
+	if width > height {
+		return OrientationLandscape
+	}
+	return OrientationPortrait
+}
+
+// screenOrientation returns the screen.orientation Chrome reports for a
+// viewport: portrait-primary at 0° or landscape-primary at 90°.
+func screenOrientation(width, height int) *proto.EmulationScreenOrientation {
+	if orientationOf(width, height) == OrientationLandscape {
+		return &proto.EmulationScreenOrientation{Type: proto.EmulationScreenOrientationTypeLandscapePrimary, Angle: 90}
+	}
+	return &proto.EmulationScreenOrientation{Type: proto.EmulationScreenOrientationTypePortraitPrimary, Angle: 0}
+}
+
+// Orientation returns the current orientation of the viewport.
+func (r *RodBrowserPage) Orientation() string {
+	return orientationOf(r.viewportWidth, r.viewportHeight)
+}
+
+// Rotate turns the device to orientation ("" toggles), swapping the viewport
+// width and height and updating screen.orientation. The page sees a resize
+// and an orientationchange event, dispatched by hand when Chrome does not fire
+// one. It returns the new viewport size; rotating to the current orientation
+// changes nothing.
+func (r *RodBrowserPage) Rotate(orientation string) (width, height int, err error) {
+	if !r.TouchEnabled() {
+		return 0, 0, ErrRotateUnsupported
+	}
+	width, height = r.viewportWidth, r.viewportHeight
+	current := r.Orientation()
+	switch orientation {
+	case "":
+		orientation = OrientationLandscape
+		if current == OrientationLandscape {
+			orientation = OrientationPortrait
+		}
+	case OrientationPortrait, OrientationLandscape:
+	default:
+		return 0, 0, fmt.Errorf("unknown orientation %q (want portrait or landscape)", orientation)
+	}
+	if orientation == current {
+		return width, height, nil
+	}
+	width, height = height, width
+
+	// Note whether Chrome fires orientationchange itself so games listening
+	// for it do not see the event twice.
+	_, _ = r.page.Eval(`() => {
+		window.__wqaOrientationFired = false;
+		window.addEventListener('orientationchange', () => { window.__wqaOrientationFired = true; }, { once: true });
+	}`)
+
+	dpr := r.devicePixelRatio
+	if dpr <= 0 {
+		dpr = 1
+	}
+	if err := r.page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+		Width:             width,
+		Height:            height,
+		DeviceScaleFactor: dpr,
+		ScreenOrientation: screenOrientation(width, height),
+	}); err != nil {
+		return 0, 0, fmt.Errorf("rotating to %s: %w", orientation, err)
+	}
+	r.viewportWidth, r.viewportHeight = width, height
+	// The touch strategy caches the viewport it clamps coordinates to.
+	r.clickStrategy = &CDPTouchStrategy{}
+
+	// Sleep rather than wait on requestAnimationFrame, which never fires while
+	// virtual time is paused.
+	time.Sleep(orientationEventDelay)
+	_, _ = r.page.Eval(`() => {
+		if (!window.__wqaOrientationFired) window.dispatchEvent(new Event('orientationchange'));
+	}`)
+	return width, height, nil
+}
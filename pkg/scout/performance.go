@@ -0,0 +1,312 @@
+package scout
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// jankFrameMs is the frame time above which a frame counts as a visible hitch.
+const jankFrameMs = 50
+
+// perfSamplerScript installs the passive performance sampler before any game
+// script runs. It keeps the last two minutes of frames (time, duration, WebGL
+// draw calls) from a requestAnimationFrame loop, one bucket per second for the
+// whole session, and long tasks from a PerformanceObserver. Draw calls are
+// counted by wrapping the WebGL draw methods, so they work for every engine
+// that renders with WebGL; the first draw also records the WebGL renderer
+// (WEBGL_debug_renderer_info) to tell GPU from software rendering. Frames longer than jankFrameMs count as jank; the
+// frame stalled by a canvas screenshot (skipNext) is not recorded.
+const perfSamplerScript = `(() => {
+	if (window.__wqaPerf) return;
+	const p = {
+		frames: [], secs: [], longTasks: [], drawSupported: false,
+		longTaskCount: 0, longTaskMs: 0, longestTaskMs: 0,
+	};
+	Object.defineProperty(window, '__wqaPerf', { value: p, enumerable: false });
+	let draws = 0, last = 0, sec = null;
+	const tick = (t) => {
+		if (p.skipNext) {
+			p.skipNext = false;
+		} else if (last) {
+			const dt = t - last;
+			p.frames.push([t, dt, draws]);
+			while (p.frames.length && p.frames[0][0] < t - 120000) p.frames.shift();
+			const s = Math.floor(t / 1000);
+			if (!sec || sec.s !== s) {
+				sec = { s, b: [0, 0, 0, 0] };
+				p.secs.push(sec.b);
+				if (p.secs.length > 3600) p.secs.shift();
+			}
+			sec.b[0]++;
+			sec.b[1] += draws;
+			sec.b[2] = Math.max(sec.b[2], dt);
+			if (dt > 50) sec.b[3]++;
+		}
+		last = t;
+		draws = 0;
+		requestAnimationFrame(tick);
+	};
+	requestAnimationFrame(tick);
+	try {
+		new PerformanceObserver((list) => {
+			for (const e of list.getEntries()) {
+				p.longTaskCount++;
+				p.longTaskMs += e.duration;
+				p.longestTaskMs = Math.max(p.longestTaskMs, e.duration);
+				p.longTasks.push([e.startTime, e.duration]);
+				if (p.longTasks.length > 1000) p.longTasks.shift();
+			}
+		}).observe({ entryTypes: ['longtask'] });
+	} catch (e) {}
+	const wrap = (proto) => {
+		if (!proto) return;
+		for (const m of ['drawArrays', 'drawElements', 'drawArraysInstanced', 'drawElementsInstanced', 'drawRangeElements']) {
+			const orig = proto[m];
+			if (typeof orig !== 'function') continue;
+			proto[m] = function() {
+				draws++;
+				if (!p.drawSupported) {
+					p.drawSupported = true;
+					try {
+						const info = this.getExtension('WEBGL_debug_renderer_info');
+						p.renderer = String(this.getParameter(info ? info.UNMASKED_RENDERER_WEBGL : this.RENDERER));
+					} catch (e) {}
+				}
+				return orig.apply(this, arguments);
+			};
+		}
+	};
+	wrap(window.WebGLRenderingContext && WebGLRenderingContext.prototype);
+	wrap(window.WebGL2RenderingContext && WebGL2RenderingContext.prototype);
+})();`
+
+// PerformanceSample summarises rendering performance over a time window.
+type PerformanceSample struct {
+	DurationMs        float64 `json:"durationMs"`
+	Frames            int     `json:"frames"`
+	AvgFPS            float64 `json:"avgFps"`
+	MinFPS            float64 `json:"minFps"`               // worst one-second window
+	P95FrameMs        float64 `json:"p95FrameMs,omitempty"` // not available for whole sessions
+	MaxFrameMs        float64 `json:"maxFrameMs"`
+	JankFrames        int     `json:"jankFrames"` // frames longer than 50ms
+	LongTasks         int     `json:"longTasks"`
+	LongTaskMs        float64 `json:"longTaskMs"` // total main-thread time blocked by long tasks
+	LongestTaskMs     float64 `json:"longestTaskMs"`
+	HeapUsedMB        float64 `json:"heapUsedMb,omitempty"`
+	HeapTotalMB       float64 `json:"heapTotalMb,omitempty"`
+	DrawCallsPerFrame float64 `json:"drawCallsPerFrame,omitempty"` // 0 when the page does not render with WebGL
+	MaxDrawCalls      int     `json:"maxDrawCalls,omitempty"`
+	// Renderer is the WebGL renderer the page drew with, when it uses WebGL.
+	Renderer string `json:"renderer,omitempty"`
+	// SoftwareRendering is set when WebGL ran on a CPU renderer (SwiftShader in
+	// headless Chrome): frame rates then say little about real devices.
+	SoftwareRendering bool `json:"softwareRendering,omitempty"`
+}
+
+// isSoftwareRenderer reports whether a WebGL renderer string names a CPU
+// rasterizer rather than a GPU, e.g. "ANGLE (Google, Vulkan 1.3.0 (SwiftShader
+// Device (Subzero) ...), SwiftShader driver)" or Mesa's llvmpipe.
+func isSoftwareRenderer(renderer string) bool {
+	r := strings.ToLower(renderer)
+	return strings.Contains(r, "swiftshader") || strings.Contains(r, "llvmpipe") || strings.Contains(r, "softpipe")
+}
+
+// setRenderer records the WebGL renderer the sampler saw on s.
+func (s *PerformanceSample) setRenderer(renderer string) {
+	s.Renderer = renderer
+	s.SoftwareRendering = isSoftwareRenderer(renderer)
+}
+
+// perfFrame is one sampled frame: timestamp, duration and WebGL draw calls.
+type perfFrame [3]float64
+
+// perfLongTask is one long task: start time and duration.
+type perfLongTask [2]float64
+
+// perfSecond is one second of the session: frames, draw calls, longest frame
+// and jank frames.
+type perfSecond [4]float64
+
+// summarizeFrames computes a sample from the frames and long tasks recorded
+// in a window of durationMs.
+func summarizeFrames(frames []perfFrame, longTasks []perfLongTask, durationMs float64, drawSupported bool) *PerformanceSample {
+	s := &PerformanceSample{DurationMs: durationMs, Frames: len(frames)}
+	for _, lt := range longTasks {
+		s.LongTasks++
+		s.LongTaskMs += lt[1]
+		s.LongestTaskMs = math.Max(s.LongestTaskMs, lt[1])
+	}
+	if len(frames) == 0 || durationMs <= 0 {
+		return s
+	}
+	s.AvgFPS = float64(len(frames)) * 1000 / durationMs
+
+	durations := make([]float64, len(frames))
+	totalDraws := 0.0
+	for i, f := range frames {
+		durations[i] = f[1]
+		s.MaxFrameMs = math.Max(s.MaxFrameMs, f[1])
+		if f[1] > jankFrameMs {
+			s.JankFrames++
+		}
+		totalDraws += f[2]
+		if int(f[2]) > s.MaxDrawCalls {
+			s.MaxDrawCalls = int(f[2])
+		}
+	}
+	sort.Float64s(durations)
+	s.P95FrameMs = durations[int(math.Ceil(0.95*float64(len(durations))))-1]
+	if drawSupported {
+		s.DrawCallsPerFrame = totalDraws / float64(len(frames))
+	}
+
+	// Worst one-second window, sliding over frame timestamps. Windows shorter
+	// than a second fall back to the average.
+	s.MinFPS = s.AvgFPS
+	if durationMs >= 1000 {
+		start := frames[0][0] - frames[0][1]
+		j := 0
+		for i := range frames {
+			for frames[j][0] <= frames[i][0]-1000 {
+				j++
+			}
+			if frames[i][0]-start >= 1000 {
+				s.MinFPS = math.Min(s.MinFPS, float64(i-j+1))
+			}
+		}
+	}
+	return s
+}
+
+// summarizeSeconds computes a whole-session sample from per-second buckets.
+// The first and last seconds are partial and only count towards the totals.
+func summarizeSeconds(secs []perfSecond, drawSupported bool) *PerformanceSample {
+	s := &PerformanceSample{DurationMs: float64(len(secs)) * 1000}
+	totalDraws := 0.0
+	for i, b := range secs {
+		s.Frames += int(b[0])
+		totalDraws += b[1]
+		s.MaxFrameMs = math.Max(s.MaxFrameMs, b[2])
+		s.JankFrames += int(b[3])
+		if i > 0 && i < len(secs)-1 && (s.MinFPS == 0 || b[0] < s.MinFPS) {
+			s.MinFPS = b[0]
+		}
+	}
+	if len(secs) > 0 {
+		s.AvgFPS = float64(s.Frames) / float64(len(secs))
+	}
+	if s.MinFPS == 0 {
+		s.MinFPS = s.AvgFPS
+	}
+	if drawSupported && s.Frames > 0 {
+		s.DrawCallsPerFrame = totalDraws / float64(s.Frames)
+	}
+	return s
+}
+
+// perfNow returns the page's performance.now() clock.
+func (r *RodBrowserPage) perfNow() (float64, error) {
+	res, err := r.page.Eval(`() => performance.now()`)
+	if err != nil {
+		return 0, fmt.Errorf("reading page clock: %w", err)
+	}
+	return res.Value.Num(), nil
+}
+
+// addHeapUsage fills in the JS heap size from CDP; failures leave it empty.
+func (r *RodBrowserPage) addHeapUsage(s *PerformanceSample) {
+	heap, err := proto.RuntimeGetHeapUsage{}.Call(r.page)
+	if err != nil {
+		return
+	}
+	s.HeapUsedMB = math.Round(heap.UsedSize/(1<<20)*10) / 10
+	s.HeapTotalMB = math.Round(heap.TotalSize/(1<<20)*10) / 10
+}
+
+// MeasurePerformance samples rendering performance for the next window:
+// frame rate, frame times, long tasks, WebGL draw calls and the JS heap.
+func (r *RodBrowserPage) MeasurePerformance(window time.Duration) (*PerformanceSample, error) {
+	start, err := r.perfNow()
+	if err != nil {
+		return nil, err
+	}
+	time.Sleep(window)
+	res, err := r.page.Eval(`(since) => {
+		const p = window.__wqaPerf;
+		if (!p) return null;
+		return {
+			now: performance.now(),
+			frames: p.frames.filter(f => f[0] > since),
+			longTasks: p.longTasks.filter(l => l[0] + l[1] > since),
+			drawSupported: p.drawSupported,
+			renderer: p.renderer || '',
+		};
+	}`, start)
+	if err != nil {
+		return nil, fmt.Errorf("reading performance samples: %w", err)
+	}
+	if res.Value.Nil() {
+		return nil, fmt.Errorf("performance sampler is not installed on this page")
+	}
+	var raw struct {
+		Now           float64        `json:"now"`
+		Frames        []perfFrame    `json:"frames"`
+		LongTasks     []perfLongTask `json:"longTasks"`
+		DrawSupported bool           `json:"drawSupported"`
+		Renderer      string         `json:"renderer"`
+	}
+	if err := json.Unmarshal([]byte(res.Value.JSON("", "")), &raw); err != nil {
+		return nil, fmt.Errorf("decoding performance samples: %w", err)
+	}
+	s := summarizeFrames(raw.Frames, raw.LongTasks, raw.Now-start, raw.DrawSupported)
+	s.setRenderer(raw.Renderer)
+	r.addHeapUsage(s)
+	return s, nil
+}
+
+// PerformanceSession summarises the passive sampler's whole session since the
+// page loaded.
+func (r *RodBrowserPage) PerformanceSession() (*PerformanceSample, error) {
+	res, err := r.page.Eval(`() => {
+		const p = window.__wqaPerf;
+		if (!p) return null;
+		return {
+			secs: p.secs,
+			drawSupported: p.drawSupported,
+			longTaskCount: p.longTaskCount,
+			longTaskMs: p.longTaskMs,
+			longestTaskMs: p.longestTaskMs,
+			renderer: p.renderer || '',
+		};
+	}`)
+	if err != nil {
+		return nil, fmt.Errorf("reading performance session: %w", err)
+	}
+	if res.Value.Nil() {
+		return nil, fmt.Errorf("performance sampler is not installed on this page")
+	}
+	var raw struct {
+		Secs          []perfSecond `json:"secs"`
+		DrawSupported bool         `json:"drawSupported"`
+		LongTaskCount int          `json:"longTaskCount"`
+		LongTaskMs    float64      `json:"longTaskMs"`
+		LongestTaskMs float64      `json:"longestTaskMs"`
+		Renderer      string       `json:"renderer"`
+	}
+	if err := json.Unmarshal([]byte(res.Value.JSON("", "")), &raw); err != nil {
+		return nil, fmt.Errorf("decoding performance session: %w", err)
+	}
+	s := summarizeSeconds(raw.Secs, raw.DrawSupported)
+	s.LongTasks = raw.LongTaskCount
+	s.LongTaskMs = raw.LongTaskMs
+	s.LongestTaskMs = raw.LongestTaskMs
+	s.setRenderer(raw.Renderer)
+	r.addHeapUsage(s)
+	return s, nil
+}
@@ -0,0 +1,65 @@
+package scout
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+)
+
+// NewRandomSeed returns a fresh seed for HeadlessConfig.RandomSeed.
+func NewRandomSeed() uint32 {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 1
+	}
+	return binary.LittleEndian.Uint32(b[:])
+}
+
+// seededRandomScript replaces Math.random, crypto.getRandomValues and
+// crypto.randomUUID with a mulberry32 generator started from seed. It runs on
+// every new document, so each load of the game draws the same sequence: a run
+// that hit a rare shuffle or spawn can be replayed with its seed. Workers and
+// float arrays keep the browser's own randomness.
+func seededRandomScript(seed uint32) string {
+	return fmt.Sprintf(`(() => {
+	if (window.__wqaRandomSeed !== undefined) return;
+	Object.defineProperty(window, '__wqaRandomSeed', { value: %d, enumerable: false });
+	let state = %d;
+	const next = () => {
+		state = (state + 0x6D2B79F5) >>> 0;
+		let t = state;
+		t = Math.imul(t ^ (t >>> 15), t | 1);
+		t ^= t + Math.imul(t ^ (t >>> 7), t | 61);
+		return (t ^ (t >>> 14)) >>> 0;
+	};
+	Math.random = () => next() / 4294967296;
+	const c = window.crypto;
+	if (!c || !c.getRandomValues) return;
+	const origGetRandomValues = c.getRandomValues.bind(c);
+	c.getRandomValues = (arr) => {
+		if (!ArrayBuffer.isView(arr) || arr instanceof DataView ||
+			arr instanceof Float32Array || arr instanceof Float64Array) {
+			return origGetRandomValues(arr);
+		}
+		const bytes = new Uint8Array(arr.buffer, arr.byteOffset, arr.byteLength);
+		for (let i = 0; i < bytes.length; i += 4) {
+			let v = next();
+			for (let j = i; j < i + 4 && j < bytes.length; j++) {
+				bytes[j] = v & 0xff;
+				v >>>= 8;
+			}
+		}
+		return arr;
+	};
+	if (c.randomUUID) {
+		c.randomUUID = () => {
+			const b = c.getRandomValues(new Uint8Array(16));
+			b[6] = (b[6] & 0x0f) | 0x40;
+			b[8] = (b[8] & 0x3f) | 0x80;
+			const h = Array.from(b, (x) => x.toString(16).padStart(2, '0')).join('');
+			return h.slice(0, 8) + '-' + h.slice(8, 12) + '-' + h.slice(12, 16) + '-' +
+				h.slice(16, 20) + '-' + h.slice(20);
+		};
+	}
+})()`, seed, seed)
+}
@@ -41,7 +41,15 @@ type HeadlessConfig struct {
 	Timeout          time.Duration
 	ScreenshotPath   string // if non-empty, save screenshot PNG here
 	DeviceCategory     string // viewport device category (e.g. "iPhone", "iPad", "Desktop")
+	Touch              bool   // dispatch touch events whatever the category (custom touch presets)
+	UserAgent          string // user agent of the viewport preset; "" = the browser's own
 	SkipMultiScreenshot bool   // when true, only capture 1 initial screenshot (skip click-based screenshots)
+	Network             *NetworkProfile // emulated connection for kept-alive pages; nil = unthrottled
+	InitialStorage      *StorageState   // seeded into kept-alive pages before the game loads; nil = fresh profile
+	RandomSeed          *uint32         // seeds Math.random and crypto.getRandomValues of kept-alive pages; nil = browser randomness
+	Locale              string          // BCP 47 locale for Accept-Language, navigator.language and Intl; "" = browser default
+	Timezone            string          // IANA timezone ID; "" = host timezone
+	Geolocation         *Geolocation    // position reported to navigator.geolocation; nil = unavailable
 }
 
 const (
@@ -1,9 +1,13 @@
 package scout
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-rod/rod/lib/proto"
 	"golang.org/x/net/html"
 )
 
@@ -193,3 +197,536 @@ func TestParseHTMLBodySnippet(t *testing.T) {
 		t.Errorf("BodySnippet length = %d, want <= %d", len(meta.BodySnippet), maxBodySnippet)
 	}
 }
+
+func TestGesturePath(t *testing.T) {
+	if n := gestureSteps(300 * time.Millisecond); n != 18 {
+		t.Errorf("gestureSteps(300ms) = %d, want 18", n)
+	}
+	if n := gestureSteps(0); n != 5 {
+		t.Errorf("gestureSteps(0) = %d, want the minimum of 5", n)
+	}
+	if n := gestureSteps(10 * time.Second); n != 60 {
+		t.Errorf("gestureSteps(10s) = %d, want the maximum of 60", n)
+	}
+
+	path := gesturePath(100, 200, 0, 300, 4)
+	want := [][2]float64{{75, 225}, {50, 250}, {25, 275}, {0, 300}}
+	if len(path) != len(want) {
+		t.Fatalf("path has %d points, want %d", len(path), len(want))
+	}
+	for i := range want {
+		if path[i] != want[i] {
+			t.Errorf("path[%d] = %v, want %v", i, path[i], want[i])
+		}
+	}
+}
+
+func TestTouchGestureFingers(t *testing.T) {
+	tests := []struct {
+		g          TouchGesture
+		start, end [2][2]float64
+	}{
+		{TouchGesture{Kind: "pinch", X: 200, Y: 300, Scale: 2}, [2][2]float64{{100, 300}, {300, 300}}, [2][2]float64{{0, 300}, {400, 300}}},
+		{TouchGesture{Kind: "pinch", X: 200, Y: 300, Spread: 100, Scale: 0.5}, [2][2]float64{{150, 300}, {250, 300}}, [2][2]float64{{175, 300}, {225, 300}}},
+		{TouchGesture{Kind: "rotate", X: 200, Y: 300, Angle: 90}, [2][2]float64{{100, 300}, {300, 300}}, [2][2]float64{{200, 200}, {200, 400}}},
+		{TouchGesture{Kind: "pan", X: 200, Y: 300, DX: 50, DY: -100}, [2][2]float64{{100, 300}, {300, 300}}, [2][2]float64{{150, 200}, {350, 200}}},
+	}
+	for _, tt := range tests {
+		if err := tt.g.Validate(); err != nil {
+			t.Errorf("%+v: %v", tt.g, err)
+		}
+		if got := tt.g.fingers(0); got != tt.start {
+			t.Errorf("%s start = %v, want %v", tt.g.Kind, got, tt.start)
+		}
+		if got := tt.g.fingers(1); got != tt.end {
+			t.Errorf("%s end = %v, want %v", tt.g.Kind, got, tt.end)
+		}
+	}
+
+	for _, g := range []TouchGesture{{Kind: "pinch", Scale: 1}, {Kind: "rotate"}, {Kind: "pan"}, {Kind: "zoom"}} {
+		if g.Validate() == nil {
+			t.Errorf("%+v should not validate", g)
+		}
+	}
+}
+
+func TestTouchDecidedAtLaunch(t *testing.T) {
+	canvas := &PageMeta{CanvasFound: true}
+	for _, tt := range []struct {
+		width    int
+		category string
+		preset   bool
+		want     bool
+	}{
+		{390, "", false, true},
+		{1280, "Handheld", true, true},
+		{1024, "iPad", false, true},
+		{1920, "Desktop", false, false},
+		{844, "", false, false},
+	} {
+		if got := isTouchDevice(tt.width, tt.category, tt.preset); got != tt.want {
+			t.Errorf("isTouchDevice(%d, %q, %v) = %v, want %v", tt.width, tt.category, tt.preset, got, tt.want)
+		}
+	}
+
+	// A narrow page without a preset rotated to 844px wide keeps touch input
+	page := &RodBrowserPage{touch: isTouchDevice(390, "", false)}
+	page.clickStrategy = selectClickStrategy(canvas, page.touch)
+	page.viewportWidth, page.viewportHeight = 844, 390
+	if got := selectClickStrategy(canvas, page.touch).Name(); got != "cdp_touch" || !page.TouchEnabled() {
+		t.Errorf("rotated page should keep touch input, got %s", got)
+	}
+}
+
+func TestPointerTrail(t *testing.T) {
+	trail := pointerTrail([]Point{{0, 0}, {50, 0}, {50, 10}})
+	want := []Point{{0, 0}, {17, 0}, {33, 0}, {50, 0}, {50, 10}}
+	if len(trail) != len(want) {
+		t.Fatalf("trail = %v, want %v", trail, want)
+	}
+	for i := range want {
+		if trail[i] != want[i] {
+			t.Errorf("trail[%d] = %v, want %v", i, trail[i], want[i])
+		}
+	}
+	if got := pointerTrail([]Point{{5, 5}}); len(got) != 1 || got[0] != (Point{5, 5}) {
+		t.Errorf("single point trail = %v", got)
+	}
+}
+
+func TestParseChord(t *testing.T) {
+	keys, err := parseChord("Ctrl+Shift+ArrowRight")
+	if err != nil || strings.Join(keys, ",") != "Control,Shift,ArrowRight" {
+		t.Errorf("parseChord = %v, %v", keys, err)
+	}
+	if keys, err := parseChord("Space"); err != nil || len(keys) != 1 {
+		t.Errorf("parseChord(Space) = %v, %v", keys, err)
+	}
+	for _, bad := range []string{"", "Hyper", "a+b", "Shift+"} {
+		if _, err := parseChord(bad); err == nil {
+			t.Errorf("parseChord(%q) should fail", bad)
+		}
+	}
+}
+
+func TestNetworkLog(t *testing.T) {
+	n := newNetworkLog()
+	n.onRequest(&proto.NetworkRequestWillBeSent{RequestID: "1", Request: &proto.NetworkRequest{URL: "https://game.test/atlas.png", Method: "GET"}, Type: proto.NetworkResourceTypeImage, Timestamp: 10})
+	n.onResponse(&proto.NetworkResponseReceived{RequestID: "1", Response: &proto.NetworkResponse{Status: 404, StatusText: "Not Found", MIMEType: "text/html"}})
+	n.onFinished(&proto.NetworkLoadingFinished{RequestID: "1", Timestamp: 10.25, EncodedDataLength: 512})
+	n.onRequest(&proto.NetworkRequestWillBeSent{RequestID: "2", Request: &proto.NetworkRequest{URL: "https://api.game.test/spin?bet=1", Method: "POST"}, Type: proto.NetworkResourceTypeXHR, Timestamp: 11})
+	n.onFailed(&proto.NetworkLoadingFailed{RequestID: "2", Timestamp: 11.5, ErrorText: "net::ERR_CONNECTION_RESET"})
+	n.onRequest(&proto.NetworkRequestWillBeSent{RequestID: "3", Request: &proto.NetworkRequest{URL: "https://www.google-analytics.com/collect", Method: "GET"}, Timestamp: 12})
+	n.onFailed(&proto.NetworkLoadingFailed{RequestID: "3", Timestamp: 12, ErrorText: "net::ERR_BLOCKED_BY_CLIENT", BlockedReason: proto.NetworkBlockedReasonInspector})
+	n.onRequest(&proto.NetworkRequestWillBeSent{RequestID: "4", Request: &proto.NetworkRequest{URL: "data:image/png;base64,AAAA"}})
+	n.onRequest(&proto.NetworkRequestWillBeSent{RequestID: "5", Request: &proto.NetworkRequest{URL: "https://game.test/main.js", Method: "GET"}, Timestamp: 13})
+
+	entries := n.snapshot()
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries (data: URL skipped), got %d", len(entries))
+	}
+	if e := entries[0]; e.Status != 404 || e.Size != 512 || e.DurationMs != 250 || e.Pending || !e.IsError() {
+		t.Errorf("unexpected 404 entry: %+v", e)
+	}
+	if e := entries[1]; !e.Failed || e.ErrorText != "net::ERR_CONNECTION_RESET" || !e.IsError() {
+		t.Errorf("unexpected failed entry: %+v", e)
+	}
+	if e := entries[2]; !e.Blocked || e.IsError() {
+		t.Errorf("blocked analytics request should not count as an error: %+v", e)
+	}
+	if !entries[3].Pending {
+		t.Error("request without a response should be pending")
+	}
+
+	failed := FilterNetworkEntries(entries, NetworkFilter{FailedOnly: true})
+	if len(failed) != 2 {
+		t.Errorf("expected 2 failed entries, got %d", len(failed))
+	}
+	if got := FilterNetworkEntries(entries, NetworkFilter{ResourceType: "xhr", URLContains: "SPIN"}); len(got) != 1 || got[0].Method != "POST" {
+		t.Errorf("unexpected filter result: %+v", got)
+	}
+	if got := FilterNetworkEntries(entries, NetworkFilter{Limit: 1}); len(got) != 1 || got[0].URL != "https://game.test/main.js" {
+		t.Errorf("Limit should keep the most recent entry, got %+v", got)
+	}
+
+	har := BuildHAR(entries)
+	if har.Log.Version != "1.2" || len(har.Log.Entries) != 4 {
+		t.Fatalf("unexpected HAR: %+v", har.Log)
+	}
+	spin := har.Log.Entries[1]
+	if spin.Response.Error != "net::ERR_CONNECTION_RESET" || len(spin.Request.QueryString) != 1 || spin.Request.QueryString[0].Value != "1" {
+		t.Errorf("unexpected HAR entry: %+v", spin)
+	}
+
+	login := BuildHAR([]NetworkEntry{{
+		URL:             "https://api.game.test/login",
+		RequestHeaders:  map[string]string{"Cookie": "session=secret", "Authorization": "Bearer secret", "Accept": "*/*"},
+		ResponseHeaders: map[string]string{"set-cookie": "session=secret; HttpOnly"},
+	}}).Log.Entries[0]
+	for _, h := range append(login.Request.Headers, login.Response.Headers...) {
+		if strings.Contains(h.Value, "secret") {
+			t.Errorf("HAR leaks credential header %s: %q", h.Name, h.Value)
+		}
+		if h.Name == "Accept" && h.Value != "*/*" {
+			t.Errorf("Accept header = %q, want it kept", h.Value)
+		}
+	}
+}
+
+func TestNetworkLogRedirectAndEviction(t *testing.T) {
+	n := newNetworkLog()
+	n.onRequest(&proto.NetworkRequestWillBeSent{RequestID: "r", Request: &proto.NetworkRequest{URL: "http://game.test/", Method: "GET"}, Timestamp: 1})
+	n.onRequest(&proto.NetworkRequestWillBeSent{RequestID: "r", Request: &proto.NetworkRequest{URL: "https://game.test/", Method: "GET"}, Timestamp: 1.1,
+		RedirectResponse: &proto.NetworkResponse{Status: 301}})
+	n.onFinished(&proto.NetworkLoadingFinished{RequestID: "r", Timestamp: 1.2})
+	entries := n.snapshot()
+	if len(entries) != 2 || entries[0].Status != 301 || entries[0].RedirectURL != "https://game.test/" || entries[1].Pending {
+		t.Fatalf("unexpected redirect entries: %+v", entries)
+	}
+
+	for i := 0; i < maxNetworkEntries+5; i++ {
+		n.onRequest(&proto.NetworkRequestWillBeSent{RequestID: proto.NetworkRequestID(strings.Repeat("x", i%7) + string(rune('a'+i%26))), Request: &proto.NetworkRequest{URL: "https://game.test/a"}})
+	}
+	if got := len(n.snapshot()); got != maxNetworkEntries {
+		t.Errorf("ring buffer should hold %d entries, got %d", maxNetworkEntries, got)
+	}
+}
+
+func TestResolveNetworkProfile(t *testing.T) {
+	p, err := ResolveNetworkProfile("Slow-3G", 1, 2, 3)
+	if err != nil || p.LatencyMs != 2000 || p.DownloadKbps != 400 {
+		t.Errorf("slow-3g = %+v, %v", p, err)
+	}
+	if p, _ := ResolveNetworkProfile("", 0, 0, 0); p.Name != NetworkOnline || !p.IsOnline() {
+		t.Errorf("empty name should be online, got %+v", p)
+	}
+	p, err = ResolveNetworkProfile("custom", 300, 1000, 0)
+	if err != nil || p.String() != "custom (300ms latency, 1000/unlimited kbps)" {
+		t.Errorf("custom = %q, %v", p.String(), err)
+	}
+	em := p.emulation()
+	if em.Latency != 300 || em.DownloadThroughput != 125000 || em.UploadThroughput != -1 || em.Offline {
+		t.Errorf("unexpected CDP conditions: %+v", em)
+	}
+	bad := []struct {
+		name    string
+		latency float64
+	}{{"2g", 100}, {"custom", 0}, {"custom", -5}}
+	for _, b := range bad {
+		if _, err := ResolveNetworkProfile(b.name, b.latency, 0, 0); err == nil {
+			t.Errorf("ResolveNetworkProfile(%q, %g) should fail", b.name, b.latency)
+		}
+	}
+	if off := GetNetworkProfile(NetworkOffline); off == nil || !off.emulation().Offline || off.String() != "offline" {
+		t.Errorf("offline preset = %+v", off)
+	}
+}
+
+func TestSummarizeFrames(t *testing.T) {
+	// Two seconds: the first at 60fps with 10 draw calls per frame, the second
+	// at 20fps with one 200ms hitch
+	var frames []perfFrame
+	for i := 1; i <= 60; i++ {
+		frames = append(frames, perfFrame{float64(i) * 1000 / 60, 1000.0 / 60, 10})
+	}
+	for i := 1; i <= 17; i++ {
+		frames = append(frames, perfFrame{1000 + float64(i)*800/17, 800.0 / 17, 10})
+	}
+	frames = append(frames, perfFrame{2000, 200, 40})
+
+	s := summarizeFrames(frames, []perfLongTask{{1100, 120}, {1500, 80}}, 2000, true)
+	if s.Frames != 78 || s.AvgFPS != 39 {
+		t.Errorf("frames/avg = %d/%v", s.Frames, s.AvgFPS)
+	}
+	if s.MinFPS != 18 {
+		t.Errorf("worst second should have 18 frames, got %v", s.MinFPS)
+	}
+	if s.MaxFrameMs != 200 || s.JankFrames != 1 || s.MaxDrawCalls != 40 {
+		t.Errorf("unexpected max frame/jank/draws: %+v", s)
+	}
+	if s.LongTasks != 2 || s.LongTaskMs != 200 || s.LongestTaskMs != 120 {
+		t.Errorf("unexpected long tasks: %+v", s)
+	}
+	if s.P95FrameMs < 47 || s.P95FrameMs > 48 {
+		t.Errorf("p95 = %v", s.P95FrameMs)
+	}
+
+	if s := summarizeFrames(frames, nil, 2000, false); s.DrawCallsPerFrame != 0 {
+		t.Errorf("draw calls should be left out without WebGL, got %v", s.DrawCallsPerFrame)
+	}
+	if s := summarizeFrames(nil, nil, 1000, false); s.AvgFPS != 0 || s.MinFPS != 0 {
+		t.Errorf("empty window = %+v", s)
+	}
+}
+
+func TestSummarizeSeconds(t *testing.T) {
+	s := summarizeSeconds([]perfSecond{{5, 50, 30, 0}, {60, 600, 20, 0}, {24, 240, 180, 2}, {58, 580, 20, 0}, {10, 100, 20, 0}}, true)
+	if s.Frames != 157 || s.MinFPS != 24 || s.MaxFrameMs != 180 || s.JankFrames != 2 {
+		t.Errorf("unexpected session: %+v", s)
+	}
+	if s.DrawCallsPerFrame != 10 || s.DurationMs != 5000 {
+		t.Errorf("unexpected draws/duration: %+v", s)
+	}
+}
+
+func TestParseStorageState(t *testing.T) {
+	s, err := ParseStorageState([]byte(`{
+		"localStorage": {"tutorialDone": "true", "level": "7"},
+		"cookies": [{"name": "session", "value": "abc"}],
+		"indexedDB": [{"name": "save", "version": 3, "stores": [
+			{"name": "slots", "keyPath": "id", "records": [{"value": {"id": 1, "coins": 500}}, {"value": {"id": 2}}]},
+			{"name": "kv", "records": [{"key": "music", "value": false}]}
+		]}]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseStorageState: %v", err)
+	}
+	if got := s.Summary(); got != "2 localStorage keys, 1 cookie, 1 IndexedDB database (3 records)" {
+		t.Errorf("Summary() = %q", got)
+	}
+	if (StorageState{}).Summary() != "empty" || !(StorageState{}).IsEmpty() {
+		t.Error("expected an empty state")
+	}
+
+	for _, bad := range []string{
+		`{"cookies": [{"value": "x"}]}`,
+		`{"cookies": [{"name": "a", "sameSite": "strict"}]}`,
+		`{"indexedDB": [{"name": ""}]}`,
+		`{"indexedDB": [{"name": "save", "stores": [{"name": "kv", "records": [{"value": 1}]}]}]}`,
+		`{"indexedDB": [{"name": "save", "stores": [{"name": "kv", "keyPath": "id", "records": [{}]}]}]}`,
+		`{"localStorage": ["not", "a", "map"]}`,
+	} {
+		if _, err := ParseStorageState([]byte(bad)); err == nil {
+			t.Errorf("expected %s to be rejected", bad)
+		}
+	}
+}
+
+func TestStorageOrigin(t *testing.T) {
+	tests := []struct {
+		url, want string
+		wantErr   bool
+	}{
+		{"https://game.test/slots/index.html?lang=en", "https://game.test", false},
+		{"http://localhost:8080/", "http://localhost:8080", false},
+		{"about:blank", "", true},
+		{"file:///tmp/game.html", "", true},
+	}
+	for _, tt := range tests {
+		got, err := storageOrigin(tt.url)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("storageOrigin(%q) = %q, %v", tt.url, got, err)
+		}
+	}
+}
+
+func TestSeededRandomScript(t *testing.T) {
+	script := seededRandomScript(4294967295)
+	if !strings.Contains(script, "let state = 4294967295;") || !strings.Contains(script, "value: 4294967295") {
+		t.Errorf("seed not embedded:\n%s", script)
+	}
+	if seededRandomScript(1) == seededRandomScript(2) {
+		t.Error("different seeds produced the same script")
+	}
+
+	// The script's generator is mulberry32: it must keep the steps of the reference
+	// below, which is pinned to outputs of the script run in a JS engine
+	for _, step := range []string{
+		"state = (state + 0x6D2B79F5) >>> 0;",
+		"t = Math.imul(t ^ (t >>> 15), t | 1);",
+		"t ^= t + Math.imul(t ^ (t >>> 7), t | 61);",
+		"return (t ^ (t >>> 14)) >>> 0;",
+	} {
+		if !strings.Contains(script, step) {
+			t.Errorf("generator step %q missing from the script", step)
+		}
+	}
+	for seed, want := range map[uint32][3]uint32{
+		1:          {2693262067, 11749833, 2265367787},
+		3000000000: {3250807182, 1603408522, 4187569887},
+		4294967295: {3850105811, 813802916, 3073704848},
+	} {
+		next := mulberry32(seed)
+		if got := [3]uint32{next(), next(), next()}; got != want {
+			t.Errorf("mulberry32(%d) = %v, want %v", seed, got, want)
+		}
+	}
+}
+
+// mulberry32 is a Go reference for the generator in seededRandomScript.
+func mulberry32(seed uint32) func() uint32 {
+	state := seed
+	return func() uint32 {
+		state += 0x6D2B79F5
+		t := state
+		t = (t ^ t>>15) * (t | 1)
+		t ^= t + (t^t>>7)*(t|61)
+		return t ^ t>>14
+	}
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := map[string]string{
+		"de":         "de",
+		"pt_br":      "pt-BR",
+		"EN-us":      "en-US",
+		"zh-hant-tw": "zh-Hant-TW",
+	}
+	for in, want := range tests {
+		if got, err := NormalizeLocale(in); err != nil || got != want {
+			t.Errorf("NormalizeLocale(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "german", "de-", "d"} {
+		if _, err := NormalizeLocale(bad); err == nil {
+			t.Errorf("NormalizeLocale(%q) should fail", bad)
+		}
+	}
+	if got := acceptLanguage("pt-BR"); got != "pt-BR,pt;q=0.9" {
+		t.Errorf("acceptLanguage(pt-BR) = %q", got)
+	}
+	if got := acceptLanguage("de"); got != "de" {
+		t.Errorf("acceptLanguage(de) = %q", got)
+	}
+}
+
+func TestParseGeolocation(t *testing.T) {
+	g, err := ParseGeolocation("51.5072, -0.1276, 20")
+	if err != nil || g.Latitude != 51.5072 || g.Longitude != -0.1276 || g.Accuracy != 20 {
+		t.Fatalf("unexpected %+v, %v", g, err)
+	}
+	if g.String() != "51.5072,-0.1276,20" {
+		t.Errorf("String() = %q", g.String())
+	}
+	for _, bad := range []string{"51.5", "91,0", "0,181", "a,b", "1,2,-3", "1,2,3,4"} {
+		if _, err := ParseGeolocation(bad); err == nil {
+			t.Errorf("ParseGeolocation(%q) should fail", bad)
+		}
+	}
+	if err := ValidateTimezone("Europe/Berlin"); err != nil {
+		t.Error(err)
+	}
+	for _, bad := range []string{"", "Local", "Mars/Olympus"} {
+		if ValidateTimezone(bad) == nil {
+			t.Errorf("ValidateTimezone(%q) should fail", bad)
+		}
+	}
+}
+
+func TestLandscapeViewportPresets(t *testing.T) {
+	p := GetViewportByName("iphone-16-landscape")
+	if p == nil {
+		t.Fatal("iphone-16-landscape preset missing")
+	}
+	if p.Width != 852 || p.Height != 393 || p.DevicePixelRatio != 3 || p.Category != "iPhone" {
+		t.Errorf("unexpected landscape preset %+v", *p)
+	}
+	if p.Label != "iPhone 16 (Landscape)" || p.Orientation() != OrientationLandscape {
+		t.Errorf("label %q, orientation %q", p.Label, p.Orientation())
+	}
+	if GetViewportByName("desktop-hd-landscape") != nil {
+		t.Error("desktop presets must not get landscape variants")
+	}
+	for _, preset := range viewportPresets {
+		if isTouchCategory(preset.Category) && preset.Orientation() == OrientationPortrait &&
+			GetViewportByName(preset.Name+landscapeSuffix) == nil {
+			t.Errorf("%s has no landscape variant", preset.Name)
+		}
+	}
+
+	if o := screenOrientation(852, 393); o.Type != "landscapePrimary" || o.Angle != 90 {
+		t.Errorf("screenOrientation(852, 393) = %+v", *o)
+	}
+	if o := screenOrientation(393, 852); o.Type != "portraitPrimary" || o.Angle != 0 {
+		t.Errorf("screenOrientation(393, 852) = %+v", *o)
+	}
+}
+
+func TestCustomViewportPresets(t *testing.T) {
+	presets, err := ParseViewportPresets([]byte(`
+viewports:
+  - name: steam-deck
+    width: 800
+    height: 1280
+    touch: true
+    userAgent: Deck/1.0
+  - name: iphone-16
+    label: iPhone 16 (Custom)
+    category: iPhone
+    width: 400
+    height: 860
+    devicePixelRatio: 3
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presets) != 4 {
+		t.Fatalf("expected 2 presets and their landscape variants, got %d", len(presets))
+	}
+	deck := presets[0]
+	if deck.Label != "steam-deck" || deck.Category != "Custom" || deck.DevicePixelRatio != 1 || !deck.IsTouch() {
+		t.Errorf("defaults not applied: %+v", deck)
+	}
+
+	// A bare JSON list is accepted too (project settings)
+	list, err := ParseViewportPresets([]byte(`[{"name": "kiosk", "width": 1080, "height": 1920}]`))
+	if err != nil || len(list) != 1 {
+		t.Fatalf("bare list: %v, %v", list, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "viewports.yaml")
+	if err := os.WriteFile(path, []byte("viewports:\n  - {name: arcade, width: 1024, height: 768}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadViewportFile(path); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		viewportMu.Lock()
+		customViewports = nil
+		viewportMu.Unlock()
+	}()
+	if GetViewportByName("arcade") == nil {
+		t.Error("configured preset not found")
+	}
+	if vp := GetViewportByName("iphone-16", presets...); vp == nil || vp.Width != 400 {
+		t.Errorf("project preset should override the built-in one, got %+v", vp)
+	}
+	if vp := GetViewportByName("steam-deck-landscape", presets...); vp == nil || vp.Width != 1280 || vp.UserAgent != "Deck/1.0" {
+		t.Errorf("landscape variant of a custom touch preset: %+v", vp)
+	}
+	if GetViewportByName("steam-deck") != nil {
+		t.Error("project presets must not leak into lookups without them")
+	}
+	all := ViewportPresets(presets)
+	if len(all) != len(viewportPresets)+3 {
+		t.Errorf("merged list has %d presets, want %d", len(all), len(viewportPresets)+3)
+	}
+
+	for _, bad := range []string{
+		`[{"name": "Bad Name", "width": 100, "height": 100}]`,
+		`[{"name": "huge", "width": 100000, "height": 100}]`,
+		`[{"name": "dup", "width": 100, "height": 100}, {"name": "dup", "width": 200, "height": 200}]`,
+		`[{"name": "dpr", "width": 100, "height": 100, "devicePixelRatio": 9}]`,
+	} {
+		if _, err := ParseViewportPresets([]byte(bad)); err == nil {
+			t.Errorf("ParseViewportPresets(%s) should fail", bad)
+		}
+	}
+}
+
+func TestSoftwareRendererDetection(t *testing.T) {
+	for renderer, want := range map[string]bool{
+		"ANGLE (Google, Vulkan 1.3.0 (SwiftShader Device (Subzero) (0x0000C0DE)), SwiftShader driver)": true,
+		"llvmpipe (LLVM 15.0.7, 256 bits)":                   true,
+		"ANGLE (NVIDIA, NVIDIA GeForce RTX 3060 Direct3D11)": false,
+		"Apple M2": false,
+		"":         false, // not a WebGL page
+	} {
+		var s PerformanceSample
+		s.setRenderer(renderer)
+		if s.SoftwareRendering != want || s.Renderer != renderer {
+			t.Errorf("setRenderer(%q) = %+v, want software %v", renderer, s, want)
+		}
+	}
+}
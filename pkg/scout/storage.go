@@ -0,0 +1,481 @@
+package scout
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Storage area names, as accepted by ClearStorage and the storage tools.
+const (
+	StorageLocal     = "localStorage"
+	StorageSession   = "sessionStorage"
+	StorageCookies   = "cookies"
+	StorageIndexedDB = "indexedDB"
+)
+
+// StorageAreas lists every storage area.
+func StorageAreas() []string {
+	return []string{StorageLocal, StorageSession, StorageCookies, StorageIndexedDB}
+}
+
+// maxIndexedDBRecords caps the records read per object store; Count still
+// reports the full size.
+const maxIndexedDBRecords = 100
+
+// storageSeedPath is the placeholder page SeedStorage loads on the game's
+// origin. It is answered by the browser itself and never reaches the server.
+const storageSeedPath = "/__wizards_qa_storage_seed__"
+
+// Cookie is a browser cookie.
+type Cookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain,omitempty"` // defaults to the page's host
+	Path     string  `json:"path,omitempty"`
+	Expires  float64 `json:"expires,omitempty"` // Unix seconds; 0 is a session cookie
+	HTTPOnly bool    `json:"httpOnly,omitempty"`
+	Secure   bool    `json:"secure,omitempty"`
+	SameSite string  `json:"sameSite,omitempty"` // Strict, Lax or None
+}
+
+// IndexedDBRecord is one object store entry. Key is only needed for stores
+// without a keyPath or autoIncrement.
+type IndexedDBRecord struct {
+	Key   json.RawMessage `json:"key,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+// IndexedDBStore is an object store and its records.
+type IndexedDBStore struct {
+	Name          string            `json:"name"`
+	KeyPath       string            `json:"keyPath,omitempty"` // comma-separated for compound key paths
+	AutoIncrement bool              `json:"autoIncrement,omitempty"`
+	Count         int               `json:"count,omitempty"` // records in the store when read; Records stops at 100
+	Records       []IndexedDBRecord `json:"records,omitempty"`
+}
+
+// IndexedDBDatabase is an IndexedDB database. When seeding, Version should be
+// the version the game opens so its upgrade handler does not run again.
+type IndexedDBDatabase struct {
+	Name    string           `json:"name"`
+	Version int              `json:"version,omitempty"`
+	Stores  []IndexedDBStore `json:"stores,omitempty"`
+}
+
+// StorageState is what a page keeps between visits: local and session
+// storage, cookies and IndexedDB. It describes one origin, e.g. a "returning
+// player" or "tutorial completed" save.
+type StorageState struct {
+	LocalStorage   map[string]string   `json:"localStorage,omitempty"`
+	SessionStorage map[string]string   `json:"sessionStorage,omitempty"`
+	Cookies        []Cookie            `json:"cookies,omitempty"`
+	IndexedDB      []IndexedDBDatabase `json:"indexedDB,omitempty"`
+}
+
+// ParseStorageState parses and validates a JSON storage state.
+func ParseStorageState(data []byte) (*StorageState, error) {
+	var s StorageState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing storage state: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// LoadStorageState reads a storage state from a JSON file.
+func LoadStorageState(path string) (*StorageState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading storage state: %w", err)
+	}
+	return ParseStorageState(data)
+}
+
+// Validate checks that cookies, databases, stores and records can be written.
+func (s StorageState) Validate() error {
+	for i, c := range s.Cookies {
+		if strings.TrimSpace(c.Name) == "" {
+			return fmt.Errorf("storage state: cookie %d has no name", i+1)
+		}
+		switch c.SameSite {
+		case "", "Strict", "Lax", "None":
+		default:
+			return fmt.Errorf("storage state: cookie %q: sameSite must be Strict, Lax or None", c.Name)
+		}
+	}
+	for _, db := range s.IndexedDB {
+		if strings.TrimSpace(db.Name) == "" {
+			return fmt.Errorf("storage state: IndexedDB database with an empty name")
+		}
+		if db.Version < 0 {
+			return fmt.Errorf("storage state: IndexedDB database %q has a negative version", db.Name)
+		}
+		for _, st := range db.Stores {
+			if strings.TrimSpace(st.Name) == "" {
+				return fmt.Errorf("storage state: IndexedDB database %q has a store with an empty name", db.Name)
+			}
+			for i, rec := range st.Records {
+				if len(rec.Value) == 0 {
+					return fmt.Errorf("storage state: %s/%s record %d has no value", db.Name, st.Name, i+1)
+				}
+				if st.KeyPath == "" && !st.AutoIncrement && len(rec.Key) == 0 {
+					return fmt.Errorf("storage state: %s/%s record %d needs a key (the store has no keyPath)", db.Name, st.Name, i+1)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// IsEmpty reports whether the state holds nothing to write.
+func (s StorageState) IsEmpty() bool {
+	return len(s.LocalStorage) == 0 && len(s.SessionStorage) == 0 && len(s.Cookies) == 0 && len(s.IndexedDB) == 0
+}
+
+// originScoped reports whether the state holds anything besides cookies,
+// which can only be written from a page on the origin.
+func (s StorageState) originScoped() bool {
+	return len(s.LocalStorage) > 0 || len(s.SessionStorage) > 0 || len(s.IndexedDB) > 0
+}
+
+// Summary describes the state's size, e.g. "2 localStorage keys, 1 cookie".
+func (s StorageState) Summary() string {
+	plural := func(n int, one, many string) string {
+		if n == 1 {
+			return "1 " + one
+		}
+		return fmt.Sprintf("%d %s", n, many)
+	}
+	var parts []string
+	if n := len(s.LocalStorage); n > 0 {
+		parts = append(parts, plural(n, "localStorage key", "localStorage keys"))
+	}
+	if n := len(s.SessionStorage); n > 0 {
+		parts = append(parts, plural(n, "sessionStorage key", "sessionStorage keys"))
+	}
+	if n := len(s.Cookies); n > 0 {
+		parts = append(parts, plural(n, "cookie", "cookies"))
+	}
+	if n := len(s.IndexedDB); n > 0 {
+		records := 0
+		for _, db := range s.IndexedDB {
+			for _, st := range db.Stores {
+				records += len(st.Records)
+			}
+		}
+		parts = append(parts, plural(n, "IndexedDB database", "IndexedDB databases")+" ("+plural(records, "record", "records")+")")
+	}
+	if len(parts) == 0 {
+		return "empty"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// storageOrigin returns the origin of an http(s) page URL. Other schemes have
+// opaque origins that cannot hold storage.
+func storageOrigin(pageURL string) (string, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing page URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("storage needs an http(s) page, got %q", pageURL)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+// readStorageScript dumps local and session storage and up to limit records of
+// every IndexedDB object store. Values that are not JSON (Blobs, typed arrays)
+// come back as their string form.
+const readStorageScript = `async (limit) => {
+	const dump = (s) => {
+		const o = {};
+		for (let i = 0; i < s.length; i++) {
+			const k = s.key(i);
+			o[k] = s.getItem(k);
+		}
+		return o;
+	};
+	const plain = (v) => {
+		try { return v === undefined ? null : JSON.parse(JSON.stringify(v)); } catch (e) { return String(v); }
+	};
+	const req = (r) => new Promise((resolve, reject) => {
+		r.onsuccess = () => resolve(r.result);
+		r.onerror = () => reject(r.error);
+	});
+	const out = { localStorage: dump(localStorage), sessionStorage: dump(sessionStorage), indexedDB: [] };
+	if (!window.indexedDB || !indexedDB.databases) return out;
+	for (const info of await indexedDB.databases()) {
+		if (!info.name) continue;
+		const db = await req(indexedDB.open(info.name));
+		const entry = { name: db.name, version: db.version, stores: [] };
+		for (const name of db.objectStoreNames) {
+			const store = db.transaction(name, 'readonly').objectStore(name);
+			const [keys, values, count] = await Promise.all([
+				req(store.getAllKeys(null, limit)), req(store.getAll(null, limit)), req(store.count()),
+			]);
+			const kp = store.keyPath;
+			entry.stores.push({
+				name, count,
+				keyPath: Array.isArray(kp) ? kp.join(',') : (kp || ''),
+				autoIncrement: store.autoIncrement,
+				records: keys.map((k, i) => kp === null ? { key: plain(k), value: plain(values[i]) } : { value: plain(values[i]) }),
+			});
+		}
+		db.close();
+		out.indexedDB.push(entry);
+	}
+	return out;
+}`
+
+// writeStorageScript merges local storage, session storage and IndexedDB
+// records into the page's origin. Missing databases are created at the given
+// version, missing stores through a version upgrade; records are put, so
+// existing keys are overwritten.
+const writeStorageScript = `async (s) => {
+	const req = (r) => new Promise((resolve, reject) => {
+		r.onsuccess = () => resolve(r.result);
+		r.onerror = () => reject(r.error);
+	});
+	for (const [k, v] of Object.entries(s.localStorage || {})) localStorage.setItem(k, v);
+	for (const [k, v] of Object.entries(s.sessionStorage || {})) sessionStorage.setItem(k, v);
+	const dbs = s.indexedDB || [];
+	if (!dbs.length) return;
+	const existing = indexedDB.databases ? (await indexedDB.databases()).map(d => d.name) : [];
+	for (const d of dbs) {
+		const stores = d.stores || [];
+		let version = d.version || 1;
+		if (existing.includes(d.name)) {
+			const probe = await req(indexedDB.open(d.name));
+			const missing = stores.some(st => !probe.objectStoreNames.contains(st.name));
+			version = Math.max(missing ? probe.version + 1 : probe.version, d.version || 0);
+			probe.close();
+		}
+		const open = indexedDB.open(d.name, version);
+		open.onupgradeneeded = () => {
+			for (const st of stores) {
+				if (open.result.objectStoreNames.contains(st.name)) continue;
+				const kp = st.keyPath ? (st.keyPath.includes(',') ? st.keyPath.split(',') : st.keyPath) : undefined;
+				open.result.createObjectStore(st.name, { keyPath: kp, autoIncrement: !!st.autoIncrement });
+			}
+		};
+		const db = await Promise.race([req(open), new Promise((_, reject) => {
+			open.onblocked = () => reject(new Error('IndexedDB database "' + d.name + '" is open in the page and cannot be upgraded; clear it and reload first'));
+		})]);
+		const names = stores.filter(st => (st.records || []).length).map(st => st.name);
+		if (names.length) {
+			const tx = db.transaction(names, 'readwrite');
+			for (const st of stores) {
+				if (!names.includes(st.name)) continue;
+				const store = tx.objectStore(st.name);
+				for (const r of st.records) {
+					if (store.keyPath === null && r.key !== undefined && r.key !== null) store.put(r.value, r.key);
+					else store.put(r.value);
+				}
+			}
+			await new Promise((resolve, reject) => {
+				tx.oncomplete = resolve;
+				tx.onerror = () => reject(tx.error);
+				tx.onabort = () => reject(tx.error);
+			});
+		}
+		db.close();
+	}
+}`
+
+// pageURL returns the URL of the page currently loaded.
+func (r *RodBrowserPage) pageURL() (string, error) {
+	info, err := r.page.Info()
+	if err != nil {
+		return "", fmt.Errorf("reading page URL: %w", err)
+	}
+	return info.URL, nil
+}
+
+// GetStorage reads the storage of the current page's origin: local and
+// session storage, the cookies sent to the page and its IndexedDB databases
+// (at most 100 records per object store).
+func (r *RodBrowserPage) GetStorage() (*StorageState, error) {
+	res, err := r.page.Eval(readStorageScript, maxIndexedDBRecords)
+	if err != nil {
+		return nil, fmt.Errorf("reading storage: %w", err)
+	}
+	var s StorageState
+	if err := json.Unmarshal([]byte(res.Value.JSON("", "")), &s); err != nil {
+		return nil, fmt.Errorf("decoding storage: %w", err)
+	}
+	cookies, err := proto.NetworkGetCookies{}.Call(r.page)
+	if err != nil {
+		return nil, fmt.Errorf("reading cookies: %w", err)
+	}
+	for _, c := range cookies.Cookies {
+		cookie := Cookie{
+			Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path,
+			HTTPOnly: c.HTTPOnly, Secure: c.Secure, SameSite: string(c.SameSite),
+		}
+		if !c.Session {
+			cookie.Expires = float64(c.Expires)
+		}
+		s.Cookies = append(s.Cookies, cookie)
+	}
+	return &s, nil
+}
+
+// setCookies writes cookies, scoping those without a domain to pageURL.
+func (r *RodBrowserPage) setCookies(cookies []Cookie, pageURL string) error {
+	if len(cookies) == 0 {
+		return nil
+	}
+	params := make([]*proto.NetworkCookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		p := &proto.NetworkCookieParam{
+			Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path,
+			Secure: c.Secure, HTTPOnly: c.HTTPOnly, SameSite: proto.NetworkCookieSameSite(c.SameSite),
+			Expires: proto.TimeSinceEpoch(c.Expires),
+		}
+		if p.Domain == "" {
+			p.URL = pageURL
+		}
+		if p.Path == "" {
+			p.Path = "/"
+		}
+		params = append(params, p)
+	}
+	if err := (proto.NetworkSetCookies{Cookies: params}).Call(r.page); err != nil {
+		return fmt.Errorf("setting cookies: %w", err)
+	}
+	return nil
+}
+
+// SetStorage merges s into the current page's origin. The game only sees
+// the new values once it reads them again, usually after a reload.
+func (r *RodBrowserPage) SetStorage(s StorageState) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	pageURL, err := r.pageURL()
+	if err != nil {
+		return err
+	}
+	if err := r.setCookies(s.Cookies, pageURL); err != nil {
+		return err
+	}
+	if !s.originScoped() {
+		return nil
+	}
+	if _, err := r.page.Eval(writeStorageScript, s); err != nil {
+		return fmt.Errorf("writing storage: %w", err)
+	}
+	return nil
+}
+
+// ClearStorage deletes the given areas (see StorageAreas) of the current
+// page's origin, or all of them when none are given. Cookies are cleared for
+// the whole browser. IndexedDB databases the game holds open are closed.
+func (r *RodBrowserPage) ClearStorage(areas ...string) error {
+	if len(areas) == 0 {
+		areas = StorageAreas()
+	}
+	var originTypes []string
+	clearSession, clearCookies := false, false
+	for _, area := range areas {
+		switch area {
+		case StorageLocal:
+			originTypes = append(originTypes, "local_storage")
+		case StorageIndexedDB:
+			originTypes = append(originTypes, "indexeddb")
+		case StorageSession:
+			clearSession = true
+		case StorageCookies:
+			clearCookies = true
+		default:
+			return fmt.Errorf("unknown storage area %q (use %s)", area, strings.Join(StorageAreas(), ", "))
+		}
+	}
+	if len(originTypes) > 0 {
+		pageURL, err := r.pageURL()
+		if err != nil {
+			return err
+		}
+		origin, err := storageOrigin(pageURL)
+		if err != nil {
+			return err
+		}
+		if err := (proto.StorageClearDataForOrigin{Origin: origin, StorageTypes: strings.Join(originTypes, ",")}).Call(r.page); err != nil {
+			return fmt.Errorf("clearing %s: %w", strings.Join(originTypes, ", "), err)
+		}
+	}
+	if clearSession {
+		if _, err := r.page.Eval(`() => sessionStorage.clear()`); err != nil {
+			return fmt.Errorf("clearing sessionStorage: %w", err)
+		}
+	}
+	if clearCookies {
+		if err := (proto.NetworkClearBrowserCookies{}).Call(r.page); err != nil {
+			return fmt.Errorf("clearing cookies: %w", err)
+		}
+	}
+	return nil
+}
+
+// SeedStorage replaces the storage of pageURL's origin with s before the page
+// is loaded, so the game boots with exactly that state: whatever an earlier
+// session saved is cleared first, cookies included. The origin is opened on a
+// placeholder page served by the browser itself. A paused clock is resumed
+// first, otherwise the placeholder could never load. Call it before navigating
+// to pageURL.
+func (r *RodBrowserPage) SeedStorage(pageURL string, s StorageState) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	origin, err := storageOrigin(pageURL)
+	if err != nil {
+		return err
+	}
+	seedURL := origin + storageSeedPath
+	if r.TimePaused() {
+		if err := r.SetTimePaused(false); err != nil {
+			return err
+		}
+	}
+
+	router := r.page.HijackRequests()
+	if err := router.Add(seedURL, "", func(h *rod.Hijack) {
+		h.Response.SetHeader("Content-Type", "text/html; charset=utf-8")
+		h.Response.SetBody("<!DOCTYPE html><title>storage seed</title>")
+	}); err != nil {
+		return fmt.Errorf("serving storage seed page: %w", err)
+	}
+	go router.Run()
+	defer router.Stop()
+
+	page := r.page.Timeout(20 * time.Second)
+	if err := page.Navigate(seedURL); err != nil {
+		return fmt.Errorf("loading storage seed page: %w", err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return fmt.Errorf("loading storage seed page: %w", err)
+	}
+	if err := r.ClearStorage(); err != nil {
+		return err
+	}
+	if err := r.setCookies(s.Cookies, pageURL); err != nil {
+		return err
+	}
+	if !s.originScoped() {
+		return nil
+	}
+	if _, err := page.Eval(writeStorageScript, s); err != nil {
+		return fmt.Errorf("seeding storage: %w", err)
+	}
+	return nil
+}
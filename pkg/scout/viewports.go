@@ -2,16 +2,19 @@ package scout
 
 // ViewportPreset defines a device viewport configuration.
 type ViewportPreset struct {
-	Name             string
-	Label            string
-	Category         string
-	Width            int
-	Height           int
-	DevicePixelRatio float64
+	Name             string  `yaml:"name" json:"name"`
+	Label            string  `yaml:"label" json:"label"`
+	Category         string  `yaml:"category" json:"category"`
+	Width            int     `yaml:"width" json:"width"`
+	Height           int     `yaml:"height" json:"height"`
+	DevicePixelRatio float64 `yaml:"devicePixelRatio" json:"devicePixelRatio"`
+	UserAgent        string  `yaml:"userAgent,omitempty" json:"userAgent,omitempty"` // "" = the browser's own
+	Touch            bool    `yaml:"touch,omitempty" json:"touch,omitempty"`         // touch input outside the phone and tablet categories
 }
 
 // viewportPresets is the Go-side lookup table matching the frontend presets.
-var viewportPresets = []ViewportPreset{
+// Every phone and tablet also gets a landscape variant (see withLandscapeVariants).
+var viewportPresets = withLandscapeVariants([]ViewportPreset{
 	// Agent-optimized (smaller viewport for SwiftShader performance)
 	{Name: "agent-default", Label: "Agent Optimized", Category: "Desktop", Width: 960, Height: 540, DevicePixelRatio: 1},
 
@@ -52,16 +55,59 @@ var viewportPresets = []ViewportPreset{
 	// Android — Tablets
 	{Name: "samsung-tab-s9", Label: "Samsung Tab S9", Category: "Android Tablet", Width: 800, Height: 1280, DevicePixelRatio: 2},
 	{Name: "pixel-tablet", Label: "Pixel Tablet", Category: "Android Tablet", Width: 800, Height: 1280, DevicePixelRatio: 2},
+})
+
+// landscapeSuffix is appended to a preset name to get its landscape variant.
+const landscapeSuffix = "-landscape"
+
+// Orientation returns OrientationLandscape when the preset is wider than it is
+// tall and OrientationPortrait otherwise.
+func (p ViewportPreset) Orientation() string {
+	return orientationOf(p.Width, p.Height)
+}
+
+// Landscape returns the preset rotated to landscape, e.g. "iphone-16" becomes
+// "iphone-16-landscape" at 852x393.
+func (p ViewportPreset) Landscape() ViewportPreset {
+	p.Name += landscapeSuffix
+	p.Label += " (Landscape)"
+	p.Width, p.Height = p.Height, p.Width
+	return p
+}
+
+// IsTouch reports whether the preset dispatches touch events: phones, tablets
+// and presets with Touch set.
+func (p ViewportPreset) IsTouch() bool {
+	return p.Touch || isTouchCategory(p.Category)
+}
+
+// withLandscapeVariants appends a landscape variant of every portrait touch
+// preset, keeping the portrait presets in their original order first. A
+// variant already defined by name is left alone.
+func withLandscapeVariants(presets []ViewportPreset) []ViewportPreset {
+	out := append([]ViewportPreset(nil), presets...)
+	defined := make(map[string]bool, len(presets))
+	for _, p := range presets {
+		defined[p.Name] = true
+	}
+	for _, p := range presets {
+		if p.IsTouch() && p.Orientation() == OrientationPortrait && !defined[p.Name+landscapeSuffix] {
+			out = append(out, p.Landscape())
+		}
+	}
+	return out
 }
 
 // DefaultViewportName is the default viewport preset name.
 const DefaultViewportName = "desktop-std"
 
-// GetViewportByName returns the viewport preset with the given name, or nil if not found.
-func GetViewportByName(name string) *ViewportPreset {
-	for i := range viewportPresets {
-		if viewportPresets[i].Name == name {
-			return &viewportPresets[i]
+// GetViewportByName returns the viewport preset with the given name, or nil if
+// not found. Project presets are consulted first, then those loaded with
+// LoadViewportFile, then the built-in ones.
+func GetViewportByName(name string, project ...ViewportPreset) *ViewportPreset {
+	for _, p := range ViewportPresets(project) {
+		if p.Name == name {
+			return &p
 		}
 	}
 	return nil
@@ -0,0 +1,144 @@
+package scout
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// MaxAdvanceTime is the longest AdvanceTime accepts in one call.
+const MaxAdvanceTime = 2 * time.Minute
+
+// virtualTimeStarvation is the number of tasks after which Chrome forces
+// virtual time forward, so a game loop that always has work queued cannot
+// hold it still.
+const virtualTimeStarvation = 100
+
+// virtualTimeSession returns the CDP session that controls the page's virtual
+// time, attaching one when needed. Virtual time gets its own session because
+// Chrome only hands the page back to real time when the session that enabled
+// it detaches.
+func (r *RodBrowserPage) virtualTimeSession() (*rod.Page, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.timeSession != nil {
+		return r.timeSession, nil
+	}
+	browser := r.page.Browser()
+	res, err := proto.TargetAttachToTarget{TargetID: r.page.TargetID, Flatten: true}.Call(browser)
+	if err != nil {
+		return nil, fmt.Errorf("attaching virtual time session: %w", err)
+	}
+	r.timeSession = browser.PageFromSession(res.SessionID)
+	return r.timeSession, nil
+}
+
+// releaseVirtualTime detaches the virtual time session, returning the page to
+// real time.
+func (r *RodBrowserPage) releaseVirtualTime() error {
+	r.mu.Lock()
+	session := r.timeSession
+	r.timeSession = nil
+	r.mu.Unlock()
+	if session == nil {
+		return nil
+	}
+	if err := (proto.TargetDetachFromTarget{SessionID: session.SessionID}).Call(r.page.Browser()); err != nil {
+		return fmt.Errorf("resuming real time: %w", err)
+	}
+	return nil
+}
+
+func setVirtualTimePolicy(session *rod.Page, policy proto.EmulationVirtualTimePolicy, budget time.Duration) error {
+	req := proto.EmulationSetVirtualTimePolicy{Policy: policy}
+	if budget > 0 {
+		ms := float64(budget.Milliseconds())
+		starvation := virtualTimeStarvation
+		req.Budget = &ms
+		req.MaxVirtualTimeTaskStarvationCount = &starvation
+	}
+	_, err := req.Call(session)
+	return err
+}
+
+// TimePaused reports whether the page's clock is frozen by SetTimePaused.
+func (r *RodBrowserPage) TimePaused() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.timePaused
+}
+
+// SetTimePaused freezes or resumes the page's clock through CDP virtual time.
+// While paused, timers, requestAnimationFrame, performance.now and Date stand
+// still for every engine, so the game loop stops; input is queued until time
+// runs again.
+func (r *RodBrowserPage) SetTimePaused(paused bool) error {
+	if !paused {
+		if err := r.releaseVirtualTime(); err != nil {
+			return err
+		}
+		r.mu.Lock()
+		r.timePaused = false
+		r.mu.Unlock()
+		return nil
+	}
+	session, err := r.virtualTimeSession()
+	if err != nil {
+		return err
+	}
+	if err := setVirtualTimePolicy(session, proto.EmulationVirtualTimePolicyPause, 0); err != nil {
+		return fmt.Errorf("pausing time: %w", err)
+	}
+	r.mu.Lock()
+	r.timePaused = true
+	r.mu.Unlock()
+	return nil
+}
+
+// AdvanceTime runs the page's clock forward by d of virtual time as fast as
+// the page can execute it: timers due in that span fire without waiting, and
+// animations driven by performance.now or frame deltas jump ahead. Time stands
+// still while network requests are pending, so loading screens finish only
+// once their assets have arrived. The page returns to its previous state
+// afterwards: real time, or paused if SetTimePaused(true) was in effect.
+func (r *RodBrowserPage) AdvanceTime(d time.Duration) error {
+	if d <= 0 || d > MaxAdvanceTime {
+		return fmt.Errorf("advance time: duration must be between 1ms and %s, got %s", MaxAdvanceTime, d)
+	}
+	wasPaused := r.TimePaused()
+	session, err := r.virtualTimeSession()
+	if err != nil {
+		return err
+	}
+
+	timeout := 30*time.Second + 2*d
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	expired := false
+	wait := session.Context(ctx).EachEvent(func(*proto.EmulationVirtualTimeBudgetExpired) bool {
+		expired = true
+		return true
+	})
+	if err := setVirtualTimePolicy(session, proto.EmulationVirtualTimePolicyPauseIfNetworkFetchesPending, d); err != nil {
+		if !wasPaused {
+			_ = r.releaseVirtualTime()
+		}
+		return fmt.Errorf("advancing time: %w", err)
+	}
+	wait()
+
+	if wasPaused {
+		if !expired {
+			_ = setVirtualTimePolicy(session, proto.EmulationVirtualTimePolicyPause, 0)
+		}
+	} else if err := r.releaseVirtualTime(); err != nil {
+		return err
+	}
+	if !expired {
+		return fmt.Errorf("advancing time: %s of virtual time did not elapse within %s, likely held by pending network requests", d, timeout)
+	}
+	return nil
+}
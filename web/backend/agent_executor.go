@@ -20,7 +20,7 @@ import (
 )
 
 // launchAgentTestRun starts executeAgentTestRun in a goroutine with panic recovery.
-func (s *Server) launchAgentTestRun(planID, testID, analysisID, planName, viewport, createdBy string) {
+func (s *Server) launchAgentTestRun(planID, testID, analysisID, planName, viewport, createdBy string, randomSeed *uint32) {
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -28,14 +28,14 @@ func (s *Server) launchAgentTestRun(planID, testID, analysisID, planName, viewpo
 				s.finishTestRun(planID, testID, planName, time.Now(), nil, fmt.Errorf("panic: %v", r), createdBy)
 			}
 		}()
-		s.executeAgentTestRun(planID, testID, analysisID, planName, createdBy, viewport)
+		s.executeAgentTestRun(planID, testID, analysisID, planName, createdBy, viewport, randomSeed)
 	}()
 }
 
 // executeAgentTestRun runs test scenarios using an AI agent with browser tools.
 // The agent receives each scenario's steps and autonomously executes them,
 // calling report_result when done.
-func (s *Server) executeAgentTestRun(planID, testID, analysisID, planName, createdBy, viewport string) {
+func (s *Server) executeAgentTestRun(planID, testID, analysisID, planName, createdBy, viewport string, randomSeed *uint32) {
 	// Acquire browser test concurrency slot (only one Chrome for tests at a time)
 	select {
 	case s.browserTestSem <- struct{}{}:
@@ -72,6 +72,7 @@ func (s *Server) executeAgentTestRun(planID, testID, analysisID, planName, creat
 		Flows:      []store.FlowResult{},
 		Logs:       []string{},
 		Status:     "running",
+		RandomSeed: randomSeed,
 	}
 	s.runningTests.Register(testID, rt)
 
@@ -87,23 +88,28 @@ func (s *Server) executeAgentTestRun(planID, testID, analysisID, planName, creat
 	})
 
 	// Resolve viewport
-	vp := scout.GetViewportByName(viewport)
-	if vp == nil {
-		vp = scout.GetViewportByName(scout.DefaultViewportName)
-	}
+	vp := s.resolveViewport(planID, viewport)
 	if vp == nil {
 		s.finishTestRun(planID, testID, planName, startTime, nil, fmt.Errorf("no viewport preset found for %q", viewport), createdBy)
 		return
 	}
 
-	// Create AI client
-	apiKey := os.Getenv("ANTHROPIC_API_KEY")
-	if apiKey == "" {
-		s.finishTestRun(planID, testID, planName, startTime, nil, fmt.Errorf("ANTHROPIC_API_KEY not set"), createdBy)
+	// Resolve the spend budget: the analysis' per-run budget, tightened by what is left
+	// of the project's monthly cap. It stays reserved until the run's cost is saved.
+	budget, releaseBudget, err := s.testRunBudget(analysisID)
+	if err != nil {
+		s.finishTestRun(planID, testID, planName, startTime, nil, err, createdBy)
 		return
 	}
+	defer releaseBudget()
+
+	// Create AI client
 	aiModel := envOrDefault("WIZARDS_QA_TEST_MODEL", "claude-sonnet-4-5-20250929")
-	aiClient := ai.NewClaudeClient(apiKey, aiModel, 0.3, 4096)
+	aiClient, err := newTestAIClient(aiModel)
+	if err != nil {
+		s.finishTestRun(planID, testID, planName, startTime, nil, err, createdBy)
+		return
+	}
 
 	// Launch headless browser
 	ctx, cancel := context.WithTimeout(s.serverCtx, AnalysisTimeout)
@@ -126,23 +132,36 @@ func (s *Server) executeAgentTestRun(planID, testID, analysisID, planName, creat
 		DevicePixelRatio: agentDPR,
 		Timeout:          30 * time.Second,
 		DeviceCategory:   vp.Category,
+		Touch:            vp.Touch,
+		UserAgent:        vp.UserAgent,
+		RandomSeed:       randomSeed,
 	})
 	if err != nil {
 		s.finishTestRun(planID, testID, planName, startTime, nil, fmt.Errorf("launching browser: %w", err), createdBy)
 		return
 	}
 	defer cleanup()
+	defer s.saveTestHAR(testID, browserPage)
 
-	toolExec := &ai.BrowserToolExecutor{Page: browserPage}
+	probes := s.analysisProbes(analysisID)
+	initialStorage := s.planStorageState(planID)
+	if initialStorage != nil {
+		s.broadcastTestLog(testID, planID, fmt.Sprintf("Seeding initial storage before each scenario: %s", initialStorage.Summary()))
+	}
+	toolExec := &ai.BrowserToolExecutor{Page: browserPage, Probes: probes, Viewport: *vp}
 	s.broadcastTestLog(testID, planID, fmt.Sprintf("Browser ready (%dx%d @ %.1fx)", vp.Width, vp.Height, vp.DevicePixelRatio))
+	if randomSeed != nil {
+		s.broadcastTestLog(testID, planID, fmt.Sprintf("Random seed: %d (pass randomSeed to replay this run)", *randomSeed))
+	}
 
 	// Build tools: browser tools + report_result
-	tools := testExecutorTools(vp.Width, vp.Height)
+	tools := testExecutorTools(vp.Width, vp.Height, browserPage.TouchEnabled(), probes)
 
 	var flowResults []store.FlowResult
 
 	// Track cumulative token usage for cost tracking
 	var totalUsage ai.TokenUsage
+	budgetExhausted := false
 
 	for fi, scenario := range scenarios {
 		flowStart := time.Now()
@@ -159,6 +178,18 @@ func (s *Server) executeAgentTestRun(planID, testID, analysisID, planName, creat
 
 		s.broadcastTestLog(testID, planID, fmt.Sprintf("--- Scenario %d/%d: %s ---", fi+1, totalFlows, scenario.Name))
 
+		if err := toolExec.ResetPage(); err != nil {
+			s.broadcastTestLog(testID, planID, fmt.Sprintf("  Warning: resetting the page failed: %v", err))
+		}
+
+		// Seed the project's initial storage so every scenario starts as the
+		// same player, whatever earlier scenarios saved
+		if initialStorage != nil {
+			if err := browserPage.SeedStorage(gameURL, *initialStorage); err != nil {
+				s.broadcastTestLog(testID, planID, fmt.Sprintf("  Warning: seeding storage failed: %v", err))
+			}
+		}
+
 		// Navigate to game URL
 		s.broadcastTestLog(testID, planID, fmt.Sprintf("  Navigating to %s", gameURL))
 		if err := browserPage.Navigate(gameURL); err != nil {
@@ -233,12 +264,21 @@ func (s *Server) executeAgentTestRun(planID, testID, analysisID, planName, creat
 				break
 			}
 
-			// Accumulate token usage
-			totalUsage.InputTokens += resp.Usage.InputTokens
-			totalUsage.OutputTokens += resp.Usage.OutputTokens
-			totalUsage.CacheCreationInputTokens += resp.Usage.CacheCreationInputTokens
-			totalUsage.CacheReadInputTokens += resp.Usage.CacheReadInputTokens
-			totalUsage.APICallCount++
+			// Accumulate token usage, priced per answering model (fallback chains may mix providers)
+			answeredBy := resp.Model
+			if answeredBy == "" {
+				answeredBy = aiModel
+			}
+			totalUsage.AddForModel(answeredBy, resp.Usage.InputTokens, resp.Usage.OutputTokens,
+				resp.Usage.CacheCreationInputTokens, resp.Usage.CacheReadInputTokens)
+
+			// Stop the run once the hard spend budget is used up
+			if exhausted, reason := budget.Exceeded(&totalUsage, aiModel); exhausted {
+				failReason = reason
+				flowFailed = true
+				budgetExhausted = true
+				break
+			}
 
 			// Append assistant response directly (same pattern as agent.go)
 			messages = append(messages, ai.AgentMessage{Role: "assistant", Content: resp.Content})
@@ -447,14 +487,36 @@ func (s *Server) executeAgentTestRun(planID, testID, analysisID, planName, creat
 				"duration": formatDuration(flowDuration),
 			},
 		})
+
+		if budgetExhausted {
+			// Record the remaining scenarios as not run so totals still add up
+			for _, skipped := range scenarios[fi+1:] {
+				fr := store.FlowResult{
+					Name:     skipped.Name,
+					Status:   store.StatusFailed,
+					Duration: formatDuration(0),
+					Reason:   "not run: " + failReason,
+				}
+				flowResults = append(flowResults, fr)
+				s.runningTests.AppendFlow(testID, fr)
+			}
+			s.broadcastTestLog(testID, planID, fmt.Sprintf("Spend budget exhausted — skipped %d remaining scenario(s)", totalFlows-fi-1))
+			break
+		}
 	}
 
-	// Persist total credits for the test run
-	totalCostUSD := totalUsage.EstimatedCost(aiModel)
+	// Persist total credits and tokens for the test run (they count against the
+	// project's monthly caps), priced as of its start like the record's timestamp
+	totalCostUSD := totalUsage.EstimatedCostAt(aiModel, startTime)
+	for m := range totalUsage.ByModel {
+		if !ai.IsPriced(m) {
+			s.broadcastTestLog(testID, planID, fmt.Sprintf("Warning: no pricing for model %s; its cost is not included in the credits", m))
+		}
+	}
 	totalCredits := int(math.Ceil(totalCostUSD * 100))
-	if totalCredits > 0 {
-		if err := s.store.UpdateTestResultCredits(testID, totalCredits); err != nil {
-			log.Printf("Warning: failed to update test result credits for %s: %v", testID, err)
+	if totalCredits > 0 || totalUsage.TotalTokens > 0 {
+		if err := s.store.UpdateTestResultCost(testID, totalCredits, totalUsage.TotalTokens); err != nil {
+			log.Printf("Warning: failed to update test result cost for %s: %v", testID, err)
 		}
 	}
 
@@ -472,6 +534,81 @@ func (s *Server) executeAgentTestRun(planID, testID, analysisID, planName, creat
 	s.finishTestRun(planID, testID, planName, startTime, flowResults, nil, createdBy)
 }
 
+// newTestAIClient creates the tool-use client for agent test runs. The provider is
+// taken from WIZARDS_QA_TEST_PROVIDER, or detected from the model name
+// (claude-*, gemini-*, gpt-*/o1/o3/o4), with the matching *_API_KEY env var.
+// OPENAI_BASE_URL selects an OpenAI-compatible server, which may run without a key.
+// WIZARDS_QA_TEST_FALLBACK_MODELS (comma-separated) adds models to fail over to when
+// the primary is overloaded or rate limited.
+// WIZARDS_QA_AI_CASSETTE=record|replay wraps the client in an AI cassette stored under
+// WIZARDS_QA_AI_CASSETTE_DIR, so agent test runs can be replayed in CI without keys.
+func newTestAIClient(model string) (ai.ToolUseAgent, error) {
+	cassetteMode, err := ai.ParseCassetteMode(os.Getenv("WIZARDS_QA_AI_CASSETTE"))
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newTestModelClient(model, os.Getenv("WIZARDS_QA_TEST_PROVIDER"), cassetteMode == ai.CassetteReplay)
+	if err != nil {
+		return nil, err
+	}
+	if fallbacks := os.Getenv("WIZARDS_QA_TEST_FALLBACK_MODELS"); fallbacks != "" {
+		chain := []ai.Client{client}
+		for _, fbModel := range strings.Split(fallbacks, ",") {
+			fbModel = strings.TrimSpace(fbModel)
+			if fbModel == "" {
+				continue
+			}
+			fbClient, err := newTestModelClient(fbModel, "", cassetteMode == ai.CassetteReplay)
+			if err != nil {
+				return nil, fmt.Errorf("fallback %s: %w", fbModel, err)
+			}
+			chain = append(chain, fbClient)
+		}
+		client = ai.NewFallbackClient(chain...)
+	}
+
+	if cassetteMode != "" {
+		dir := envOrDefault("WIZARDS_QA_AI_CASSETTE_DIR", "testdata/ai-cassettes")
+		client, err = ai.NewCassetteClient(client, filepath.Join(dir, "agent-test"), cassetteMode)
+		if err != nil {
+			return nil, err
+		}
+	}
+	agent, ok := client.(ai.ToolUseAgent)
+	if !ok {
+		return nil, fmt.Errorf("AI client for %s does not support tool use for agent test runs", model)
+	}
+	return agent, nil
+}
+
+// newTestModelClient creates a client for one model, detecting the provider from the
+// model name when provider is empty. keyOptional skips the API key check (cassette replay).
+func newTestModelClient(model, provider string, keyOptional bool) (ai.Client, error) {
+	if provider == "" {
+		switch {
+		case strings.HasPrefix(model, "gemini"):
+			provider = "google"
+		case strings.HasPrefix(model, "gpt") || strings.HasPrefix(model, "o1") ||
+			strings.HasPrefix(model, "o3") || strings.HasPrefix(model, "o4"):
+			provider = "openai"
+		default:
+			provider = "anthropic"
+		}
+	}
+
+	keyEnv := ai.APIKeyEnvVar(provider)
+	if keyEnv == "" {
+		return nil, fmt.Errorf("unsupported AI provider: %s (use 'anthropic', 'google' or 'openai')", provider)
+	}
+	apiKey := os.Getenv(keyEnv)
+	if apiKey == "" && !keyOptional && ai.APIKeyRequired(provider, os.Getenv("OPENAI_BASE_URL")) {
+		return nil, fmt.Errorf("%s not set", keyEnv)
+	}
+
+	return ai.NewClientFromConfig(provider, apiKey, model, 0.3, 4096)
+}
+
 // extractScenariosFromAnalysis loads the analysis result from the DB and extracts TestScenario data.
 func (s *Server) extractScenariosFromAnalysis(analysisID string) ([]ai.TestScenario, string, error) {
 	analysis, err := s.store.GetAnalysis(analysisID)
@@ -510,9 +647,17 @@ func (s *Server) extractScenariosFromAnalysis(analysisID string) ([]ai.TestScena
 	return scenarios, analysis.GameURL, nil
 }
 
-// testExecutorTools returns browser tools plus the report_result tool.
-func testExecutorTools(vpWidth, vpHeight int) []ai.ToolDefinition {
+// testExecutorTools returns browser tools plus the report_result tool, the
+// touch_gesture and rotate_device tools on touch viewports and read_game_state
+// when the project defines probes.
+func testExecutorTools(vpWidth, vpHeight int, touch bool, probes ai.GameStateProbes) []ai.ToolDefinition {
 	tools := ai.BrowserTools(vpWidth, vpHeight)
+	if touch {
+		tools = append(tools, ai.TouchGestureTool(vpWidth, vpHeight), ai.RotateDeviceTool())
+	}
+	if len(probes) > 0 {
+		tools = append(tools, ai.ReadGameStateTool(probes.Names()))
+	}
 	tools = append(tools, ai.ToolDefinition{
 		Name:        "report_result",
 		Description: "Report the final result of the current test scenario. Call this when you have finished executing all steps and verified the expected outcomes, OR when a step has failed and you want to report the failure.",
@@ -555,7 +700,7 @@ func agentTestSystemPrompt(vpWidth, vpHeight int) string {
 ## Browser Info
 
 - Viewport: %dx%d pixels
-- Tools that modify the page (click, type_text, scroll, navigate) automatically return a screenshot.
+- Tools that modify the page (click, hover, drag, swipe, long_press, type_text, scroll, navigate) automatically return a screenshot.
 - Use the screenshot tool only when you need to observe without interacting.
 
 ## Important
@@ -18,6 +18,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/Global-Wizards/wizards-qa/pkg/ai"
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
 	"github.com/Global-Wizards/wizards-qa/web/backend/auth"
 	"github.com/Global-Wizards/wizards-qa/web/backend/store"
 	"github.com/Global-Wizards/wizards-qa/web/backend/ws"
@@ -49,6 +51,14 @@ type AnalysisRequest struct {
 	MaxTotalTimeout int             `json:"maxTotalTimeout,omitempty"` // minutes
 	Viewport        string          `json:"viewport,omitempty"`       // viewport preset name
 	SynthesisModel  string          `json:"synthesisModel,omitempty"` // secondary model for synthesis/flow gen
+	BudgetUSD       float64         `json:"budgetUsd,omitempty"`      // hard spend cap for agent exploration
+	BudgetTokens    int             `json:"budgetTokens,omitempty"`   // hard token cap for agent exploration
+	ThinkingBudget  int             `json:"thinkingBudget,omitempty"` // extended thinking tokens per agent step
+	Network         *scout.NetworkProfile `json:"network,omitempty"` // emulated connection for agent exploration
+	RandomSeed      *uint32         `json:"randomSeed,omitempty"`     // replays an earlier run's randomness; nil = fresh seed
+	Locale          string          `json:"locale,omitempty"`         // browser locale, e.g. "de-DE"
+	Timezone        string          `json:"timezone,omitempty"`       // IANA timezone ID, e.g. "Europe/Berlin"
+	Geolocation     *scout.Geolocation `json:"geolocation,omitempty"` // position reported to navigator.geolocation
 }
 
 type AnalysisProgress struct {
@@ -100,6 +110,33 @@ func (s *Server) handleAnalyzeGame(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "maxTotalTimeout must be between 1 and 60 minutes")
 		return
 	}
+	if req.BudgetUSD < 0 || req.BudgetTokens < 0 {
+		respondError(w, http.StatusBadRequest, "budgetUsd and budgetTokens must not be negative")
+		return
+	}
+	if req.ThinkingBudget != 0 && (req.ThinkingBudget < 1024 || req.ThinkingBudget > 64000) {
+		respondError(w, http.StatusBadRequest, "thinkingBudget must be between 1024 and 64000 tokens")
+		return
+	}
+	if req.Network, err = resolveNetworkRequest(req.Network); err != nil {
+		respondError(w, http.StatusBadRequest, "network: "+err.Error())
+		return
+	}
+	if req.AgentMode {
+		req.RandomSeed = s.resolveRandomSeed(req.ProjectID, req.RandomSeed)
+	}
+	if req.Locale, err = resolveLocaleRequest(req.Locale, req.Timezone, req.Geolocation); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.checkViewports(req.ProjectID, req.Viewport); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := s.projectBudgetRemaining(req.ProjectID); err != nil {
+		respondError(w, http.StatusPaymentRequired, err.Error())
+		return
+	}
 
 	analysisID := newID("analysis")
 
@@ -153,6 +190,12 @@ type BatchAnalysisRequest struct {
 	MaxTotalSteps   int               `json:"maxTotalSteps,omitempty"`
 	AdaptiveTimeout bool              `json:"adaptiveTimeout,omitempty"`
 	MaxTotalTimeout int               `json:"maxTotalTimeout,omitempty"`
+	Network         *scout.NetworkProfile `json:"network,omitempty"`
+	RandomSeed      *uint32           `json:"randomSeed,omitempty"`
+	Locale          string            `json:"locale,omitempty"`
+	Timezone        string            `json:"timezone,omitempty"`
+	Geolocation     *scout.Geolocation `json:"geolocation,omitempty"`
+	Locales         []string          `json:"locales,omitempty"` // run the wording check once per locale and merge the findings
 }
 
 func (s *Server) handleBatchAnalyze(w http.ResponseWriter, r *http.Request) {
@@ -181,6 +224,43 @@ func (s *Server) handleBatchAnalyze(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "Maximum 5 devices per batch")
 		return
 	}
+	for _, d := range req.Devices {
+		if err := s.checkViewports(req.ProjectID, d.Viewport); err != nil {
+			respondError(w, http.StatusBadRequest, "devices: "+err.Error())
+			return
+		}
+	}
+	if req.Network, err = resolveNetworkRequest(req.Network); err != nil {
+		respondError(w, http.StatusBadRequest, "network: "+err.Error())
+		return
+	}
+	if req.AgentMode {
+		req.RandomSeed = s.resolveRandomSeed(req.ProjectID, req.RandomSeed)
+	}
+	if req.Locale, err = resolveLocaleRequest(req.Locale, req.Timezone, req.Geolocation); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for i, l := range req.Locales {
+		if req.Locales[i], err = scout.NormalizeLocale(l); err != nil {
+			respondError(w, http.StatusBadRequest, "locales: "+err.Error())
+			return
+		}
+	}
+	if len(req.Locales) > 0 {
+		if runs := len(req.Devices) * len(req.Locales); runs > maxBatchRuns {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("Maximum %d device/locale runs per batch, got %d", maxBatchRuns, runs))
+			return
+		}
+		if req.Modules, err = localeBatchModules(req.Modules); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if _, err := s.projectBudgetRemaining(req.ProjectID); err != nil {
+		respondError(w, http.StatusPaymentRequired, err.Error())
+		return
+	}
 
 	var createdBy string
 	if claims := auth.UserFromContext(r.Context()); claims != nil {
@@ -216,8 +296,11 @@ func (s *Server) handleBatchAnalyze(w http.ResponseWriter, r *http.Request) {
 type deviceResult struct {
 	Device    string                 `json:"device"`
 	Viewport  string                 `json:"viewport"`
+	Locale    string                 `json:"locale,omitempty"`
+	HAR       string                 `json:"har,omitempty"` // ?device= key of the run's HAR download
 	FlowCount int                    `json:"flowCount"`
-	Status    string                 `json:"status"` // "completed" or "failed"
+	Wording   int                    `json:"wordingFindings,omitempty"` // locale batches: wording findings of this run
+	Status    string                 `json:"status"`                    // "completed" or "failed"
 	Error     string                 `json:"error,omitempty"`
 	Flows     []interface{}          `json:"-"`
 	PageMeta  map[string]interface{} `json:"-"`
@@ -277,6 +360,24 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 		if req.MaxTotalTimeout > 0 {
 			p["maxTotalTimeout"] = req.MaxTotalTimeout
 		}
+		if req.Network != nil {
+			p["network"] = req.Network
+		}
+		if req.RandomSeed != nil {
+			p["randomSeed"] = *req.RandomSeed
+		}
+		if req.Locale != "" {
+			p["locale"] = req.Locale
+		}
+		if req.Timezone != "" {
+			p["timezone"] = req.Timezone
+		}
+		if req.Geolocation != nil {
+			p["geolocation"] = req.Geolocation
+		}
+		if len(req.Locales) > 0 {
+			p["locales"] = req.Locales
+		}
 		// Store device configs in profile for reference
 		devicesJSON, _ := json.Marshal(req.Devices)
 		p["devices"] = string(devicesJSON)
@@ -367,7 +468,8 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 			perDeviceTimeout = maxClamp
 		}
 	}
-	totalTimeout := perDeviceTimeout * time.Duration(len(req.Devices))
+	runs := batchRuns(req)
+	totalTimeout := perDeviceTimeout * time.Duration(len(runs))
 	if totalTimeout > 60*time.Minute {
 		totalTimeout = 60 * time.Minute
 	}
@@ -385,17 +487,20 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 	var gameName, framework string
 	totalFlowCount := 0
 	agentStepOffset := 0
+	wordingByLocale := map[string][]ai.WordingFinding{}
 
-	for i, device := range req.Devices {
+	for i, run := range runs {
+		device := run.Device
 		deviceNum := i + 1
-		deviceTotal := len(req.Devices)
-		deviceLabel := fmt.Sprintf("[%s %d/%d]", device.Category, deviceNum, deviceTotal)
+		deviceTotal := len(runs)
+		deviceLabel := run.label(deviceNum, deviceTotal)
 
 		// Check if context is already cancelled
 		if ctx.Err() != nil {
 			deviceResults = append(deviceResults, deviceResult{
 				Device:   device.Category,
 				Viewport: device.Viewport,
+				Locale:   run.Locale,
 				Status:   "failed",
 				Error:    "Batch timed out before this device could run",
 			})
@@ -453,6 +558,7 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 			deviceResults = append(deviceResults, deviceResult{
 				Device:   device.Category,
 				Viewport: device.Viewport,
+				Locale:   run.Locale,
 				Status:   "failed",
 				Error:    fmt.Sprintf("Failed to create temp dir: %v", err),
 			})
@@ -466,6 +572,11 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 			if req.AgentSteps > 0 {
 				args = append(args, "--agent-steps", fmt.Sprintf("%d", req.AgentSteps))
 			}
+			args = append(args, s.probesArgs(req.ProjectID, tmpDir)...)
+			args = append(args, s.storageStateArgs(req.ProjectID, tmpDir)...)
+			args = append(args, harArgs(tmpDir)...)
+			args = append(args, networkArgs(req.Network)...)
+			args = append(args, randomSeedArgs(req.RandomSeed)...)
 		}
 		if req.Adaptive {
 			args = append(args, "--adaptive")
@@ -489,6 +600,8 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 			args = append(args, "--temperature", fmt.Sprintf("%g", *req.Temperature))
 		}
 		args = append(args, "--viewport", device.Viewport)
+		args = append(args, s.viewportArgs(req.ProjectID, tmpDir)...)
+		args = append(args, localeArgs(run.Locale, req.Timezone, req.Geolocation)...)
 		if req.Modules.UIUX != nil && !*req.Modules.UIUX {
 			args = append(args, "--no-uiux")
 		}
@@ -507,6 +620,9 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 		if len(req.Modules.GLIJurisdictions) > 0 {
 			args = append(args, "--gli-jurisdictions", strings.Join(req.Modules.GLIJurisdictions, ","))
 		}
+		if req.Modules.NavigationMap != nil && !*req.Modules.NavigationMap {
+			args = append(args, "--no-nav-map")
+		}
 
 		log.Printf("Batch analysis %s [%s %d/%d]: executing %s %s", analysisID, device.Category, deviceNum, deviceTotal, cliPath, strings.Join(args, " "))
 		cmd := exec.CommandContext(ctx, cliPath, args...)
@@ -518,6 +634,7 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 			deviceResults = append(deviceResults, deviceResult{
 				Device:   device.Category,
 				Viewport: device.Viewport,
+				Locale:   run.Locale,
 				Status:   "failed",
 				Error:    fmt.Sprintf("stdout pipe: %v", err),
 			})
@@ -530,6 +647,7 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 			deviceResults = append(deviceResults, deviceResult{
 				Device:   device.Category,
 				Viewport: device.Viewport,
+				Locale:   run.Locale,
 				Status:   "failed",
 				Error:    fmt.Sprintf("stderr pipe: %v", err),
 			})
@@ -542,6 +660,7 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 			deviceResults = append(deviceResults, deviceResult{
 				Device:   device.Category,
 				Viewport: device.Viewport,
+				Locale:   run.Locale,
 				Status:   "failed",
 				Error:    fmt.Sprintf("stdin pipe: %v", err),
 			})
@@ -553,6 +672,7 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 			deviceResults = append(deviceResults, deviceResult{
 				Device:   device.Category,
 				Viewport: device.Viewport,
+				Locale:   run.Locale,
 				Status:   "failed",
 				Error:    fmt.Sprintf("Failed to start CLI: %v", err),
 			})
@@ -583,7 +703,14 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 					rest := line[len("PROGRESS:"):]
 					parts := strings.SplitN(rest, ":", 2)
 					step := strings.TrimSpace(parts[0])
-					if step != "cost_estimate" {
+					if step == "ai_stream" {
+						// Live AI output is relayed to the UI only; it is not a pipeline step
+						if len(parts) > 1 {
+							s.broadcastAIStream(analysisID, parts[1])
+						}
+						continue
+					}
+					if step != "cost_estimate" && step != "pricing_warning" {
 						lastKnownStep = step
 					}
 					message := ""
@@ -621,6 +748,12 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 								InputTokens:  intFromMap(detailData, "inputTokens"),
 								OutputTokens: intFromMap(detailData, "outputTokens"),
 								Credits:      intFromMap(detailData, "credits"),
+								GameState:    gameStateFromDetail(detailData),
+							}
+							// Prefer the reasoning carried in the detail: it includes thinking and
+							// survives multi-line text, which agent_reasoning lines cut short
+							if r := strFromMap(detailData, "reasoning"); r != "" {
+								stepRecord.Reasoning = r
 							}
 							if dbID, saveErr := s.store.SaveAgentStep(stepRecord); saveErr != nil {
 								log.Printf("Warning: failed to save agent step %d for %s: %v", stepRecord.StepNumber, analysisID, saveErr)
@@ -752,6 +885,10 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 		statusWg.Wait()
 
 		cmdErr := cmd.Wait()
+		var harKey string
+		if agentMode && s.persistAnalysisHAR(analysisID, tmpDir, run.harKey()) {
+			harKey = run.harKey()
+		}
 
 		// Clean up active analysis registration.
 		// Safe: cmd.Wait() above guarantees the subprocess has exited, so the stderr
@@ -779,6 +916,8 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 			deviceResults = append(deviceResults, deviceResult{
 				Device:   device.Category,
 				Viewport: device.Viewport,
+				Locale:   run.Locale,
+				HAR:      harKey,
 				Status:   "failed",
 				Error:    userMsg,
 			})
@@ -793,6 +932,8 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 			deviceResults = append(deviceResults, deviceResult{
 				Device:   device.Category,
 				Viewport: device.Viewport,
+				Locale:   run.Locale,
+				HAR:      harKey,
 				Status:   "failed",
 				Error:    fmt.Sprintf("Failed to parse CLI output: %v", err),
 			})
@@ -834,6 +975,14 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 			}
 		}
 
+		// Collect wording findings per locale for locale batches
+		wordingCount := 0
+		if len(req.Locales) > 0 {
+			findings := wordingFindingsOf(result)
+			wordingByLocale[run.Locale] = append(wordingByLocale[run.Locale], findings...)
+			wordingCount = len(findings)
+		}
+
 		// Use first device as primary for pageMeta and analysis
 		if i == 0 {
 			if pm, ok := result["pageMeta"].(map[string]interface{}); ok {
@@ -872,7 +1021,10 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 		deviceResults = append(deviceResults, deviceResult{
 			Device:    device.Category,
 			Viewport:  device.Viewport,
+			Locale:    run.Locale,
+			HAR:       harKey,
 			FlowCount: deviceFlowCount,
+			Wording:   wordingCount,
 			Status:    "completed",
 		})
 
@@ -904,6 +1056,13 @@ func (s *Server) executeBatchAnalysis(analysisID, createdBy string, req BatchAna
 		"agentSteps": allAgentSteps,
 		"devices":    deviceResults,
 	}
+	if len(req.Locales) > 0 {
+		if primaryAnalysis == nil {
+			primaryAnalysis = map[string]interface{}{}
+		}
+		primaryAnalysis["wordingCheck"] = nonNil(ai.MergeWordingFindings(req.Locales, wordingByLocale))
+		mergedResult["locales"] = req.Locales
+	}
 	if primaryPageMeta != nil {
 		mergedResult["pageMeta"] = primaryPageMeta
 	}
@@ -1046,6 +1205,30 @@ func (s *Server) executeAnalysis(analysisID, createdBy string, req AnalysisReque
 		if req.Viewport != "" {
 			p["viewport"] = req.Viewport
 		}
+		if req.BudgetUSD > 0 {
+			p["budgetUsd"] = req.BudgetUSD
+		}
+		if req.BudgetTokens > 0 {
+			p["budgetTokens"] = req.BudgetTokens
+		}
+		if req.ThinkingBudget > 0 {
+			p["thinkingBudget"] = req.ThinkingBudget
+		}
+		if req.Network != nil {
+			p["network"] = req.Network
+		}
+		if req.RandomSeed != nil {
+			p["randomSeed"] = *req.RandomSeed
+		}
+		if req.Locale != "" {
+			p["locale"] = req.Locale
+		}
+		if req.Timezone != "" {
+			p["timezone"] = req.Timezone
+		}
+		if req.Geolocation != nil {
+			p["geolocation"] = req.Geolocation
+		}
 		if len(p) > 0 {
 			if b, err := json.Marshal(p); err == nil {
 				profileJSON = string(b)
@@ -1110,6 +1293,15 @@ func (s *Server) executeAnalysis(analysisID, createdBy string, req AnalysisReque
 	}
 	defer releaseAnalysisSem()
 
+	// Re-check the project's monthly cap: other runs may have used it up while this one was
+	// queued. The run's budget stays reserved until its spend is saved.
+	runBudget, releaseBudget, budgetErr := s.reserveProjectBudget(req.ProjectID, ai.SpendBudget{USD: req.BudgetUSD, Tokens: req.BudgetTokens})
+	if budgetErr != nil {
+		s.broadcastAnalysisError(analysisID, budgetErr.Error())
+		return
+	}
+	defer releaseBudget()
+
 	// Update step now that we have the semaphore
 	if err := s.store.UpdateAnalysisStatus(analysisID, store.StatusRunning, "scouting"); err != nil {
 		log.Printf("Warning: failed to update analysis %s step to scouting: %v", analysisID, err)
@@ -1176,6 +1368,20 @@ func (s *Server) executeAnalysis(analysisID, createdBy string, req AnalysisReque
 		if req.AgentSteps > 0 {
 			args = append(args, "--agent-steps", fmt.Sprintf("%d", req.AgentSteps))
 		}
+		if runBudget.USD > 0 {
+			args = append(args, "--budget-usd", fmt.Sprintf("%g", runBudget.USD))
+		}
+		if runBudget.Tokens > 0 {
+			args = append(args, "--budget-tokens", fmt.Sprintf("%d", runBudget.Tokens))
+		}
+		if req.ThinkingBudget > 0 {
+			args = append(args, "--thinking-budget", fmt.Sprintf("%d", req.ThinkingBudget))
+		}
+		args = append(args, s.probesArgs(req.ProjectID, tmpDir)...)
+		args = append(args, s.storageStateArgs(req.ProjectID, tmpDir)...)
+		args = append(args, harArgs(tmpDir)...)
+		args = append(args, networkArgs(req.Network)...)
+		args = append(args, randomSeedArgs(req.RandomSeed)...)
 	}
 	if req.Adaptive {
 		args = append(args, "--adaptive")
@@ -1203,6 +1409,8 @@ func (s *Server) executeAnalysis(analysisID, createdBy string, req AnalysisReque
 	} else if agentMode {
 		args = append(args, "--viewport", "agent-default")
 	}
+	args = append(args, s.viewportArgs(req.ProjectID, tmpDir)...)
+	args = append(args, localeArgs(req.Locale, req.Timezone, req.Geolocation)...)
 	if req.SynthesisModel != "" {
 		args = append(args, "--synthesis-model", req.SynthesisModel)
 	}
@@ -1285,7 +1493,14 @@ func (s *Server) executeAnalysis(analysisID, createdBy string, req AnalysisReque
 				rest := line[len("PROGRESS:"):]
 				parts := strings.SplitN(rest, ":", 2)
 				step := strings.TrimSpace(parts[0])
-				if step != "cost_estimate" {
+				if step == "ai_stream" {
+					// Live AI output is relayed to the UI only; it is not a pipeline step
+					if len(parts) > 1 {
+						s.broadcastAIStream(analysisID, parts[1])
+					}
+					continue
+				}
+				if step != "cost_estimate" && step != "pricing_warning" {
 					lastKnownStep = step
 				}
 				message := ""
@@ -1318,6 +1533,12 @@ func (s *Server) executeAnalysis(analysisID, createdBy string, req AnalysisReque
 							InputTokens:  intFromMap(detailData, "inputTokens"),
 							OutputTokens: intFromMap(detailData, "outputTokens"),
 							Credits:      intFromMap(detailData, "credits"),
+							GameState:    gameStateFromDetail(detailData),
+						}
+						// Prefer the reasoning carried in the detail: it includes thinking and
+						// survives multi-line text, which agent_reasoning lines cut short
+						if r := strFromMap(detailData, "reasoning"); r != "" {
+							stepRecord.Reasoning = r
 						}
 						if dbID, saveErr := s.store.SaveAgentStep(stepRecord); saveErr != nil {
 							log.Printf("Warning: failed to save agent step %d for %s: %v", stepRecord.StepNumber, analysisID, saveErr)
@@ -1450,6 +1671,9 @@ func (s *Server) executeAnalysis(analysisID, createdBy string, req AnalysisReque
 	statusWg.Wait()
 
 	err = cmd.Wait()
+	if agentMode {
+		s.persistAnalysisHAR(analysisID, tmpDir, "")
+	}
 	if err != nil {
 		// Classify error concisely for the user
 		var userMsg string
@@ -1565,6 +1789,11 @@ func (s *Server) executeAnalysis(analysisID, createdBy string, req AnalysisReque
 	if err := s.store.UpdateAnalysisResult(analysisID, store.StatusCompleted, result, gameName, framework, flowCount); err != nil {
 		log.Printf("Warning: failed to update analysis record for %s: %v", analysisID, err)
 	}
+	if analysis, ok := result["analysis"].(map[string]interface{}); ok && analysis["budgetExhausted"] == true {
+		if err := s.store.UpdateAnalysisStatus(analysisID, store.StatusCompleted, store.StepBudgetExhausted); err != nil {
+			log.Printf("Warning: failed to mark analysis %s as budget exhausted: %v", analysisID, err)
+		}
+	}
 
 	// Auto-create a test plan
 	s.wsHub.Broadcast(ws.Message{
@@ -1657,12 +1886,12 @@ func (s *Server) executeAnalysis(analysisID, createdBy string, req AnalysisReque
 			}()
 
 			if agentMode {
-				s.executeAgentTestRun(testPlanID, testRunID, plan.AnalysisID, plan.Name, createdBy, viewport)
+				s.executeAgentTestRun(testPlanID, testRunID, plan.AnalysisID, plan.Name, createdBy, viewport, req.RandomSeed)
 			} else {
 				flowDir2, flowErr := s.prepareFlowDir(plan)
 				if flowErr == nil {
 					defer os.RemoveAll(flowDir2)
-					s.executeBrowserTestRun(testPlanID, testRunID, flowDir2, plan.Name, createdBy, viewport)
+					s.executeBrowserTestRun(testPlanID, testRunID, flowDir2, plan.Name, createdBy, viewport, s.resolveRandomSeed(req.ProjectID, req.RandomSeed))
 				} else {
 					log.Printf("Warning: failed to prepare flow dir for auto-test on %s: %v", analysisID, flowErr)
 				}
@@ -1746,6 +1975,16 @@ func (s *Server) handleSendAgentMessage(w http.ResponseWriter, r *http.Request)
 	respondJSON(w, http.StatusOK, map[string]string{"status": "sent"})
 }
 
+// broadcastAIStream relays an ai_stream progress payload (streamed AI output) to the UI.
+func (s *Server) broadcastAIStream(analysisID, message string) {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(message), &data); err != nil {
+		return
+	}
+	data["analysisId"] = analysisID
+	s.wsHub.Broadcast(ws.Message{Type: "ai_stream", Data: data})
+}
+
 func intFromMap(m map[string]interface{}, key string) int {
 	v, ok := m[key]
 	if !ok {
@@ -2084,6 +2323,8 @@ func (s *Server) executeContinuedAnalysis(analysisID, createdBy string, analysis
 
 	if analysis.AgentMode {
 		args = append(args, "--agent")
+		args = append(args, s.probesArgs(analysis.ProjectID, tmpDir)...)
+		args = append(args, s.storageStateArgs(analysis.ProjectID, tmpDir)...)
 	}
 
 	// Reconstruct profile params
@@ -2116,7 +2357,9 @@ func (s *Server) executeContinuedAnalysis(analysisID, createdBy string, analysis
 			}
 			if vp, ok := profile["viewport"].(string); ok && vp != "" {
 				args = append(args, "--viewport", vp)
+				args = append(args, s.viewportArgs(analysis.ProjectID, tmpDir)...)
 			}
+			args = append(args, profileLocaleArgs(profile)...)
 		}
 	}
 
@@ -2192,7 +2435,14 @@ func (s *Server) executeContinuedAnalysis(analysisID, createdBy string, analysis
 				rest := line[len("PROGRESS:"):]
 				parts := strings.SplitN(rest, ":", 2)
 				progressStep := strings.TrimSpace(parts[0])
-				if progressStep != "cost_estimate" {
+				if progressStep == "ai_stream" {
+					// Live AI output is relayed to the UI only; it is not a pipeline step
+					if len(parts) > 1 {
+						s.broadcastAIStream(analysisID, parts[1])
+					}
+					continue
+				}
+				if progressStep != "cost_estimate" && progressStep != "pricing_warning" {
 					lastKnownStep = progressStep
 				}
 				message := ""
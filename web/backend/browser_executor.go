@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -51,7 +52,7 @@ type flowContext struct {
 }
 
 // launchBrowserTestRun starts executeBrowserTestRun in a goroutine with panic recovery.
-func (s *Server) launchBrowserTestRun(planID, testID, flowDir, planName, viewport string, cleanupDir bool, createdBy string) {
+func (s *Server) launchBrowserTestRun(planID, testID, flowDir, planName, viewport string, cleanupDir bool, createdBy string, randomSeed *uint32) {
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -64,12 +65,12 @@ func (s *Server) launchBrowserTestRun(planID, testID, flowDir, planName, viewpor
 				}
 			}
 		}()
-		s.executeBrowserTestRun(planID, testID, flowDir, planName, createdBy, viewport)
+		s.executeBrowserTestRun(planID, testID, flowDir, planName, createdBy, viewport, randomSeed)
 	}()
 }
 
 // executeBrowserTestRun runs test flows in headless Chrome using the browser automation infrastructure.
-func (s *Server) executeBrowserTestRun(planID, testID, flowDir, planName, createdBy, viewport string) {
+func (s *Server) executeBrowserTestRun(planID, testID, flowDir, planName, createdBy, viewport string, randomSeed *uint32) {
 	// Acquire browser test concurrency slot (only one Chrome for tests at a time)
 	select {
 	case s.browserTestSem <- struct{}{}:
@@ -106,6 +107,7 @@ func (s *Server) executeBrowserTestRun(planID, testID, flowDir, planName, create
 		Flows:      []store.FlowResult{},
 		Logs:       []string{},
 		Status:     "running",
+		RandomSeed: randomSeed,
 	}
 	s.runningTests.Register(testID, rt)
 
@@ -121,10 +123,7 @@ func (s *Server) executeBrowserTestRun(planID, testID, flowDir, planName, create
 	})
 
 	// Resolve viewport
-	vp := scout.GetViewportByName(viewport)
-	if vp == nil {
-		vp = scout.GetViewportByName(scout.DefaultViewportName)
-	}
+	vp := s.resolveViewport(planID, viewport)
 
 	// Create AI client for vision queries
 	apiKey := os.Getenv("ANTHROPIC_API_KEY")
@@ -146,16 +145,23 @@ func (s *Server) executeBrowserTestRun(planID, testID, flowDir, planName, create
 		DevicePixelRatio: vp.DevicePixelRatio,
 		Timeout:          30 * time.Second,
 		DeviceCategory:   vp.Category,
+		Touch:            vp.Touch,
+		UserAgent:        vp.UserAgent,
+		RandomSeed:       randomSeed,
 	})
 	if err != nil {
 		s.finishTestRun(planID, testID, planName, startTime, nil, fmt.Errorf("launching browser: %w", err), createdBy)
 		return
 	}
 	defer cleanup()
+	defer s.saveTestHAR(testID, browserPage)
 	_ = pageMeta
 
-	toolExec := &ai.BrowserToolExecutor{Page: browserPage}
+	toolExec := &ai.BrowserToolExecutor{Page: browserPage, Probes: s.planProbes(planID), Viewport: *vp}
 	s.broadcastTestLog(testID, planID, fmt.Sprintf("Browser ready (%dx%d @ %.1fx)", vp.Width, vp.Height, vp.DevicePixelRatio))
+	if randomSeed != nil {
+		s.broadcastTestLog(testID, planID, fmt.Sprintf("Random seed: %d (pass randomSeed to replay this run)", *randomSeed))
+	}
 
 	fctx := &flowContext{flows: flows, flowDir: flowDir, visiting: make(map[string]bool)}
 
@@ -176,6 +182,10 @@ func (s *Server) executeBrowserTestRun(planID, testID, flowDir, planName, create
 
 		s.broadcastTestLog(testID, planID, fmt.Sprintf("--- Flow %d/%d: %s (%d commands) ---", fi+1, totalFlows, flow.Name, len(flow.Commands)))
 
+		if err := toolExec.ResetPage(); err != nil {
+			s.broadcastTestLog(testID, planID, fmt.Sprintf("  Warning: resetting the page failed: %v", err))
+		}
+
 		// Navigate to flow URL if specified
 		if flow.Meta.URL != "" {
 			s.broadcastTestLog(testID, planID, fmt.Sprintf("  Navigating to %s", flow.Meta.URL))
@@ -514,9 +524,18 @@ func executeMapCommand(page ai.BrowserPage, toolExec *ai.BrowserToolExecutor, cm
 			r, ss, err := toolExec.Execute("type_text", input)
 			return r, ss, "", err
 
+		case "longPressOn":
+			return executeLongPressOn(page, toolExec, value, aiClient, vpWidth, vpHeight)
+
 		case "scroll":
 			return executeScroll(toolExec, value)
 
+		case "swipe":
+			return executeSwipe(toolExec, value, vpWidth, vpHeight)
+
+		case "pinch", "rotate", "twoFingerPan":
+			return executeTouchGesture(toolExec, cmdName, value, vpWidth, vpHeight)
+
 		case "extendedWaitUntil":
 			return executeWaitUntil(page, value, aiClient, vpWidth, vpHeight)
 
@@ -551,6 +570,50 @@ func executeMapCommand(page ai.BrowserPage, toolExec *ai.BrowserToolExecutor, cm
 			ss, _ := ai.CaptureScreenshotWithTimeout(page, screenshotTimeout)
 			return fmt.Sprintf("Pressed key %q.", key), ss, "", nil
 
+		case "keyDown", "keyUp":
+			key, _ := value.(string)
+			if key == "" {
+				return "", "", "", fmt.Errorf("%s: missing key", cmdName)
+			}
+			var err error
+			if cmdName == "keyDown" {
+				err = page.KeyDown(key)
+			} else {
+				err = page.KeyUp(key)
+			}
+			if err != nil {
+				return "", "", "", fmt.Errorf("%s: %w", cmdName, err)
+			}
+			time.Sleep(150 * time.Millisecond)
+			ss, _ := ai.CaptureScreenshotWithTimeout(page, screenshotTimeout)
+			return fmt.Sprintf("%s %q.", cmdName, key), ss, "", nil
+
+		case "holdKey", "holdKeys":
+			return executeHoldKeys(toolExec, cmdName, value)
+
+		case "assertState":
+			return executeAssertState(toolExec, value)
+
+		case "setNetwork":
+			return executeSetNetwork(toolExec, value)
+
+		case "advanceTime":
+			return executeAdvanceTime(toolExec, value)
+
+		case "pauseGame":
+			paused, ok := value.(bool)
+			if !ok {
+				return "", "", "", fmt.Errorf("pauseGame: expected true or false, got %T", value)
+			}
+			r, ss, err := toolExec.Execute("pause_game", json.RawMessage(fmt.Sprintf(`{"paused": %t}`, paused)))
+			if err != nil {
+				return "", "", "", fmt.Errorf("pauseGame: %w", err)
+			}
+			return r, ss, "", nil
+
+		case "rotateDevice":
+			return executeRotateDevice(toolExec, value)
+
 		case "eraseText":
 			count := 10
 			if n, ok := value.(int); ok {
@@ -631,13 +694,33 @@ func executeTapOn(page ai.BrowserPage, toolExec *ai.BrowserToolExecutor, value i
 
 // tapOnText uses AI vision to find text on screen and click its coordinates.
 func tapOnText(page ai.BrowserPage, toolExec *ai.BrowserToolExecutor, text string, aiClient *ai.ClaudeClient, vpWidth, vpHeight int) (string, string, string, error) {
+	x, y, ss, response, err := locateText(page, "tapOn", text, aiClient, vpWidth, vpHeight)
+	if err != nil {
+		return "", ss, response, err
+	}
+
+	input, marshalErr := json.Marshal(map[string]int{"x": x, "y": y})
+	if marshalErr != nil {
+		log.Printf("Warning: failed to marshal click input: %v", marshalErr)
+	}
+	result, clickSS, clickErr := toolExec.Execute("click", input)
+	if clickErr != nil {
+		return "", ss, response, fmt.Errorf("tapOn text %q: click failed: %w", text, clickErr)
+	}
+
+	return fmt.Sprintf("Tapped on %q at (%d,%d). %s", text, x, y, result), clickSS, response, nil
+}
+
+// locateText uses AI vision to find the center of the element showing text.
+// It returns the screenshot it looked at and the raw AI response for reasoning.
+func locateText(page ai.BrowserPage, cmdName, text string, aiClient *ai.ClaudeClient, vpWidth, vpHeight int) (x, y int, ss, response string, err error) {
 	if aiClient == nil {
-		return "", "", "", fmt.Errorf("tapOn text %q: AI client not configured (set ANTHROPIC_API_KEY)", text)
+		return 0, 0, "", "", fmt.Errorf("%s text %q: AI client not configured (set ANTHROPIC_API_KEY)", cmdName, text)
 	}
 
-	ss, err := ai.CaptureScreenshotWithTimeout(page, screenshotTimeout)
+	ss, err = ai.CaptureScreenshotWithTimeout(page, screenshotTimeout)
 	if err != nil {
-		return "", "", "", fmt.Errorf("tapOn text: screenshot failed: %w", err)
+		return 0, 0, "", "", fmt.Errorf("%s text: screenshot failed: %w", cmdName, err)
 	}
 
 	prompt := fmt.Sprintf(
@@ -645,38 +728,43 @@ func tapOnText(page ai.BrowserPage, toolExec *ai.BrowserToolExecutor, text strin
 		vpWidth, vpHeight, text,
 	)
 
-	response, err := aiClient.AnalyzeWithImage(context.Background(), prompt, ss)
+	response, err = aiClient.AnalyzeWithImage(context.Background(), prompt, ss)
 	if err != nil {
-		return "", "", "", fmt.Errorf("tapOn text %q: AI vision failed: %w", text, err)
+		return 0, 0, "", "", fmt.Errorf("%s text %q: AI vision failed: %w", cmdName, text, err)
 	}
 
 	response = strings.TrimSpace(response)
 	if strings.Contains(strings.ToUpper(response), "NOT_FOUND") {
-		return "", ss, response, fmt.Errorf("tapOn text %q: text not found on screen", text)
+		return 0, 0, ss, response, fmt.Errorf("%s text %q: text not found on screen", cmdName, text)
+	}
+
+	x, y, err = parseCoordinates(response)
+	if err != nil {
+		return 0, 0, ss, response, fmt.Errorf("%s text %q: could not parse coordinates from AI response %q: %w", cmdName, text, response, err)
 	}
+	return x, y, ss, response, nil
+}
 
-	x, y, parseErr := parseCoordinates(response)
-	if parseErr != nil {
-		return "", ss, response, fmt.Errorf("tapOn text %q: could not parse coordinates from AI response %q: %w", text, response, parseErr)
+// tapOnPoint handles tapOn with point: "x,y" or "x%,y%".
+func tapOnPoint(page ai.BrowserPage, toolExec *ai.BrowserToolExecutor, pointStr string, vpWidth, vpHeight int) (string, string, string, error) {
+	x, y, err := parsePoint(pointStr, vpWidth, vpHeight)
+	if err != nil {
+		return "", "", "", fmt.Errorf("tapOn point: %w", err)
 	}
 
 	input, marshalErr := json.Marshal(map[string]int{"x": x, "y": y})
 	if marshalErr != nil {
 		log.Printf("Warning: failed to marshal click input: %v", marshalErr)
 	}
-	result, clickSS, clickErr := toolExec.Execute("click", input)
-	if clickErr != nil {
-		return "", ss, response, fmt.Errorf("tapOn text %q: click failed: %w", text, clickErr)
-	}
-
-	return fmt.Sprintf("Tapped on %q at (%d,%d). %s", text, x, y, result), clickSS, response, nil
+	r, ss, err := toolExec.Execute("click", input)
+	return r, ss, "", err
 }
 
-// tapOnPoint handles tapOn with point: "x,y" or "x%,y%".
-func tapOnPoint(page ai.BrowserPage, toolExec *ai.BrowserToolExecutor, pointStr string, vpWidth, vpHeight int) (string, string, string, error) {
+// parsePoint parses a Maestro point, "x,y" in pixels or "x%,y%" of the viewport.
+func parsePoint(pointStr string, vpWidth, vpHeight int) (int, int, error) {
 	parts := strings.Split(pointStr, ",")
 	if len(parts) != 2 {
-		return "", "", "", fmt.Errorf("tapOn point: invalid format %q, expected 'x,y'", pointStr)
+		return 0, 0, fmt.Errorf("invalid format %q, expected 'x,y'", pointStr)
 	}
 
 	xStr := strings.TrimSpace(parts[0])
@@ -687,13 +775,13 @@ func tapOnPoint(page ai.BrowserPage, toolExec *ai.BrowserToolExecutor, pointStr
 	if strings.HasSuffix(xStr, "%") {
 		pct, err := strconv.ParseFloat(strings.TrimSuffix(xStr, "%"), 64)
 		if err != nil {
-			return "", "", "", fmt.Errorf("tapOn point: invalid x percentage %q", xStr)
+			return 0, 0, fmt.Errorf("invalid x percentage %q", xStr)
 		}
 		x = int(pct / 100.0 * float64(vpWidth))
 	} else {
 		val, err := strconv.Atoi(xStr)
 		if err != nil {
-			return "", "", "", fmt.Errorf("tapOn point: invalid x coordinate %q", xStr)
+			return 0, 0, fmt.Errorf("invalid x coordinate %q", xStr)
 		}
 		x = val
 	}
@@ -701,23 +789,78 @@ func tapOnPoint(page ai.BrowserPage, toolExec *ai.BrowserToolExecutor, pointStr
 	if strings.HasSuffix(yStr, "%") {
 		pct, err := strconv.ParseFloat(strings.TrimSuffix(yStr, "%"), 64)
 		if err != nil {
-			return "", "", "", fmt.Errorf("tapOn point: invalid y percentage %q", yStr)
+			return 0, 0, fmt.Errorf("invalid y percentage %q", yStr)
 		}
 		y = int(pct / 100.0 * float64(vpHeight))
 	} else {
 		val, err := strconv.Atoi(yStr)
 		if err != nil {
-			return "", "", "", fmt.Errorf("tapOn point: invalid y coordinate %q", yStr)
+			return 0, 0, fmt.Errorf("invalid y coordinate %q", yStr)
 		}
 		y = val
 	}
 
+	return x, y, nil
+}
+
+// executeLongPressOn handles longPressOn with the same point, text, or id
+// targeting as tapOn, holding the press instead of clicking.
+func executeLongPressOn(page ai.BrowserPage, toolExec *ai.BrowserToolExecutor, value interface{}, aiClient *ai.ClaudeClient, vpWidth, vpHeight int) (string, string, string, error) {
+	var (
+		x, y      int
+		target    string
+		ss        string
+		reasoning string
+		err       error
+	)
+	switch v := value.(type) {
+	case string:
+		target = fmt.Sprintf("%q", v)
+		x, y, ss, reasoning, err = locateText(page, "longPressOn", v, aiClient, vpWidth, vpHeight)
+
+	case map[string]interface{}:
+		if pointStr, ok := v["point"].(string); ok {
+			target = pointStr
+			x, y, err = parsePoint(pointStr, vpWidth, vpHeight)
+			if err != nil {
+				err = fmt.Errorf("longPressOn point: %w", err)
+			}
+		} else if text, ok := v["text"].(string); ok {
+			target = fmt.Sprintf("%q", text)
+			x, y, ss, reasoning, err = locateText(page, "longPressOn", text, aiClient, vpWidth, vpHeight)
+		} else if id, ok := v["id"].(string); ok {
+			target = "#" + id
+			jsResult, jsErr := page.EvalJS(fmt.Sprintf(`(() => {
+				const el = document.getElementById(%q);
+				if (!el) return 'not_found';
+				const r = el.getBoundingClientRect();
+				return Math.round(r.left + r.width / 2) + ',' + Math.round(r.top + r.height / 2);
+			})()`, id))
+			if jsErr == nil && jsResult != "not_found" {
+				x, y, err = parseCoordinates(jsResult)
+			} else {
+				x, y, ss, reasoning, err = locateText(page, "longPressOn", id, aiClient, vpWidth, vpHeight)
+			}
+		} else {
+			return "", "", "", fmt.Errorf("longPressOn: no recognizable selector (text, point, or id)")
+		}
+
+	default:
+		return "", "", "", fmt.Errorf("longPressOn: unexpected value type %T", value)
+	}
+	if err != nil {
+		return "", ss, reasoning, err
+	}
+
 	input, marshalErr := json.Marshal(map[string]int{"x": x, "y": y})
 	if marshalErr != nil {
-		log.Printf("Warning: failed to marshal click input: %v", marshalErr)
+		log.Printf("Warning: failed to marshal long_press input: %v", marshalErr)
 	}
-	r, ss, err := toolExec.Execute("click", input)
-	return r, ss, "", err
+	result, pressSS, pressErr := toolExec.Execute("long_press", input)
+	if pressErr != nil {
+		return "", ss, reasoning, fmt.Errorf("longPressOn %s: %w", target, pressErr)
+	}
+	return fmt.Sprintf("Long-pressed %s. %s", target, result), pressSS, reasoning, nil
 }
 
 // executeScroll handles the scroll command.
@@ -748,6 +891,348 @@ func executeScroll(toolExec *ai.BrowserToolExecutor, value interface{}) (string,
 	return r, ss, "", err
 }
 
+// executeSwipe handles the swipe command. It accepts a direction (UP, DOWN,
+// LEFT, RIGHT — the way the finger moves, across the middle of the screen) or
+// explicit start and end points, plus an optional duration in milliseconds.
+func executeSwipe(toolExec *ai.BrowserToolExecutor, value interface{}, vpWidth, vpHeight int) (string, string, string, error) {
+	direction := ""
+	start, end := "", ""
+	duration := 400
+
+	switch v := value.(type) {
+	case string:
+		direction = v
+	case map[string]interface{}:
+		direction, _ = v["direction"].(string)
+		start, _ = v["start"].(string)
+		end, _ = v["end"].(string)
+		if d, ok := v["duration"].(int); ok {
+			duration = d
+		}
+		if d, ok := v["duration"].(float64); ok {
+			duration = int(d)
+		}
+	}
+
+	if start != "" && end != "" {
+		fromX, fromY, err := parsePoint(start, vpWidth, vpHeight)
+		if err != nil {
+			return "", "", "", fmt.Errorf("swipe start: %w", err)
+		}
+		toX, toY, err := parsePoint(end, vpWidth, vpHeight)
+		if err != nil {
+			return "", "", "", fmt.Errorf("swipe end: %w", err)
+		}
+		input, marshalErr := json.Marshal(map[string]int{"from_x": fromX, "from_y": fromY, "to_x": toX, "to_y": toY, "duration_ms": duration})
+		if marshalErr != nil {
+			log.Printf("Warning: failed to marshal drag input: %v", marshalErr)
+		}
+		r, ss, err := toolExec.Execute("drag", input)
+		return r, ss, "", err
+	}
+
+	// Direction swipes run from 80% to 20% of the screen (or the reverse)
+	x, y, distance := vpWidth/2, vpHeight/2, 0
+	switch strings.ToLower(direction) {
+	case "left":
+		x, distance = vpWidth*8/10, vpWidth*6/10
+	case "right":
+		x, distance = vpWidth*2/10, vpWidth*6/10
+	case "up":
+		y, distance = vpHeight*8/10, vpHeight*6/10
+	case "down":
+		y, distance = vpHeight*2/10, vpHeight*6/10
+	default:
+		return "", "", "", fmt.Errorf("swipe: needs a direction (UP, DOWN, LEFT, RIGHT) or start and end points")
+	}
+	input, marshalErr := json.Marshal(map[string]interface{}{
+		"x": x, "y": y, "direction": strings.ToLower(direction), "distance": distance, "duration_ms": duration,
+	})
+	if marshalErr != nil {
+		log.Printf("Warning: failed to marshal swipe input: %v", marshalErr)
+	}
+	r, ss, err := toolExec.Execute("swipe", input)
+	return r, ss, "", err
+}
+
+// executeTouchGesture handles the browser-only two-finger commands, which need a
+// touch viewport:
+//
+//	pinch: {point: "50%,50%", scale: 2, duration: 500}
+//	rotate: {point: "50%,50%", degrees: 90}
+//	twoFingerPan: {start: "50%,50%", end: "30%,50%"}
+func executeTouchGesture(toolExec *ai.BrowserToolExecutor, cmdName string, value interface{}, vpWidth, vpHeight int) (string, string, string, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return "", "", "", fmt.Errorf("%s: expected map, got %T", cmdName, value)
+	}
+	point, _ := m["point"].(string)
+	if point == "" {
+		point = "50%,50%"
+	}
+	input := map[string]interface{}{"duration_ms": intFromMap(m, "duration")}
+
+	switch cmdName {
+	case "pinch":
+		input["gesture"] = "pinch"
+		input["scale"] = floatFromMap(m, "scale")
+	case "rotate":
+		input["gesture"] = "rotate"
+		input["angle"] = floatFromMap(m, "degrees")
+	case "twoFingerPan":
+		if start, ok := m["start"].(string); ok {
+			point = start
+		}
+		end, _ := m["end"].(string)
+		if end == "" {
+			return "", "", "", fmt.Errorf("twoFingerPan: missing end point")
+		}
+		fromX, fromY, err := parsePoint(point, vpWidth, vpHeight)
+		if err != nil {
+			return "", "", "", fmt.Errorf("twoFingerPan start: %w", err)
+		}
+		toX, toY, err := parsePoint(end, vpWidth, vpHeight)
+		if err != nil {
+			return "", "", "", fmt.Errorf("twoFingerPan end: %w", err)
+		}
+		input["gesture"] = "pan"
+		input["dx"], input["dy"] = toX-fromX, toY-fromY
+	}
+
+	x, y, err := parsePoint(point, vpWidth, vpHeight)
+	if err != nil {
+		return "", "", "", fmt.Errorf("%s point: %w", cmdName, err)
+	}
+	input["x"], input["y"] = x, y
+	if spread := intFromMap(m, "spread"); spread > 0 {
+		input["spread"] = spread
+	}
+
+	data, marshalErr := json.Marshal(input)
+	if marshalErr != nil {
+		log.Printf("Warning: failed to marshal touch_gesture input: %v", marshalErr)
+	}
+	r, ss, err := toolExec.Execute("touch_gesture", data)
+	if err != nil {
+		return "", "", "", fmt.Errorf("%s: %w", cmdName, err)
+	}
+	return r, ss, "", nil
+}
+
+func floatFromMap(m map[string]interface{}, key string) float64 {
+	switch n := m[key].(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	default:
+		return 0
+	}
+}
+
+// executeHoldKeys handles the browser-only key hold commands by running the
+// hold_keys tool:
+//
+//	holdKey: {key: ArrowRight, duration: 2000}
+//	holdKeys: [{key: ArrowRight, duration: 2000}, {key: Space, at: 500}]
+func executeHoldKeys(toolExec *ai.BrowserToolExecutor, cmdName string, value interface{}) (string, string, string, error) {
+	var items []interface{}
+	switch v := value.(type) {
+	case string:
+		// holdKey: ArrowRight holds for a second
+		items = []interface{}{map[string]interface{}{"key": v, "duration": 1000}}
+	case map[string]interface{}:
+		items = []interface{}{v}
+	case []interface{}:
+		items = v
+	default:
+		return "", "", "", fmt.Errorf("%s: unexpected value type %T", cmdName, value)
+	}
+
+	keys := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return "", "", "", fmt.Errorf("%s: each entry needs a key, got %T", cmdName, item)
+		}
+		keys = append(keys, map[string]interface{}{
+			"key":         strFromMap(m, "key"),
+			"at_ms":       intFromMap(m, "at"),
+			"duration_ms": intFromMap(m, "duration"),
+		})
+	}
+	input, marshalErr := json.Marshal(map[string]interface{}{"keys": keys})
+	if marshalErr != nil {
+		log.Printf("Warning: failed to marshal hold_keys input: %v", marshalErr)
+	}
+	r, ss, err := toolExec.Execute("hold_keys", input)
+	if err != nil {
+		return "", "", "", fmt.Errorf("%s: %w", cmdName, err)
+	}
+	return r, ss, "", nil
+}
+
+// executeSetNetwork handles the browser-only setNetwork command by running the
+// set_network tool:
+//
+//	setNetwork: offline
+//	setNetwork: {profile: offline, duration: 3000}
+//	setNetwork: {profile: custom, latency: 400, download: 1000, upload: 500}
+func executeSetNetwork(toolExec *ai.BrowserToolExecutor, value interface{}) (string, string, string, error) {
+	params := map[string]interface{}{}
+	switch v := value.(type) {
+	case string:
+		params["profile"] = v
+	case map[string]interface{}:
+		params["profile"] = strFromMap(v, "profile")
+		params["latency_ms"] = floatFromMap(v, "latency")
+		params["download_kbps"] = floatFromMap(v, "download")
+		params["upload_kbps"] = floatFromMap(v, "upload")
+		params["duration_ms"] = intFromMap(v, "duration")
+	default:
+		return "", "", "", fmt.Errorf("setNetwork: unexpected value type %T", value)
+	}
+	input, marshalErr := json.Marshal(params)
+	if marshalErr != nil {
+		log.Printf("Warning: failed to marshal set_network input: %v", marshalErr)
+	}
+	r, ss, err := toolExec.Execute("set_network", input)
+	if err != nil {
+		return "", "", "", fmt.Errorf("setNetwork: %w", err)
+	}
+	return r, ss, "", nil
+}
+
+// executeAdvanceTime handles the browser-only advanceTime command by running
+// the advance_time tool:
+//
+//	advanceTime: 5000
+//	advanceTime: {ms: 5000}
+func executeAdvanceTime(toolExec *ai.BrowserToolExecutor, value interface{}) (string, string, string, error) {
+	var ms int
+	switch v := value.(type) {
+	case int:
+		ms = v
+	case float64:
+		ms = int(v)
+	case map[string]interface{}:
+		ms = intFromMap(v, "ms")
+	default:
+		return "", "", "", fmt.Errorf("advanceTime: unexpected value type %T", value)
+	}
+	if ms <= 0 {
+		return "", "", "", fmt.Errorf("advanceTime: requires a positive number of milliseconds")
+	}
+	r, ss, err := toolExec.Execute("advance_time", json.RawMessage(fmt.Sprintf(`{"ms": %d}`, ms)))
+	if err != nil {
+		return "", "", "", fmt.Errorf("advanceTime: %w", err)
+	}
+	return r, ss, "", nil
+}
+
+// executeRotateDevice handles the browser-only rotateDevice command by running
+// the rotate_device tool; later commands see the rotated viewport:
+//
+//	rotateDevice: landscape
+//	rotateDevice: portrait
+func executeRotateDevice(toolExec *ai.BrowserToolExecutor, value interface{}) (string, string, string, error) {
+	orientation, ok := value.(string)
+	if !ok || (orientation != scout.OrientationPortrait && orientation != scout.OrientationLandscape) {
+		return "", "", "", fmt.Errorf("rotateDevice: expected portrait or landscape, got %v", value)
+	}
+	r, ss, err := toolExec.Execute("rotate_device", json.RawMessage(fmt.Sprintf(`{"orientation": %q}`, orientation)))
+	if err != nil {
+		return "", "", "", fmt.Errorf("rotateDevice: %w", err)
+	}
+	return r, ss, "", nil
+}
+
+// executeAssertState reads a project game-state probe and compares it:
+//
+//	assertState: {probe: balance, equals: 1000}
+//	assertState: {probe: score, gt: 0, lt: 500}
+//
+// Every comparison given must hold; gt and lt need numeric values.
+func executeAssertState(toolExec *ai.BrowserToolExecutor, value interface{}) (string, string, string, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return "", "", "", fmt.Errorf("assertState: expected a map with probe and equals/gt/lt, got %T", value)
+	}
+	probe := strFromMap(m, "probe")
+	if probe == "" {
+		return "", "", "", fmt.Errorf("assertState: missing probe")
+	}
+	state, err := ai.ReadGameState(toolExec.Page, toolExec.Probes, []string{probe})
+	if err != nil {
+		return "", "", "", fmt.Errorf("assertState: %w", err)
+	}
+	if msg, failed := state.Errors[probe]; failed {
+		return "", "", "", fmt.Errorf("assertState: probe %q failed: %s", probe, msg)
+	}
+	got := state.Values[probe]
+	gotJSON, _ := json.Marshal(got)
+
+	var checks []string
+	if want, ok := m["equals"]; ok {
+		if !stateEquals(got, want) {
+			wantJSON, _ := json.Marshal(want)
+			return "", "", "", fmt.Errorf("assertState: %s is %s, expected %s", probe, gotJSON, wantJSON)
+		}
+		checks = append(checks, fmt.Sprintf("equals %s", gotJSON))
+	}
+	for _, op := range []string{"gt", "lt"} {
+		want, ok := m[op]
+		if !ok {
+			continue
+		}
+		g, gOK := stateNumber(got)
+		w, wOK := stateNumber(want)
+		if !gOK || !wOK {
+			return "", "", "", fmt.Errorf("assertState: %s needs numbers, %s is %s", op, probe, gotJSON)
+		}
+		if (op == "gt" && !(g > w)) || (op == "lt" && !(g < w)) {
+			return "", "", "", fmt.Errorf("assertState: %s is %s, expected %s %v", probe, gotJSON, op, w)
+		}
+		checks = append(checks, fmt.Sprintf("%s %v", op, w))
+	}
+	if len(checks) == 0 {
+		return "", "", "", fmt.Errorf("assertState: %s needs equals, gt or lt", probe)
+	}
+	return fmt.Sprintf("State %s = %s (%s)", probe, gotJSON, strings.Join(checks, ", ")), "", "", nil
+}
+
+// stateEquals compares a probe value with a flow value, treating numbers of
+// any type as equal when their values are.
+func stateEquals(got, want interface{}) bool {
+	if g, ok := stateNumber(got); ok {
+		w, ok := stateNumber(want)
+		return ok && g == w
+	}
+	// Round-trip the YAML value through JSON so maps and lists compare like probe values
+	var normalized interface{}
+	if data, err := json.Marshal(want); err == nil && json.Unmarshal(data, &normalized) == nil {
+		want = normalized
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+// stateNumber returns v as a float64 when it is a number.
+func stateNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
 // executeWaitUntil handles extendedWaitUntil by polling screenshots with AI vision.
 func executeWaitUntil(page ai.BrowserPage, value interface{}, aiClient *ai.ClaudeClient, vpWidth, vpHeight int) (string, string, string, error) {
 	m, ok := value.(map[string]interface{})
@@ -1036,6 +1521,27 @@ func describeCommand(cmd interface{}) string {
 				if vis, ok := v["visible"].(string); ok {
 					return fmt.Sprintf("%s: {visible: %q}", name, vis)
 				}
+				if dir, ok := v["direction"].(string); ok {
+					return fmt.Sprintf("%s: {direction: %s}", name, dir)
+				}
+				if start, ok := v["start"].(string); ok {
+					end, _ := v["end"].(string)
+					return fmt.Sprintf("%s: {start: %s, end: %s}", name, start, end)
+				}
+				if ms, ok := v["ms"]; ok {
+					return fmt.Sprintf("%s: {ms: %v}", name, ms)
+				}
+				if profile, ok := v["profile"].(string); ok {
+					return fmt.Sprintf("%s: {profile: %s}", name, profile)
+				}
+				if probe, ok := v["probe"].(string); ok {
+					for _, op := range []string{"equals", "gt", "lt"} {
+						if want, ok := v[op]; ok {
+							return fmt.Sprintf("%s: {probe: %s, %s: %v}", name, probe, op, want)
+						}
+					}
+					return fmt.Sprintf("%s: {probe: %s}", name, probe)
+				}
 				return fmt.Sprintf("%s: {map}", name)
 			default:
 				return fmt.Sprintf("%s: %v", name, value)
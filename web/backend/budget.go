@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/ai"
+)
+
+// Project settings keys for monthly AI spend caps. Values are decimal strings;
+// empty or zero means uncapped.
+const (
+	settingMonthlyBudgetUSD    = "monthlyBudgetUsd"
+	settingMonthlyBudgetTokens = "monthlyBudgetTokens"
+)
+
+// budgetReservations tracks the budgets handed to in-flight analyses and test runs per
+// project. They count as spent until the run finishes and records what it used, so
+// concurrent runs share what is left of a monthly cap instead of each getting all of it.
+type budgetReservations struct {
+	mu        sync.Mutex
+	byProject map[string]ai.SpendBudget
+}
+
+// projectBudgetRemaining returns what is left of a project's monthly AI caps for the
+// current calendar month (zero fields = uncapped), or an error once a cap is reached.
+// Budgets reserved by in-flight runs count as spent.
+func (s *Server) projectBudgetRemaining(projectID string) (ai.SpendBudget, error) {
+	s.budgetReservations.mu.Lock()
+	defer s.budgetReservations.mu.Unlock()
+	return s.projectBudgetRemainingLocked(projectID)
+}
+
+// reserveProjectBudget returns the budget for a run of a project — want, tightened by
+// what is left of the monthly caps — and reserves it until release is called, which the
+// caller does once the run's spend is recorded. Only capped kinds are reserved.
+func (s *Server) reserveProjectBudget(projectID string, want ai.SpendBudget) (budget ai.SpendBudget, release func(), err error) {
+	s.budgetReservations.mu.Lock()
+	defer s.budgetReservations.mu.Unlock()
+	remaining, err := s.projectBudgetRemainingLocked(projectID)
+	if err != nil {
+		return ai.SpendBudget{}, func() {}, err
+	}
+	budget = tighterBudget(want, remaining)
+
+	var held ai.SpendBudget
+	if remaining.USD > 0 {
+		held.USD = budget.USD
+	}
+	if remaining.Tokens > 0 {
+		held.Tokens = budget.Tokens
+	}
+	if held.IsZero() {
+		return budget, func() {}, nil
+	}
+	s.adjustReservation(projectID, held, 1)
+	var once sync.Once
+	return budget, func() {
+		once.Do(func() {
+			s.budgetReservations.mu.Lock()
+			defer s.budgetReservations.mu.Unlock()
+			s.adjustReservation(projectID, held, -1)
+		})
+	}, nil
+}
+
+// adjustReservation adds (sign 1) or removes (sign -1) a reservation. The caller holds
+// the reservations lock.
+func (s *Server) adjustReservation(projectID string, held ai.SpendBudget, sign int) {
+	r := &s.budgetReservations
+	if r.byProject == nil {
+		r.byProject = map[string]ai.SpendBudget{}
+	}
+	total := r.byProject[projectID]
+	total.USD += float64(sign) * held.USD
+	total.Tokens += sign * held.Tokens
+	if total.Tokens <= 0 && total.USD <= 1e-9 {
+		delete(r.byProject, projectID)
+		return
+	}
+	r.byProject[projectID] = total
+}
+
+// projectBudgetRemainingLocked is projectBudgetRemaining with the reservations lock held.
+// Lookup failures are logged and leave the project uncapped rather than blocking work.
+func (s *Server) projectBudgetRemainingLocked(projectID string) (ai.SpendBudget, error) {
+	var remaining ai.SpendBudget
+	if projectID == "" {
+		return remaining, nil
+	}
+	project, err := s.store.GetProject(projectID)
+	if err != nil {
+		return remaining, nil
+	}
+	capUSD, _ := strconv.ParseFloat(project.Settings[settingMonthlyBudgetUSD], 64)
+	capTokens, _ := strconv.Atoi(project.Settings[settingMonthlyBudgetTokens])
+	if capUSD <= 0 && capTokens <= 0 {
+		return remaining, nil
+	}
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	credits, tokens, err := s.store.ProjectSpendSince(projectID, monthStart)
+	if err != nil {
+		log.Printf("Warning: failed to check monthly spend for project %s: %v", projectID, err)
+		return remaining, nil
+	}
+
+	reserved := s.budgetReservations.byProject[projectID]
+	spentUSD := float64(credits) / 100
+	if capUSD > 0 {
+		if spentUSD >= capUSD {
+			return remaining, fmt.Errorf("project monthly AI budget exceeded ($%.2f of $%.2f spent)", spentUSD, capUSD)
+		}
+		if spentUSD+reserved.USD >= capUSD {
+			return remaining, fmt.Errorf("project monthly AI budget is taken by running jobs ($%.2f spent, $%.2f reserved of $%.2f)", spentUSD, reserved.USD, capUSD)
+		}
+		remaining.USD = capUSD - spentUSD - reserved.USD
+	}
+	if capTokens > 0 {
+		if tokens >= capTokens {
+			return remaining, fmt.Errorf("project monthly AI token budget exceeded (%d of %d tokens used)", tokens, capTokens)
+		}
+		if tokens+reserved.Tokens >= capTokens {
+			return remaining, fmt.Errorf("project monthly AI token budget is taken by running jobs (%d used, %d reserved of %d)", tokens, reserved.Tokens, capTokens)
+		}
+		remaining.Tokens = capTokens - tokens - reserved.Tokens
+	}
+	return remaining, nil
+}
+
+// tighterBudget combines two budgets, keeping the stricter limit of each kind.
+func tighterBudget(a, b ai.SpendBudget) ai.SpendBudget {
+	if a.USD <= 0 || (b.USD > 0 && b.USD < a.USD) {
+		a.USD = b.USD
+	}
+	if a.Tokens <= 0 || (b.Tokens > 0 && b.Tokens < a.Tokens) {
+		a.Tokens = b.Tokens
+	}
+	return a
+}
+
+// testRunBudget returns the spend budget for an agent test run: the budget saved in the
+// analysis profile, tightened by what is left of the project's monthly cap and reserved
+// until release is called.
+func (s *Server) testRunBudget(analysisID string) (ai.SpendBudget, func(), error) {
+	analysis, err := s.store.GetAnalysis(analysisID)
+	if err != nil {
+		return ai.SpendBudget{}, func() {}, nil
+	}
+	return s.reserveProjectBudget(analysis.ProjectID, budgetFromProfile(analysis.Profile))
+}
+
+// budgetFromProfile reads the per-run budget saved in an analysis profile.
+func budgetFromProfile(profileJSON string) ai.SpendBudget {
+	var budget ai.SpendBudget
+	if profileJSON == "" {
+		return budget
+	}
+	var profile map[string]interface{}
+	if json.Unmarshal([]byte(profileJSON), &profile) == nil {
+		if v, ok := profile["budgetUsd"].(float64); ok {
+			budget.USD = v
+		}
+		if v, ok := profile["budgetTokens"].(float64); ok {
+			budget.Tokens = int(v)
+		}
+	}
+	return budget
+}
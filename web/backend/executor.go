@@ -32,6 +32,7 @@ type runningTest struct {
 	Flows      []store.FlowResult `json:"flows"`
 	Logs       []string           `json:"logs"`
 	Status     string             `json:"status"`
+	RandomSeed *uint32            `json:"randomSeed,omitempty"` // seed of the page's randomness; nil for Maestro runs
 }
 
 const maxRunningTestLogs = 500
@@ -172,7 +173,11 @@ func (s *Server) launchTestRun(planID, testID, flowDir, planName string, cleanup
 
 // finishTestRun saves the result and broadcasts completion.
 func (s *Server) finishTestRun(planID, testID, planName string, startTime time.Time, flows []store.FlowResult, runErr error, createdBy string) {
-	// Remove from running tests
+	// Remove from running tests, keeping the seed for the result
+	var randomSeed *uint32
+	if rt := s.runningTests.Get(testID); rt != nil {
+		randomSeed = rt.RandomSeed
+	}
 	s.runningTests.Remove(testID)
 
 	duration := time.Since(startTime)
@@ -223,6 +228,7 @@ func (s *Server) finishTestRun(planID, testID, planName string, startTime time.T
 		CreatedBy:   createdBy,
 		ProjectID:   projectID,
 		PlanID:      planID,
+		RandomSeed:  randomSeed,
 	}
 
 	if err := s.store.SaveTestResult(result); err != nil {
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+// harFileName is the HAR the CLI writes into its output dir and the name it is
+// kept under next to an analysis's or test run's screenshots, so deleting the
+// run removes it too.
+const harFileName = "network.har"
+
+// harArgs returns the scout flags that make an agent run write its network
+// traffic into dir.
+func harArgs(dir string) []string {
+	return []string{"--har", filepath.Join(dir, harFileName)}
+}
+
+// analysisHARName returns the stored HAR file name for an analysis, one per
+// run for batch analyses, keyed by batchRun.harKey.
+func analysisHARName(key string) string {
+	if key == "" {
+		return harFileName
+	}
+	key = strings.ReplaceAll(filepath.Base(key), " ", "_")
+	return fmt.Sprintf("network-%s.har", key)
+}
+
+// persistAnalysisHAR moves the HAR written by the CLI in tmpDir to the data dir
+// and reports whether one was saved. Runs without a HAR (non-agent mode,
+// browser never launched) are skipped.
+func (s *Server) persistAnalysisHAR(analysisID, tmpDir, key string) bool {
+	dataDir := s.store.DataDir()
+	if dataDir == "" {
+		return false
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, harFileName))
+	if err != nil {
+		return false
+	}
+	dstDir := filepath.Join(dataDir, "screenshots", analysisID)
+	if err := os.MkdirAll(dstDir, 0755); err != nil {
+		log.Printf("Warning: failed to create HAR dir for analysis %s: %v", analysisID, err)
+		return false
+	}
+	if err := os.WriteFile(filepath.Join(dstDir, analysisHARName(key)), data, 0600); err != nil {
+		log.Printf("Warning: failed to save HAR for analysis %s: %v", analysisID, err)
+		return false
+	}
+	return true
+}
+
+// saveTestHAR writes a test run's network traffic next to its screenshots.
+func (s *Server) saveTestHAR(testID string, page *scout.RodBrowserPage) {
+	dataDir := s.store.DataDir()
+	if dataDir == "" {
+		return
+	}
+	dstDir := filepath.Join(dataDir, "test-screenshots", testID)
+	if err := os.MkdirAll(dstDir, 0755); err != nil {
+		log.Printf("Warning: failed to create HAR dir for test %s: %v", testID, err)
+		return
+	}
+	if err := page.SaveHAR(filepath.Join(dstDir, harFileName)); err != nil {
+		log.Printf("Warning: failed to save HAR for test %s: %v", testID, err)
+	}
+}
+
+// handleAnalysisHAR downloads the HAR of an agent analysis. Batch analyses keep
+// one per run, selected with ?device= set to the run's deviceResult.HAR key.
+func (s *Server) handleAnalysisHAR(w http.ResponseWriter, r *http.Request) {
+	id := filepath.Base(chi.URLParam(r, "id"))
+	s.serveHAR(w, filepath.Join("screenshots", id, analysisHARName(r.URL.Query().Get("device"))), id)
+}
+
+// handleTestHAR downloads the HAR of a test run.
+func (s *Server) handleTestHAR(w http.ResponseWriter, r *http.Request) {
+	id := filepath.Base(chi.URLParam(r, "id"))
+	s.serveHAR(w, filepath.Join("test-screenshots", id, harFileName), id)
+}
+
+func (s *Server) serveHAR(w http.ResponseWriter, relPath, id string) {
+	dataDir := s.store.DataDir()
+	if dataDir == "" {
+		respondError(w, http.StatusNotFound, "HAR storage not configured")
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(dataDir, relPath))
+	if err != nil {
+		respondError(w, http.StatusNotFound, "HAR not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s"`, id, filepath.Base(relPath)))
+	w.Write(data)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/ai"
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+// maxBatchRuns caps the device × locale runs of one batch analysis.
+const maxBatchRuns = 10
+
+// resolveLocaleRequest validates the locale emulation of an analysis request
+// and returns the locale in canonical form.
+func resolveLocaleRequest(locale, timezone string, geo *scout.Geolocation) (string, error) {
+	if locale != "" {
+		var err error
+		if locale, err = scout.NormalizeLocale(locale); err != nil {
+			return "", err
+		}
+	}
+	if timezone != "" {
+		if err := scout.ValidateTimezone(timezone); err != nil {
+			return "", err
+		}
+	}
+	if geo != nil {
+		if err := geo.Validate(); err != nil {
+			return "", fmt.Errorf("geolocation: %w", err)
+		}
+	}
+	return locale, nil
+}
+
+// localeArgs returns the scout flags that emulate a locale, timezone and
+// position.
+func localeArgs(locale, timezone string, geo *scout.Geolocation) []string {
+	var args []string
+	if locale != "" {
+		args = append(args, "--locale", locale)
+	}
+	if timezone != "" {
+		args = append(args, "--timezone", timezone)
+	}
+	if geo != nil {
+		args = append(args, "--geolocation", geo.String())
+	}
+	return args
+}
+
+// localeBatchModules returns the modules of a locale batch, which only runs
+// the wording check. Modules the request turns on, or a wording check it turns
+// off, are an error rather than silently overridden.
+func localeBatchModules(m AnalysisModules) (AnalysisModules, error) {
+	for _, mod := range []struct {
+		name string
+		on   *bool
+	}{
+		{"uiux", m.UIUX},
+		{"gameDesign", m.GameDesign},
+		{"testFlows", m.TestFlows},
+		{"runTests", m.RunTests},
+		{"gli", m.GLI},
+		{"navigationMap", m.NavigationMap},
+	} {
+		if mod.on != nil && *mod.on {
+			return m, fmt.Errorf("modules.%s: locale batches only run the wording check", mod.name)
+		}
+	}
+	if m.Wording != nil && !*m.Wording {
+		return m, fmt.Errorf("modules.wording: locale batches run the wording check, it cannot be turned off")
+	}
+	off, on := false, true
+	return AnalysisModules{UIUX: &off, Wording: &on, GameDesign: &off, TestFlows: &off, GLI: &off, NavigationMap: &off}, nil
+}
+
+// profileLocaleArgs returns the localeArgs of a stored analysis profile, so a
+// continued analysis emulates the same locale, timezone and position.
+func profileLocaleArgs(profile map[string]interface{}) []string {
+	locale, _ := profile["locale"].(string)
+	timezone, _ := profile["timezone"].(string)
+	var geo *scout.Geolocation
+	if raw, ok := profile["geolocation"]; ok && raw != nil {
+		if data, err := json.Marshal(raw); err == nil {
+			var g scout.Geolocation
+			if json.Unmarshal(data, &g) == nil {
+				geo = &g
+			}
+		}
+	}
+	return localeArgs(locale, timezone, geo)
+}
+
+// batchRun is one CLI run of a batch analysis: a device, in a locale when the
+// batch checks wording across locales.
+type batchRun struct {
+	Device BatchDeviceConfig
+	Locale string
+}
+
+// batchRuns lists the runs of a batch: one per device, or one per device and
+// locale when Locales is set.
+func batchRuns(req BatchAnalysisRequest) []batchRun {
+	if len(req.Locales) == 0 {
+		runs := make([]batchRun, len(req.Devices))
+		for i, d := range req.Devices {
+			runs[i] = batchRun{Device: d, Locale: req.Locale}
+		}
+		return runs
+	}
+	runs := make([]batchRun, 0, len(req.Devices)*len(req.Locales))
+	for _, d := range req.Devices {
+		for _, l := range req.Locales {
+			runs = append(runs, batchRun{Device: d, Locale: l})
+		}
+	}
+	return runs
+}
+
+// label names the run in progress messages, e.g. "[ios de-DE 2/4]".
+func (r batchRun) label(n, total int) string {
+	if r.Locale != "" {
+		return fmt.Sprintf("[%s %s %d/%d]", r.Device.Category, r.Locale, n, total)
+	}
+	return fmt.Sprintf("[%s %d/%d]", r.Device.Category, n, total)
+}
+
+// harKey names the run's HAR among those of the batch: the viewport, plus the
+// locale in locale batches, so runs of one device in several locales (or two
+// devices of one category) do not overwrite each other's HAR.
+func (r batchRun) harKey() string {
+	key := r.Device.Viewport
+	if key == "" {
+		key = r.Device.Category
+	}
+	if r.Locale != "" {
+		key += "_" + r.Locale
+	}
+	return key
+}
+
+// wordingFindingsOf returns the wording findings in a CLI result.
+func wordingFindingsOf(result map[string]interface{}) []ai.WordingFinding {
+	analysis, ok := result["analysis"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(analysis["wordingCheck"])
+	if err != nil {
+		return nil
+	}
+	var findings []ai.WordingFinding
+	if err := json.Unmarshal(data, &findings); err != nil {
+		return nil
+	}
+	return findings
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+// resolveNetworkRequest validates the network profile of an analysis request,
+// filling in preset values. Online and unset profiles resolve to nil.
+func resolveNetworkRequest(p *scout.NetworkProfile) (*scout.NetworkProfile, error) {
+	if p == nil {
+		return nil, nil
+	}
+	resolved, err := scout.ResolveNetworkProfile(p.Name, p.LatencyMs, p.DownloadKbps, p.UploadKbps)
+	if err != nil {
+		return nil, err
+	}
+	if resolved.IsOnline() {
+		return nil, nil
+	}
+	return &resolved, nil
+}
+
+// networkArgs returns the scout flags that make an agent run emulate p.
+func networkArgs(p *scout.NetworkProfile) []string {
+	if p == nil {
+		return nil
+	}
+	args := []string{"--network", p.Name}
+	if p.Name == scout.NetworkCustom {
+		args = append(args,
+			"--network-latency", fmt.Sprintf("%g", p.LatencyMs),
+			"--network-down", fmt.Sprintf("%g", p.DownloadKbps),
+			"--network-up", fmt.Sprintf("%g", p.UploadKbps),
+		)
+	}
+	return args
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/ai"
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+// settingGameStateProbes is the project settings key holding the game-state
+// probes as a JSON object of probe name to JavaScript getter.
+const settingGameStateProbes = "gameStateProbes"
+
+// validateProjectSettings rejects settings the runners could not use.
+func validateProjectSettings(settings map[string]string) error {
+	if raw := settings[settingGameStateProbes]; raw != "" {
+		if _, err := ai.ParseGameStateProbes([]byte(raw)); err != nil {
+			return err
+		}
+	}
+	if raw := settings[settingInitialStorageState]; raw != "" {
+		if _, err := scout.ParseStorageState([]byte(raw)); err != nil {
+			return err
+		}
+	}
+	if raw := settings[settingViewports]; raw != "" {
+		if _, err := scout.ParseViewportPresets([]byte(raw)); err != nil {
+			return err
+		}
+	}
+	if raw := settings[settingDeterministicRandom]; raw != "" {
+		if _, err := strconv.ParseBool(raw); err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", settingDeterministicRandom, raw)
+		}
+	}
+	return nil
+}
+
+// projectProbes returns the game-state probes stored on a project, or nil when it
+// has none. Lookup and parse failures are logged and leave the run without probes.
+func (s *Server) projectProbes(projectID string) ai.GameStateProbes {
+	if projectID == "" {
+		return nil
+	}
+	project, err := s.store.GetProject(projectID)
+	if err != nil {
+		return nil
+	}
+	raw := project.Settings[settingGameStateProbes]
+	if raw == "" {
+		return nil
+	}
+	probes, err := ai.ParseGameStateProbes([]byte(raw))
+	if err != nil {
+		log.Printf("Warning: ignoring game state probes of project %s: %v", projectID, err)
+		return nil
+	}
+	return probes
+}
+
+// planProbes returns the probes of the project a test plan belongs to.
+func (s *Server) planProbes(planID string) ai.GameStateProbes {
+	if planID == "" {
+		return nil
+	}
+	plan, err := s.store.GetTestPlan(planID)
+	if err != nil {
+		return nil
+	}
+	return s.projectProbes(plan.ProjectID)
+}
+
+// analysisProbes returns the probes of the project an analysis belongs to.
+func (s *Server) analysisProbes(analysisID string) ai.GameStateProbes {
+	analysis, err := s.store.GetAnalysis(analysisID)
+	if err != nil {
+		return nil
+	}
+	return s.projectProbes(analysis.ProjectID)
+}
+
+// probesArgs writes a project's probes into dir and returns the scout flags that
+// load them, or nil when the project has no probes.
+func (s *Server) probesArgs(projectID, dir string) []string {
+	probes := s.projectProbes(projectID)
+	if len(probes) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(probes)
+	if err != nil {
+		return nil
+	}
+	path := filepath.Join(dir, "probes.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		log.Printf("Warning: failed to write game state probes for project %s: %v", projectID, err)
+		return nil
+	}
+	return []string{"--probes", path}
+}
+
+// gameStateFromDetail returns the probe values of an agent step detail as JSON.
+func gameStateFromDetail(detail map[string]interface{}) string {
+	state, ok := detail["gameState"]
+	if !ok || state == nil {
+		return ""
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
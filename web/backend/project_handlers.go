@@ -66,6 +66,10 @@ func (s *Server) handleCreateProject(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "Project name is required")
 		return
 	}
+	if err := validateProjectSettings(p.Settings); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	now := time.Now().Format(time.RFC3339)
 	p.ID = newID("proj")
@@ -161,6 +165,10 @@ func (s *Server) handleUpdateProject(w http.ResponseWriter, r *http.Request) {
 		existing.Tags = updates.Tags
 	}
 	if updates.Settings != nil {
+		if err := validateProjectSettings(updates.Settings); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		existing.Settings = updates.Settings
 	}
 	existing.UpdatedAt = time.Now().Format(time.RFC3339)
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+// settingDeterministicRandom is the project settings key controlling whether
+// browser runs seed Math.random and crypto.getRandomValues. Seeding is on
+// unless the project sets it to "false", e.g. for games that use randomness
+// for anti-cheat tokens.
+const settingDeterministicRandom = "deterministicRandom"
+
+// resolveRandomSeed returns the seed of a browser run in a project: the
+// requested seed when replaying a run, nil when the project opted out, and a
+// fresh seed otherwise.
+func (s *Server) resolveRandomSeed(projectID string, requested *uint32) *uint32 {
+	if requested != nil {
+		return requested
+	}
+	if projectID != "" {
+		if project, err := s.store.GetProject(projectID); err == nil {
+			if on, err := strconv.ParseBool(project.Settings[settingDeterministicRandom]); err == nil && !on {
+				return nil
+			}
+		}
+	}
+	seed := scout.NewRandomSeed()
+	return &seed
+}
+
+// randomSeedArgs returns the scout flags that seed an agent run.
+func randomSeedArgs(seed *uint32) []string {
+	if seed == nil {
+		return nil
+	}
+	return []string{"--seed", fmt.Sprintf("%d", *seed)}
+}
@@ -24,7 +24,10 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 
+	"github.com/Global-Wizards/wizards-qa/pkg/ai"
+	"github.com/Global-Wizards/wizards-qa/pkg/config"
 	"github.com/Global-Wizards/wizards-qa/pkg/flows"
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
 	"github.com/Global-Wizards/wizards-qa/web/backend/auth"
 	"github.com/Global-Wizards/wizards-qa/web/backend/store"
 	"github.com/Global-Wizards/wizards-qa/web/backend/ws"
@@ -54,6 +57,7 @@ type Server struct {
 	activeAnalyses   map[string]*activeAnalysis
 	activeAnalysesMu sync.Mutex
 	runningTests *RunningTestTracker
+	budgetReservations budgetReservations // budgets held by in-flight runs, per project
 }
 
 func NewServer(port string) *Server {
@@ -77,6 +81,30 @@ func NewServer(port string) *Server {
 		log.Printf("Warning: directory validation failed: %v", err)
 	}
 
+	// Load the external model pricing table and custom viewport presets from the
+	// same sources as the spawned CLI: the env var, else the config file setting
+	pricingPath, viewportsPath := os.Getenv(ai.PricingEnvVar), os.Getenv(scout.ViewportsEnvVar)
+	if cfg, err := config.Load(configPath); err != nil {
+		log.Printf("Warning: failed to load config %s: %v", configPath, err)
+	} else {
+		if pricingPath == "" {
+			pricingPath = cfg.AI.PricingFile
+		}
+		if viewportsPath == "" {
+			viewportsPath = cfg.Browser.ViewportsFile
+		}
+	}
+	if pricingPath != "" {
+		if err := ai.LoadPricingFile(pricingPath); err != nil {
+			log.Printf("Warning: failed to load pricing file: %v", err)
+		}
+	}
+	if viewportsPath != "" {
+		if err := scout.LoadViewportFile(viewportsPath); err != nil {
+			log.Printf("Warning: failed to load viewports file: %v", err)
+		}
+	}
+
 	// One-time migration from JSON files to SQLite
 	st.MigrateFromJSON(dataDir)
 
@@ -181,6 +209,7 @@ func (s *Server) setupRoutes() {
 		r.Get("/api/tests", s.handleListTests)
 		r.Get("/api/tests/{id}", s.handleGetTest)
 		r.Get("/api/tests/{id}/live", s.handleGetLiveTest)
+		r.Get("/api/tests/{id}/har", s.handleTestHAR)
 		r.Post("/api/tests/run", s.handleRunTest)
 		r.Delete("/api/tests/{id}", s.handleDeleteTestResult)
 		r.Post("/api/tests/delete-batch", s.handleDeleteTestResultsBatch)
@@ -193,6 +222,7 @@ func (s *Server) setupRoutes() {
 		r.Get("/api/config", s.handleGetConfig)
 		r.Get("/api/performance", s.handleGetPerformance)
 		r.Get("/api/templates", s.handleListTemplates)
+		r.Get("/api/viewports", s.handleListViewports)
 		r.Get("/api/test-plans", s.handleListTestPlans)
 		r.Post("/api/test-plans", s.handleCreateTestPlan)
 		r.Get("/api/test-plans/{id}", s.handleGetTestPlan)
@@ -210,6 +240,7 @@ func (s *Server) setupRoutes() {
 		r.Get("/api/analyses/{id}/steps", s.handleListAgentSteps)
 		r.Get("/api/analyses/{id}/steps/{stepNumber}/screenshot", s.handleAgentStepScreenshot)
 		r.Get("/api/analyses/{id}/screenshots/{filename}", s.handleAnalysisScreenshot)
+		r.Get("/api/analyses/{id}/har", s.handleAnalysisHAR)
 		r.Post("/api/analyses/{id}/message", s.handleSendAgentMessage)
 		r.Post("/api/analyses/{id}/continue", s.handleContinueAnalysis)
 		r.Post("/api/analyses/{id}/share", s.handleCreateShareLink)
@@ -693,8 +724,9 @@ func (s *Server) handleRunTestPlan(w http.ResponseWriter, r *http.Request) {
 
 	// Parse optional run mode and viewport from request body
 	var req struct {
-		Mode     string `json:"mode"`     // "maestro" (default), "browser", or "agent"
-		Viewport string `json:"viewport"` // viewport preset name for browser/agent mode
+		Mode       string  `json:"mode"`       // "maestro" (default), "browser", or "agent"
+		Viewport   string  `json:"viewport"`   // viewport preset name for browser/agent mode
+		RandomSeed *uint32 `json:"randomSeed"` // replays an earlier browser/agent run's randomness
 	}
 	// Body is optional — ignore decode errors for backward compat (e.g. empty body)
 	json.NewDecoder(r.Body).Decode(&req)
@@ -719,7 +751,7 @@ func (s *Server) handleRunTestPlan(w http.ResponseWriter, r *http.Request) {
 		if viewport == "" {
 			viewport = "desktop-std"
 		}
-		s.launchAgentTestRun(plan.ID, testID, plan.AnalysisID, plan.Name, viewport, createdByPlan)
+		s.launchAgentTestRun(plan.ID, testID, plan.AnalysisID, plan.Name, viewport, createdByPlan, s.resolveRandomSeed(plan.ProjectID, req.RandomSeed))
 
 	case "browser":
 		flowDir, err := s.prepareFlowDir(plan)
@@ -731,7 +763,7 @@ func (s *Server) handleRunTestPlan(w http.ResponseWriter, r *http.Request) {
 		if viewport == "" {
 			viewport = "desktop-std"
 		}
-		s.launchBrowserTestRun(plan.ID, testID, flowDir, plan.Name, viewport, true, createdByPlan)
+		s.launchBrowserTestRun(plan.ID, testID, flowDir, plan.Name, viewport, true, createdByPlan, s.resolveRandomSeed(plan.ProjectID, req.RandomSeed))
 
 	default:
 		mode = "maestro"
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+// settingInitialStorageState is the project settings key holding the storage
+// (localStorage, sessionStorage, cookies, IndexedDB) seeded before the game
+// loads, as a scout.StorageState JSON object.
+const settingInitialStorageState = "initialStorageState"
+
+// projectStorageState returns the initial storage state of a project, or nil
+// when it has none. Lookup and parse failures are logged and leave the run
+// with a fresh browser profile.
+func (s *Server) projectStorageState(projectID string) *scout.StorageState {
+	if projectID == "" {
+		return nil
+	}
+	project, err := s.store.GetProject(projectID)
+	if err != nil {
+		return nil
+	}
+	raw := project.Settings[settingInitialStorageState]
+	if raw == "" {
+		return nil
+	}
+	state, err := scout.ParseStorageState([]byte(raw))
+	if err != nil {
+		log.Printf("Warning: ignoring initial storage state of project %s: %v", projectID, err)
+		return nil
+	}
+	if state.IsEmpty() {
+		return nil
+	}
+	return state
+}
+
+// planStorageState returns the initial storage state of the project a test
+// plan belongs to.
+func (s *Server) planStorageState(planID string) *scout.StorageState {
+	if planID == "" {
+		return nil
+	}
+	plan, err := s.store.GetTestPlan(planID)
+	if err != nil {
+		return nil
+	}
+	return s.projectStorageState(plan.ProjectID)
+}
+
+// storageStateArgs writes a project's initial storage state into dir and
+// returns the scout flags that seed it, or nil when the project has none.
+func (s *Server) storageStateArgs(projectID, dir string) []string {
+	state := s.projectStorageState(projectID)
+	if state == nil {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	path := filepath.Join(dir, "storage-state.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		log.Printf("Warning: failed to write initial storage state for project %s: %v", projectID, err)
+		return nil
+	}
+	return []string{"--storage-state", path}
+}
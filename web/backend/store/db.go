@@ -181,6 +181,9 @@ func runMigrations(db *sql.DB) error {
 		`ALTER TABLE agent_steps ADD COLUMN output_tokens INTEGER DEFAULT 0`,
 		`ALTER TABLE agent_steps ADD COLUMN credits INTEGER DEFAULT 0`,
 		`ALTER TABLE test_results ADD COLUMN total_credits INTEGER DEFAULT 0`,
+		`ALTER TABLE agent_steps ADD COLUMN game_state TEXT DEFAULT ''`,
+		`ALTER TABLE test_results ADD COLUMN random_seed INTEGER`,
+		`ALTER TABLE test_results ADD COLUMN total_tokens INTEGER DEFAULT 0`,
 	}
 	for _, stmt := range alters {
 		if _, err := db.Exec(stmt); err != nil {
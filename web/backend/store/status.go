@@ -8,3 +8,7 @@ const (
 	StatusDraft     = "draft"
 	StatusPassed    = "passed"
 )
+
+// StepBudgetExhausted marks a completed analysis whose exploration stopped early
+// on its spend budget (the result covers only what was explored).
+const StepBudgetExhausted = "budget_exhausted"
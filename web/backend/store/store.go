@@ -445,7 +445,7 @@ func scanTestPlanSummary(rows *sql.Rows) (TestPlanSummary, error) {
 func scanAgentStep(rows *sql.Rows) (AgentStepRecord, error) {
 	var step AgentStepRecord
 	err := rows.Scan(&step.ID, &step.AnalysisID, &step.StepNumber, &step.ToolName, &step.Input,
-		&step.Result, &step.ScreenshotPath, &step.DurationMs, &step.ThinkingMs, &step.Error, &step.Reasoning, &step.CreatedAt, &step.InputTokens, &step.OutputTokens, &step.Credits)
+		&step.Result, &step.ScreenshotPath, &step.DurationMs, &step.ThinkingMs, &step.Error, &step.Reasoning, &step.CreatedAt, &step.InputTokens, &step.OutputTokens, &step.Credits, &step.GameState)
 	return step, err
 }
 
@@ -487,11 +487,11 @@ func (s *Store) SaveAgentStep(step AgentStepRecord) (int64, error) {
 		step.CreatedAt = time.Now().Format(time.RFC3339)
 	}
 	res, err := s.db.Exec(
-		`INSERT INTO agent_steps (analysis_id, step_number, tool_name, input, result, screenshot_path, duration_ms, thinking_ms, error, reasoning, created_at, input_tokens, output_tokens, credits)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO agent_steps (analysis_id, step_number, tool_name, input, result, screenshot_path, duration_ms, thinking_ms, error, reasoning, created_at, input_tokens, output_tokens, credits, game_state)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		step.AnalysisID, step.StepNumber, step.ToolName, step.Input, step.Result,
 		step.ScreenshotPath, step.DurationMs, step.ThinkingMs, step.Error, step.Reasoning, step.CreatedAt,
-		step.InputTokens, step.OutputTokens, step.Credits,
+		step.InputTokens, step.OutputTokens, step.Credits, step.GameState,
 	)
 	if err != nil {
 		return 0, err
@@ -526,7 +526,7 @@ func (s *Store) GetAgentStepScreenshot(analysisID string, stepNumber int) (strin
 
 func (s *Store) ListAgentSteps(analysisID string) ([]AgentStepRecord, error) {
 	rows, err := s.db.Query(
-		`SELECT id, analysis_id, step_number, tool_name, input, result, screenshot_path, duration_ms, COALESCE(thinking_ms,0), error, reasoning, created_at, COALESCE(input_tokens,0), COALESCE(output_tokens,0), COALESCE(credits,0)
+		`SELECT id, analysis_id, step_number, tool_name, input, result, screenshot_path, duration_ms, COALESCE(thinking_ms,0), error, reasoning, created_at, COALESCE(input_tokens,0), COALESCE(output_tokens,0), COALESCE(credits,0), COALESCE(game_state,'')
 		 FROM agent_steps WHERE analysis_id = ? ORDER BY step_number ASC`, analysisID,
 	)
 	if err != nil {
@@ -586,11 +586,16 @@ func (s *Store) SaveTestResult(result TestResultDetail) error {
 	if result.CreatedBy != "" {
 		createdBy = &result.CreatedBy
 	}
+	var randomSeed *int64
+	if result.RandomSeed != nil {
+		seed := int64(*result.RandomSeed)
+		randomSeed = &seed
+	}
 
 	_, err = s.db.Exec(
-		`INSERT INTO test_results (id, name, status, timestamp, duration, success_rate, flows, error_output, created_by, project_id, plan_id, total_credits, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		result.ID, result.Name, result.Status, ts, result.Duration, result.SuccessRate, flowsJSON, result.ErrorOutput, createdBy, result.ProjectID, result.PlanID, result.TotalCredits, ts,
+		`INSERT INTO test_results (id, name, status, timestamp, duration, success_rate, flows, error_output, created_by, project_id, plan_id, total_credits, random_seed, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		result.ID, result.Name, result.Status, ts, result.Duration, result.SuccessRate, flowsJSON, result.ErrorOutput, createdBy, result.ProjectID, result.PlanID, result.TotalCredits, randomSeed, ts,
 	)
 	if err != nil {
 		return fmt.Errorf("SaveTestResult: %w", err)
@@ -610,14 +615,19 @@ func (s *Store) ListTestResults(limit, offset int) ([]TestResultSummary, error)
 
 func (s *Store) GetTestResult(id string) (*TestResultDetail, error) {
 	row := s.db.QueryRow(
-		`SELECT id, name, status, timestamp, duration, success_rate, flows, error_output, COALESCE(created_by,''), COALESCE(project_id,''), COALESCE(plan_id,''), COALESCE(total_credits,0) FROM test_results WHERE id = ?`, id,
+		`SELECT id, name, status, timestamp, duration, success_rate, flows, error_output, COALESCE(created_by,''), COALESCE(project_id,''), COALESCE(plan_id,''), COALESCE(total_credits,0), random_seed FROM test_results WHERE id = ?`, id,
 	)
 	var r TestResultDetail
 	var flowsJSON sql.NullString
-	err := row.Scan(&r.ID, &r.Name, &r.Status, &r.Timestamp, &r.Duration, &r.SuccessRate, &flowsJSON, &r.ErrorOutput, &r.CreatedBy, &r.ProjectID, &r.PlanID, &r.TotalCredits)
+	var randomSeed sql.NullInt64
+	err := row.Scan(&r.ID, &r.Name, &r.Status, &r.Timestamp, &r.Duration, &r.SuccessRate, &flowsJSON, &r.ErrorOutput, &r.CreatedBy, &r.ProjectID, &r.PlanID, &r.TotalCredits, &randomSeed)
 	if err != nil {
 		return nil, fmt.Errorf("test result not found: %s", id)
 	}
+	if randomSeed.Valid {
+		seed := uint32(randomSeed.Int64)
+		r.RandomSeed = &seed
+	}
 	unmarshalJSONField(flowsJSON, &r.Flows, fmt.Sprintf("flows for test %s", id))
 	return &r, nil
 }
@@ -1443,9 +1453,9 @@ func (s *Store) UpdateAnalysisCost(id string, credits, inputTokens, outputTokens
 	return nil
 }
 
-// UpdateTestResultCredits updates the total credits for a test result.
-func (s *Store) UpdateTestResultCredits(id string, credits int) error {
-	result, err := s.db.Exec(`UPDATE test_results SET total_credits=? WHERE id=?`, credits, id)
+// UpdateTestResultCost updates the total credits and AI tokens for a test result.
+func (s *Store) UpdateTestResultCost(id string, credits, tokens int) error {
+	result, err := s.db.Exec(`UPDATE test_results SET total_credits=?, total_tokens=? WHERE id=?`, credits, tokens, id)
 	if err != nil {
 		return err
 	}
@@ -1455,6 +1465,28 @@ func (s *Store) UpdateTestResultCredits(id string, credits int) error {
 	return nil
 }
 
+// ProjectSpendSince sums the credits and AI tokens (analyses + test runs) a project has
+// used since the given time.
+func (s *Store) ProjectSpendSince(projectID string, since time.Time) (credits, tokens int, err error) {
+	sinceStr := since.Format(time.RFC3339)
+	var analysisCredits, testCredits, testTokens int
+	err = s.db.QueryRow(
+		`SELECT COALESCE(SUM(total_credits),0), COALESCE(SUM(input_tokens),0) + COALESCE(SUM(output_tokens),0)
+		 FROM analyses WHERE project_id = ? AND created_at >= ?`, projectID, sinceStr,
+	).Scan(&analysisCredits, &tokens)
+	if err != nil {
+		return 0, 0, fmt.Errorf("ProjectSpendSince analyses: %w", err)
+	}
+	err = s.db.QueryRow(
+		`SELECT COALESCE(SUM(total_credits),0), COALESCE(SUM(total_tokens),0)
+		 FROM test_results WHERE project_id = ? AND created_at >= ?`, projectID, sinceStr,
+	).Scan(&testCredits, &testTokens)
+	if err != nil {
+		return 0, 0, fmt.Errorf("ProjectSpendSince test results: %w", err)
+	}
+	return analysisCredits + testCredits, tokens + testTokens, nil
+}
+
 // --- Share Tokens ---
 
 // CreateShareToken inserts a new share token for an analysis.
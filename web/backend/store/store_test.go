@@ -250,3 +250,75 @@ func TestIsYAMLFile(t *testing.T) {
 		t.Error("expected .json to not be YAML")
 	}
 }
+
+func TestProjectSpendSince(t *testing.T) {
+	db, s := setupTestDB(t)
+	monthStart := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	before := monthStart.Add(-time.Hour).Format(time.RFC3339)
+	during := monthStart.Add(time.Hour).Format(time.RFC3339)
+
+	db.Exec(`INSERT INTO analyses (id, game_url, status, project_id, total_credits, input_tokens, output_tokens, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"old", "https://example.com", "completed", "p1", 500, 9000, 1000, before, before)
+	db.Exec(`INSERT INTO analyses (id, game_url, status, project_id, total_credits, input_tokens, output_tokens, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"new", "https://example.com", "completed", "p1", 120, 4000, 600, during, during)
+	db.Exec(`INSERT INTO analyses (id, game_url, status, project_id, total_credits, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		"other", "https://example.com", "completed", "p2", 999, during, during)
+	db.Exec(`INSERT INTO test_results (id, name, status, timestamp, project_id, total_credits, total_tokens, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"t1", "run", "passed", during, "p1", 30, 2500, during)
+
+	credits, tokens, err := s.ProjectSpendSince("p1", monthStart)
+	if err != nil {
+		t.Fatalf("ProjectSpendSince failed: %v", err)
+	}
+	if credits != 150 || tokens != 7100 {
+		t.Errorf("expected 150 credits and 7100 tokens, got %d and %d", credits, tokens)
+	}
+}
+
+func TestAgentStepGameState(t *testing.T) {
+	db, s := setupTestDB(t)
+	now := time.Now().Format(time.RFC3339)
+	db.Exec(`INSERT INTO analyses (id, game_url, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		"a1", "https://example.com", "running", now, now)
+
+	if _, err := s.SaveAgentStep(AgentStepRecord{AnalysisID: "a1", StepNumber: 1, ToolName: "click", GameState: `{"balance":990}`}); err != nil {
+		t.Fatalf("SaveAgentStep failed: %v", err)
+	}
+	if _, err := s.SaveAgentStep(AgentStepRecord{AnalysisID: "a1", StepNumber: 2, ToolName: "screenshot"}); err != nil {
+		t.Fatalf("SaveAgentStep failed: %v", err)
+	}
+	steps, err := s.ListAgentSteps("a1")
+	if err != nil {
+		t.Fatalf("ListAgentSteps failed: %v", err)
+	}
+	if len(steps) != 2 || steps[0].GameState != `{"balance":990}` || steps[1].GameState != "" {
+		t.Errorf("unexpected game state on steps: %+v", steps)
+	}
+}
+
+func TestTestResultRandomSeed(t *testing.T) {
+	_, s := setupTestDB(t)
+	seed := uint32(3_000_000_000) // above 2^31: must not come back negative or truncated
+
+	if err := s.SaveTestResult(TestResultDetail{ID: "seeded", Name: "run", Status: "passed", RandomSeed: &seed}); err != nil {
+		t.Fatalf("SaveTestResult failed: %v", err)
+	}
+	if err := s.SaveTestResult(TestResultDetail{ID: "unseeded", Name: "run", Status: "passed"}); err != nil {
+		t.Fatalf("SaveTestResult failed: %v", err)
+	}
+
+	got, err := s.GetTestResult("seeded")
+	if err != nil {
+		t.Fatalf("GetTestResult failed: %v", err)
+	}
+	if got.RandomSeed == nil || *got.RandomSeed != seed {
+		t.Errorf("expected seed %d, got %v", seed, got.RandomSeed)
+	}
+	got, err = s.GetTestResult("unseeded")
+	if err != nil {
+		t.Fatalf("GetTestResult failed: %v", err)
+	}
+	if got.RandomSeed != nil {
+		t.Errorf("expected no seed, got %d", *got.RandomSeed)
+	}
+}
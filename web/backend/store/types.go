@@ -61,6 +61,7 @@ type TestResultDetail struct {
 	ProjectID   string       `json:"projectId,omitempty"`
 	PlanID       string       `json:"planId,omitempty"`
 	TotalCredits int          `json:"totalCredits,omitempty"`
+	RandomSeed   *uint32      `json:"randomSeed,omitempty"` // seed of the run's Math.random/crypto.getRandomValues
 }
 
 type HistoryPoint struct {
@@ -179,6 +180,7 @@ type AgentStepRecord struct {
 	InputTokens    int    `json:"inputTokens,omitempty"`
 	OutputTokens   int    `json:"outputTokens,omitempty"`
 	Credits        int    `json:"credits,omitempty"`
+	GameState      string `json:"gameState,omitempty"` // JSON object of probe values read after the step
 }
 
 type AnalysesFile struct {
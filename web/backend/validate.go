@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
 	"gopkg.in/yaml.v3"
 )
 
@@ -12,12 +13,25 @@ import (
 var maestroAllowedCommands = map[string]bool{
 	"launchApp":         true,
 	"tapOn":             true,
+	"longPressOn":       true,
 	"inputText":         true,
 	"assertVisible":     true,
 	"assertNotVisible":  true,
 	"extendedWaitUntil": true,
 	"scroll":            true,
 	"swipe":             true,
+	"pinch":             true,
+	"rotate":            true,
+	"twoFingerPan":      true,
+	"keyDown":           true,
+	"keyUp":             true,
+	"holdKey":           true,
+	"holdKeys":          true,
+	"assertState":       true,
+	"setNetwork":        true,
+	"advanceTime":       true,
+	"pauseGame":         true,
+	"rotateDevice":      true,
 	"back":              true,
 	"takeScreenshot":    true,
 	"openLink":          true,
@@ -279,25 +293,25 @@ func validateCommand(cmd interface{}, cmdNum int, result *flowValidationResult)
 // validateCommandValue does deep validation of specific command arguments.
 func validateCommandValue(cmdName string, value interface{}, cmdNum int, result *flowValidationResult) {
 	switch cmdName {
-	case "tapOn":
+	case "tapOn", "longPressOn":
 		switch val := value.(type) {
 		case string:
 			if val == "" {
-				result.Warnings = append(result.Warnings, fmt.Sprintf("Command %d (tapOn): empty text selector", cmdNum))
+				result.Warnings = append(result.Warnings, fmt.Sprintf("Command %d (%s): empty text selector", cmdNum, cmdName))
 			}
 		case map[string]interface{}:
 			if _, has := val["visible"]; has {
-				result.Errors = append(result.Errors, fmt.Sprintf("Command %d (tapOn): 'visible' is not valid here — use tapOn: \"text\" directly or extendedWaitUntil for waiting", cmdNum))
+				result.Errors = append(result.Errors, fmt.Sprintf("Command %d (%s): 'visible' is not valid here — use %s: \"text\" directly or extendedWaitUntil for waiting", cmdNum, cmdName, cmdName))
 				result.Valid = false
 			}
 			if _, has := val["notVisible"]; has {
-				result.Errors = append(result.Errors, fmt.Sprintf("Command %d (tapOn): 'notVisible' is not valid here — use tapOn: \"text\" directly", cmdNum))
+				result.Errors = append(result.Errors, fmt.Sprintf("Command %d (%s): 'notVisible' is not valid here — use %s: \"text\" directly", cmdNum, cmdName, cmdName))
 				result.Valid = false
 			}
 			if point, ok := val["point"]; ok {
 				if pointStr, ok := point.(string); ok {
 					if !strings.Contains(pointStr, ",") {
-						result.Errors = append(result.Errors, fmt.Sprintf("Command %d (tapOn): point must be 'x,y' format (e.g. \"50%%,50%%\"), got '%s'", cmdNum, pointStr))
+						result.Errors = append(result.Errors, fmt.Sprintf("Command %d (%s): point must be 'x,y' format (e.g. \"50%%,50%%\"), got '%s'", cmdNum, cmdName, pointStr))
 						result.Valid = false
 					}
 				}
@@ -318,11 +332,11 @@ func validateCommandValue(cmdName string, value interface{}, cmdNum int, result
 					}
 				}
 				if !hasAnyValid && len(val) > 0 {
-					result.Warnings = append(result.Warnings, fmt.Sprintf("Command %d (tapOn): no recognizable selector (text, id, or point) found", cmdNum))
+					result.Warnings = append(result.Warnings, fmt.Sprintf("Command %d (%s): no recognizable selector (text, id, or point) found", cmdNum, cmdName))
 				}
 			}
 		case nil:
-			result.Errors = append(result.Errors, fmt.Sprintf("Command %d (tapOn): missing selector — provide a text string or map with text/id/point", cmdNum))
+			result.Errors = append(result.Errors, fmt.Sprintf("Command %d (%s): missing selector — provide a text string or map with text/id/point", cmdNum, cmdName))
 			result.Valid = false
 		}
 
@@ -422,6 +436,81 @@ func validateCommandValue(cmdName string, value interface{}, cmdNum int, result
 			}
 		}
 
+	case "pinch", "rotate", "twoFingerPan":
+		// Browser-runner extensions, performed with two touch points
+		required := map[string]string{"pinch": "scale", "rotate": "degrees", "twoFingerPan": "end"}[cmdName]
+		m, _ := value.(map[string]interface{})
+		if _, ok := m[required]; !ok {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Command %d (%s): missing '%s'", cmdNum, cmdName, required))
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Command %d (%s): only supported by the browser runner on phone/tablet viewports", cmdNum, cmdName))
+
+	case "keyDown", "keyUp", "holdKey", "holdKeys":
+		// Browser-runner extensions for held keys and chords
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Command %d (%s): only supported by the browser runner", cmdNum, cmdName))
+
+	case "assertState":
+		// Browser-runner extension: compares a project game-state probe
+		m, _ := value.(map[string]interface{})
+		if probe, _ := m["probe"].(string); probe == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("Command %d (assertState): missing 'probe'", cmdNum))
+		}
+		_, hasEquals := m["equals"]
+		_, hasGt := m["gt"]
+		_, hasLt := m["lt"]
+		if !hasEquals && !hasGt && !hasLt {
+			result.Errors = append(result.Errors, fmt.Sprintf("Command %d (assertState): requires 'equals', 'gt' or 'lt'", cmdNum))
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Command %d (assertState): only supported by the browser runner", cmdNum))
+
+	case "setNetwork":
+		// Browser-runner extension: emulates offline, slow-3g, fast-3g or a custom connection
+		profile, _ := value.(string)
+		if m, ok := value.(map[string]interface{}); ok {
+			profile, _ = m["profile"].(string)
+		}
+		if profile == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("Command %d (setNetwork): missing profile (online, offline, slow-3g, fast-3g or custom)", cmdNum))
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Command %d (setNetwork): only supported by the browser runner", cmdNum))
+
+	case "advanceTime":
+		// Browser-runner extension: fast-forwards the page clock (CDP virtual time)
+		ms := 0
+		switch v := value.(type) {
+		case int:
+			ms = v
+		case float64:
+			ms = int(v)
+		case map[string]interface{}:
+			switch n := v["ms"].(type) {
+			case int:
+				ms = n
+			case float64:
+				ms = int(n)
+			}
+		}
+		if ms <= 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("Command %d (advanceTime): requires a positive number of milliseconds", cmdNum))
+		} else if max := int(scout.MaxAdvanceTime.Milliseconds()); ms > max {
+			result.Errors = append(result.Errors, fmt.Sprintf("Command %d (advanceTime): %dms exceeds the %dms maximum per command", cmdNum, ms, max))
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Command %d (advanceTime): only supported by the browser runner", cmdNum))
+
+	case "pauseGame":
+		// Browser-runner extension: freezes (true) or resumes (false) the page clock
+		if _, ok := value.(bool); !ok {
+			result.Errors = append(result.Errors, fmt.Sprintf("Command %d (pauseGame): value must be true or false", cmdNum))
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Command %d (pauseGame): only supported by the browser runner", cmdNum))
+
+	case "rotateDevice":
+		// Browser-runner extension: turns a phone or tablet to portrait or landscape
+		if o, _ := value.(string); o != "portrait" && o != "landscape" {
+			result.Errors = append(result.Errors, fmt.Sprintf("Command %d (rotateDevice): value must be portrait or landscape", cmdNum))
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Command %d (rotateDevice): only supported by the browser runner on phone and tablet viewports", cmdNum))
+
 	case "repeat":
 		if m, ok := value.(map[string]interface{}); ok {
 			_, hasTimes := m["times"]
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/Global-Wizards/wizards-qa/pkg/scout"
+)
+
+// settingViewports is the project settings key holding the project's own
+// viewport presets, as a JSON list of scout.ViewportPreset. They are consulted
+// before the configured and built-in presets.
+const settingViewports = "viewports"
+
+// projectViewports returns the viewport presets defined on a project, or nil
+// when it has none. Lookup and parse failures are logged and leave the
+// project with the configured and built-in presets.
+func (s *Server) projectViewports(projectID string) []scout.ViewportPreset {
+	raw := s.rawProjectViewports(projectID)
+	if raw == "" {
+		return nil
+	}
+	presets, err := scout.ParseViewportPresets([]byte(raw))
+	if err != nil {
+		log.Printf("Warning: ignoring viewport presets of project %s: %v", projectID, err)
+		return nil
+	}
+	return presets
+}
+
+// rawProjectViewports returns a project's viewport presets setting as stored,
+// without the landscape variants parsing adds, or "" when it has none.
+func (s *Server) rawProjectViewports(projectID string) string {
+	if projectID == "" {
+		return ""
+	}
+	project, err := s.store.GetProject(projectID)
+	if err != nil {
+		return ""
+	}
+	return project.Settings[settingViewports]
+}
+
+// checkViewports returns an error naming the first of names that is neither
+// a preset of the project nor a configured or built-in one. Empty names pick
+// the default and are skipped.
+func (s *Server) checkViewports(projectID string, names ...string) error {
+	project := s.projectViewports(projectID)
+	for _, name := range names {
+		if name != "" && scout.GetViewportByName(name, project...) == nil {
+			return fmt.Errorf("unknown viewport %q", name)
+		}
+	}
+	return nil
+}
+
+// planViewports returns the viewport presets of the project a test plan
+// belongs to.
+func (s *Server) planViewports(planID string) []scout.ViewportPreset {
+	if planID == "" {
+		return nil
+	}
+	plan, err := s.store.GetTestPlan(planID)
+	if err != nil {
+		return nil
+	}
+	return s.projectViewports(plan.ProjectID)
+}
+
+// resolveViewport returns the named preset of a test plan's project, falling
+// back to the default preset for unknown names.
+func (s *Server) resolveViewport(planID, name string) *scout.ViewportPreset {
+	project := s.planViewports(planID)
+	if vp := scout.GetViewportByName(name, project...); vp != nil {
+		return vp
+	}
+	return scout.GetViewportByName(scout.DefaultViewportName, project...)
+}
+
+// viewportArgs writes a project's viewport presets into dir and returns the
+// scout flags that make them available to --viewport, or nil when the project
+// has none. The presets are written as stored: the CLI adds the landscape
+// variants itself when it loads them.
+func (s *Server) viewportArgs(projectID, dir string) []string {
+	if len(s.projectViewports(projectID)) == 0 {
+		return nil
+	}
+	path := filepath.Join(dir, "viewports.json")
+	if err := os.WriteFile(path, []byte(s.rawProjectViewports(projectID)), 0600); err != nil {
+		log.Printf("Warning: failed to write viewport presets for project %s: %v", projectID, err)
+		return nil
+	}
+	return []string{"--viewports", path}
+}
+
+// handleListViewports returns the viewport presets: built-in, overridden by
+// the configured viewports file, overridden by the presets of the project
+// named by ?projectId.
+func (s *Server) handleListViewports(w http.ResponseWriter, r *http.Request) {
+	projectID := r.URL.Query().Get("projectId")
+	if projectID != "" && !s.requireProjectAccess(w, r, projectID) {
+		return
+	}
+	presets := scout.ViewportPresets(s.projectViewports(projectID))
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"viewports": presets,
+		"default":   scout.DefaultViewportName,
+		"total":     len(presets),
+	})
+}
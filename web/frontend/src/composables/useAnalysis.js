@@ -7,6 +7,7 @@ import { useTimer } from '@/composables/useTimer'
 
 const MAX_PERSISTED_STEPS_LOAD = 200
 const MAX_LIVE_STEPS = 50
+const MAX_STREAM_CHARS = 4000
 const LS_KEY = 'wizards-qa-running-analysis'
 
 // Map granular step names to coarse status for backward compat
@@ -45,11 +46,14 @@ const STEP_TO_STATUS = {
   agent_done: 'analyzing',
   agent_synthesize: 'analyzing',
   synthesis_retry: 'analyzing',
+  synthesis_repair: 'analyzing',
+  synthesis_fallback: 'analyzing',
   agent_reasoning: 'analyzing',
   agent_step_detail: 'analyzing',
   agent_screenshot: 'analyzing',
   agent_adaptive: 'analyzing',
   agent_timeout_extend: 'analyzing',
+  agent_context_compact: 'analyzing',
   user_hint: 'analyzing',
   flows_retry: 'generating',
   resuming: 'scouting',
@@ -79,6 +83,9 @@ export function useAnalysis() {
   const liveAgentSteps = shallowRef([])
   const latestScreenshot = ref(null)
   const agentReasoning = ref('')
+  // Streamed AI output (synthesis / flow generation), tail-capped
+  const aiStreamText = ref('')
+  const aiStreamChars = ref(0)
   const userHints = ref([])
   const hintCooldown = ref(false)
   const agentStepCurrent = ref(0)
@@ -162,6 +169,8 @@ export function useAnalysis() {
     liveAgentSteps.value = []
     latestScreenshot.value = null
     agentReasoning.value = ''
+    aiStreamText.value = ''
+    aiStreamChars.value = 0
     userHints.value = []
     hintCooldown.value = false
     agentStepCurrent.value = 0
@@ -205,6 +214,8 @@ export function useAnalysis() {
           currentStep.value = 'complete'
           latestScreenshot.value = null
           agentReasoning.value = ''
+          aiStreamText.value = ''
+          aiStreamChars.value = 0
           timer.stop()
           clearLocalStorage()
           loadPersistedSteps(analysisId.value)
@@ -398,6 +409,15 @@ export function useAnalysis() {
       appendCapped(liveAgentSteps, hintEntry, MAX_LIVE_STEPS)
     })
 
+    const offAIStream = ws.on('ai_stream', (data) => {
+      if (analysisId.value && data.analysisId !== analysisId.value) return
+      if (data.kind !== 'text' && data.kind !== 'json') return
+      // chars restarts at each new response; start a fresh buffer with it
+      const prev = (data.chars || 0) > aiStreamChars.value ? aiStreamText.value : ''
+      aiStreamText.value = (prev + (data.text || '')).slice(-MAX_STREAM_CHARS)
+      aiStreamChars.value = data.chars || 0
+    })
+
     const offAnalysisCost = ws.on('analysis_cost', (data) => {
       if (analysisId.value && data.analysisId !== analysisId.value) return
       totalCredits.value = data.credits || 0
@@ -456,6 +476,8 @@ export function useAnalysis() {
       currentStep.value = 'complete'
       latestScreenshot.value = null
       agentReasoning.value = ''
+      aiStreamText.value = ''
+      aiStreamChars.value = 0
       timer.stop()
       clearLocalStorage()
 
@@ -497,7 +519,7 @@ export function useAnalysis() {
       loadPersistedSteps(data.analysisId)
     })
 
-    cleanups = [offProgress, offStepDetail, offAgentReasoning, offAgentScreenshot, offUserHint, offAnalysisCost, offTestProgress, offTestStepScreenshot, offCompleted, offFailed]
+    cleanups = [offProgress, offStepDetail, offAgentReasoning, offAgentScreenshot, offUserHint, offAIStream, offAnalysisCost, offTestProgress, offTestStepScreenshot, offCompleted, offFailed]
 
     startStatusPolling()
   }
@@ -542,6 +564,8 @@ export function useAnalysis() {
     liveAgentSteps.value = []
     latestScreenshot.value = null
     agentReasoning.value = ''
+    aiStreamText.value = ''
+    aiStreamChars.value = 0
     testRunId.value = null
     testStepScreenshots.value = []
     testFlowProgress.value = []
@@ -644,7 +668,7 @@ export function useAnalysis() {
         currentStep.value = statusData.step || ''
 
         // Restore agent mode from persisted state or infer from step name
-        const agentStepNames = ['agent_start', 'agent_step', 'agent_action', 'agent_adaptive', 'agent_timeout_extend', 'agent_done', 'agent_synthesize', 'synthesis_retry', 'agent_reasoning', 'agent_step_detail', 'agent_screenshot']
+        const agentStepNames = ['agent_start', 'agent_step', 'agent_action', 'agent_adaptive', 'agent_timeout_extend', 'agent_context_compact', 'agent_done', 'agent_synthesize', 'synthesis_retry', 'agent_reasoning', 'agent_step_detail', 'agent_screenshot']
         if (parsed.agentMode || agentStepNames.includes(statusData.step)) {
           agentMode.value = true
         }
@@ -772,6 +796,8 @@ export function useAnalysis() {
     liveAgentSteps,
     latestScreenshot,
     agentReasoning,
+    aiStreamText,
+    aiStreamChars,
     userHints,
     hintCooldown,
     agentStepCurrent,
@@ -118,6 +118,7 @@ export const testsApi = {
   list: () => api.get('/tests'),
   get: (id) => api.get(`/tests/${id}`),
   live: (id) => api.get(`/tests/${id}/live`),
+  harUrl: (id) => authUrl(`/api/tests/${id}/har`),
   run: (payload) => api.post('/tests/run', payload),
   delete: (id) => api.delete(`/tests/${id}`),
   deleteBatch: (ids) => api.post('/tests/delete-batch', { ids }),
@@ -146,6 +147,10 @@ export const templatesApi = {
   list: () => api.get('/templates'),
 }
 
+export const viewportsApi = {
+  list: (projectId) => api.get('/viewports', { params: projectId ? { projectId } : {} }),
+}
+
 export const testPlansApi = {
   list: () => api.get('/test-plans'),
   get: (id, params) => api.get(`/test-plans/${id}`, { params }),
@@ -172,6 +177,7 @@ export const analysesApi = {
   flows: (id) => api.get(`/analyses/${id}/flows`),
   stepScreenshotUrl: (id, stepNumber) => authUrl(`/api/analyses/${id}/steps/${stepNumber}/screenshot`),
   screenshotUrl: (id, filename) => authUrl(`/api/analyses/${id}/screenshots/${encodeURIComponent(filename)}`),
+  harUrl: (id, device) => authUrl(`/api/analyses/${id}/har${device ? `?device=${encodeURIComponent(device)}` : ''}`),
   exportUrl: (id, format = 'json') => `/api/analyses/${id}/export?format=${format}`,
   export: (id, format = 'json') =>
     axios.get(`/api/analyses/${id}/export?format=${format}`, {
@@ -1,4 +1,7 @@
-export const VIEWPORT_PRESETS = [
+import { ref } from 'vue'
+import { viewportsApi } from '@/lib/api'
+
+const PORTRAIT_PRESETS = [
   // Desktop
   { name: 'desktop-hd', label: 'Desktop HD', category: 'Desktop', width: 1920, height: 1080, devicePixelRatio: 1 },
   { name: 'desktop-std', label: 'Desktop Standard', category: 'Desktop', width: 1280, height: 720, devicePixelRatio: 1 },
@@ -38,6 +41,16 @@ export const VIEWPORT_PRESETS = [
   { name: 'pixel-tablet', label: 'Pixel Tablet', category: 'Android Tablet', width: 800, height: 1280, devicePixelRatio: 2 },
 ]
 
+const TOUCH_CATEGORIES = ['iPhone', 'iPad', 'Android', 'Android Tablet']
+
+// Every phone and tablet also gets a landscape variant, matching pkg/scout/viewports.go.
+export const VIEWPORT_PRESETS = [
+  ...PORTRAIT_PRESETS,
+  ...PORTRAIT_PRESETS
+    .filter(p => TOUCH_CATEGORIES.includes(p.category) && p.width <= p.height)
+    .map(p => ({ ...p, name: `${p.name}-landscape`, label: `${p.label} (Landscape)`, width: p.height, height: p.width })),
+]
+
 // Subset shown in "Recommended" group at the top of the selector
 export const RECOMMENDED_VIEWPORTS = [
   'desktop-std',
@@ -49,13 +62,26 @@ export const RECOMMENDED_VIEWPORTS = [
 
 export const DEFAULT_VIEWPORT = 'desktop-std' // 1280x720
 
+// Presets served by GET /api/viewports: the built-in ones plus configured and
+// project presets. Falls back to the static list until loaded.
+const presets = ref(VIEWPORT_PRESETS)
+
+export async function loadViewports(projectId) {
+  try {
+    const data = await viewportsApi.list(projectId)
+    if (data?.viewports?.length) presets.value = data.viewports
+  } catch {
+    // Keep the built-in presets
+  }
+}
+
 export function getViewportByName(name) {
-  return VIEWPORT_PRESETS.find(p => p.name === name) || null
+  return presets.value.find(p => p.name === name) || null
 }
 
 export function getViewportCategories() {
-  const cats = [...new Set(VIEWPORT_PRESETS.map(p => p.category))]
-  return cats.map(cat => ({ name: cat, presets: VIEWPORT_PRESETS.filter(p => p.category === cat) }))
+  const cats = [...new Set(presets.value.map(p => p.category))]
+  return cats.map(cat => ({ name: cat, presets: presets.value.filter(p => p.category === cat) }))
 }
 
 export function getRecommendedViewports() {